@@ -34,7 +34,7 @@ func departures(cmd *cobra.Command, args []string) error {
 	stopID := args[0]
 
 	type DepartureProvider interface {
-		Departures(string, time.Time, time.Duration, int, string, int8, []model.RouteType) ([]model.Departure, error)
+		Departures(string, time.Time, time.Duration, int, string, int8, []model.RouteType, ...string) ([]model.Departure, error)
 	}
 
 	var provider DepartureProvider
@@ -49,7 +49,7 @@ func departures(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	departures, err := provider.Departures(stopID, time.Now(), window, limit, routeID, int8(direction), nil)
+	departures, err := provider.Departures(stopID, time.Now(), window, limit, routeID, int8(direction), nil, langs...)
 	if err != nil {
 		return err
 	}
@@ -27,6 +27,10 @@ var (
 	staticHeaders   []string
 	realtimeHeaders []string
 	sharedHeaders   []string
+	langs           []string
+	lenient         bool
+	encoding        string
+	extras          bool
 )
 
 func init() {
@@ -53,6 +57,16 @@ func init() {
 		[]string{},
 		"GTFS HTTP header (shared between static and realtime)",
 	)
+	rootCmd.PersistentFlags().StringSliceVarP(
+		&langs,
+		"lang",
+		"",
+		[]string{},
+		"Preferred languages for translated names, in order of preference",
+	)
+	rootCmd.PersistentFlags().BoolVarP(&lenient, "lenient", "", false, "Drop bad rows from the static feed rather than rejecting it")
+	rootCmd.PersistentFlags().StringVarP(&encoding, "encoding", "", "", "Character encoding of the static feed, e.g. windows-1252 (detected if not set)")
+	rootCmd.PersistentFlags().BoolVarP(&extras, "extras", "", false, "Keep values in columns not defined by the GTFS spec")
 	rootCmd.AddCommand(departuresCmd)
 }
 
@@ -103,6 +117,24 @@ func LoadStaticFeed() (*gtfs.Static, error) {
 
 	static, err := manager.LoadStaticAsync("cli", staticURL, headers, time.Now())
 	if err != nil {
+		if lenient {
+			err = manager.SetLenient(staticURL, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if encoding != "" {
+			err = manager.SetEncoding(staticURL, encoding)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if extras {
+			err = manager.SetExtras(staticURL, true)
+			if err != nil {
+				return nil, err
+			}
+		}
 		err = manager.Refresh(context.Background())
 		if err != nil {
 			return nil, err
@@ -54,7 +54,7 @@ func stops(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	stops, err := static.NearbyStops(lat, lng, limit, nil)
+	stops, err := static.NearbyStops(lat, lng, limit, nil, langs...)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"tidbyt.dev/gtfs"
+	"tidbyt.dev/gtfs/downloader"
+	"tidbyt.dev/gtfs/parse"
+	"tidbyt.dev/gtfs/validate"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [path]",
+	Short: "Validates a static GTFS feed",
+	Long:  "Validates a static GTFS feed, from a zip file or directory at path, or from --static-url",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  validateFeed,
+}
+
+var validateJSON bool
+
+func init() {
+	validateCmd.Flags().BoolVarP(&validateJSON, "json", "", false, "Print report as JSON")
+	rootCmd.AddCommand(validateCmd)
+}
+
+func validateFeed(cmd *cobra.Command, args []string) error {
+	var fsys fs.FS
+	if len(args) == 1 {
+		info, err := os.Stat(args[0])
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			fsys = os.DirFS(args[0])
+		} else {
+			z, err := zip.OpenReader(args[0])
+			if err != nil {
+				return fmt.Errorf("opening zip: %w", err)
+			}
+			defer z.Close()
+			fsys = z
+		}
+	} else {
+		if staticURL == "" {
+			return fmt.Errorf("path or static URL is required")
+		}
+
+		headers, err := parseHeaders(append(staticHeaders, sharedHeaders...))
+		if err != nil {
+			return fmt.Errorf("invalid header: %w", err)
+		}
+
+		body, err := downloader.HTTPGet(context.Background(), staticURL, headers, downloader.GetOptions{
+			Timeout: gtfs.DefaultStaticTimeout,
+			MaxSize: gtfs.DefaultStaticMaxSize,
+		})
+		if err != nil {
+			return fmt.Errorf("downloading: %w", err)
+		}
+
+		fsys, err = zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			return fmt.Errorf("unzipping: %w", err)
+		}
+	}
+
+	enc, err := parse.LookupEncoding(encoding)
+	if err != nil {
+		return err
+	}
+
+	report, err := validate.Validate(fsys, validate.Options{Encoding: enc})
+	if err != nil {
+		return err
+	}
+
+	if validateJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, notice := range report.Notices {
+			fmt.Println(notice)
+		}
+		fmt.Printf(
+			"%d errors, %d warnings, %d infos\n",
+			report.Count(validate.SeverityError),
+			report.Count(validate.SeverityWarning),
+			report.Count(validate.SeverityInfo),
+		)
+	}
+
+	if report.HasErrors() {
+		return fmt.Errorf("feed has %d errors", report.Count(validate.SeverityError))
+	}
+
+	return nil
+}
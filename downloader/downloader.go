@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -20,16 +21,37 @@ type Downloader interface {
 	Get(ctx context.Context, url string, headers map[string]string, options GetOptions) ([]byte, error)
 }
 
+// A Downloader that can also stream a file to a writer as it's
+// downloaded, e.g. to a temp file, without holding it in
+// memory. Returns the number of bytes written.
+type FileDownloader interface {
+	Downloader
+	GetFile(ctx context.Context, url string, headers map[string]string, options GetOptions, w io.Writer) (int64, error)
+}
+
 // Gets a file. Doesn't cache. Provided as convenience for
 // implementing custom Downloaders.
 func HTTPGet(ctx context.Context, url string, headers map[string]string, options GetOptions) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	_, err := HTTPGetFile(ctx, url, headers, options, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Gets a file, writing it to w as it's downloaded. Doesn't
+// cache. Provided as convenience for implementing custom
+// FileDownloaders.
+func HTTPGetFile(ctx context.Context, url string, headers map[string]string, options GetOptions, w io.Writer) (int64, error) {
 	client := &http.Client{
 		Timeout: options.Timeout,
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return 0, fmt.Errorf("creating request: %w", err)
 	}
 
 	for k, v := range headers {
@@ -38,11 +60,11 @@ func HTTPGet(ctx context.Context, url string, headers map[string]string, options
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("making request: %w", err)
+		return 0, fmt.Errorf("making request: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status %d", resp.StatusCode)
+		return 0, fmt.Errorf("status %d", resp.StatusCode)
 	}
 
 	defer resp.Body.Close()
@@ -52,10 +74,10 @@ func HTTPGet(ctx context.Context, url string, headers map[string]string, options
 		reader = io.LimitReader(resp.Body, int64(options.MaxSize))
 	}
 
-	body, err := io.ReadAll(reader)
+	n, err := io.Copy(w, reader)
 	if err != nil {
-		return nil, fmt.Errorf("reading body: %w", err)
+		return n, fmt.Errorf("reading body: %w", err)
 	}
 
-	return body, nil
+	return n, nil
 }
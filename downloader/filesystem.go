@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"sync"
 	"time"
@@ -84,6 +85,27 @@ func (f *Filesystem) Get(
 	return body, nil
 }
 
+// Streams a file to w. Cached files are held in memory, so only
+// uncached downloads are streamed.
+func (f *Filesystem) GetFile(
+	ctx context.Context,
+	url string,
+	headers map[string]string,
+	options GetOptions,
+	w io.Writer,
+) (int64, error) {
+	if options.Cache {
+		body, err := f.Get(ctx, url, headers, options)
+		if err != nil {
+			return 0, err
+		}
+		n, err := w.Write(body)
+		return int64(n), err
+	}
+
+	return HTTPGetFile(ctx, url, headers, options, w)
+}
+
 func (f *Filesystem) load() error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
@@ -2,6 +2,7 @@ package downloader
 
 import (
 	"context"
+	"io"
 	"sync"
 	"time"
 )
@@ -57,3 +58,24 @@ func (d *Memory) Get(
 
 	return body, nil
 }
+
+// Streams a file to w. Cached files are served from memory, but
+// uncached downloads are never held there.
+func (d *Memory) GetFile(
+	ctx context.Context,
+	url string,
+	headers map[string]string,
+	options GetOptions,
+	w io.Writer,
+) (int64, error) {
+	if options.Cache {
+		body, err := d.Get(ctx, url, headers, options)
+		if err != nil {
+			return 0, err
+		}
+		n, err := w.Write(body)
+		return int64(n), err
+	}
+
+	return HTTPGetFile(ctx, url, headers, options, w)
+}
@@ -0,0 +1,306 @@
+package gtfs
+
+import (
+	"fmt"
+	"time"
+
+	"tidbyt.dev/gtfs/model"
+)
+
+// Prices a multi-leg itinerary using Fares v2 (fare_leg_rules.txt
+// and fare_transfer_rules.txt).
+//
+// Each leg is matched against the leg rules by network and by the
+// areas of its origin and destination stops, including the areas of
+// their parent stations. A route's network comes from
+// route_networks.txt, or from the network_id column of routes.txt
+// when the feed has no route networks. Unless fare_leg_rules.txt has
+// a rule_priority column, rules with blank fields only apply when no
+// rule matches the field explicitly. With the column, blank fields
+// match anything and only the highest priority rules are kept. When
+// several products match, the cheapest is used.
+//
+// Transfer rules are then applied between consecutive legs. Transfer
+// counts and duration limits are evaluated against the sub-journey,
+// i.e. the run of legs connected by transfers, starting from its
+// first leg.
+//
+// Timeframes are not supported. Returns an error if a leg can't be
+// priced, or if the products involved use different currencies.
+func (s Static) CalculateFare(legs []model.FareLeg) (*model.FareCalculation, error) {
+	legRules, err := s.Reader.FareLegRules()
+	if err != nil {
+		return nil, fmt.Errorf("getting fare leg rules: %w", err)
+	}
+	transferRules, err := s.Reader.FareTransferRules()
+	if err != nil {
+		return nil, fmt.Errorf("getting fare transfer rules: %w", err)
+	}
+
+	products, err := s.Reader.FareProducts()
+	if err != nil {
+		return nil, fmt.Errorf("getting fare products: %w", err)
+	}
+	productsByID := map[string][]model.FareProduct{}
+	for _, p := range products {
+		productsByID[p.ID] = append(productsByID[p.ID], p)
+	}
+
+	routeNetworks, err := s.Reader.RouteNetworks()
+	if err != nil {
+		return nil, fmt.Errorf("getting route networks: %w", err)
+	}
+	networkByRoute := map[string]string{}
+	for _, rn := range routeNetworks {
+		networkByRoute[rn.RouteID] = rn.NetworkID
+	}
+	if len(routeNetworks) == 0 {
+		routes, err := s.Reader.Routes()
+		if err != nil {
+			return nil, fmt.Errorf("getting routes: %w", err)
+		}
+		for _, route := range routes {
+			networkByRoute[route.ID] = route.NetworkID
+		}
+	}
+
+	stopAreas, err := s.Reader.StopAreas()
+	if err != nil {
+		return nil, fmt.Errorf("getting stop areas: %w", err)
+	}
+	areasByStop := map[string][]string{}
+	for _, sa := range stopAreas {
+		areasByStop[sa.StopID] = append(areasByStop[sa.StopID], sa.AreaID)
+	}
+
+	calc := &model.FareCalculation{}
+
+	for i, leg := range legs {
+		fromAreas, err := s.fareAreas(leg.FromStopID, areasByStop)
+		if err != nil {
+			return nil, err
+		}
+		toAreas, err := s.fareAreas(leg.ToStopID, areasByStop)
+		if err != nil {
+			return nil, err
+		}
+
+		matched := matchFareLegRules(legRules, networkByRoute[leg.RouteID], fromAreas, toAreas)
+
+		found := false
+		legFare := model.FareCalculationLeg{}
+		for _, rule := range matched {
+			product, ok := cheapestFareProduct(productsByID[rule.FareProductID])
+			if !ok {
+				continue
+			}
+			if !found || product.Amount < legFare.Product.Amount {
+				legFare.LegGroupID = rule.LegGroupID
+				legFare.Product = product
+				legFare.Amount = product.Amount
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no fare for leg %d", i)
+		}
+
+		calc.Legs = append(calc.Legs, legFare)
+	}
+
+	// Apply transfer rules between consecutive legs
+	subJourneyStart := 0
+	transferCount := 0
+	for i := 1; i < len(legs); i++ {
+		prev := &calc.Legs[i-1]
+		cur := &calc.Legs[i]
+
+		var best *model.FareTransferRule
+		var bestAmount, bestTransferAmount float64
+		for _, rule := range matchFareTransferRules(transferRules, prev.LegGroupID, cur.LegGroupID) {
+			if rule.TransferCount != -1 && transferCount+1 > rule.TransferCount {
+				continue
+			}
+			if rule.DurationLimit > 0 && fareTransferDuration(legs[subJourneyStart], legs[i], rule.DurationLimitType) > time.Duration(rule.DurationLimit)*time.Second {
+				continue
+			}
+
+			transferAmount := 0.0
+			if rule.FareProductID != "" {
+				product, ok := cheapestFareProduct(productsByID[rule.FareProductID])
+				if !ok {
+					continue
+				}
+				transferAmount = product.Amount
+			}
+
+			// What the transfer adds to the total under
+			// this rule. For AB transfers, the transfer
+			// product replaces the previous leg's fare.
+			amount := transferAmount
+			switch rule.FareTransferType {
+			case model.FareTransferTypeAPlusABPlusB:
+				amount += cur.Product.Amount
+			case model.FareTransferTypeAB:
+				amount -= prev.Amount
+			}
+
+			if best == nil || amount < bestAmount {
+				r := rule
+				best = &r
+				bestAmount = amount
+				bestTransferAmount = transferAmount
+			}
+		}
+
+		if best == nil {
+			subJourneyStart = i
+			transferCount = 0
+			continue
+		}
+
+		// Only the fares of the legs are replaced, keeping
+		// any transfer charged on the way to the previous leg.
+		cur.Transfer = best
+		cur.TransferAmount = bestTransferAmount
+		switch best.FareTransferType {
+		case model.FareTransferTypeAPlusAB:
+			cur.Amount = 0
+		case model.FareTransferTypeAB:
+			prev.Amount = 0
+			cur.Amount = 0
+		}
+		transferCount++
+	}
+
+	for _, leg := range calc.Legs {
+		if calc.Currency == "" {
+			calc.Currency = leg.Product.Currency
+		} else if leg.Product.Currency != calc.Currency {
+			return nil, fmt.Errorf("mixed currencies %s and %s", calc.Currency, leg.Product.Currency)
+		}
+		calc.Amount += leg.Amount + leg.TransferAmount
+	}
+
+	return calc, nil
+}
+
+// Returns the areas of a stop, including those of its parent
+// station.
+func (s Static) fareAreas(stopID string, areasByStop map[string][]string) (map[string]bool, error) {
+	stop, err := s.Reader.Stop(stopID)
+	if err != nil {
+		return nil, fmt.Errorf("getting stop: %w", err)
+	}
+	if stop == nil {
+		return nil, fmt.Errorf("unknown stop '%s'", stopID)
+	}
+
+	areas := map[string]bool{}
+	for _, areaID := range areasByStop[stop.ID] {
+		areas[areaID] = true
+	}
+	if stop.ParentStation != "" {
+		for _, areaID := range areasByStop[stop.ParentStation] {
+			areas[areaID] = true
+		}
+	}
+
+	return areas, nil
+}
+
+func matchFareLegRules(rules []model.FareLegRule, networkID string, fromAreas map[string]bool, toAreas map[string]bool) []model.FareLegRule {
+	if len(rules) > 0 && rules[0].HasRulePriority {
+		matched := []model.FareLegRule{}
+		maxPriority := 0
+		for _, r := range rules {
+			if r.NetworkID != "" && r.NetworkID != networkID {
+				continue
+			}
+			if r.FromAreaID != "" && !fromAreas[r.FromAreaID] {
+				continue
+			}
+			if r.ToAreaID != "" && !toAreas[r.ToAreaID] {
+				continue
+			}
+			if len(matched) == 0 || r.RulePriority > maxPriority {
+				matched = []model.FareLegRule{}
+				maxPriority = r.RulePriority
+			}
+			if r.RulePriority == maxPriority {
+				matched = append(matched, r)
+			}
+		}
+		return matched
+	}
+
+	matched := filterFareRules(rules,
+		func(r model.FareLegRule) string { return r.NetworkID },
+		func(id string) bool { return id == networkID },
+	)
+	matched = filterFareRules(matched,
+		func(r model.FareLegRule) string { return r.FromAreaID },
+		func(id string) bool { return fromAreas[id] },
+	)
+	matched = filterFareRules(matched,
+		func(r model.FareLegRule) string { return r.ToAreaID },
+		func(id string) bool { return toAreas[id] },
+	)
+	return matched
+}
+
+func matchFareTransferRules(rules []model.FareTransferRule, fromLegGroupID string, toLegGroupID string) []model.FareTransferRule {
+	matched := filterFareRules(rules,
+		func(r model.FareTransferRule) string { return r.FromLegGroupID },
+		func(id string) bool { return id == fromLegGroupID },
+	)
+	matched = filterFareRules(matched,
+		func(r model.FareTransferRule) string { return r.ToLegGroupID },
+		func(id string) bool { return id == toLegGroupID },
+	)
+	return matched
+}
+
+// Keeps the rules where field matches. If there are none, the rules
+// where field is blank are kept instead.
+func filterFareRules[T any](rules []T, field func(T) string, match func(string) bool) []T {
+	exact := []T{}
+	blank := []T{}
+	for _, r := range rules {
+		value := field(r)
+		if value == "" {
+			blank = append(blank, r)
+		} else if match(value) {
+			exact = append(exact, r)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return blank
+}
+
+func cheapestFareProduct(products []model.FareProduct) (model.FareProduct, bool) {
+	if len(products) == 0 {
+		return model.FareProduct{}, false
+	}
+	cheapest := products[0]
+	for _, p := range products[1:] {
+		if p.Amount < cheapest.Amount {
+			cheapest = p
+		}
+	}
+	return cheapest, true
+}
+
+func fareTransferDuration(from model.FareLeg, to model.FareLeg, limitType model.DurationLimitType) time.Duration {
+	switch limitType {
+	case model.DurationLimitTypeDepartureDeparture:
+		return to.Departure.Sub(from.Departure)
+	case model.DurationLimitTypeArrivalDeparture:
+		return to.Departure.Sub(from.Arrival)
+	case model.DurationLimitTypeArrivalArrival:
+		return to.Arrival.Sub(from.Arrival)
+	}
+	return to.Arrival.Sub(from.Departure)
+}
@@ -0,0 +1,162 @@
+package gtfs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+// Distance from a stop in a location group within which on-demand
+// service at the group is considered available, in kilometers.
+const flexStopRadius = 0.4
+
+// Returns on-demand service picking up at lat,lon at time t, as per
+// GTFS-Flex. Service is available when lat,lon is within a zone in
+// locations.geojson, or near a stop in a location group, or near a
+// stop with a pickup window of its own, and t is within the pickup
+// window of a trip running that day. Each result
+// carries the booking rule for the pickup, if any, telling how to
+// book.
+//
+// Windows are given in t's timezone. Results are ordered by window
+// start, then by route and trip ID.
+func (s Static) OnDemandServices(lat float64, lon float64, t time.Time) ([]model.OnDemandService, error) {
+	locations, err := s.Reader.Locations()
+	if err != nil {
+		return nil, fmt.Errorf("getting locations: %w", err)
+	}
+	locationIDs := []string{}
+	for _, location := range locations {
+		if locationContains(location, lat, lon) {
+			locationIDs = append(locationIDs, location.ID)
+		}
+	}
+
+	stops, err := s.Reader.Stops()
+	if err != nil {
+		return nil, fmt.Errorf("getting stops: %w", err)
+	}
+	stopIDs := []string{}
+	nearStop := map[string]bool{}
+	for _, stop := range stops {
+		if storage.HaversineDistance(lat, lon, stop.Lat, stop.Lon) <= flexStopRadius {
+			nearStop[stop.ID] = true
+			stopIDs = append(stopIDs, stop.ID)
+		}
+	}
+
+	groupStops, err := s.Reader.LocationGroupStops()
+	if err != nil {
+		return nil, fmt.Errorf("getting location group stops: %w", err)
+	}
+	groupIDs := []string{}
+	groupSeen := map[string]bool{}
+	for _, gs := range groupStops {
+		if nearStop[gs.StopID] && !groupSeen[gs.LocationGroupID] {
+			groupSeen[gs.LocationGroupID] = true
+			groupIDs = append(groupIDs, gs.LocationGroupID)
+		}
+	}
+
+	if len(locationIDs) == 0 && len(groupIDs) == 0 && len(stopIDs) == 0 {
+		return []model.OnDemandService{}, nil
+	}
+
+	rules, err := s.Reader.BookingRules()
+	if err != nil {
+		return nil, fmt.Errorf("getting booking rules: %w", err)
+	}
+	ruleByID := map[string]*model.BookingRule{}
+	for i := range rules {
+		ruleByID[rules[i].ID] = &rules[i]
+	}
+
+	// Windows are relative to noon minus 12h of the service day,
+	// in the agency's timezone. Yesterday's service can run past
+	// midnight.
+	local := t.In(s.location)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+
+	services := []model.OnDemandService{}
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		noon := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, s.location)
+		offset := t.Sub(noon) + 12*time.Hour
+
+		serviceIDs, err := s.Reader.ActiveServices(day.Format("20060102"))
+		if err != nil {
+			return nil, fmt.Errorf("getting active services: %w", err)
+		}
+		if len(serviceIDs) == 0 {
+			continue
+		}
+
+		events, err := s.Reader.FlexStopTimes(storage.FlexStopTimeFilter{
+			LocationIDs:      locationIDs,
+			LocationGroupIDs: groupIDs,
+			StopIDs:          stopIDs,
+			ServiceIDs:       serviceIDs,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting flex stop times: %w", err)
+		}
+
+		for _, event := range events {
+			st := event.StopTime
+			if st.PickupType == model.PickupDropOffNone {
+				continue
+			}
+			if offset < st.StartWindowTime() || offset > st.EndWindowTime() {
+				continue
+			}
+
+			services = append(services, model.OnDemandService{
+				RouteID:         event.Route.ID,
+				TripID:          event.Trip.ID,
+				StopSequence:    st.StopSequence,
+				LocationID:      st.LocationID,
+				LocationGroupID: st.LocationGroupID,
+				StopID:          st.StopID,
+				WindowStart:     noon.Add(st.StartWindowTime() - 12*time.Hour).In(t.Location()),
+				WindowEnd:       noon.Add(st.EndWindowTime() - 12*time.Hour).In(t.Location()),
+				PickupType:      st.PickupType,
+				BookingRule:     ruleByID[st.PickupBookingRuleID],
+			})
+		}
+	}
+
+	sort.SliceStable(services, func(i, j int) bool {
+		if !services[i].WindowStart.Equal(services[j].WindowStart) {
+			return services[i].WindowStart.Before(services[j].WindowStart)
+		}
+		if services[i].RouteID != services[j].RouteID {
+			return services[i].RouteID < services[j].RouteID
+		}
+		return services[i].TripID < services[j].TripID
+	})
+
+	return services, nil
+}
+
+// Checks if lat,lon is within a location's zone. Uses the even-odd
+// rule, so holes are excluded from the polygons they're in.
+func locationContains(location model.Location, lat float64, lon float64) bool {
+	for _, polygon := range location.Polygons {
+		inside := false
+		for _, ring := range polygon {
+			for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+				a, b := ring[i], ring[j]
+				if (a[1] > lat) != (b[1] > lat) &&
+					lon < (b[0]-a[0])*(lat-a[1])/(b[1]-a[1])+a[0] {
+					inside = !inside
+				}
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,18 @@
 package gtfs
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"math/rand"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -37,6 +43,10 @@ type Manager struct {
 	StaticRefreshInterval time.Duration
 	Downloader            downloader.Downloader
 
+	// Directory for temp files holding static feeds while they're
+	// parsed. Defaults to os.TempDir().
+	StaticTempDir string
+
 	storage storage.Storage
 }
 
@@ -165,6 +175,63 @@ func (m *Manager) Refresh(ctx context.Context) error {
 	return errors.Join(errs...)
 }
 
+// A downloaded static feed, held either in memory or in a temp file.
+type staticData struct {
+	io.ReaderAt
+	size int64
+	hash string
+	file *os.File
+}
+
+// Removes the temp file, if any.
+func (d *staticData) Close() error {
+	if d.file == nil {
+		return nil
+	}
+	d.file.Close()
+	return os.Remove(d.file.Name())
+}
+
+// Downloads a static feed and computes its hash. If the Downloader
+// supports it, the feed is streamed to a temp file in StaticTempDir,
+// keeping memory use bounded regardless of feed size.
+func (m *Manager) downloadStatic(feedURL string, headers map[string]string) (*staticData, error) {
+	options := downloader.GetOptions{
+		Cache:   false,
+		Timeout: m.StaticTimeout,
+		MaxSize: m.StaticMaxSize,
+	}
+
+	fileDownloader, ok := m.Downloader.(downloader.FileDownloader)
+	if !ok {
+		body, err := m.Downloader.Get(context.Background(), feedURL, headers, options)
+		if err != nil {
+			return nil, err
+		}
+		return &staticData{
+			ReaderAt: bytes.NewReader(body),
+			size:     int64(len(body)),
+			hash:     fmt.Sprintf("%x", sha256.Sum256(body)),
+		}, nil
+	}
+
+	f, err := os.CreateTemp(m.StaticTempDir, "gtfs-static-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp file: %w", err)
+	}
+	data := &staticData{ReaderAt: f, file: f}
+
+	hasher := sha256.New()
+	data.size, err = fileDownloader.GetFile(context.Background(), feedURL, headers, options, io.MultiWriter(f, hasher))
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+	data.hash = fmt.Sprintf("%x", hasher.Sum(nil))
+
+	return data, nil
+}
+
 // Downloads a requested URL. A randomly selected consumer's headers
 // will be used. If the data is already in storage, a copy may be made
 // to ensure a FeedMetadata record with the hash and this URL
@@ -182,20 +249,12 @@ func (m *Manager) processRequest(
 	}
 
 	// Download the feed and compute its hash
-	body, err := m.Downloader.Get(
-		context.Background(),
-		req.URL,
-		headers,
-		downloader.GetOptions{
-			Cache:   false,
-			Timeout: m.StaticTimeout,
-			MaxSize: m.StaticMaxSize,
-		},
-	)
+	data, err := m.downloadStatic(req.URL, headers)
 	if err != nil {
 		return fmt.Errorf("downloading feed at %s: %w", req.URL, err)
 	}
-	hash := fmt.Sprintf("%x", sha256.Sum256(body))
+	defer data.Close()
+	hash := feedHash(data.hash, req)
 
 	// The data we just downloaded may already exist in storage.
 	feeds := feedByHash[hash]
@@ -230,13 +289,14 @@ func (m *Manager) processRequest(
 		}
 		defer writer.Close()
 
-		metadata, err := parse.ParseStatic(writer, body)
+		metadata, err := m.parseStatic(writer, data, req)
 		if err != nil {
 
 			// If the downloaded data is broken (parse
 			// failed), we still mark the request as
-			// refreshed.
+			// refreshed, and record why it failed.
 			req.RefreshedAt = time.Now().UTC()
+			req.LastFailure = feedFailure(req.RefreshedAt, err)
 			reqErr := m.storage.WriteFeedRequest(req)
 			if reqErr != nil {
 				return errors.Join(
@@ -263,6 +323,285 @@ func (m *Manager) processRequest(
 
 	// Mark the request as refreshed.
 	req.RefreshedAt = time.Now().UTC()
+	req.LastFailure = nil
+	err = m.storage.WriteFeedRequest(req)
+	if err != nil {
+		return fmt.Errorf("writing feed request: %w", err)
+	}
+
+	return nil
+}
+
+// Describes a failure to parse a feed, with details from the
+// ParseError if there is one.
+func feedFailure(failedAt time.Time, err error) *storage.FeedFailure {
+	failure := &storage.FeedFailure{
+		FailedAt: failedAt,
+		Message:  err.Error(),
+	}
+
+	var parseErr *parse.ParseError
+	if errors.As(err, &parseErr) {
+		failure.Code = string(parseErr.Code)
+		failure.File = parseErr.File
+		failure.Row = parseErr.Row
+		failure.Column = parseErr.Column
+		failure.Value = parseErr.Value
+	}
+
+	return failure
+}
+
+// Imports a static GTFS feed from a local zip file or unzipped feed
+// directory into storage, without going through the Downloader. The
+// feed is recorded under a file:// URL of its absolute path.
+func (m *Manager) ImportStaticFile(path string) (*Static, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving path: %w", err)
+	}
+	feedURL := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat: %w", err)
+	}
+	if info.IsDir() {
+		return m.ImportStatic(feedURL, os.DirFS(path))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening: %w", err)
+	}
+	defer f.Close()
+
+	// Zip files are hashed just like downloaded feeds, so the
+	// same feed is only stored once regardless of origin.
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return nil, fmt.Errorf("reading: %w", err)
+	}
+
+	z, err := zip.NewReader(f, size)
+	if err != nil {
+		return nil, fmt.Errorf("unzipping: %w", err)
+	}
+
+	return m.importStatic(feedURL, fmt.Sprintf("%x", hasher.Sum(nil)), z)
+}
+
+// Imports a static GTFS feed from a filesystem, e.g. an unzipped feed
+// directory opened with os.DirFS(), into storage. The feed is recorded
+// under feedURL, which needn't be fetchable. Imported feeds have no
+// FeedRequest, so aren't refreshed.
+//
+// If a feed with identical files is already in storage, it is not
+// parsed again.
+func (m *Manager) ImportStatic(feedURL string, fsys fs.FS) (*Static, error) {
+	hash, err := hashFS(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("hashing: %w", err)
+	}
+
+	return m.importStatic(feedURL, hash, fsys)
+}
+
+func (m *Manager) importStatic(feedURL string, hash string, fsys fs.FS) (*Static, error) {
+	feeds, err := m.storage.ListFeeds(storage.ListFeedsFilter{Hash: hash})
+	if err != nil {
+		return nil, fmt.Errorf("listing feeds: %w", err)
+	}
+
+	var metadata *storage.FeedMetadata
+	for _, feed := range feeds {
+		if feed.URL == feedURL {
+			metadata = feed
+			break
+		}
+	}
+
+	if metadata == nil && len(feeds) > 0 {
+		// It's in storage, but for a different URL. Add a
+		// metadata record for this URL.
+		metadata = feeds[0]
+		metadata.URL = feedURL
+
+		err = m.storage.WriteFeedMetadata(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("writing metadata: %w", err)
+		}
+	} else if metadata == nil {
+		writer, err := m.storage.GetWriter(hash)
+		if err != nil {
+			return nil, fmt.Errorf("getting writer: %w", err)
+		}
+		defer writer.Close()
+
+		metadata, err = parse.ParseStaticFS(writer, fsys)
+		if err != nil {
+			return nil, fmt.Errorf("parsing: %w", err)
+		}
+
+		metadata.Hash = hash
+		metadata.URL = feedURL
+		metadata.RetrievedAt = time.Now().UTC()
+
+		err = m.storage.WriteFeedMetadata(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("writing metadata: %w", err)
+		}
+	}
+
+	reader, err := m.storage.GetReader(hash)
+	if err != nil {
+		return nil, fmt.Errorf("getting reader: %w", err)
+	}
+	static, err := NewStatic(reader, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("creating static: %w", err)
+	}
+
+	return static, nil
+}
+
+// Hashes all regular files in a filesystem, along with their paths.
+func hashFS(fsys fs.FS) (string, error) {
+	hasher := sha256.New()
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(hasher, "%s\x00%d\x00", path, info.Size())
+		_, err = io.Copy(hasher, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// The same data parsed leniently, with an encoding override, or with
+// extras captured, is stored apart from what was parsed without.
+func feedHash(dataHash string, req storage.FeedRequest) string {
+	if !req.Lenient && req.Encoding == "" && !req.Extras {
+		return dataHash
+	}
+	options := fmt.Sprintf("%s\x00%t\x00%s\x00%t", dataHash, req.Lenient, req.Encoding, req.Extras)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(options)))
+}
+
+// Parses a downloaded static feed, with the options set on its
+// request.
+func (m *Manager) parseStatic(writer storage.FeedWriter, data *staticData, req storage.FeedRequest) (*storage.FeedMetadata, error) {
+	z, err := zip.NewReader(data, data.size)
+	if err != nil {
+		return nil, &parse.ParseError{Code: parse.CodeUnreadableFeed, Err: fmt.Errorf("unzipping: %w", err)}
+	}
+	return parse.ParseStaticFSWithOptions(writer, z, parse.Options{
+		Lenient:  req.Lenient,
+		Encoding: parse.Encoding(req.Encoding),
+		Extras:   req.Extras,
+	})
+}
+
+// Sets whether the static feed at staticURL is parsed leniently,
+// dropping bad rows rather than rejecting the feed. The feed must
+// have been requested with LoadStaticAsync(). It will be downloaded
+// and parsed again on the next Refresh().
+//
+// Rows dropped are recorded in the feed's metadata.
+func (m *Manager) SetLenient(staticURL string, lenient bool) error {
+	reqs, err := m.storage.ListFeedRequests(staticURL)
+	if err != nil {
+		return fmt.Errorf("listing feed requests: %w", err)
+	}
+	if len(reqs) == 0 {
+		return fmt.Errorf("no request for %s", staticURL)
+	}
+
+	req := reqs[0]
+	req.Lenient = lenient
+	req.Consumers = nil
+	req.RefreshedAt = time.Unix(0, 0).UTC()
+
+	err = m.storage.WriteFeedRequest(req)
+	if err != nil {
+		return fmt.Errorf("writing feed request: %w", err)
+	}
+
+	return nil
+}
+
+// Sets the character encoding of the static feed at staticURL,
+// overriding detection, e.g. "windows-1252". A blank encoding
+// restores detection. The feed must have been requested with
+// LoadStaticAsync(). It will be downloaded and parsed again on the
+// next Refresh().
+func (m *Manager) SetEncoding(staticURL string, encoding string) error {
+	enc, err := parse.LookupEncoding(encoding)
+	if err != nil {
+		return err
+	}
+
+	reqs, err := m.storage.ListFeedRequests(staticURL)
+	if err != nil {
+		return fmt.Errorf("listing feed requests: %w", err)
+	}
+	if len(reqs) == 0 {
+		return fmt.Errorf("no request for %s", staticURL)
+	}
+
+	req := reqs[0]
+	req.Encoding = string(enc)
+	req.Consumers = nil
+	req.RefreshedAt = time.Unix(0, 0).UTC()
+
+	err = m.storage.WriteFeedRequest(req)
+	if err != nil {
+		return fmt.Errorf("writing feed request: %w", err)
+	}
+
+	return nil
+}
+
+// Sets whether values in columns not defined by the spec are
+// captured when parsing the static feed at staticURL. They're exposed
+// in the Extras of agencies, stops, routes and trips. The feed must
+// have been requested with LoadStaticAsync(). It will be downloaded
+// and parsed again on the next Refresh().
+func (m *Manager) SetExtras(staticURL string, extras bool) error {
+	reqs, err := m.storage.ListFeedRequests(staticURL)
+	if err != nil {
+		return fmt.Errorf("listing feed requests: %w", err)
+	}
+	if len(reqs) == 0 {
+		return fmt.Errorf("no request for %s", staticURL)
+	}
+
+	req := reqs[0]
+	req.Extras = extras
+	req.Consumers = nil
+	req.RefreshedAt = time.Unix(0, 0).UTC()
+
 	err = m.storage.WriteFeedRequest(req)
 	if err != nil {
 		return fmt.Errorf("writing feed request: %w", err)
@@ -318,10 +657,21 @@ func feedActive(feed *storage.FeedMetadata, now time.Time) (bool, error) {
 		feedTz,
 	).Format("20060102")
 
-	if feed.CalendarStartDate > todayThere {
+	// The publisher's feed_start_date and feed_end_date take
+	// precedence over the span of the calendar, when provided.
+	startDate := feed.CalendarStartDate
+	if feed.FeedStartDate != "" {
+		startDate = feed.FeedStartDate
+	}
+	endDate := feed.CalendarEndDate
+	if feed.FeedEndDate != "" {
+		endDate = feed.FeedEndDate
+	}
+
+	if startDate > todayThere {
 		return false, nil
 	}
-	if feed.CalendarEndDate < todayThere {
+	if endDate < todayThere {
 		return false, nil
 	}
 
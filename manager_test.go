@@ -2,13 +2,16 @@ package gtfs_test
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -622,6 +625,14 @@ func testManagerBrokenData(t *testing.T, strg storage.Storage) {
 	assert.Error(t, m.Refresh(context.Background()))
 	assert.Equal(t, []string{"/static.zip", "/static.zip", "/static.zip"}, server.Requests)
 
+	// The parse failure is recorded on the request.
+	reqs, err := strg.ListFeedRequests(server.Server.URL + "/static.zip")
+	require.NoError(t, err)
+	require.NotNil(t, reqs[0].LastFailure)
+	assert.Equal(t, "missing_file", reqs[0].LastFailure.Code)
+	assert.Equal(t, "calendar.txt", reqs[0].LastFailure.File)
+	assert.Equal(t, reqs[0].RefreshedAt, reqs[0].LastFailure.FailedAt)
+
 	// Since the last refresh failed due to a parse error (bad
 	// data), the manager will wait for the refresh interval
 	// before new requests are made.
@@ -639,6 +650,11 @@ func testManagerBrokenData(t *testing.T, strg storage.Storage) {
 	assert.NoError(t, m.Refresh(context.Background()))
 	assert.Equal(t, 4, len(server.Requests))
 
+	// Which clears the failure.
+	reqs, err = strg.ListFeedRequests(server.Server.URL + "/static.zip")
+	require.NoError(t, err)
+	assert.Nil(t, reqs[0].LastFailure)
+
 	// Data can be loaded
 	s, err := m.LoadStaticAsync("a", server.Server.URL+"/static.zip", nil, when)
 	require.NoError(t, err)
@@ -813,6 +829,44 @@ func testManagerLoadRealtime(t *testing.T, strg storage.Storage) {
 
 }
 
+// When feed_info.txt provides feed_start_date and feed_end_date,
+// these determine whether the feed is active, rather than the span
+// of the calendar.
+func testManagerFeedInfoDates(t *testing.T, strg storage.Storage) {
+	m := gtfs.NewManager(strg)
+
+	server := managerFixture()
+	defer server.Server.Close()
+
+	// Calendar spans 20190101 - 20190302, but the publisher
+	// says the feed is valid 20190115 - 20190215.
+	files := validFeed()
+	files["feed_info.txt"] = []string{
+		"feed_publisher_name,feed_publisher_url,feed_lang,feed_start_date,feed_end_date,feed_version",
+		"Publisher,http://example.com,en,20190115,20190215,v1",
+	}
+	server.Feeds["/static.zip"] = testutil.BuildZip(t, files)
+
+	url := server.Server.URL + "/static.zip"
+	_, err := m.LoadStaticAsync("a", url, nil, time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, gtfs.ErrNoActiveFeed)
+	require.NoError(t, m.Refresh(context.Background()))
+
+	// Inside the feed_info range
+	s, err := m.LoadStaticAsync("a", url, nil, time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", s.Metadata.FeedVersion)
+	assert.Equal(t, "Publisher", s.Metadata.FeedPublisherName)
+
+	// Inside the calendar, but before feed_start_date
+	_, err = m.LoadStaticAsync("a", url, nil, time.Date(2019, 1, 10, 12, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, gtfs.ErrNoActiveFeed)
+
+	// Inside the calendar, but after feed_end_date
+	_, err = m.LoadStaticAsync("a", url, nil, time.Date(2019, 2, 20, 12, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, gtfs.ErrNoActiveFeed)
+}
+
 // Verifies that Manager respects agency timezone when determining if
 // a feed is active.
 func testManagerRespectTimezones(t *testing.T, strg storage.Storage) {
@@ -825,6 +879,266 @@ func testManagerRefreshFeeds(t *testing.T, strg storage.Storage) {
 	// TODO: write me
 }
 
+// Downloader supporting only Get(), i.e. not streaming to file.
+type getOnlyDownloader struct {
+	downloader.Downloader
+}
+
+// Static feeds are streamed to a temp file, which is removed once
+// parsed. Downloaders that can't stream still work, with the feed
+// held in memory.
+func testManagerStaticTempFile(t *testing.T, strg storage.Storage) {
+	server := managerFixture()
+	defer server.Server.Close()
+
+	when := time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, dl := range []downloader.Downloader{
+		downloader.NewMemory(),
+		getOnlyDownloader{downloader.NewMemory()},
+	} {
+		// Distinct feed per downloader, so each is parsed
+		files := validFeed()
+		files["routes.txt"][1] = fmt.Sprintf("r,R%d,3", i)
+		feed := testutil.BuildZip(t, files)
+		feedURL := fmt.Sprintf("%s/static%d.zip", server.Server.URL, i)
+		server.Feeds[fmt.Sprintf("/static%d.zip", i)] = feed
+
+		m := gtfs.NewManager(strg)
+		m.Downloader = dl
+		m.StaticTempDir = t.TempDir()
+
+		_, err := m.LoadStaticAsync("a", feedURL, nil, when)
+		require.ErrorIs(t, err, gtfs.ErrNoActiveFeed)
+		require.NoError(t, m.Refresh(context.Background()))
+
+		s, err := m.LoadStaticAsync("a", feedURL, nil, when)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(feed)), s.Metadata.Hash)
+
+		entries, err := os.ReadDir(m.StaticTempDir)
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(entries))
+	}
+}
+
+func testManagerImportStatic(t *testing.T, strg storage.Storage) {
+	m := gtfs.NewManager(strg)
+	m.Downloader = nil // must not be used
+
+	// Unzipped feed, in a subdirectory
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "feed"), 0755))
+	for name, lines := range validFeed() {
+		require.NoError(t, os.WriteFile(
+			filepath.Join(dir, "feed", name),
+			[]byte(strings.Join(lines, "\n")),
+			0644,
+		))
+	}
+
+	s, err := m.ImportStaticFile(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+filepath.ToSlash(dir), s.Metadata.URL)
+	routes, err := s.Reader.Routes()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(routes))
+	assert.Equal(t, "r", routes[0].ID)
+
+	// Importing again is a no-op
+	s2, err := m.ImportStaticFile(dir)
+	require.NoError(t, err)
+	assert.Equal(t, s.Metadata.Hash, s2.Metadata.Hash)
+	feeds, err := strg.ListFeeds(storage.ListFeedsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(feeds))
+
+	// Same files under another URL reuse the stored feed
+	s3, err := m.ImportStatic("local:feed", os.DirFS(dir))
+	require.NoError(t, err)
+	assert.Equal(t, s.Metadata.Hash, s3.Metadata.Hash)
+	assert.Equal(t, "local:feed", s3.Metadata.URL)
+	feeds, err = strg.ListFeeds(storage.ListFeedsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(feeds))
+
+	// Zip files are hashed as if downloaded
+	files := validFeed()
+	files["routes.txt"] = append(files["routes.txt"], "r2,R2,3")
+	feed := testutil.BuildZip(t, files)
+	zipPath := filepath.Join(t.TempDir(), "static.zip")
+	require.NoError(t, os.WriteFile(zipPath, feed, 0644))
+
+	s, err = m.ImportStaticFile(zipPath)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(feed)), s.Metadata.Hash)
+	routes, err = s.Reader.Routes()
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(routes))
+
+	// And can be loaded by their file:// URL
+	s, err = m.LoadStaticAsync("a", "file://"+filepath.ToSlash(zipPath), nil, time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(feed)), s.Metadata.Hash)
+
+	// Broken feeds fail to import
+	require.NoError(t, os.Remove(filepath.Join(dir, "feed", "stops.txt")))
+	_, err = m.ImportStaticFile(dir)
+	assert.Error(t, err)
+}
+
+func testManagerLenient(t *testing.T, strg storage.Storage) {
+	server := managerFixture()
+	defer server.Server.Close()
+
+	when := time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)
+	feedURL := server.Server.URL + "/static.zip"
+
+	// Feed with one broken trip
+	files := validFeed()
+	files["trips.txt"] = append(files["trips.txt"], "r,mondays,t2")
+	files["stop_times.txt"] = append(files["stop_times.txt"], "t2,13:00:00,13:00:00,unknown,1")
+	server.Feeds["/static.zip"] = testutil.BuildZip(t, files)
+
+	m := gtfs.NewManager(strg)
+
+	// Can't be lenient about feeds not requested
+	assert.Error(t, m.SetLenient(feedURL, true))
+
+	// Strict parsing rejects the feed
+	_, err := m.LoadStaticAsync("a", feedURL, nil, when)
+	require.ErrorIs(t, err, gtfs.ErrNoActiveFeed)
+	require.Error(t, m.Refresh(context.Background()))
+	_, err = m.LoadStaticAsync("a", feedURL, nil, when)
+	require.ErrorIs(t, err, gtfs.ErrNoActiveFeed)
+
+	// Lenient parsing drops the broken trip, on the next refresh
+	require.NoError(t, m.SetLenient(feedURL, true))
+	require.NoError(t, m.Refresh(context.Background()))
+	s, err := m.LoadStaticAsync("a", feedURL, nil, when)
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.Metadata.DroppedCount)
+	assert.Equal(t, []string{
+		"stop_times.txt: unknown stop_id 'unknown' (row 2); dropped trip_id 't2'",
+	}, s.Metadata.Dropped)
+
+	trips, err := s.Reader.Trips()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trips))
+	assert.Equal(t, "t", trips[0].ID)
+
+	// Consumers are unaffected by SetLenient
+	requests, err := strg.ListFeedRequests(feedURL)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(requests))
+	assert.True(t, requests[0].Lenient)
+	assert.Equal(t, 1, len(requests[0].Consumers))
+
+	// The leniently parsed feed isn't reused when strict again
+	require.NoError(t, m.SetLenient(feedURL, false))
+	require.Error(t, m.Refresh(context.Background()))
+	requests, err = strg.ListFeedRequests(feedURL)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(requests))
+	assert.NotNil(t, requests[0].LastFailure)
+}
+
+func testManagerEncoding(t *testing.T, strg storage.Storage) {
+	server := managerFixture()
+	defer server.Server.Close()
+
+	when := time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)
+	feedURL := server.Server.URL + "/static.zip"
+
+	// Windows-1252 encoded "Ã©", which happens to be valid UTF-8
+	// for "é"
+	files := validFeed()
+	files["stops.txt"][1] = "s,Caf\xc3\xa9,12,34"
+	server.Feeds["/static.zip"] = testutil.BuildZip(t, files)
+
+	m := gtfs.NewManager(strg)
+
+	// Can't set encoding of feeds not requested
+	assert.Error(t, m.SetEncoding(feedURL, "windows-1252"))
+
+	// Detection reads it as UTF-8
+	_, err := m.LoadStaticAsync("a", feedURL, nil, when)
+	require.ErrorIs(t, err, gtfs.ErrNoActiveFeed)
+	require.NoError(t, m.Refresh(context.Background()))
+	s, err := m.LoadStaticAsync("a", feedURL, nil, when)
+	require.NoError(t, err)
+	stops, err := s.Reader.Stops()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stops))
+	assert.Equal(t, "Café", stops[0].Name)
+
+	// Unknown encodings are rejected
+	assert.Error(t, m.SetEncoding(feedURL, "klingon"))
+
+	// Overriding the encoding parses the same data again
+	require.NoError(t, m.SetEncoding(feedURL, "CP1252"))
+	require.NoError(t, m.Refresh(context.Background()))
+	s, err = m.LoadStaticAsync("a", feedURL, nil, when)
+	require.NoError(t, err)
+	stops, err = s.Reader.Stops()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stops))
+	assert.Equal(t, "CafÃ©", stops[0].Name)
+
+	requests, err := strg.ListFeedRequests(feedURL)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(requests))
+	assert.Equal(t, "windows-1252", requests[0].Encoding)
+	assert.Equal(t, 1, len(requests[0].Consumers))
+}
+
+func testManagerExtras(t *testing.T, strg storage.Storage) {
+	server := managerFixture()
+	defer server.Server.Close()
+
+	when := time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)
+	feedURL := server.Server.URL + "/static.zip"
+
+	files := validFeed()
+	files["stops.txt"] = []string{
+		"stop_id,stop_name,stop_lat,stop_lon,platform_side",
+		"s,S,12,34,left",
+	}
+	server.Feeds["/static.zip"] = testutil.BuildZip(t, files)
+
+	m := gtfs.NewManager(strg)
+
+	// Can't capture extras of feeds not requested
+	assert.Error(t, m.SetExtras(feedURL, true))
+
+	// Not captured by default
+	_, err := m.LoadStaticAsync("a", feedURL, nil, when)
+	require.ErrorIs(t, err, gtfs.ErrNoActiveFeed)
+	require.NoError(t, m.Refresh(context.Background()))
+	s, err := m.LoadStaticAsync("a", feedURL, nil, when)
+	require.NoError(t, err)
+	stops, err := s.Reader.Stops()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stops))
+	assert.Nil(t, stops[0].Extras)
+
+	// Enabling extras parses the same data again
+	require.NoError(t, m.SetExtras(feedURL, true))
+	require.NoError(t, m.Refresh(context.Background()))
+	s, err = m.LoadStaticAsync("a", feedURL, nil, when)
+	require.NoError(t, err)
+	stops, err = s.Reader.Stops()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stops))
+	assert.Equal(t, map[string]string{"platform_side": "left"}, stops[0].Extras)
+
+	requests, err := strg.ListFeedRequests(feedURL)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(requests))
+	assert.True(t, requests[0].Extras)
+	assert.Equal(t, 1, len(requests[0].Consumers))
+}
+
 func TestManager(t *testing.T) {
 	for _, test := range []struct {
 		Name string
@@ -838,8 +1152,14 @@ func TestManager(t *testing.T) {
 		{"ManagerBrokenData", testManagerBrokenData},
 		{"AsyncLoad", testManagerAsyncLoad},
 		{"LoadRealtime", testManagerLoadRealtime},
+		{"FeedInfoDates", testManagerFeedInfoDates},
 		{"RespectTimezones", testManagerRespectTimezones},
 		{"RefreshFeeds", testManagerRefreshFeeds},
+		{"StaticTempFile", testManagerStaticTempFile},
+		{"ImportStatic", testManagerImportStatic},
+		{"Lenient", testManagerLenient},
+		{"Encoding", testManagerEncoding},
+		{"Extras", testManagerExtras},
 	} {
 		t.Run(fmt.Sprintf("%s_SQLiteMemory", test.Name), func(t *testing.T) {
 			s, err := storage.NewSQLiteStorage(storage.SQLiteConfig{OnDisk: false})
@@ -32,6 +32,120 @@ const (
 	RouteTypeMonorail             = 12
 )
 
+// Extended route types, from the Hierarchical Vehicle Type (HVT)
+// codes used by many European feeds. Codes range from 100 to 1799,
+// with each hundred a category named by its first code. The codes
+// following it (e.g. 109 for suburban rail) are more specific.
+const (
+	RouteTypeRailwayService         RouteType = 100
+	RouteTypeCoachService           RouteType = 200
+	RouteTypeSuburbanRailwayService RouteType = 300
+	RouteTypeUrbanRailwayService    RouteType = 400
+	RouteTypeMetroService           RouteType = 500
+	RouteTypeUndergroundService     RouteType = 600
+	RouteTypeBusService             RouteType = 700
+	RouteTypeTrolleybusService      RouteType = 800
+	RouteTypeTramService            RouteType = 900
+	RouteTypeWaterTransportService  RouteType = 1000
+	RouteTypeAirService             RouteType = 1100
+	RouteTypeFerryService           RouteType = 1200
+	RouteTypeAerialLiftService      RouteType = 1300
+	RouteTypeFunicularService       RouteType = 1400
+	RouteTypeTaxiService            RouteType = 1500
+	RouteTypeSelfDriveService       RouteType = 1600
+	RouteTypeMiscellaneousService   RouteType = 1700
+)
+
+// Extended route types without a basic equivalent map to this.
+const routeTypeNone RouteType = -1
+
+// Ranges of extended route types, and the basic route type each
+// falls under. Mostly by category, except for monorail (405) and
+// cable car (1701).
+var extendedRouteTypes = []struct {
+	first RouteType
+	last  RouteType
+	basic RouteType
+}{
+	{100, 199, RouteTypeRail},
+	{200, 299, RouteTypeBus},
+	{300, 399, RouteTypeRail},
+	{400, 404, RouteTypeSubway},
+	{405, 405, RouteTypeMonorail},
+	{406, 699, RouteTypeSubway},
+	{700, 799, RouteTypeBus},
+	{800, 899, RouteTypeTrolleybus},
+	{900, 999, RouteTypeTram},
+	{1000, 1099, RouteTypeFerry},
+	{1100, 1199, routeTypeNone},
+	{1200, 1299, RouteTypeFerry},
+	{1300, 1399, RouteTypeAerial},
+	{1400, 1499, RouteTypeFunicular},
+	{1500, 1700, routeTypeNone},
+	{1701, 1701, RouteTypeCable},
+	{1702, 1799, routeTypeNone},
+}
+
+// A range of route types, from First to Last inclusive.
+type RouteTypeRange struct {
+	First RouteType
+	Last  RouteType
+}
+
+// Reports whether t is one of the basic route types.
+func (t RouteType) IsBasic() bool {
+	return (t >= RouteTypeTram && t <= RouteTypeFunicular) ||
+		t == RouteTypeTrolleybus ||
+		t == RouteTypeMonorail
+}
+
+// Reports whether t is a basic or an extended route type.
+func (t RouteType) Valid() bool {
+	return t.IsBasic() || (t >= 100 && t <= 1799)
+}
+
+// Returns the basic route type t falls under. Basic route types are
+// returned as is. Returns false for route types without a basic
+// equivalent, like air and taxi services, and for unknown ones.
+func (t RouteType) Basic() (RouteType, bool) {
+	if t.IsBasic() {
+		return t, true
+	}
+	for _, r := range extendedRouteTypes {
+		if t >= r.first && t <= r.last && r.basic != routeTypeNone {
+			return r.basic, true
+		}
+	}
+	return routeTypeNone, false
+}
+
+// Returns the ranges of extended route types falling under basic
+// route type t, in ascending order.
+func (t RouteType) Extended() []RouteTypeRange {
+	ranges := []RouteTypeRange{}
+	if !t.IsBasic() {
+		return ranges
+	}
+	for _, r := range extendedRouteTypes {
+		if r.basic == t {
+			ranges = append(ranges, RouteTypeRange{r.first, r.last})
+		}
+	}
+	return ranges
+}
+
+type PathwayMode int
+
+const (
+	PathwayModeWalkway PathwayMode = iota + 1
+	PathwayModeStairs
+	PathwayModeMovingSidewalk
+	PathwayModeEscalator
+	PathwayModeElevator
+	PathwayModeFareGate
+	PathwayModeExitGate
+)
+
 type ExceptionType int
 
 const (
@@ -39,11 +153,92 @@ const (
 	ExceptionTypeRemoved               = 2
 )
 
+type TransferType int
+
+const (
+	TransferTypeRecommended TransferType = iota
+	TransferTypeTimed
+	TransferTypeMinTime
+	TransferTypeNotPossible
+	TransferTypeInSeat
+	TransferTypeInSeatNotAllowed
+)
+
+// Wheelchair accessibility of a stop (wheelchair_boarding) or trip
+// (wheelchair_accessible).
+type WheelchairAccessibility int8
+
+const (
+	WheelchairNoInfo WheelchairAccessibility = iota
+	WheelchairAccessible
+	WheelchairInaccessible
+)
+
+// Whether bikes (bikes_allowed) or cars (cars_allowed) are allowed
+// on a trip.
+type AmenityAllowance int8
+
+const (
+	AmenityNoInfo AmenityAllowance = iota
+	AmenityAllowed
+	AmenityNotAllowed
+)
+
+// Pickup or drop off method at a stop_time (pickup_type and
+// drop_off_type).
+type PickupDropOffType int8
+
+const (
+	PickupDropOffRegular PickupDropOffType = iota
+	PickupDropOffNone
+	PickupDropOffPhoneAgency
+	PickupDropOffCoordinateWithDriver
+)
+
+// Continuous stopping for pickup or drop off along a route or
+// between stops (continuous_pickup and continuous_drop_off). Note
+// that the zero value, unlike in the spec, means there's no
+// continuous stopping.
+type ContinuousStopping int8
+
+const (
+	ContinuousStoppingNone ContinuousStopping = iota
+	ContinuousStoppingRegular
+	ContinuousStoppingPhoneAgency
+	ContinuousStoppingCoordinateWithDriver
+)
+
+type PaymentMethod int
+
+const (
+	PaymentMethodOnBoard PaymentMethod = iota
+	PaymentMethodBeforeBoarding
+)
+
 type Agency struct {
 	ID       string
 	Name     string
 	URL      string
 	Timezone string
+	Lang     string
+	Phone    string
+	FareURL  string
+	Email    string
+
+	// Values in columns not defined by the spec, if captured.
+	Extras map[string]string
+}
+
+type FeedInfo struct {
+	PublisherName string
+	PublisherURL  string
+	Lang          string
+	DefaultLang   string
+	StartDate     string
+	EndDate       string
+	Version       string
+	ContactEmail  string
+	ContactURL    string
 }
 
 type Calendar struct {
@@ -70,6 +265,16 @@ type Stop struct {
 	LocationType  LocationType
 	ParentStation string
 	PlatformCode  string
+	ZoneID        string
+	LevelID       string
+	Timezone      string
+
+	// For stops within a station, WheelchairNoInfo means the
+	// parent station's value applies.
+	WheelchairBoarding WheelchairAccessibility
+
+	// Values in columns not defined by the spec, if captured.
+	Extras map[string]string
 }
 
 type Trip struct {
@@ -79,6 +284,15 @@ type Trip struct {
 	Headsign    string
 	ShortName   string
 	DirectionID int8
+	ShapeID     string
+	BlockID     string
+
+	WheelchairAccessible WheelchairAccessibility
+	BikesAllowed         AmenityAllowance
+	CarsAllowed          AmenityAllowance
+
+	// Values in columns not defined by the spec, if captured.
+	Extras map[string]string
 }
 
 type Route struct {
@@ -91,6 +305,39 @@ type Route struct {
 	URL       string
 	Color     string
 	TextColor string
+
+	ContinuousPickup  ContinuousStopping
+	ContinuousDropOff ContinuousStopping
+
+	// Order in which routes should be presented, lowest
+	// first. Only meaningful when HasSortOrder is set.
+	SortOrder    int
+	HasSortOrder bool
+
+	// Fares v2 network the route belongs to, if set in
+	// routes.txt. Feeds may instead use route_networks.txt.
+	NetworkID string
+
+	// Values in columns not defined by the spec, if captured.
+	Extras map[string]string
+}
+
+// The geometry of a trip, as a sequence of points.
+type Shape struct {
+	ID     string
+	Points []ShapePoint
+}
+
+type ShapePoint struct {
+	Lat      float64
+	Lon      float64
+	Sequence uint32
+
+	// Distance traveled along the shape from the first point,
+	// in whatever unit the feed uses. Only meaningful if
+	// HasDistTraveled is set.
+	DistTraveled    float64
+	HasDistTraveled bool
 }
 
 type StopTime struct {
@@ -100,19 +347,63 @@ type StopTime struct {
 	StopSequence uint32
 	Arrival      string
 	Departure    string
+	PickupType   PickupDropOffType
+	DropOffType  PickupDropOffType
+
+	// Distance traveled along the trip's shape. Only meaningful
+	// when HasShapeDistTraveled is set.
+	ShapeDistTraveled    float64
+	HasShapeDistTraveled bool
+
+	// Set when Arrival and Departure are approximate, either
+	// because the stop isn't a timepoint or because the times
+	// were interpolated from surrounding stops.
+	Approximate bool
+
+	// Continuous stopping between this stop and the next. When
+	// not set, the route's continuous stopping applies.
+	ContinuousPickup     ContinuousStopping
+	HasContinuousPickup  bool
+	ContinuousDropOff    ContinuousStopping
+	HasContinuousDropOff bool
 }
 
 func (st *StopTime) ArrivalTime() time.Duration {
-	h, _ := strconv.Atoi(st.Arrival[0:2])
-	m, _ := strconv.Atoi(st.Arrival[2:4])
-	s, _ := strconv.Atoi(st.Arrival[4:6])
-	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	return hhmmssToDuration(st.Arrival)
 }
 
 func (st *StopTime) DepartureTime() time.Duration {
-	h, _ := strconv.Atoi(st.Departure[0:2])
-	m, _ := strconv.Atoi(st.Departure[2:4])
-	s, _ := strconv.Atoi(st.Departure[4:6])
+	return hhmmssToDuration(st.Departure)
+}
+
+// A headway based service period for a trip, as per
+// frequencies.txt. The trip's stop_times are used as a template, with
+// times relative to the first departure. Start and End are given as
+// "HHMMSS".
+type Frequency struct {
+	TripID      string
+	Start       string
+	End         string
+	HeadwaySecs uint32
+	ExactTimes  bool
+}
+
+func (f *Frequency) StartTime() time.Duration {
+	return hhmmssToDuration(f.Start)
+}
+
+func (f *Frequency) EndTime() time.Duration {
+	return hhmmssToDuration(f.End)
+}
+
+func (f *Frequency) Headway() time.Duration {
+	return time.Duration(f.HeadwaySecs) * time.Second
+}
+
+func hhmmssToDuration(hhmmss string) time.Duration {
+	h, _ := strconv.Atoi(hhmmss[0:2])
+	m, _ := strconv.Atoi(hhmmss[2:4])
+	s, _ := strconv.Atoi(hhmmss[4:6])
 	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
 }
 
@@ -123,6 +414,10 @@ type RouteDirection struct {
 	RouteID     string
 	DirectionID int8
 	Headsigns   []string
+
+	// Short and long names of the route.
+	RouteShortName string
+	RouteLongName  string
 }
 
 // A vehicle departing from a stop.
@@ -135,4 +430,353 @@ type Departure struct {
 	Time         time.Time
 	Headsign     string
 	Delay        time.Duration
+
+	// Short and long names of the route.
+	RouteShortName string
+	RouteLongName  string
+
+	// Set for departures of frequency based trips without exact
+	// times. Time is then an estimate derived from the headway.
+	HeadwayBased bool
+
+	// Set when the scheduled time is approximate, as the stop
+	// isn't a timepoint.
+	Approximate bool
+
+	// Set when boarding requires phoning the agency or
+	// coordinating with the driver.
+	PickupType PickupDropOffType
+
+	// Accessibility of the stop, inherited from its parent
+	// station when not set, and of the trip.
+	WheelchairBoarding   WheelchairAccessibility
+	WheelchairAccessible WheelchairAccessibility
+
+	// Whether bikes and cars are allowed on the trip.
+	BikesAllowed AmenityAllowance
+	CarsAllowed  AmenityAllowance
+
+	// Continuous stopping after the stop, as per the stop time
+	// or else the route. When set, riders can board or alight
+	// between stops, e.g. by flagging down the vehicle.
+	ContinuousPickup  ContinuousStopping
+	ContinuousDropOff ContinuousStopping
+
+	// Set for departures from the last stop of a trip, when the
+	// vehicle continues as another trip in the same block.
+	ContinuesAsTripID  string
+	ContinuesAsRouteID string
+}
+
+// A transfer rule, as per transfers.txt. Stop, route and trip IDs are
+// blank when not specified. For in-seat transfers (types 4 and 5),
+// the trip IDs are always set. MinTransferTime is given in seconds.
+type Transfer struct {
+	FromStopID      string
+	ToStopID        string
+	FromRouteID     string
+	ToRouteID       string
+	FromTripID      string
+	ToTripID        string
+	Type            TransferType
+	MinTransferTime uint32
+}
+
+// A fare, as per fare_attributes.txt. Transfers is the number of
+// transfers permitted, or -1 for unlimited. TransferDuration is given
+// in seconds, and is 0 when not set.
+type FareAttribute struct {
+	ID               string
+	Price            float64
+	CurrencyType     string
+	PaymentMethod    PaymentMethod
+	Transfers        int
+	AgencyID         string
+	TransferDuration uint32
+}
+
+// Rule for when a fare applies, as per fare_rules.txt. Origin,
+// destination and contains IDs reference stop zone IDs.
+type FareRule struct {
+	FareID        string
+	RouteID       string
+	OriginID      string
+	DestinationID string
+	ContainsID    string
+}
+
+type FareMediaType int
+
+const (
+	FareMediaTypeNone FareMediaType = iota
+	FareMediaTypePaperTicket
+	FareMediaTypeTransitCard
+	FareMediaTypeCEMV
+	FareMediaTypeMobileApp
+)
+
+type FareTransferType int
+
+const (
+	FareTransferTypeAPlusAB FareTransferType = iota
+	FareTransferTypeAPlusABPlusB
+	FareTransferTypeAB
+)
+
+type DurationLimitType int
+
+const (
+	DurationLimitTypeDepartureArrival DurationLimitType = iota
+	DurationLimitTypeDepartureDeparture
+	DurationLimitTypeArrivalDeparture
+	DurationLimitTypeArrivalArrival
+)
+
+// Fare media, as per fare_media.txt (Fares v2).
+type FareMedia struct {
+	ID   string
+	Name string
+	Type FareMediaType
+}
+
+// A fare product, as per fare_products.txt (Fares v2). The same
+// product can be listed multiple times with different fare media.
+type FareProduct struct {
+	ID          string
+	Name        string
+	FareMediaID string
+	Amount      float64
+	Currency    string
+}
+
+// Rule for pricing a single leg, as per fare_leg_rules.txt (Fares
+// v2). Blank fields are unspecified. HasRulePriority is set on every
+// rule if fare_leg_rules.txt has a rule_priority column, in which
+// case blank values are 0.
+type FareLegRule struct {
+	LegGroupID      string
+	NetworkID       string
+	FromAreaID      string
+	ToAreaID        string
+	FareProductID   string
+	RulePriority    int
+	HasRulePriority bool
+}
+
+// Rule for pricing a transfer between legs, as per
+// fare_transfer_rules.txt (Fares v2). TransferCount is -1 for
+// unlimited transfers. DurationLimit is given in seconds, and is 0
+// when there's no limit.
+type FareTransferRule struct {
+	FromLegGroupID    string
+	ToLegGroupID      string
+	TransferCount     int
+	DurationLimit     uint32
+	DurationLimitType DurationLimitType
+	FareTransferType  FareTransferType
+	FareProductID     string
+}
+
+type Area struct {
+	ID   string
+	Name string
+}
+
+type StopArea struct {
+	AreaID string
+	StopID string
+}
+
+type Network struct {
+	ID   string
+	Name string
+}
+
+type RouteNetwork struct {
+	NetworkID string
+	RouteID   string
+}
+
+// A leg of an itinerary to be priced by the Fares v2 calculator.
+type FareLeg struct {
+	RouteID    string
+	FromStopID string
+	ToStopID   string
+	Departure  time.Time
+	Arrival    time.Time
+}
+
+// Outcome of pricing an itinerary with Fares v2.
+type FareCalculation struct {
+	Amount   float64
+	Currency string
+	Legs     []FareCalculationLeg
+}
+
+// The pricing of a single leg. Product is the fare product matched
+// for the leg, and Transfer the transfer rule applied when coming
+// from the previous leg, if any. Amount is what the leg's own fare
+// adds to the total, and TransferAmount what the transfer adds. An
+// A to B transfer replaces the fares of both legs, leaving their
+// Amount 0.
+type FareCalculationLeg struct {
+	LegGroupID     string
+	Product        FareProduct
+	Transfer       *FareTransferRule
+	Amount         float64
+	TransferAmount float64
+}
+
+// A level within a station, as per levels.txt.
+type Level struct {
+	ID    string
+	Index float64
+	Name  string
+}
+
+// A pathway linking two locations within a station, as per
+// pathways.txt. Length is given in meters, and TraversalTime in
+// seconds. Optional numeric fields are 0 when not set.
+type Pathway struct {
+	ID                   string
+	FromStopID           string
+	ToStopID             string
+	Mode                 PathwayMode
+	IsBidirectional      bool
+	Length               float64
+	TraversalTime        uint32
+	StairCount           int
+	MaxSlope             float64
+	MinWidth             float64
+	SignpostedAs         string
+	ReversedSignpostedAs string
+}
+
+// A walk through a station along pathways. TraversalTime is the
+// total, in seconds.
+type WalkingPath struct {
+	Steps         []WalkingPathStep
+	TraversalTime uint32
+}
+
+// A single pathway traversed on a walking path, in the direction
+// walked. Levels are nil when the stops lack level information.
+type WalkingPathStep struct {
+	PathwayID     string
+	FromStopID    string
+	ToStopID      string
+	Mode          PathwayMode
+	TraversalTime uint32
+	SignpostedAs  string
+	FromLevel     *Level
+	ToLevel       *Level
+}
+
+// A translation of a field value, as per translations.txt.
+//
+// Translations apply either to a specific record, identified by
+// RecordID (and RecordSubID, for stop_times), or to all records of
+// the table where the field has the value FieldValue.
+type Translation struct {
+	TableName   string
+	FieldName   string
+	Language    string
+	Translation string
+	RecordID    string
+	RecordSubID string
+	FieldValue  string
+}
+
+type BookingType int8
+
+const (
+	BookingTypeRealTime BookingType = iota
+	BookingTypeSameDay
+	BookingTypePriorDays
+)
+
+// A GTFS-Flex zone, as per locations.geojson. The zone is the union
+// of its polygons. Each polygon is a list of rings of [lon, lat]
+// points, where the first ring is the exterior and any others are
+// holes.
+type Location struct {
+	ID       string
+	Name     string
+	Desc     string
+	Polygons [][][][2]float64
+}
+
+// A group of stops served as one by on-demand service, as per
+// location_groups.txt (GTFS-Flex).
+type LocationGroup struct {
+	ID   string
+	Name string
+}
+
+type LocationGroupStop struct {
+	LocationGroupID string
+	StopID          string
+}
+
+// How on-demand service is booked, as per booking_rules.txt
+// (GTFS-Flex). Notice durations are given in minutes, and times as
+// "HHMMSS". Optional numeric fields are 0 when not set.
+type BookingRule struct {
+	ID                     string
+	Type                   BookingType
+	PriorNoticeDurationMin int
+	PriorNoticeDurationMax int
+	PriorNoticeLastDay     int
+	PriorNoticeLastTime    string
+	PriorNoticeStartDay    int
+	PriorNoticeStartTime   string
+	PriorNoticeServiceID   string
+	Message                string
+	PickupMessage          string
+	DropOffMessage         string
+	PhoneNumber            string
+	InfoURL                string
+	BookingURL             string
+}
+
+// A stop_time served on demand within a time window, as per
+// GTFS-Flex. Exactly one of StopID, LocationGroupID and LocationID
+// is set. The window is given as "HHMMSS".
+type FlexStopTime struct {
+	TripID               string
+	StopSequence         uint32
+	StopID               string
+	LocationGroupID      string
+	LocationID           string
+	StartWindow          string
+	EndWindow            string
+	PickupType           PickupDropOffType
+	DropOffType          PickupDropOffType
+	PickupBookingRuleID  string
+	DropOffBookingRuleID string
+}
+
+func (st *FlexStopTime) StartWindowTime() time.Duration {
+	return hhmmssToDuration(st.StartWindow)
+}
+
+func (st *FlexStopTime) EndWindowTime() time.Duration {
+	return hhmmssToDuration(st.EndWindow)
+}
+
+// On-demand service picking up at a location, as per GTFS-Flex. One
+// of LocationID, LocationGroupID and StopID is set, identifying the
+// zone, group of stops or single stop served. BookingRule is nil when the feed doesn't
+// say how to book.
+type OnDemandService struct {
+	RouteID         string
+	TripID          string
+	StopSequence    uint32
+	LocationID      string
+	LocationGroupID string
+	StopID          string
+	WindowStart     time.Time
+	WindowEnd       time.Time
+	PickupType      PickupDropOffType
+	BookingRule     *BookingRule
 }
@@ -5,8 +5,6 @@ import (
 	"io"
 	"time"
 
-	"github.com/gocarina/gocsv"
-
 	"tidbyt.dev/gtfs/model"
 	"tidbyt.dev/gtfs/storage"
 )
@@ -16,54 +14,60 @@ type AgencyCSV struct {
 	Name     string `csv:"agency_name"`
 	URL      string `csv:"agency_url"`
 	Timezone string `csv:"agency_timezone"`
-	// Lang     string `csv:"agency_lang"`
-	// Phone    string `csv:"agency_phone"`
-	// FareURL  string `csv:"agency_fare_url"`
-	// Email    string `csv:"agency_email"`
+	Lang     string `csv:"agency_lang"`
+	Phone    string `csv:"agency_phone"`
+	FareURL  string `csv:"agency_fare_url"`
+	Email    string `csv:"agency_email"`
+
+	Extras map[string]string `csv:"-"`
 }
 
 func ParseAgency(writer storage.FeedWriter, data io.Reader) (map[string]bool, string, error) {
+	return parseAgency(writer, data, false)
+}
+
+// Parses agency.txt, capturing values in extension columns if
+// extras is set.
+func parseAgency(writer storage.FeedWriter, data io.Reader, extras bool) (map[string]bool, string, error) {
 	agencyCsv := []*AgencyCSV{}
-	if err := gocsv.Unmarshal(data, &agencyCsv); err != nil {
-		return nil, "", fmt.Errorf("unmarshaling agency csv: %w", err)
+	if err := unmarshalExtras(data, &agencyCsv, "agency.txt", extras); err != nil {
+		return nil, "", errMalformed("agency.txt", err)
 	}
 
 	if len(agencyCsv) == 0 {
-		return nil, "", fmt.Errorf("no agency record found")
+		return nil, "", &ParseError{File: "agency.txt", Code: CodeMissingValue, Err: fmt.Errorf("no agency record found")}
 	}
 
 	// "If multiple agencies are specified in the dataset, each
 	// must have the same agency_timezone."
-	agencyTz := map[string]bool{}
-	for _, a := range agencyCsv {
-		agencyTz[a.Timezone] = true
-	}
-	if len(agencyTz) != 1 {
-		return nil, "", fmt.Errorf("multiple agency_timezone")
+	tz := agencyCsv[0].Timezone
+	for i, a := range agencyCsv {
+		if a.Timezone != tz {
+			return nil, "", errInvalid("agency.txt", i+1, "agency_timezone", a.Timezone, fmt.Errorf("differs from '%s'", tz))
+		}
 	}
 
-	tz := agencyCsv[0].Timezone
 	if tz == "" {
-		return nil, "", fmt.Errorf("missing agency_timezone")
+		return nil, "", errMissing("agency.txt", 1, "agency_timezone")
 	}
 	_, err := time.LoadLocation(tz)
 	if err != nil {
-		return nil, "", fmt.Errorf("agency_timezone '%s' is invalid: %w", tz, err)
+		return nil, "", errInvalid("agency.txt", 1, "agency_timezone", tz, err)
 	}
 
 	agency := map[string]bool{}
-	for _, a := range agencyCsv {
+	for i, a := range agencyCsv {
 		if agency[a.ID] {
-			return nil, "", fmt.Errorf("duplicated agency_id: '%s'", a.ID)
+			return nil, "", errDuplicate("agency.txt", i+1, "agency_id", a.ID)
 		}
 		agency[a.ID] = true
 
 		if a.Name == "" {
-			return nil, "", fmt.Errorf("missing agency_name")
+			return nil, "", errMissing("agency.txt", i+1, "agency_name")
 		}
 
 		if a.URL == "" {
-			return nil, "", fmt.Errorf("missing agency_url")
+			return nil, "", errMissing("agency.txt", i+1, "agency_url")
 		}
 
 		writer.WriteAgency(model.Agency{
@@ -71,6 +75,11 @@ func ParseAgency(writer storage.FeedWriter, data io.Reader) (map[string]bool, st
 			Name:     a.Name,
 			URL:      a.URL,
 			Timezone: tz,
+			Lang:     a.Lang,
+			Phone:    a.Phone,
+			FareURL:  a.FareURL,
+			Email:    a.Email,
+			Extras:   a.Extras,
 		})
 
 	}
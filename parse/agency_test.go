@@ -36,6 +36,26 @@ Agency Name,http://www.example.com,America/New_York`,
 			false,
 		},
 
+		{
+			"all fields",
+			`
+agency_id,agency_name,agency_url,agency_timezone,agency_lang,agency_phone,agency_fare_url,agency_email
+a,Agency Name,http://www.example.com,America/New_York,en,555-1234,http://www.example.com/fares,info@example.com`,
+			map[string]bool{"a": true},
+			"America/New_York",
+			[]model.Agency{model.Agency{
+				ID:       "a",
+				Name:     "Agency Name",
+				URL:      "http://www.example.com",
+				Timezone: "America/New_York",
+				Lang:     "en",
+				Phone:    "555-1234",
+				FareURL:  "http://www.example.com/fares",
+				Email:    "info@example.com",
+			}},
+			false,
+		},
+
 		{
 			"multiple agencies",
 			`
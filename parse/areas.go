@@ -0,0 +1,130 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type AreaCSV struct {
+	ID   string `csv:"area_id"`
+	Name string `csv:"area_name"`
+}
+
+type StopAreaCSV struct {
+	AreaID string `csv:"area_id"`
+	StopID string `csv:"stop_id"`
+}
+
+// Parses areas.txt. Returns the set of area IDs.
+func ParseAreas(writer storage.FeedWriter, data io.Reader) (map[string]bool, error) {
+	return parseAreas(writer, data, nil)
+}
+
+// Parses areas.txt. If drop is non-nil, bad rows are dropped rather
+// than failing the parse, and left out of the returned area IDs.
+func parseAreas(writer storage.FeedWriter, data io.Reader, drop *dropped) (map[string]bool, error) {
+	areaCsv := []*AreaCSV{}
+	if err := gocsv.Unmarshal(data, &areaCsv); err != nil {
+		return nil, errMalformed("areas.txt", err)
+	}
+
+	areas := map[string]bool{}
+	parseRow := func(row int, a *AreaCSV) error {
+		if a.ID == "" {
+			return errMissing("areas.txt", row, "area_id")
+		}
+		if areas[a.ID] {
+			return errDuplicate("areas.txt", row, "area_id", a.ID)
+		}
+
+		err := writer.WriteArea(model.Area{
+			ID:   a.ID,
+			Name: a.Name,
+		})
+		if err != nil {
+			return errStorage("areas.txt", row, fmt.Errorf("writing area: %w", err))
+		}
+
+		areas[a.ID] = true
+		return nil
+	}
+
+	for i, a := range areaCsv {
+		if err := drop.skip(parseRow(i+1, a)); err != nil {
+			return nil, err
+		}
+	}
+
+	return areas, nil
+}
+
+// Parses stop_areas.txt.
+func ParseStopAreas(
+	writer storage.FeedWriter,
+	data io.Reader,
+	areas map[string]bool,
+	stops map[string]bool,
+) error {
+	return parseStopAreas(writer, data, areas, stops, nil)
+}
+
+// Parses stop_areas.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse.
+func parseStopAreas(
+	writer storage.FeedWriter,
+	data io.Reader,
+	areas map[string]bool,
+	stops map[string]bool,
+	drop *dropped,
+) error {
+	saCsv := []*StopAreaCSV{}
+	if err := gocsv.Unmarshal(data, &saCsv); err != nil {
+		return errMalformed("stop_areas.txt", err)
+	}
+
+	seen := map[model.StopArea]bool{}
+	parseRow := func(row int, sa *StopAreaCSV) error {
+		if !areas[sa.AreaID] {
+			return errUnknown("stop_areas.txt", row, "area_id", sa.AreaID)
+		}
+		if !stops[sa.StopID] {
+			return errUnknown("stop_areas.txt", row, "stop_id", sa.StopID)
+		}
+
+		stopArea := model.StopArea{
+			AreaID: sa.AreaID,
+			StopID: sa.StopID,
+		}
+		if seen[stopArea] {
+			return &ParseError{
+				File:   "stop_areas.txt",
+				Row:    row,
+				Column: "stop_id",
+				Value:  sa.StopID,
+				Code:   CodeDuplicateKey,
+				Err:    fmt.Errorf("for area_id '%s'", sa.AreaID),
+			}
+		}
+		seen[stopArea] = true
+
+		err := writer.WriteStopArea(stopArea)
+		if err != nil {
+			return errStorage("stop_areas.txt", row, fmt.Errorf("writing stop area: %w", err))
+		}
+
+		return nil
+	}
+
+	for i, sa := range saCsv {
+		if err := drop.skip(parseRow(i+1, sa)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
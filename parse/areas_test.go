@@ -0,0 +1,109 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseAreas(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		areas      string
+		stopAreas  string
+		expected   []model.Area
+		expectedSA []model.StopArea
+		err        bool
+	}{
+		{
+			"basic",
+			`
+area_id,area_name
+downtown,Downtown
+uptown,`,
+			`
+area_id,stop_id
+downtown,s1
+downtown,s2
+uptown,s2`,
+			[]model.Area{{ID: "downtown", Name: "Downtown"}, {ID: "uptown"}},
+			[]model.StopArea{
+				{AreaID: "downtown", StopID: "s1"},
+				{AreaID: "downtown", StopID: "s2"},
+				{AreaID: "uptown", StopID: "s2"},
+			},
+			false,
+		},
+
+		{
+			"empty area_id",
+			"area_id,area_name\n,Nowhere",
+			"area_id,stop_id",
+			nil, nil, true,
+		},
+
+		{
+			"repeated area_id",
+			"area_id\na\na",
+			"area_id,stop_id",
+			nil, nil, true,
+		},
+
+		{
+			"unknown area",
+			"area_id\na",
+			"area_id,stop_id\nb,s1",
+			nil, nil, true,
+		},
+
+		{
+			"unknown stop",
+			"area_id\na",
+			"area_id,stop_id\na,s3",
+			nil, nil, true,
+		},
+
+		{
+			"repeated stop area",
+			"area_id\na",
+			"area_id,stop_id\na,s1\na,s1",
+			nil, nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			areaIDs, err := ParseAreas(writer, bytes.NewBufferString(tc.areas))
+			if err == nil {
+				err = ParseStopAreas(
+					writer,
+					bytes.NewBufferString(tc.stopAreas),
+					areaIDs,
+					map[string]bool{"s1": true, "s2": true},
+				)
+			}
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			areas, err := reader.Areas()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, areas)
+			stopAreas, err := reader.StopAreas()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedSA, stopAreas)
+		})
+	}
+}
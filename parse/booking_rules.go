@@ -0,0 +1,185 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type BookingRuleCSV struct {
+	ID                     string `csv:"booking_rule_id"`
+	Type                   string `csv:"booking_type"`
+	PriorNoticeDurationMin string `csv:"prior_notice_duration_min"`
+	PriorNoticeDurationMax string `csv:"prior_notice_duration_max"`
+	PriorNoticeLastDay     string `csv:"prior_notice_last_day"`
+	PriorNoticeLastTime    string `csv:"prior_notice_last_time"`
+	PriorNoticeStartDay    string `csv:"prior_notice_start_day"`
+	PriorNoticeStartTime   string `csv:"prior_notice_start_time"`
+	PriorNoticeServiceID   string `csv:"prior_notice_service_id"`
+	Message                string `csv:"message"`
+	PickupMessage          string `csv:"pickup_message"`
+	DropOffMessage         string `csv:"drop_off_message"`
+	PhoneNumber            string `csv:"phone_number"`
+	InfoURL                string `csv:"info_url"`
+	BookingURL             string `csv:"booking_url"`
+}
+
+// Parses booking_rules.txt (GTFS-Flex). Returns the set of booking
+// rule IDs.
+func ParseBookingRules(writer storage.FeedWriter, data io.Reader, services map[string]bool) (map[string]bool, error) {
+	return parseBookingRules(writer, data, services, nil)
+}
+
+// Parses booking_rules.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse, and left out of the returned booking
+// rule IDs.
+func parseBookingRules(writer storage.FeedWriter, data io.Reader, services map[string]bool, drop *dropped) (map[string]bool, error) {
+	ruleCsv := []*BookingRuleCSV{}
+	if err := gocsv.Unmarshal(data, &ruleCsv); err != nil {
+		return nil, errMalformed("booking_rules.txt", err)
+	}
+
+	rules := map[string]bool{}
+	parseRow := func(row int, r *BookingRuleCSV) error {
+		if r.ID == "" {
+			return errMissing("booking_rules.txt", row, "booking_rule_id")
+		}
+		if rules[r.ID] {
+			return errDuplicate("booking_rules.txt", row, "booking_rule_id", r.ID)
+		}
+
+		rule := model.BookingRule{
+			ID:                   r.ID,
+			PriorNoticeServiceID: r.PriorNoticeServiceID,
+			Message:              r.Message,
+			PickupMessage:        r.PickupMessage,
+			DropOffMessage:       r.DropOffMessage,
+			PhoneNumber:          r.PhoneNumber,
+			InfoURL:              r.InfoURL,
+			BookingURL:           r.BookingURL,
+		}
+
+		switch r.Type {
+		case "0":
+			rule.Type = model.BookingTypeRealTime
+		case "1":
+			rule.Type = model.BookingTypeSameDay
+		case "2":
+			rule.Type = model.BookingTypePriorDays
+		default:
+			return errInvalid("booking_rules.txt", row, "booking_type", r.Type, nil)
+		}
+
+		for _, field := range []struct {
+			name  string
+			value string
+			dst   *int
+		}{
+			{"prior_notice_duration_min", r.PriorNoticeDurationMin, &rule.PriorNoticeDurationMin},
+			{"prior_notice_duration_max", r.PriorNoticeDurationMax, &rule.PriorNoticeDurationMax},
+			{"prior_notice_last_day", r.PriorNoticeLastDay, &rule.PriorNoticeLastDay},
+			{"prior_notice_start_day", r.PriorNoticeStartDay, &rule.PriorNoticeStartDay},
+		} {
+			if field.value == "" {
+				continue
+			}
+			n, err := strconv.Atoi(field.value)
+			if err != nil || n < 0 {
+				return errInvalid("booking_rules.txt", row, field.name, field.value, nil)
+			}
+			*field.dst = n
+		}
+
+		for _, field := range []struct {
+			name  string
+			value string
+			dst   *string
+		}{
+			{"prior_notice_last_time", r.PriorNoticeLastTime, &rule.PriorNoticeLastTime},
+			{"prior_notice_start_time", r.PriorNoticeStartTime, &rule.PriorNoticeStartTime},
+		} {
+			if field.value == "" {
+				continue
+			}
+			t, err := parseStopTimeTime(field.value)
+			if err != nil {
+				return errInvalid("booking_rules.txt", row, field.name, field.value, err)
+			}
+			*field.dst = t
+		}
+
+		// Which prior notice fields are required and forbidden
+		// depends on the booking type.
+		var forbidden []string
+		notAllowed := fmt.Errorf("not allowed with booking_type %s", r.Type)
+		switch rule.Type {
+		case model.BookingTypeRealTime:
+			forbidden = []string{
+				"prior_notice_duration_min",
+				"prior_notice_duration_max",
+				"prior_notice_last_day",
+				"prior_notice_start_day",
+				"prior_notice_service_id",
+			}
+		case model.BookingTypeSameDay:
+			if r.PriorNoticeDurationMin == "" {
+				return errMissing("booking_rules.txt", row, "prior_notice_duration_min")
+			}
+			if r.PriorNoticeDurationMax != "" && rule.PriorNoticeDurationMax < rule.PriorNoticeDurationMin {
+				return errInvalid("booking_rules.txt", row, "prior_notice_duration_max", r.PriorNoticeDurationMax, fmt.Errorf("less than prior_notice_duration_min"))
+			}
+			forbidden = []string{"prior_notice_last_day", "prior_notice_service_id"}
+			if r.PriorNoticeDurationMax != "" && r.PriorNoticeStartDay != "" {
+				return errInvalid("booking_rules.txt", row, "prior_notice_start_day", r.PriorNoticeStartDay, fmt.Errorf("not allowed with prior_notice_duration_max"))
+			}
+		case model.BookingTypePriorDays:
+			if r.PriorNoticeLastDay == "" {
+				return errMissing("booking_rules.txt", row, "prior_notice_last_day")
+			}
+			forbidden = []string{"prior_notice_duration_min", "prior_notice_duration_max"}
+		}
+		values := map[string]string{
+			"prior_notice_duration_min": r.PriorNoticeDurationMin,
+			"prior_notice_duration_max": r.PriorNoticeDurationMax,
+			"prior_notice_last_day":     r.PriorNoticeLastDay,
+			"prior_notice_start_day":    r.PriorNoticeStartDay,
+			"prior_notice_service_id":   r.PriorNoticeServiceID,
+		}
+		for _, column := range forbidden {
+			if values[column] != "" {
+				return errInvalid("booking_rules.txt", row, column, values[column], notAllowed)
+			}
+		}
+
+		if r.PriorNoticeLastDay != "" && r.PriorNoticeLastTime == "" {
+			return errMissing("booking_rules.txt", row, "prior_notice_last_time")
+		}
+		if r.PriorNoticeStartDay != "" && r.PriorNoticeStartTime == "" {
+			return errMissing("booking_rules.txt", row, "prior_notice_start_time")
+		}
+		if r.PriorNoticeServiceID != "" && !services[r.PriorNoticeServiceID] {
+			return errUnknown("booking_rules.txt", row, "prior_notice_service_id", r.PriorNoticeServiceID)
+		}
+
+		err := writer.WriteBookingRule(rule)
+		if err != nil {
+			return errStorage("booking_rules.txt", row, fmt.Errorf("writing booking rule: %w", err))
+		}
+
+		rules[r.ID] = true
+		return nil
+	}
+
+	for i, r := range ruleCsv {
+		if err := drop.skip(parseRow(i+1, r)); err != nil {
+			return nil, err
+		}
+	}
+
+	return rules, nil
+}
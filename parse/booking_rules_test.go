@@ -0,0 +1,161 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseBookingRules(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected []model.BookingRule
+		err      bool
+	}{
+		{
+			"real time",
+			`
+booking_rule_id,booking_type,message,phone_number,info_url,booking_url
+rt,0,Call us,555-1234,http://example.com/info,http://example.com/book`,
+			[]model.BookingRule{{
+				ID:          "rt",
+				Type:        model.BookingTypeRealTime,
+				Message:     "Call us",
+				PhoneNumber: "555-1234",
+				InfoURL:     "http://example.com/info",
+				BookingURL:  "http://example.com/book",
+			}},
+			false,
+		},
+
+		{
+			"same day",
+			`
+booking_rule_id,booking_type,prior_notice_duration_min,prior_notice_duration_max,pickup_message,drop_off_message
+sd,1,30,120,Pick up,Drop off`,
+			[]model.BookingRule{{
+				ID:                     "sd",
+				Type:                   model.BookingTypeSameDay,
+				PriorNoticeDurationMin: 30,
+				PriorNoticeDurationMax: 120,
+				PickupMessage:          "Pick up",
+				DropOffMessage:         "Drop off",
+			}},
+			false,
+		},
+
+		{
+			"prior days",
+			`
+booking_rule_id,booking_type,prior_notice_last_day,prior_notice_last_time,prior_notice_start_day,prior_notice_start_time,prior_notice_service_id
+pd,2,1,17:00:00,14,08:00:00,weekdays`,
+			[]model.BookingRule{{
+				ID:                   "pd",
+				Type:                 model.BookingTypePriorDays,
+				PriorNoticeLastDay:   1,
+				PriorNoticeLastTime:  "170000",
+				PriorNoticeStartDay:  14,
+				PriorNoticeStartTime: "080000",
+				PriorNoticeServiceID: "weekdays",
+			}},
+			false,
+		},
+
+		{
+			"empty booking_rule_id",
+			"booking_rule_id,booking_type\n,0",
+			nil, true,
+		},
+
+		{
+			"repeated booking_rule_id",
+			"booking_rule_id,booking_type\nb,0\nb,0",
+			nil, true,
+		},
+
+		{
+			"invalid booking_type",
+			"booking_rule_id,booking_type\nb,3",
+			nil, true,
+		},
+
+		{
+			"missing booking_type",
+			"booking_rule_id,booking_type\nb,",
+			nil, true,
+		},
+
+		{
+			"prior notice on real time booking",
+			"booking_rule_id,booking_type,prior_notice_duration_min\nb,0,30",
+			nil, true,
+		},
+
+		{
+			"same day without prior_notice_duration_min",
+			"booking_rule_id,booking_type\nb,1",
+			nil, true,
+		},
+
+		{
+			"same day with max less than min",
+			"booking_rule_id,booking_type,prior_notice_duration_min,prior_notice_duration_max\nb,1,60,30",
+			nil, true,
+		},
+
+		{
+			"prior days without prior_notice_last_day",
+			"booking_rule_id,booking_type,prior_notice_last_time\nb,2,17:00:00",
+			nil, true,
+		},
+
+		{
+			"prior days without prior_notice_last_time",
+			"booking_rule_id,booking_type,prior_notice_last_day\nb,2,1",
+			nil, true,
+		},
+
+		{
+			"invalid prior_notice_last_time",
+			"booking_rule_id,booking_type,prior_notice_last_day,prior_notice_last_time\nb,2,1,5pm",
+			nil, true,
+		},
+
+		{
+			"unknown prior_notice_service_id",
+			"booking_rule_id,booking_type,prior_notice_last_day,prior_notice_last_time,prior_notice_service_id\nb,2,1,17:00:00,weekends",
+			nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			ruleIDs, err := ParseBookingRules(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"weekdays": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.expected), len(ruleIDs))
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			rules, err := reader.BookingRules()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rules)
+		})
+	}
+}
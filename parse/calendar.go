@@ -3,6 +3,7 @@ package parse
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/gocarina/gocsv"
@@ -26,23 +27,29 @@ type CalendarCSV struct {
 
 // Returns set of all service IDs, min date and max date.
 func ParseCalendar(writer storage.FeedWriter, data io.Reader) (map[string]bool, string, string, error) {
+	return parseCalendar(writer, data, nil)
+}
+
+// Parses calendar.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse, and left out of the returned service
+// IDs.
+func parseCalendar(writer storage.FeedWriter, data io.Reader, drop *dropped) (map[string]bool, string, string, error) {
 	calendarCsv := []*CalendarCSV{}
 	if err := gocsv.Unmarshal(data, &calendarCsv); err != nil {
-		return nil, "", "", fmt.Errorf("unmarshaling csv: %w", err)
+		return nil, "", "", errMalformed("calendar.txt", err)
 	}
 
 	knownServices := map[string]bool{}
 
 	var minDate, maxDate string
 
-	for _, c := range calendarCsv {
+	parseRow := func(row int, c *CalendarCSV) error {
 		if knownServices[c.ServiceID] {
-			return nil, "", "", fmt.Errorf("repeated service_id '%s'", c.ServiceID)
+			return errDuplicate("calendar.txt", row, "service_id", c.ServiceID)
 		}
-		knownServices[c.ServiceID] = true
 
 		if c.ServiceID == "" {
-			return nil, "", "", fmt.Errorf("empty service_id")
+			return errMissing("calendar.txt", row, "service_id")
 		}
 
 		var weekday int8
@@ -50,47 +57,47 @@ func ParseCalendar(writer storage.FeedWriter, data io.Reader) (map[string]bool,
 		if c.Monday == 1 {
 			weekday |= 1 << time.Monday
 		} else if c.Monday != 0 {
-			return nil, "", "", fmt.Errorf("invalid monday value '%d'", c.Monday)
+			return errInvalid("calendar.txt", row, "monday", strconv.Itoa(int(c.Monday)), nil)
 		}
 		if c.Tuesday == 1 {
 			weekday |= 1 << time.Tuesday
 		} else if c.Tuesday != 0 {
-			return nil, "", "", fmt.Errorf("invalid tuesday value '%d'", c.Tuesday)
+			return errInvalid("calendar.txt", row, "tuesday", strconv.Itoa(int(c.Tuesday)), nil)
 		}
 		if c.Wednesday == 1 {
 			weekday |= 1 << time.Wednesday
 		} else if c.Wednesday != 0 {
-			return nil, "", "", fmt.Errorf("invalid wednesday value '%d'", c.Wednesday)
+			return errInvalid("calendar.txt", row, "wednesday", strconv.Itoa(int(c.Wednesday)), nil)
 		}
 		if c.Thursday == 1 {
 			weekday |= 1 << time.Thursday
 		} else if c.Thursday != 0 {
-			return nil, "", "", fmt.Errorf("invalid thursday value '%d'", c.Thursday)
+			return errInvalid("calendar.txt", row, "thursday", strconv.Itoa(int(c.Thursday)), nil)
 		}
 		if c.Friday == 1 {
 			weekday |= 1 << time.Friday
 		} else if c.Friday != 0 {
-			return nil, "", "", fmt.Errorf("invalid friday value '%d'", c.Friday)
+			return errInvalid("calendar.txt", row, "friday", strconv.Itoa(int(c.Friday)), nil)
 		}
 		if c.Saturday == 1 {
 			weekday |= 1 << time.Saturday
 		} else if c.Saturday != 0 {
-			return nil, "", "", fmt.Errorf("invalid saturday value '%d'", c.Saturday)
+			return errInvalid("calendar.txt", row, "saturday", strconv.Itoa(int(c.Saturday)), nil)
 		}
 		if c.Sunday == 1 {
 			weekday |= 1 << time.Sunday
 		} else if c.Sunday != 0 {
-			return nil, "", "", fmt.Errorf("invalid sunday value '%d'", c.Sunday)
+			return errInvalid("calendar.txt", row, "sunday", strconv.Itoa(int(c.Sunday)), nil)
 		}
 
 		_, err := time.ParseInLocation("20060102", c.StartDate, time.UTC)
 		if err != nil {
-			return nil, "", "", fmt.Errorf("parsing start_date: %w", err)
+			return errInvalid("calendar.txt", row, "start_date", c.StartDate, err)
 		}
 
 		_, err = time.ParseInLocation("20060102", c.EndDate, time.UTC)
 		if err != nil {
-			return nil, "", "", fmt.Errorf("parsing end_date: %w", err)
+			return errInvalid("calendar.txt", row, "end_date", c.EndDate, err)
 		}
 
 		if minDate == "" || c.StartDate < minDate {
@@ -107,7 +114,16 @@ func ParseCalendar(writer storage.FeedWriter, data io.Reader) (map[string]bool,
 			Weekday:   weekday,
 		})
 		if err != nil {
-			return nil, "", "", fmt.Errorf("writing calendar: %w", err)
+			return errStorage("calendar.txt", row, fmt.Errorf("writing calendar: %w", err))
+		}
+
+		knownServices[c.ServiceID] = true
+		return nil
+	}
+
+	for i, c := range calendarCsv {
+		if err := drop.skip(parseRow(i+1, c)); err != nil {
+			return nil, "", "", err
 		}
 	}
 
@@ -3,6 +3,7 @@ package parse
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/gocarina/gocsv"
@@ -21,29 +22,46 @@ func ParseCalendarDates(
 	writer storage.FeedWriter,
 	data io.Reader,
 ) (map[string]bool, string, string, error) {
+	return parseCalendarDates(writer, data, nil)
+}
+
+// Parses calendar_dates.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse.
+func parseCalendarDates(
+	writer storage.FeedWriter,
+	data io.Reader,
+	drop *dropped,
+) (map[string]bool, string, string, error) {
 
 	calendarDateCsv := []*CalendarDateCSV{}
 	if err := gocsv.Unmarshal(data, &calendarDateCsv); err != nil {
-		return nil, "", "", fmt.Errorf("unmarshaling calendar_dates csv: %w", err)
+		return nil, "", "", errMalformed("calendar_dates.txt", err)
 	}
 
 	knownService := map[string]bool{}
 	knownServiceDate := map[string]bool{}
 	var minDate, maxDate string
 
-	for _, cd := range calendarDateCsv {
+	parseRow := func(row int, cd *CalendarDateCSV) error {
 		if cd.ExceptionType < 1 || cd.ExceptionType > 2 {
-			return nil, "", "", fmt.Errorf("illegal exception_type: '%d'", cd.ExceptionType)
+			return errInvalid("calendar_dates.txt", row, "exception_type", strconv.Itoa(int(cd.ExceptionType)), nil)
 		}
 
 		_, err := time.ParseInLocation("20060102", cd.Date, time.UTC)
 		if err != nil {
-			return nil, "", "", fmt.Errorf("parsing date '%s': %w", cd.Date, err)
+			return errInvalid("calendar_dates.txt", row, "date", cd.Date, err)
 		}
 
 		serviceDate := fmt.Sprintf("%s-%s", cd.Date, cd.ServiceID)
 		if knownServiceDate[serviceDate] {
-			return nil, "", "", fmt.Errorf("duplicate service/date: '%s'", serviceDate)
+			return &ParseError{
+				File:   "calendar_dates.txt",
+				Row:    row,
+				Column: "date",
+				Value:  cd.Date,
+				Code:   CodeDuplicateKey,
+				Err:    fmt.Errorf("for service_id '%s'", cd.ServiceID),
+			}
 		}
 		knownServiceDate[serviceDate] = true
 		knownService[cd.ServiceID] = true
@@ -60,6 +78,14 @@ func ParseCalendarDates(
 			Date:          cd.Date,
 			ExceptionType: model.ExceptionType(cd.ExceptionType),
 		})
+
+		return nil
+	}
+
+	for i, cd := range calendarDateCsv {
+		if err := drop.skip(parseRow(i+1, cd)); err != nil {
+			return nil, "", "", err
+		}
 	}
 
 	return knownService, minDate, maxDate, nil
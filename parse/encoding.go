@@ -0,0 +1,274 @@
+package parse
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Character encoding of the files in a static feed. The GTFS spec
+// requires UTF-8, but feeds encoded otherwise are common enough.
+type Encoding string
+
+const (
+	// Detect the encoding of each file. Files starting with a
+	// UTF-16 byte order mark, or looking like UTF-16 (i.e. with
+	// NUL bytes alternating with ASCII), are read as UTF-16.
+	// Otherwise, files are read as UTF-8, with any bytes that
+	// aren't valid UTF-8 read as Windows-1252.
+	EncodingAuto Encoding = ""
+
+	EncodingUTF8        Encoding = "utf-8"
+	EncodingUTF16LE     Encoding = "utf-16le"
+	EncodingUTF16BE     Encoding = "utf-16be"
+	EncodingWindows1252 Encoding = "windows-1252"
+	EncodingLatin1      Encoding = "iso-8859-1"
+)
+
+var encodingAliases = map[string]Encoding{
+	"":             EncodingAuto,
+	"auto":         EncodingAuto,
+	"utf-8":        EncodingUTF8,
+	"utf8":         EncodingUTF8,
+	"utf-16le":     EncodingUTF16LE,
+	"utf-16be":     EncodingUTF16BE,
+	"windows-1252": EncodingWindows1252,
+	"cp1252":       EncodingWindows1252,
+	"iso-8859-1":   EncodingLatin1,
+	"latin1":       EncodingLatin1,
+	"latin-1":      EncodingLatin1,
+}
+
+// Looks up an encoding by name, e.g. "utf-8" or "windows-1252".
+// Names are case insensitive, and "" or "auto" gives EncodingAuto.
+func LookupEncoding(name string) (Encoding, error) {
+	enc, found := encodingAliases[strings.ToLower(strings.TrimSpace(name))]
+	if !found {
+		return "", fmt.Errorf("unknown encoding '%s'", name)
+	}
+	return enc, nil
+}
+
+// Number of bytes examined when looking for UTF-16 without a byte
+// order mark.
+const sniffLen = 512
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// Transcodes data in the given encoding to UTF-8. A byte order mark
+// matching the encoding is stripped. Closing the returned reader
+// closes data. Used by ParseStatic on every file, and exported for
+// other readers of feeds, e.g. the validator.
+func NewDecoder(data io.ReadCloser, enc Encoding) io.ReadCloser {
+	return &decoder{data: data, r: bufio.NewReader(data), enc: enc}
+}
+
+type decoder struct {
+	data io.ReadCloser
+	r    *bufio.Reader
+	enc  Encoding
+
+	// Converts src to UTF-8, appending to dst. Returns the
+	// number of bytes of src consumed, which is all of them if
+	// atEOF is set.
+	convert func(dst []byte, src []byte, atEOF bool) ([]byte, int)
+
+	src []byte // read, but not yet converted
+	dst []byte // converted, but not yet returned
+	err error
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	if d.convert == nil {
+		d.convert = d.detect()
+	}
+
+	for len(d.dst) == 0 && d.err == nil {
+		var chunk [4096]byte
+		n, err := d.r.Read(chunk[:])
+		d.src = append(d.src, chunk[:n]...)
+		d.err = err
+
+		var consumed int
+		d.dst, consumed = d.convert(d.dst[:0], d.src, err != nil)
+		d.src = append(d.src[:0], d.src[consumed:]...)
+	}
+
+	n := copy(p, d.dst)
+	d.dst = d.dst[n:]
+	if n == 0 {
+		return 0, d.err
+	}
+	return n, nil
+}
+
+func (d *decoder) Close() error {
+	return d.data.Close()
+}
+
+// Picks a converter for the configured encoding, consuming any byte
+// order mark. With EncodingAuto, the encoding is detected from the
+// start of the data.
+func (d *decoder) detect() func([]byte, []byte, bool) ([]byte, int) {
+	// Errors are left for Read() to pick up.
+	head, _ := d.r.Peek(sniffLen)
+
+	skip := func(bom []byte) {
+		if bytes.HasPrefix(head, bom) {
+			d.r.Discard(len(bom))
+		}
+	}
+
+	switch d.enc {
+	case EncodingUTF8:
+		skip(bomUTF8)
+		return convertUTF8
+	case EncodingUTF16LE:
+		skip(bomUTF16LE)
+		return convertUTF16(binary.LittleEndian)
+	case EncodingUTF16BE:
+		skip(bomUTF16BE)
+		return convertUTF16(binary.BigEndian)
+	case EncodingWindows1252:
+		return convertWindows1252
+	case EncodingLatin1:
+		return convertLatin1
+	}
+
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		skip(bomUTF8)
+		return convertUTF8
+	case bytes.HasPrefix(head, bomUTF16LE):
+		skip(bomUTF16LE)
+		return convertUTF16(binary.LittleEndian)
+	case bytes.HasPrefix(head, bomUTF16BE):
+		skip(bomUTF16BE)
+		return convertUTF16(binary.BigEndian)
+	}
+
+	// CSV headers are ASCII, so UTF-16 without a BOM has NUL in
+	// every other byte.
+	evenNUL, oddNUL := 0, 0
+	for i := 0; i+1 < len(head); i += 2 {
+		if head[i] == 0 {
+			evenNUL++
+		}
+		if head[i+1] == 0 {
+			oddNUL++
+		}
+	}
+	pairs := len(head) / 2
+	switch {
+	case pairs > 0 && oddNUL*2 > pairs && evenNUL == 0:
+		return convertUTF16(binary.LittleEndian)
+	case pairs > 0 && evenNUL*2 > pairs && oddNUL == 0:
+		return convertUTF16(binary.BigEndian)
+	}
+
+	return convertUTF8
+}
+
+// Passes valid UTF-8 through as is. Bytes that aren't valid UTF-8
+// are read as Windows-1252.
+func convertUTF8(dst []byte, src []byte, atEOF bool) ([]byte, int) {
+	i := 0
+	for i < len(src) {
+		if src[i] < utf8.RuneSelf {
+			dst = append(dst, src[i])
+			i++
+			continue
+		}
+		if !atEOF && !utf8.FullRune(src[i:]) {
+			break
+		}
+		r, size := utf8.DecodeRune(src[i:])
+		if r == utf8.RuneError && size <= 1 {
+			dst = utf8.AppendRune(dst, windows1252(src[i]))
+			i++
+			continue
+		}
+		dst = append(dst, src[i:i+size]...)
+		i += size
+	}
+	return dst, i
+}
+
+func convertWindows1252(dst []byte, src []byte, atEOF bool) ([]byte, int) {
+	for _, b := range src {
+		dst = utf8.AppendRune(dst, windows1252(b))
+	}
+	return dst, len(src)
+}
+
+func convertLatin1(dst []byte, src []byte, atEOF bool) ([]byte, int) {
+	for _, b := range src {
+		dst = utf8.AppendRune(dst, rune(b))
+	}
+	return dst, len(src)
+}
+
+// Unpaired surrogates, and a trailing odd byte, are replaced with
+// U+FFFD.
+func convertUTF16(order binary.ByteOrder) func([]byte, []byte, bool) ([]byte, int) {
+	return func(dst []byte, src []byte, atEOF bool) ([]byte, int) {
+		i := 0
+		for i+1 < len(src) {
+			r := rune(order.Uint16(src[i:]))
+			if !utf16.IsSurrogate(r) {
+				dst = utf8.AppendRune(dst, r)
+				i += 2
+				continue
+			}
+			if i+3 >= len(src) {
+				if !atEOF {
+					break
+				}
+				dst = utf8.AppendRune(dst, utf8.RuneError)
+				i += 2
+				continue
+			}
+			r = utf16.DecodeRune(r, rune(order.Uint16(src[i+2:])))
+			if r == utf8.RuneError {
+				// Unpaired. The next unit is decoded
+				// on its own.
+				dst = utf8.AppendRune(dst, r)
+				i += 2
+				continue
+			}
+			dst = utf8.AppendRune(dst, r)
+			i += 4
+		}
+		if atEOF && i < len(src) {
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			i = len(src)
+		}
+		return dst, i
+	}
+}
+
+// Windows-1252 matches Latin-1, except for printable characters in
+// 0x80-0x9f. The five bytes left undefined are read as the C1
+// control characters Latin-1 has there.
+var windows1252High = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', 0x8d, 'Ž', 0x8f,
+	0x90, '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', 0x9d, 'ž', 'Ÿ',
+}
+
+func windows1252(b byte) rune {
+	if b >= 0x80 && b < 0xa0 {
+		return windows1252High[b-0x80]
+	}
+	return rune(b)
+}
@@ -0,0 +1,190 @@
+package parse
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/storage"
+)
+
+// Encodes s as UTF-16, with a BOM if requested.
+func encodeUTF16(s string, order binary.ByteOrder, bom bool) string {
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xfeff}, units...)
+	}
+	buf := make([]byte, 2*len(units))
+	for i, u := range units {
+		order.PutUint16(buf[2*i:], u)
+	}
+	return string(buf)
+}
+
+func decode(t *testing.T, data string, enc Encoding) string {
+	buf, err := io.ReadAll(NewDecoder(io.NopCloser(strings.NewReader(data)), enc))
+	require.NoError(t, err)
+	return string(buf)
+}
+
+func TestDecoder(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		data     string
+		enc      Encoding
+		expected string
+	}{
+		{"utf-8", "stop_name\nK\xc3\xb6ln", EncodingAuto, "stop_name\nKöln"},
+		{"utf-8 bom", "\xef\xbb\xbfstop_name\nK\xc3\xb6ln", EncodingAuto, "stop_name\nKöln"},
+		{"windows-1252", "stop_name\nK\xf6ln \x80", EncodingAuto, "stop_name\nKöln €"},
+		{"windows-1252 at eof", "stop_name\nKoel\xe9", EncodingAuto, "stop_name\nKoelé"},
+		{"utf-16le bom", encodeUTF16("stop_name\nKöln 𝄞", binary.LittleEndian, true), EncodingAuto, "stop_name\nKöln 𝄞"},
+		{"utf-16be bom", encodeUTF16("stop_name\nKöln 𝄞", binary.BigEndian, true), EncodingAuto, "stop_name\nKöln 𝄞"},
+		{"utf-16le", encodeUTF16("stop_name\nKöln", binary.LittleEndian, false), EncodingAuto, "stop_name\nKöln"},
+		{"utf-16be", encodeUTF16("stop_name\nKöln", binary.BigEndian, false), EncodingAuto, "stop_name\nKöln"},
+		{"utf-16 odd byte", encodeUTF16("stop_name", binary.LittleEndian, true) + "x", EncodingAuto, "stop_name�"},
+		{"utf-16 unpaired surrogate", encodeUTF16("ab", binary.BigEndian, true)[:4] + "\xd8\x00" + encodeUTF16("c", binary.BigEndian, false), EncodingAuto, "a�c"},
+		{"override utf-8", "K\xc3\xb6ln", EncodingUTF8, "Köln"},
+		{"override windows-1252", "K\xc3\xb6ln \x80", EncodingWindows1252, "KÃ¶ln €"},
+		{"override latin-1", "K\xf6ln \x80", EncodingLatin1, "Köln \u0080"},
+		{"override utf-16le", encodeUTF16("Köln", binary.LittleEndian, true), EncodingUTF16LE, "Köln"},
+		{"override utf-16be", encodeUTF16("Köln", binary.BigEndian, false), EncodingUTF16BE, "Köln"},
+		{"empty", "", EncodingAuto, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, decode(t, tc.data, tc.enc))
+		})
+	}
+}
+
+func TestDecoderLargeInput(t *testing.T) {
+	// Multibyte characters straddle the chunks read from the
+	// underlying reader.
+	text := "stop_name\n" + strings.Repeat("Köln 𝄞 Café\n", 2000)
+
+	assert.Equal(t, text, decode(t, text, EncodingAuto))
+	assert.Equal(t, text, decode(t, encodeUTF16(text, binary.LittleEndian, false), EncodingAuto))
+	assert.Equal(t, text, decode(t, encodeUTF16(text, binary.BigEndian, true), EncodingAuto))
+
+	latin := strings.Repeat("K\xf6ln\n", 2000)
+	assert.Equal(t, strings.Repeat("Köln\n", 2000), decode(t, latin, EncodingAuto))
+}
+
+func TestLookupEncoding(t *testing.T) {
+	for name, expected := range map[string]Encoding{
+		"":             EncodingAuto,
+		"auto":         EncodingAuto,
+		"UTF-8":        EncodingUTF8,
+		"utf-16le":     EncodingUTF16LE,
+		"UTF-16BE":     EncodingUTF16BE,
+		"cp1252":       EncodingWindows1252,
+		"Windows-1252": EncodingWindows1252,
+		"latin1":       EncodingLatin1,
+	} {
+		enc, err := LookupEncoding(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, enc, name)
+	}
+
+	_, err := LookupEncoding("ebcdic")
+	assert.Error(t, err)
+}
+
+func TestParseStaticEncoding(t *testing.T) {
+	latin1 := func(s string) string {
+		buf := []byte{}
+		for _, r := range s {
+			buf = append(buf, byte(r))
+		}
+		return string(buf)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		encode   func(string) string
+		options  Options
+		expected string
+	}{
+		{
+			"utf-8",
+			func(s string) string { return s },
+			Options{},
+			"Düsseldorf Hbf",
+		},
+		{
+			"latin-1",
+			latin1,
+			Options{},
+			"Düsseldorf Hbf",
+		},
+		{
+			"utf-16le",
+			func(s string) string { return encodeUTF16(s, binary.LittleEndian, true) },
+			Options{},
+			"Düsseldorf Hbf",
+		},
+		{
+			"utf-16be without bom",
+			func(s string) string { return encodeUTF16(s, binary.BigEndian, false) },
+			Options{},
+			"Düsseldorf Hbf",
+		},
+		{
+			"utf-16le lenient",
+			func(s string) string { return encodeUTF16(s, binary.LittleEndian, false) },
+			Options{Lenient: true},
+			"Düsseldorf Hbf",
+		},
+		{
+			"override",
+			func(s string) string { return s },
+			Options{Encoding: EncodingWindows1252},
+			"DÃ¼sseldorf Hbf",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files := fixtureSimple()
+			files["stops.txt"] = []string{
+				"stop_id,stop_name,stop_lat,stop_lon",
+				"s,Düsseldorf Hbf,12,34",
+			}
+			fsys := fstest.MapFS{}
+			for name, content := range files {
+				fsys[name] = &fstest.MapFile{Data: []byte(tc.encode(strings.Join(content, "\n")))}
+			}
+
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+			_, err = ParseStaticFSWithOptions(writer, fsys, tc.options)
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			stops, err := reader.Stops()
+			require.NoError(t, err)
+			require.Equal(t, 1, len(stops))
+			assert.Equal(t, tc.expected, stops[0].Name)
+			stopTimes, err := reader.StopTimes()
+			require.NoError(t, err)
+			assert.Equal(t, 1, len(stopTimes))
+		})
+	}
+
+	// Unknown encodings are rejected
+	s, err := storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err := s.GetWriter("test")
+	require.NoError(t, err)
+	_, err = ParseStaticFSWithOptions(writer, fstest.MapFS{}, Options{Encoding: "ebcdic"})
+	parseErr, ok := err.(*ParseError)
+	require.True(t, ok)
+	assert.Equal(t, CodeUnreadableFeed, parseErr.Code)
+}
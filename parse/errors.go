@@ -0,0 +1,121 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Machine-readable classification of a ParseError.
+type ErrorCode string
+
+const (
+	// The archive or filesystem holding the feed can't be read.
+	CodeUnreadableFeed ErrorCode = "unreadable_feed"
+
+	// A required file is missing from the feed.
+	CodeMissingFile ErrorCode = "missing_file"
+
+	// A file can't be decoded as CSV (or GeoJSON).
+	CodeMalformedFile ErrorCode = "malformed_file"
+
+	// A required value is empty or missing.
+	CodeMissingValue ErrorCode = "missing_value"
+
+	// A value is malformed, out of range or inconsistent with
+	// other values.
+	CodeInvalidValue ErrorCode = "invalid_value"
+
+	// A value that must be unique is repeated.
+	CodeDuplicateKey ErrorCode = "duplicate_key"
+
+	// A value references an ID not found in the feed.
+	CodeUnknownReference ErrorCode = "unknown_reference"
+
+	// The parsed data couldn't be written to storage.
+	CodeStorage ErrorCode = "storage"
+)
+
+// A problem encountered when parsing a static feed. All errors out of
+// ParseStatic and the Parse* functions for individual files are
+// ParseErrors.
+//
+// File is the name of the offending file, if any. Row is the 1-based
+// row number within the file, not counting the header, or 0 if the
+// problem isn't with a single row. Column and Value are set when the
+// problem is with a single value.
+type ParseError struct {
+	File   string
+	Row    int
+	Column string
+	Value  string
+	Code   ErrorCode
+
+	// Underlying error, or further detail on the problem.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	var msg string
+	switch {
+	case e.Column == "" && e.Err != nil:
+		msg = e.Err.Error()
+	case e.Column == "":
+		msg = strings.ReplaceAll(string(e.Code), "_", " ")
+	case e.Code == CodeMissingValue:
+		msg = fmt.Sprintf("missing %s", e.Column)
+	case e.Code == CodeDuplicateKey:
+		msg = fmt.Sprintf("repeated %s '%s'", e.Column, e.Value)
+	case e.Code == CodeUnknownReference:
+		msg = fmt.Sprintf("unknown %s '%s'", e.Column, e.Value)
+	default:
+		msg = fmt.Sprintf("invalid %s '%s'", e.Column, e.Value)
+	}
+
+	if e.Column != "" && e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err)
+	}
+	if e.File != "" {
+		msg = fmt.Sprintf("%s: %s", e.File, msg)
+	}
+	if e.Row > 0 {
+		msg = fmt.Sprintf("%s (row %d)", msg, e.Row)
+	}
+
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors are passed through as is, as when returned from a
+// gocsv callback.
+func errMalformed(file string, err error) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr
+	}
+	return &ParseError{File: file, Code: CodeMalformedFile, Err: err}
+}
+
+func errMissing(file string, row int, column string) error {
+	return &ParseError{File: file, Row: row, Column: column, Code: CodeMissingValue}
+}
+
+// Detail on why the value is invalid is optional, and may be nil.
+func errInvalid(file string, row int, column string, value string, detail error) error {
+	return &ParseError{File: file, Row: row, Column: column, Value: value, Code: CodeInvalidValue, Err: detail}
+}
+
+func errDuplicate(file string, row int, column string, value string) error {
+	return &ParseError{File: file, Row: row, Column: column, Value: value, Code: CodeDuplicateKey}
+}
+
+func errUnknown(file string, row int, column string, value string) error {
+	return &ParseError{File: file, Row: row, Column: column, Value: value, Code: CodeUnknownReference}
+}
+
+func errStorage(file string, row int, err error) error {
+	return &ParseError{File: file, Row: row, Code: CodeStorage, Err: err}
+}
@@ -0,0 +1,142 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseErrorMessage(t *testing.T) {
+	for _, tc := range []struct {
+		err      *ParseError
+		expected string
+	}{
+		{
+			&ParseError{File: "stops.txt", Code: CodeMissingFile},
+			"stops.txt: missing file",
+		},
+		{
+			&ParseError{File: "routes.txt", Code: CodeMalformedFile, Err: fmt.Errorf("bad quote")},
+			"routes.txt: bad quote",
+		},
+		{
+			&ParseError{File: "trips.txt", Row: 2, Column: "trip_id", Code: CodeMissingValue},
+			"trips.txt: missing trip_id (row 2)",
+		},
+		{
+			&ParseError{File: "trips.txt", Row: 3, Column: "trip_id", Value: "t", Code: CodeDuplicateKey},
+			"trips.txt: repeated trip_id 't' (row 3)",
+		},
+		{
+			&ParseError{File: "trips.txt", Row: 4, Column: "route_id", Value: "r", Code: CodeUnknownReference},
+			"trips.txt: unknown route_id 'r' (row 4)",
+		},
+		{
+			&ParseError{File: "frequencies.txt", Row: 5, Column: "end_time", Value: "10:00:00", Code: CodeInvalidValue, Err: fmt.Errorf("not after start_time")},
+			"frequencies.txt: invalid end_time '10:00:00': not after start_time (row 5)",
+		},
+		{
+			&ParseError{Code: CodeUnreadableFeed, Err: fmt.Errorf("unzipping: not a zip")},
+			"unzipping: not a zip",
+		},
+	} {
+		assert.Equal(t, tc.expected, tc.err.Error())
+	}
+}
+
+func TestParseErrorUnwrap(t *testing.T) {
+	cause := fmt.Errorf("disk full")
+	err := fmt.Errorf("wrapped: %w", errStorage("stops.txt", 1, cause))
+
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, CodeStorage, parseErr.Code)
+	assert.True(t, errors.Is(err, cause))
+}
+
+func TestParseStaticError(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		modify   func(files map[string][]string)
+		expected ParseError
+	}{
+		{
+			"missing file",
+			func(files map[string][]string) {
+				delete(files, "stops.txt")
+			},
+			ParseError{File: "stops.txt", Code: CodeMissingFile},
+		},
+		{
+			"missing value",
+			func(files map[string][]string) {
+				files["agency.txt"][1] = "America/Los_Angeles,,http://agency/index.html"
+			},
+			ParseError{File: "agency.txt", Row: 1, Column: "agency_name", Code: CodeMissingValue},
+		},
+		{
+			"invalid value",
+			func(files map[string][]string) {
+				files["calendar_dates.txt"] = append(files["calendar_dates.txt"], "mondays,20190303,3")
+			},
+			ParseError{File: "calendar_dates.txt", Row: 2, Column: "exception_type", Value: "3", Code: CodeInvalidValue},
+		},
+		{
+			"duplicate key",
+			func(files map[string][]string) {
+				files["stops.txt"] = append(files["stops.txt"], "s,S again,12,34")
+			},
+			ParseError{File: "stops.txt", Row: 2, Column: "stop_id", Value: "s", Code: CodeDuplicateKey},
+		},
+		{
+			"unknown reference",
+			func(files map[string][]string) {
+				files["stop_times.txt"] = append(files["stop_times.txt"], "t,12:05:00,12:05:00,x,2")
+			},
+			ParseError{File: "stop_times.txt", Row: 2, Column: "stop_id", Value: "x", Code: CodeUnknownReference},
+		},
+		{
+			"invalid time",
+			func(files map[string][]string) {
+				files["stop_times.txt"] = append(files["stop_times.txt"], "t,12:5,12:05:00,s,2")
+			},
+			ParseError{File: "stop_times.txt", Row: 2, Column: "arrival_time", Value: "12:5", Code: CodeInvalidValue},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			files := fixtureSimple()
+			tc.modify(files)
+
+			_, err = ParseStatic(writer, buildZip(t, files))
+			require.Error(t, err)
+
+			parseErr, ok := err.(*ParseError)
+			require.True(t, ok, "not a ParseError: %s", err)
+			assert.Equal(t, tc.expected.File, parseErr.File)
+			assert.Equal(t, tc.expected.Row, parseErr.Row)
+			assert.Equal(t, tc.expected.Column, parseErr.Column)
+			assert.Equal(t, tc.expected.Value, parseErr.Value)
+			assert.Equal(t, tc.expected.Code, parseErr.Code)
+		})
+	}
+
+	// Broken zip
+	s, err := storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err := s.GetWriter("test")
+	require.NoError(t, err)
+	_, err = ParseStatic(writer, []byte("malformed"))
+	parseErr, ok := err.(*ParseError)
+	require.True(t, ok)
+	assert.Equal(t, CodeUnreadableFeed, parseErr.Code)
+}
@@ -0,0 +1,105 @@
+package parse
+
+import (
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gocarina/gocsv"
+	"github.com/spkg/bom"
+)
+
+// Columns defined by the spec for a file, but not parsed by its CSV
+// struct. They're not extensions, so aren't captured as extras.
+var unparsedSpecColumns = map[string][]string{
+	"agency.txt": {"cemv_support"},
+	"stops.txt":  {"tts_stop_name", "stop_access"},
+	"routes.txt": {"cemv_support"},
+}
+
+// Unmarshals CSV data from file into out, a pointer to a slice of
+// CSV struct pointers, like gocsv.Unmarshal(). If extras is set,
+// values in columns neither the CSV struct nor the spec know of are
+// also captured, in the struct's Extras field. Records without such
+// values are left with a nil map.
+func unmarshalExtras(data io.Reader, out interface{}, file string, extras bool) error {
+	if !extras {
+		return gocsv.Unmarshal(data, out)
+	}
+
+	known := csvColumns(out)
+	for _, column := range unparsedSpecColumns[file] {
+		known[column] = true
+	}
+
+	// Same reader as set up by ParseStatic.
+	r := &extrasReader{
+		CSVReader: gocsv.LazyCSVReader(bom.NewReader(data)),
+		known:     known,
+	}
+	err := gocsv.UnmarshalCSV(r, out)
+	if err != nil {
+		return err
+	}
+
+	// gocsv produces one record per row, in order.
+	records := reflect.ValueOf(out).Elem()
+	for i := 0; i < records.Len() && i < len(r.extras); i++ {
+		records.Index(i).Elem().FieldByName("Extras").Set(reflect.ValueOf(r.extras[i]))
+	}
+
+	return nil
+}
+
+// Columns tagged in the CSV struct held by out.
+func csvColumns(out interface{}) map[string]bool {
+	t := reflect.TypeOf(out)
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	columns := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("csv"), ",")
+		if name != "" && name != "-" {
+			columns[name] = true
+		}
+	}
+	return columns
+}
+
+// A CSVReader recording values in unknown columns as gocsv reads
+// all rows.
+type extrasReader struct {
+	gocsv.CSVReader
+	known  map[string]bool
+	extras []map[string]string
+}
+
+func (r *extrasReader) ReadAll() ([][]string, error) {
+	records, err := r.CSVReader.ReadAll()
+	if err != nil || len(records) == 0 {
+		return records, err
+	}
+
+	header := make([]string, len(records[0]))
+	for i, column := range records[0] {
+		header[i] = strings.TrimSpace(column)
+	}
+
+	for _, record := range records[1:] {
+		var extras map[string]string
+		for i, value := range record {
+			if i >= len(header) || header[i] == "" || r.known[header[i]] || value == "" {
+				continue
+			}
+			if extras == nil {
+				extras = map[string]string{}
+			}
+			extras[header[i]] = value
+		}
+		r.extras = append(r.extras, extras)
+	}
+
+	return records, nil
+}
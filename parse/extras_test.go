@@ -0,0 +1,107 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseStaticExtras(t *testing.T) {
+	files := fixtureSimple()
+	files["agency.txt"] = []string{
+		"agency_timezone,agency_name,agency_url,ticket_url,cemv_support",
+		"America/Los_Angeles,Fake Agency,http://agency/index.html,http://agency/tickets,1",
+	}
+	files["routes.txt"] = []string{
+		"route_id,route_short_name,route_type,route_branding,network_id,cemv_support",
+		"r,R,3,Express,bus,2",
+	}
+	files["trips.txt"] = []string{
+		"route_id,service_id,trip_id,vehicle_type, bikes_racks ",
+		"r,mondays,t,articulated,2",
+	}
+	files["stops.txt"] = []string{
+		"stop_id,stop_name,platform_side,stop_lat,wifi,stop_lon,tts_stop_name,stop_access",
+		"s,S,left,12,,34,Ess,1",
+		"s2,S2,,56,,78,,",
+	}
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(strings.Join(content, "\n"))}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		options Options
+	}{
+		{"strict", Options{Extras: true}},
+		{"lenient", Options{Extras: true, Lenient: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+			_, err = ParseStaticFSWithOptions(writer, fsys, tc.options)
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+
+			agencies, err := reader.Agencies()
+			require.NoError(t, err)
+			require.Equal(t, 1, len(agencies))
+			assert.Equal(t, map[string]string{"ticket_url": "http://agency/tickets"}, agencies[0].Extras)
+
+			routes, err := reader.Routes()
+			require.NoError(t, err)
+			require.Equal(t, 1, len(routes))
+			assert.Equal(t, map[string]string{"route_branding": "Express"}, routes[0].Extras)
+			assert.Equal(t, "bus", routes[0].NetworkID)
+
+			// Header whitespace is trimmed
+			trips, err := reader.Trips()
+			require.NoError(t, err)
+			require.Equal(t, 1, len(trips))
+			assert.Equal(t, map[string]string{"vehicle_type": "articulated", "bikes_racks": "2"}, trips[0].Extras)
+
+			// Blank values are left out, and spec columns
+			// are parsed as usual, or ignored if not
+			// parsed at all
+			stop, err := reader.Stop("s")
+			require.NoError(t, err)
+			require.NotNil(t, stop)
+			assert.Equal(t, 12.0, stop.Lat)
+			assert.Equal(t, 34.0, stop.Lon)
+			assert.Equal(t, map[string]string{"platform_side": "left"}, stop.Extras)
+			stop, err = reader.Stop("s2")
+			require.NoError(t, err)
+			require.NotNil(t, stop)
+			assert.Nil(t, stop.Extras)
+		})
+	}
+
+	// Not captured unless requested
+	s, err := storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err := s.GetWriter("test")
+	require.NoError(t, err)
+	_, err = ParseStaticFSWithOptions(writer, fsys, Options{})
+	require.NoError(t, err)
+
+	reader, err := s.GetReader("test")
+	require.NoError(t, err)
+	stop, err := reader.Stop("s")
+	require.NoError(t, err)
+	require.NotNil(t, stop)
+	assert.Nil(t, stop.Extras)
+	trips, err := reader.Trips()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trips))
+	assert.Nil(t, trips[0].Extras)
+}
@@ -0,0 +1,101 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type FareLegRuleCSV struct {
+	LegGroupID    string `csv:"leg_group_id"`
+	NetworkID     string `csv:"network_id"`
+	FromAreaID    string `csv:"from_area_id"`
+	ToAreaID      string `csv:"to_area_id"`
+	FareProductID string `csv:"fare_product_id"`
+	RulePriority  int    `csv:"rule_priority"`
+}
+
+// Parses fare_leg_rules.txt. Returns the set of leg group IDs.
+//
+// Network IDs are only verified if networks.txt was provided, since
+// they may otherwise reference routes.network_id.
+func ParseFareLegRules(
+	writer storage.FeedWriter,
+	data io.Reader,
+	networks map[string]bool,
+	areas map[string]bool,
+	products map[string]bool,
+) (map[string]bool, error) {
+	return parseFareLegRules(writer, data, networks, areas, products, nil)
+}
+
+// Parses fare_leg_rules.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse.
+func parseFareLegRules(
+	writer storage.FeedWriter,
+	data io.Reader,
+	networks map[string]bool,
+	areas map[string]bool,
+	products map[string]bool,
+	drop *dropped,
+) (map[string]bool, error) {
+	// Rules are prioritized only if the rule_priority column is
+	// present, even if all its values are blank.
+	header, data := readHeader(data)
+	hasRulePriority := hasColumn(header, "rule_priority")
+
+	ruleCsv := []*FareLegRuleCSV{}
+	if err := gocsv.Unmarshal(data, &ruleCsv); err != nil {
+		return nil, errMalformed("fare_leg_rules.txt", err)
+	}
+
+	legGroups := map[string]bool{}
+	parseRow := func(row int, r *FareLegRuleCSV) error {
+		if r.NetworkID != "" && len(networks) > 0 && !networks[r.NetworkID] {
+			return errUnknown("fare_leg_rules.txt", row, "network_id", r.NetworkID)
+		}
+		if r.FromAreaID != "" && !areas[r.FromAreaID] {
+			return errUnknown("fare_leg_rules.txt", row, "from_area_id", r.FromAreaID)
+		}
+		if r.ToAreaID != "" && !areas[r.ToAreaID] {
+			return errUnknown("fare_leg_rules.txt", row, "to_area_id", r.ToAreaID)
+		}
+		if !products[r.FareProductID] {
+			return errUnknown("fare_leg_rules.txt", row, "fare_product_id", r.FareProductID)
+		}
+		if r.RulePriority < 0 {
+			return errInvalid("fare_leg_rules.txt", row, "rule_priority", strconv.Itoa(r.RulePriority), fmt.Errorf("negative"))
+		}
+
+		err := writer.WriteFareLegRule(model.FareLegRule{
+			LegGroupID:      r.LegGroupID,
+			NetworkID:       r.NetworkID,
+			FromAreaID:      r.FromAreaID,
+			ToAreaID:        r.ToAreaID,
+			FareProductID:   r.FareProductID,
+			RulePriority:    r.RulePriority,
+			HasRulePriority: hasRulePriority,
+		})
+		if err != nil {
+			return errStorage("fare_leg_rules.txt", row, fmt.Errorf("writing fare leg rule: %w", err))
+		}
+
+		if r.LegGroupID != "" {
+			legGroups[r.LegGroupID] = true
+		}
+		return nil
+	}
+
+	for i, r := range ruleCsv {
+		if err := drop.skip(parseRow(i+1, r)); err != nil {
+			return nil, err
+		}
+	}
+
+	return legGroups, nil
+}
@@ -0,0 +1,99 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseFareLegRules(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		content   string
+		rules     []model.FareLegRule
+		legGroups map[string]bool
+		err       bool
+	}{
+		{
+			"all the fields",
+			`
+leg_group_id,network_id,from_area_id,to_area_id,fare_product_id,rule_priority
+local,bus,a1,a2,p1,1
+,,,,p2,`,
+			[]model.FareLegRule{
+				{FareProductID: "p2", HasRulePriority: true},
+				{LegGroupID: "local", NetworkID: "bus", FromAreaID: "a1", ToAreaID: "a2", FareProductID: "p1", RulePriority: 1, HasRulePriority: true},
+			},
+			map[string]bool{"local": true},
+			false,
+		},
+
+		{
+			"no rule_priority",
+			`
+leg_group_id,fare_product_id
+local,p1`,
+			[]model.FareLegRule{
+				{LegGroupID: "local", FareProductID: "p1"},
+			},
+			map[string]bool{"local": true},
+			false,
+		},
+
+		{
+			"unknown network",
+			"network_id,fare_product_id\nrail,p1",
+			nil, nil, true,
+		},
+
+		{
+			"unknown area",
+			"from_area_id,fare_product_id\na3,p1",
+			nil, nil, true,
+		},
+
+		{
+			"unknown product",
+			"leg_group_id,fare_product_id\nlocal,p3",
+			nil, nil, true,
+		},
+
+		{
+			"missing product",
+			"leg_group_id,fare_product_id\nlocal,",
+			nil, nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			legGroups, err := ParseFareLegRules(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"bus": true},
+				map[string]bool{"a1": true, "a2": true},
+				map[string]bool{"p1": true, "p2": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.legGroups, legGroups)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			rules, err := reader.FareLegRules()
+			require.NoError(t, err)
+			assert.Equal(t, tc.rules, rules)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type FareMediaCSV struct {
+	ID   string `csv:"fare_media_id"`
+	Name string `csv:"fare_media_name"`
+	Type int8   `csv:"fare_media_type"`
+}
+
+type FareProductCSV struct {
+	ID          string `csv:"fare_product_id"`
+	Name        string `csv:"fare_product_name"`
+	FareMediaID string `csv:"fare_media_id"`
+	Amount      string `csv:"amount"`
+	Currency    string `csv:"currency"`
+}
+
+// Parses fare_media.txt. Returns the set of fare media IDs.
+func ParseFareMedia(writer storage.FeedWriter, data io.Reader) (map[string]bool, error) {
+	return parseFareMedia(writer, data, nil)
+}
+
+// Parses fare_media.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse, and left out of the returned fare
+// media IDs.
+func parseFareMedia(writer storage.FeedWriter, data io.Reader, drop *dropped) (map[string]bool, error) {
+	mediaCsv := []*FareMediaCSV{}
+	if err := gocsv.Unmarshal(data, &mediaCsv); err != nil {
+		return nil, errMalformed("fare_media.txt", err)
+	}
+
+	media := map[string]bool{}
+	parseRow := func(row int, m *FareMediaCSV) error {
+		if m.ID == "" {
+			return errMissing("fare_media.txt", row, "fare_media_id")
+		}
+		if media[m.ID] {
+			return errDuplicate("fare_media.txt", row, "fare_media_id", m.ID)
+		}
+
+		mediaType := model.FareMediaType(m.Type)
+		if mediaType < model.FareMediaTypeNone || mediaType > model.FareMediaTypeMobileApp {
+			return errInvalid("fare_media.txt", row, "fare_media_type", strconv.Itoa(int(m.Type)), nil)
+		}
+
+		err := writer.WriteFareMedia(model.FareMedia{
+			ID:   m.ID,
+			Name: m.Name,
+			Type: mediaType,
+		})
+		if err != nil {
+			return errStorage("fare_media.txt", row, fmt.Errorf("writing fare media: %w", err))
+		}
+
+		media[m.ID] = true
+		return nil
+	}
+
+	for i, m := range mediaCsv {
+		if err := drop.skip(parseRow(i+1, m)); err != nil {
+			return nil, err
+		}
+	}
+
+	return media, nil
+}
+
+// Parses fare_products.txt. Returns the set of fare product IDs.
+func ParseFareProducts(writer storage.FeedWriter, data io.Reader, media map[string]bool) (map[string]bool, error) {
+	return parseFareProducts(writer, data, media, nil)
+}
+
+// Parses fare_products.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse. Products are left out of the
+// returned IDs only if none of their rows remain.
+func parseFareProducts(writer storage.FeedWriter, data io.Reader, media map[string]bool, drop *dropped) (map[string]bool, error) {
+	productCsv := []*FareProductCSV{}
+	if err := gocsv.Unmarshal(data, &productCsv); err != nil {
+		return nil, errMalformed("fare_products.txt", err)
+	}
+
+	products := map[string]bool{}
+	seen := map[[2]string]bool{}
+	parseRow := func(row int, p *FareProductCSV) error {
+		if p.ID == "" {
+			return errMissing("fare_products.txt", row, "fare_product_id")
+		}
+
+		// A product can be listed once per fare media
+		key := [2]string{p.ID, p.FareMediaID}
+		if seen[key] {
+			return &ParseError{
+				File:   "fare_products.txt",
+				Row:    row,
+				Column: "fare_product_id",
+				Value:  p.ID,
+				Code:   CodeDuplicateKey,
+				Err:    fmt.Errorf("for fare_media_id '%s'", p.FareMediaID),
+			}
+		}
+
+		if p.FareMediaID != "" && !media[p.FareMediaID] {
+			return errUnknown("fare_products.txt", row, "fare_media_id", p.FareMediaID)
+		}
+
+		amount, err := strconv.ParseFloat(p.Amount, 64)
+		if err != nil {
+			return errInvalid("fare_products.txt", row, "amount", p.Amount, err)
+		}
+
+		if len(p.Currency) != 3 {
+			return errInvalid("fare_products.txt", row, "currency", p.Currency, nil)
+		}
+
+		err = writer.WriteFareProduct(model.FareProduct{
+			ID:          p.ID,
+			Name:        p.Name,
+			FareMediaID: p.FareMediaID,
+			Amount:      amount,
+			Currency:    p.Currency,
+		})
+		if err != nil {
+			return errStorage("fare_products.txt", row, fmt.Errorf("writing fare product: %w", err))
+		}
+
+		seen[key] = true
+		products[p.ID] = true
+		return nil
+	}
+
+	for i, p := range productCsv {
+		if err := drop.skip(parseRow(i+1, p)); err != nil {
+			return nil, err
+		}
+	}
+
+	return products, nil
+}
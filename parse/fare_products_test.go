@@ -0,0 +1,114 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseFareProducts(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		media            string
+		products         string
+		expectedMedia    []model.FareMedia
+		expectedProducts []model.FareProduct
+		err              bool
+	}{
+		{
+			"products with and without media",
+			`
+fare_media_id,fare_media_name,fare_media_type
+card,Transit Card,2
+app,,4`,
+			`
+fare_product_id,fare_product_name,fare_media_id,amount,currency
+single,Single Ride,card,2.50,USD
+single,Single Ride,app,2.25,USD
+day,Day Pass,,8,USD`,
+			[]model.FareMedia{
+				{ID: "app", Type: model.FareMediaTypeMobileApp},
+				{ID: "card", Name: "Transit Card", Type: model.FareMediaTypeTransitCard},
+			},
+			[]model.FareProduct{
+				{ID: "day", Name: "Day Pass", Amount: 8, Currency: "USD"},
+				{ID: "single", Name: "Single Ride", FareMediaID: "app", Amount: 2.25, Currency: "USD"},
+				{ID: "single", Name: "Single Ride", FareMediaID: "card", Amount: 2.5, Currency: "USD"},
+			},
+			false,
+		},
+
+		{
+			"invalid media type",
+			"fare_media_id,fare_media_type\ncard,5",
+			"fare_product_id,amount,currency",
+			nil, nil, true,
+		},
+
+		{
+			"repeated media",
+			"fare_media_id,fare_media_type\ncard,2\ncard,1",
+			"fare_product_id,amount,currency",
+			nil, nil, true,
+		},
+
+		{
+			"repeated product and media",
+			"fare_media_id,fare_media_type\ncard,2",
+			"fare_product_id,fare_media_id,amount,currency\np,card,1,USD\np,card,2,USD",
+			nil, nil, true,
+		},
+
+		{
+			"unknown media",
+			"fare_media_id,fare_media_type\ncard,2",
+			"fare_product_id,fare_media_id,amount,currency\np,app,1,USD",
+			nil, nil, true,
+		},
+
+		{
+			"missing amount",
+			"fare_media_id,fare_media_type",
+			"fare_product_id,amount,currency\np,,USD",
+			nil, nil, true,
+		},
+
+		{
+			"invalid currency",
+			"fare_media_id,fare_media_type",
+			"fare_product_id,amount,currency\np,1,US",
+			nil, nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			mediaIDs, err := ParseFareMedia(writer, bytes.NewBufferString(tc.media))
+			if err == nil {
+				_, err = ParseFareProducts(writer, bytes.NewBufferString(tc.products), mediaIDs)
+			}
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			media, err := reader.FareMedia()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMedia, media)
+			products, err := reader.FareProducts()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedProducts, products)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type FareTransferRuleCSV struct {
+	FromLegGroupID    string `csv:"from_leg_group_id"`
+	ToLegGroupID      string `csv:"to_leg_group_id"`
+	TransferCount     string `csv:"transfer_count"`
+	DurationLimit     string `csv:"duration_limit"`
+	DurationLimitType string `csv:"duration_limit_type"`
+	FareTransferType  int8   `csv:"fare_transfer_type"`
+	FareProductID     string `csv:"fare_product_id"`
+}
+
+// Parses fare_transfer_rules.txt.
+func ParseFareTransferRules(
+	writer storage.FeedWriter,
+	data io.Reader,
+	legGroups map[string]bool,
+	products map[string]bool,
+) error {
+	return parseFareTransferRules(writer, data, legGroups, products, nil)
+}
+
+// Parses fare_transfer_rules.txt. If drop is non-nil, bad rows are
+// dropped rather than failing the parse.
+func parseFareTransferRules(
+	writer storage.FeedWriter,
+	data io.Reader,
+	legGroups map[string]bool,
+	products map[string]bool,
+	drop *dropped,
+) error {
+	ruleCsv := []*FareTransferRuleCSV{}
+	if err := gocsv.Unmarshal(data, &ruleCsv); err != nil {
+		return errMalformed("fare_transfer_rules.txt", err)
+	}
+
+	parseRow := func(row int, r *FareTransferRuleCSV) error {
+		if r.FromLegGroupID != "" && !legGroups[r.FromLegGroupID] {
+			return errUnknown("fare_transfer_rules.txt", row, "from_leg_group_id", r.FromLegGroupID)
+		}
+		if r.ToLegGroupID != "" && !legGroups[r.ToLegGroupID] {
+			return errUnknown("fare_transfer_rules.txt", row, "to_leg_group_id", r.ToLegGroupID)
+		}
+		if r.FareProductID != "" && !products[r.FareProductID] {
+			return errUnknown("fare_transfer_rules.txt", row, "fare_product_id", r.FareProductID)
+		}
+
+		// transfer_count is required when transferring
+		// within a leg group, and forbidden otherwise.
+		transferCount := -1
+		if r.FromLegGroupID == r.ToLegGroupID {
+			if r.TransferCount == "" {
+				return errMissing("fare_transfer_rules.txt", row, "transfer_count")
+			}
+			count, err := strconv.Atoi(r.TransferCount)
+			if err != nil || count == 0 || count < -1 {
+				return errInvalid("fare_transfer_rules.txt", row, "transfer_count", r.TransferCount, nil)
+			}
+			transferCount = count
+		} else if r.TransferCount != "" {
+			return errInvalid("fare_transfer_rules.txt", row, "transfer_count", r.TransferCount, fmt.Errorf("not allowed between leg groups"))
+		}
+
+		// duration_limit_type is required with
+		// duration_limit, and forbidden otherwise.
+		durationLimit := 0
+		durationLimitType := 0
+		if r.DurationLimit != "" {
+			var err error
+			durationLimit, err = strconv.Atoi(r.DurationLimit)
+			if err != nil || durationLimit <= 0 {
+				return errInvalid("fare_transfer_rules.txt", row, "duration_limit", r.DurationLimit, nil)
+			}
+			durationLimitType, err = strconv.Atoi(r.DurationLimitType)
+			if err != nil || durationLimitType < 0 || durationLimitType > 3 {
+				return errInvalid("fare_transfer_rules.txt", row, "duration_limit_type", r.DurationLimitType, nil)
+			}
+		} else if r.DurationLimitType != "" {
+			return errInvalid("fare_transfer_rules.txt", row, "duration_limit_type", r.DurationLimitType, fmt.Errorf("not allowed without duration_limit"))
+		}
+
+		transferType := model.FareTransferType(r.FareTransferType)
+		if transferType < model.FareTransferTypeAPlusAB || transferType > model.FareTransferTypeAB {
+			return errInvalid("fare_transfer_rules.txt", row, "fare_transfer_type", strconv.Itoa(int(r.FareTransferType)), nil)
+		}
+
+		err := writer.WriteFareTransferRule(model.FareTransferRule{
+			FromLegGroupID:    r.FromLegGroupID,
+			ToLegGroupID:      r.ToLegGroupID,
+			TransferCount:     transferCount,
+			DurationLimit:     uint32(durationLimit),
+			DurationLimitType: model.DurationLimitType(durationLimitType),
+			FareTransferType:  transferType,
+			FareProductID:     r.FareProductID,
+		})
+		if err != nil {
+			return errStorage("fare_transfer_rules.txt", row, fmt.Errorf("writing fare transfer rule: %w", err))
+		}
+
+		return nil
+	}
+
+	for i, r := range ruleCsv {
+		if err := drop.skip(parseRow(i+1, r)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
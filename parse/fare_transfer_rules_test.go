@@ -0,0 +1,109 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseFareTransferRules(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		rules   []model.FareTransferRule
+		err     bool
+	}{
+		{
+			"all the fields",
+			`
+from_leg_group_id,to_leg_group_id,transfer_count,duration_limit,duration_limit_type,fare_transfer_type,fare_product_id
+local,local,-1,5400,1,0,p1
+local,express,,,,1,
+express,local,,3600,0,2,p2`,
+			[]model.FareTransferRule{
+				{FromLegGroupID: "express", ToLegGroupID: "local", TransferCount: -1, DurationLimit: 3600, DurationLimitType: model.DurationLimitTypeDepartureArrival, FareTransferType: model.FareTransferTypeAB, FareProductID: "p2"},
+				{FromLegGroupID: "local", ToLegGroupID: "express", TransferCount: -1, FareTransferType: model.FareTransferTypeAPlusABPlusB},
+				{FromLegGroupID: "local", ToLegGroupID: "local", TransferCount: -1, DurationLimit: 5400, DurationLimitType: model.DurationLimitTypeDepartureDeparture, FareTransferType: model.FareTransferTypeAPlusAB, FareProductID: "p1"},
+			},
+			false,
+		},
+
+		{
+			"unknown leg group",
+			"from_leg_group_id,to_leg_group_id,fare_transfer_type\nlocal,rail,0",
+			nil, true,
+		},
+
+		{
+			"unknown product",
+			"from_leg_group_id,to_leg_group_id,fare_transfer_type,fare_product_id\nlocal,express,0,p3",
+			nil, true,
+		},
+
+		{
+			"missing transfer_count within leg group",
+			"from_leg_group_id,to_leg_group_id,fare_transfer_type\nlocal,local,0",
+			nil, true,
+		},
+
+		{
+			"transfer_count between leg groups",
+			"from_leg_group_id,to_leg_group_id,transfer_count,fare_transfer_type\nlocal,express,1,0",
+			nil, true,
+		},
+
+		{
+			"zero transfer_count",
+			"from_leg_group_id,to_leg_group_id,transfer_count,fare_transfer_type\nlocal,local,0,0",
+			nil, true,
+		},
+
+		{
+			"duration_limit without type",
+			"from_leg_group_id,to_leg_group_id,duration_limit,fare_transfer_type\nlocal,express,60,0",
+			nil, true,
+		},
+
+		{
+			"duration_limit_type without limit",
+			"from_leg_group_id,to_leg_group_id,duration_limit_type,fare_transfer_type\nlocal,express,1,0",
+			nil, true,
+		},
+
+		{
+			"invalid fare_transfer_type",
+			"from_leg_group_id,to_leg_group_id,fare_transfer_type\nlocal,express,3",
+			nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			err = ParseFareTransferRules(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"local": true, "express": true},
+				map[string]bool{"p1": true, "p2": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			rules, err := reader.FareTransferRules()
+			require.NoError(t, err)
+			assert.Equal(t, tc.rules, rules)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type FareAttributeCSV struct {
+	ID               string  `csv:"fare_id"`
+	Price            float64 `csv:"price"`
+	CurrencyType     string  `csv:"currency_type"`
+	PaymentMethod    int8    `csv:"payment_method"`
+	Transfers        string  `csv:"transfers"`
+	AgencyID         string  `csv:"agency_id"`
+	TransferDuration int     `csv:"transfer_duration"`
+}
+
+type FareRuleCSV struct {
+	FareID        string `csv:"fare_id"`
+	RouteID       string `csv:"route_id"`
+	OriginID      string `csv:"origin_id"`
+	DestinationID string `csv:"destination_id"`
+	ContainsID    string `csv:"contains_id"`
+}
+
+// Parses fare_attributes.txt. Returns the set of fare IDs.
+func ParseFareAttributes(writer storage.FeedWriter, data io.Reader, agency map[string]bool) (map[string]bool, error) {
+	return parseFareAttributes(writer, data, agency, nil)
+}
+
+// Parses fare_attributes.txt. If drop is non-nil, bad rows are
+// dropped rather than failing the parse, and left out of the returned
+// fare IDs.
+func parseFareAttributes(writer storage.FeedWriter, data io.Reader, agency map[string]bool, drop *dropped) (map[string]bool, error) {
+	fareCsv := []*FareAttributeCSV{}
+	if err := gocsv.Unmarshal(data, &fareCsv); err != nil {
+		return nil, errMalformed("fare_attributes.txt", err)
+	}
+
+	fares := map[string]bool{}
+	parseRow := func(row int, f *FareAttributeCSV) error {
+		if f.ID == "" {
+			return errMissing("fare_attributes.txt", row, "fare_id")
+		}
+		if fares[f.ID] {
+			return errDuplicate("fare_attributes.txt", row, "fare_id", f.ID)
+		}
+
+		if f.Price < 0 {
+			return errInvalid("fare_attributes.txt", row, "price", strconv.FormatFloat(f.Price, 'f', -1, 64), fmt.Errorf("negative"))
+		}
+
+		if len(f.CurrencyType) != 3 {
+			return errInvalid("fare_attributes.txt", row, "currency_type", f.CurrencyType, nil)
+		}
+
+		paymentMethod := model.PaymentMethod(f.PaymentMethod)
+		if paymentMethod != model.PaymentMethodOnBoard && paymentMethod != model.PaymentMethodBeforeBoarding {
+			return errInvalid("fare_attributes.txt", row, "payment_method", strconv.Itoa(int(f.PaymentMethod)), nil)
+		}
+
+		// Empty transfers means unlimited transfers
+		transfers := -1
+		if f.Transfers != "" {
+			t, err := strconv.Atoi(f.Transfers)
+			if err != nil || t < 0 || t > 2 {
+				return errInvalid("fare_attributes.txt", row, "transfers", f.Transfers, nil)
+			}
+			transfers = t
+		}
+
+		if f.AgencyID != "" && !agency[f.AgencyID] {
+			return errUnknown("fare_attributes.txt", row, "agency_id", f.AgencyID)
+		}
+
+		if f.TransferDuration < 0 {
+			return errInvalid("fare_attributes.txt", row, "transfer_duration", strconv.Itoa(f.TransferDuration), fmt.Errorf("negative"))
+		}
+
+		err := writer.WriteFareAttribute(model.FareAttribute{
+			ID:               f.ID,
+			Price:            f.Price,
+			CurrencyType:     f.CurrencyType,
+			PaymentMethod:    paymentMethod,
+			Transfers:        transfers,
+			AgencyID:         f.AgencyID,
+			TransferDuration: uint32(f.TransferDuration),
+		})
+		if err != nil {
+			return errStorage("fare_attributes.txt", row, fmt.Errorf("writing fare attribute: %w", err))
+		}
+
+		fares[f.ID] = true
+		return nil
+	}
+
+	for i, f := range fareCsv {
+		if err := drop.skip(parseRow(i+1, f)); err != nil {
+			return nil, err
+		}
+	}
+
+	return fares, nil
+}
+
+// Parses fare_rules.txt. Fare, route and zone IDs must all be known.
+func ParseFareRules(
+	writer storage.FeedWriter,
+	data io.Reader,
+	fares map[string]bool,
+	routes map[string]bool,
+	zones map[string]bool,
+) error {
+	return parseFareRules(writer, data, fares, routes, zones, nil)
+}
+
+// Parses fare_rules.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse.
+func parseFareRules(
+	writer storage.FeedWriter,
+	data io.Reader,
+	fares map[string]bool,
+	routes map[string]bool,
+	zones map[string]bool,
+	drop *dropped,
+) error {
+	ruleCsv := []*FareRuleCSV{}
+	if err := gocsv.Unmarshal(data, &ruleCsv); err != nil {
+		return errMalformed("fare_rules.txt", err)
+	}
+
+	parseRow := func(row int, r *FareRuleCSV) error {
+		if !fares[r.FareID] {
+			return errUnknown("fare_rules.txt", row, "fare_id", r.FareID)
+		}
+		if r.RouteID != "" && !routes[r.RouteID] {
+			return errUnknown("fare_rules.txt", row, "route_id", r.RouteID)
+		}
+		for _, zone := range [][2]string{
+			{"origin_id", r.OriginID},
+			{"destination_id", r.DestinationID},
+			{"contains_id", r.ContainsID},
+		} {
+			if zone[1] != "" && !zones[zone[1]] {
+				return errUnknown("fare_rules.txt", row, zone[0], zone[1])
+			}
+		}
+
+		err := writer.WriteFareRule(model.FareRule{
+			FareID:        r.FareID,
+			RouteID:       r.RouteID,
+			OriginID:      r.OriginID,
+			DestinationID: r.DestinationID,
+			ContainsID:    r.ContainsID,
+		})
+		if err != nil {
+			return errStorage("fare_rules.txt", row, fmt.Errorf("writing fare rule: %w", err))
+		}
+
+		return nil
+	}
+
+	for i, r := range ruleCsv {
+		if err := drop.skip(parseRow(i+1, r)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseFareAttributes(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		fares   []model.FareAttribute
+		err     bool
+	}{
+		{
+			"minimal",
+			`
+fare_id,price,currency_type,payment_method,transfers
+f,2.50,USD,0,`,
+			[]model.FareAttribute{
+				{ID: "f", Price: 2.5, CurrencyType: "USD", Transfers: -1},
+			},
+			false,
+		},
+
+		{
+			"all the fields",
+			`
+fare_id,price,currency_type,payment_method,transfers,agency_id,transfer_duration
+a,1.25,EUR,1,2,ag,5400
+b,0,EUR,0,0,,`,
+			[]model.FareAttribute{
+				{
+					ID:               "a",
+					Price:            1.25,
+					CurrencyType:     "EUR",
+					PaymentMethod:    model.PaymentMethodBeforeBoarding,
+					Transfers:        2,
+					AgencyID:         "ag",
+					TransferDuration: 5400,
+				},
+				{ID: "b", CurrencyType: "EUR", Transfers: 0},
+			},
+			false,
+		},
+
+		{
+			"repeated fare_id",
+			`
+fare_id,price,currency_type,payment_method,transfers
+f,2.50,USD,0,
+f,3.50,USD,0,`,
+			nil,
+			true,
+		},
+
+		{
+			"negative price",
+			`
+fare_id,price,currency_type,payment_method,transfers
+f,-1,USD,0,`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid currency",
+			`
+fare_id,price,currency_type,payment_method,transfers
+f,1,dollars,0,`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid payment_method",
+			`
+fare_id,price,currency_type,payment_method,transfers
+f,1,USD,2,`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid transfers",
+			`
+fare_id,price,currency_type,payment_method,transfers
+f,1,USD,0,3`,
+			nil,
+			true,
+		},
+
+		{
+			"unknown agency",
+			`
+fare_id,price,currency_type,payment_method,transfers,agency_id
+f,1,USD,0,,nope`,
+			nil,
+			true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			fareIDs, err := ParseFareAttributes(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"ag": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			fares, err := reader.FareAttributes()
+			require.NoError(t, err)
+			assert.Equal(t, tc.fares, fares)
+			assert.Equal(t, len(tc.fares), len(fareIDs))
+			for _, f := range fares {
+				assert.True(t, fareIDs[f.ID])
+			}
+		})
+	}
+}
+
+func TestParseFareRules(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		rules   []model.FareRule
+		err     bool
+	}{
+		{
+			"all the fields",
+			`
+fare_id,route_id,origin_id,destination_id,contains_id
+f1,r,z1,z2,
+f2,,,,z1
+f2,,,,z2`,
+			[]model.FareRule{
+				{FareID: "f1", RouteID: "r", OriginID: "z1", DestinationID: "z2"},
+				{FareID: "f2", ContainsID: "z1"},
+				{FareID: "f2", ContainsID: "z2"},
+			},
+			false,
+		},
+
+		{
+			"unknown fare",
+			`
+fare_id,route_id
+f3,r`,
+			nil,
+			true,
+		},
+
+		{
+			"unknown route",
+			`
+fare_id,route_id
+f1,q`,
+			nil,
+			true,
+		},
+
+		{
+			"unknown zone",
+			`
+fare_id,origin_id
+f1,z3`,
+			nil,
+			true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			err = ParseFareRules(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"f1": true, "f2": true},
+				map[string]bool{"r": true},
+				map[string]bool{"z1": true, "z2": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			rules, err := reader.FareRules()
+			require.NoError(t, err)
+			assert.Equal(t, tc.rules, rules)
+		})
+	}
+}
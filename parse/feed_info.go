@@ -0,0 +1,88 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type FeedInfoCSV struct {
+	PublisherName string `csv:"feed_publisher_name"`
+	PublisherURL  string `csv:"feed_publisher_url"`
+	Lang          string `csv:"feed_lang"`
+	DefaultLang   string `csv:"default_lang"`
+	StartDate     string `csv:"feed_start_date"`
+	EndDate       string `csv:"feed_end_date"`
+	Version       string `csv:"feed_version"`
+	ContactEmail  string `csv:"feed_contact_email"`
+	ContactURL    string `csv:"feed_contact_url"`
+}
+
+// Parses feed_info.txt. The file holds (at most) a single record,
+// which is written and returned. If the file has no records, nil is
+// returned.
+func ParseFeedInfo(writer storage.FeedWriter, data io.Reader) (*model.FeedInfo, error) {
+	feedInfoCsv := []*FeedInfoCSV{}
+	if err := gocsv.Unmarshal(data, &feedInfoCsv); err != nil {
+		return nil, errMalformed("feed_info.txt", err)
+	}
+
+	if len(feedInfoCsv) == 0 {
+		return nil, nil
+	}
+	if len(feedInfoCsv) > 1 {
+		return nil, &ParseError{File: "feed_info.txt", Row: 2, Code: CodeInvalidValue, Err: fmt.Errorf("found %d records, expected 1", len(feedInfoCsv))}
+	}
+
+	fi := feedInfoCsv[0]
+
+	if fi.PublisherName == "" {
+		return nil, errMissing("feed_info.txt", 1, "feed_publisher_name")
+	}
+	if fi.PublisherURL == "" {
+		return nil, errMissing("feed_info.txt", 1, "feed_publisher_url")
+	}
+	if fi.Lang == "" {
+		return nil, errMissing("feed_info.txt", 1, "feed_lang")
+	}
+
+	if fi.StartDate != "" {
+		_, err := time.ParseInLocation("20060102", fi.StartDate, time.UTC)
+		if err != nil {
+			return nil, errInvalid("feed_info.txt", 1, "feed_start_date", fi.StartDate, err)
+		}
+	}
+	if fi.EndDate != "" {
+		_, err := time.ParseInLocation("20060102", fi.EndDate, time.UTC)
+		if err != nil {
+			return nil, errInvalid("feed_info.txt", 1, "feed_end_date", fi.EndDate, err)
+		}
+	}
+	if fi.StartDate != "" && fi.EndDate != "" && fi.StartDate > fi.EndDate {
+		return nil, errInvalid("feed_info.txt", 1, "feed_end_date", fi.EndDate, fmt.Errorf("before feed_start_date '%s'", fi.StartDate))
+	}
+
+	info := &model.FeedInfo{
+		PublisherName: fi.PublisherName,
+		PublisherURL:  fi.PublisherURL,
+		Lang:          fi.Lang,
+		DefaultLang:   fi.DefaultLang,
+		StartDate:     fi.StartDate,
+		EndDate:       fi.EndDate,
+		Version:       fi.Version,
+		ContactEmail:  fi.ContactEmail,
+		ContactURL:    fi.ContactURL,
+	}
+
+	err := writer.WriteFeedInfo(*info)
+	if err != nil {
+		return nil, errStorage("feed_info.txt", 1, fmt.Errorf("writing feed info: %w", err))
+	}
+
+	return info, nil
+}
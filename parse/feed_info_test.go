@@ -0,0 +1,151 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseFeedInfo(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		feedInfo []model.FeedInfo
+		err      bool
+	}{
+		{
+			"minimal",
+			`
+feed_publisher_name,feed_publisher_url,feed_lang
+Publisher,http://example.com,en`,
+			[]model.FeedInfo{{
+				PublisherName: "Publisher",
+				PublisherURL:  "http://example.com",
+				Lang:          "en",
+			}},
+			false,
+		},
+
+		{
+			"all fields",
+			`
+feed_publisher_name,feed_publisher_url,feed_lang,default_lang,feed_start_date,feed_end_date,feed_version,feed_contact_email,feed_contact_url
+Publisher,http://example.com,mul,en,20240101,20240114,68,gtfs@example.com,http://example.com/contact`,
+			[]model.FeedInfo{{
+				PublisherName: "Publisher",
+				PublisherURL:  "http://example.com",
+				Lang:          "mul",
+				DefaultLang:   "en",
+				StartDate:     "20240101",
+				EndDate:       "20240114",
+				Version:       "68",
+				ContactEmail:  "gtfs@example.com",
+				ContactURL:    "http://example.com/contact",
+			}},
+			false,
+		},
+
+		{
+			"no records",
+			`
+feed_publisher_name,feed_publisher_url,feed_lang`,
+			[]model.FeedInfo{},
+			false,
+		},
+
+		{
+			"multiple records",
+			`
+feed_publisher_name,feed_publisher_url,feed_lang
+Publisher,http://example.com,en
+Other,http://example.com/other,en`,
+			nil,
+			true,
+		},
+
+		{
+			"missing feed_publisher_name",
+			`
+feed_publisher_url,feed_lang
+http://example.com,en`,
+			nil,
+			true,
+		},
+
+		{
+			"missing feed_publisher_url",
+			`
+feed_publisher_name,feed_lang
+Publisher,en`,
+			nil,
+			true,
+		},
+
+		{
+			"missing feed_lang",
+			`
+feed_publisher_name,feed_publisher_url
+Publisher,http://example.com`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid feed_start_date",
+			`
+feed_publisher_name,feed_publisher_url,feed_lang,feed_start_date
+Publisher,http://example.com,en,2024-01-01`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid feed_end_date",
+			`
+feed_publisher_name,feed_publisher_url,feed_lang,feed_end_date
+Publisher,http://example.com,en,20241301`,
+			nil,
+			true,
+		},
+
+		{
+			"start after end",
+			`
+feed_publisher_name,feed_publisher_url,feed_lang,feed_start_date,feed_end_date
+Publisher,http://example.com,en,20240114,20240101`,
+			nil,
+			true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			info, err := ParseFeedInfo(writer, bytes.NewBufferString(tc.content))
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if len(tc.feedInfo) == 0 {
+				assert.Nil(t, info)
+			} else {
+				assert.Equal(t, tc.feedInfo[0], *info)
+			}
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			feedInfo, err := reader.FeedInfo()
+			require.NoError(t, err)
+			assert.Equal(t, tc.feedInfo, feedInfo)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type FrequencyCSV struct {
+	TripID      string `csv:"trip_id"`
+	StartTime   string `csv:"start_time"`
+	EndTime     string `csv:"end_time"`
+	HeadwaySecs int    `csv:"headway_secs"`
+	ExactTimes  int8   `csv:"exact_times"`
+}
+
+func ParseFrequencies(writer storage.FeedWriter, data io.Reader, trips map[string]bool) error {
+	return parseFrequencies(writer, data, trips, nil)
+}
+
+// Parses frequencies.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse. Of overlapping frequencies, the
+// later one is dropped.
+func parseFrequencies(writer storage.FeedWriter, data io.Reader, trips map[string]bool, drop *dropped) error {
+	frequencyCsv := []*FrequencyCSV{}
+	if err := gocsv.Unmarshal(data, &frequencyCsv); err != nil {
+		return errMalformed("frequencies.txt", err)
+	}
+
+	// Frequencies along with the rows they came from. Identical
+	// rows make for identical frequencies, so they can't be keyed
+	// by frequency.
+	type rowFrequency struct {
+		freq model.Frequency
+		row  int
+	}
+	freqsByTrip := map[string][]rowFrequency{}
+	parseRow := func(row int, f *FrequencyCSV) error {
+		if !trips[f.TripID] {
+			return errUnknown("frequencies.txt", row, "trip_id", f.TripID)
+		}
+
+		start, err := parseStopTimeTime(f.StartTime)
+		if err != nil {
+			return errInvalid("frequencies.txt", row, "start_time", f.StartTime, err)
+		}
+		end, err := parseStopTimeTime(f.EndTime)
+		if err != nil {
+			return errInvalid("frequencies.txt", row, "end_time", f.EndTime, err)
+		}
+		if start >= end {
+			return errInvalid("frequencies.txt", row, "end_time", f.EndTime, fmt.Errorf("not after start_time"))
+		}
+
+		if f.HeadwaySecs <= 0 {
+			return errInvalid("frequencies.txt", row, "headway_secs", strconv.Itoa(f.HeadwaySecs), nil)
+		}
+
+		if f.ExactTimes != 0 && f.ExactTimes != 1 {
+			return errInvalid("frequencies.txt", row, "exact_times", strconv.Itoa(int(f.ExactTimes)), nil)
+		}
+
+		freq := model.Frequency{
+			TripID:      f.TripID,
+			Start:       start,
+			End:         end,
+			HeadwaySecs: uint32(f.HeadwaySecs),
+			ExactTimes:  f.ExactTimes == 1,
+		}
+		freqsByTrip[f.TripID] = append(freqsByTrip[f.TripID], rowFrequency{freq, row})
+
+		return nil
+	}
+
+	for i, f := range frequencyCsv {
+		if err := drop.skip(parseRow(i+1, f)); err != nil {
+			return err
+		}
+	}
+
+	// Periods for the same trip must not overlap. Frequencies
+	// are written once this has been verified.
+	for tripID, freqs := range freqsByTrip {
+		sort.SliceStable(freqs, func(i, j int) bool {
+			return freqs[i].freq.Start < freqs[j].freq.Start
+		})
+		end := ""
+		for _, rf := range freqs {
+			freq := rf.freq
+			if end != "" && freq.Start < end {
+				err := drop.skip(errInvalid("frequencies.txt", rf.row, "start_time", freq.Start, fmt.Errorf("overlaps another frequency of trip_id '%s'", tripID)))
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			end = freq.End
+
+			err := writer.WriteFrequency(freq)
+			if err != nil {
+				return errStorage("frequencies.txt", rf.row, fmt.Errorf("writing frequency: %w", err))
+			}
+		}
+	}
+
+	return nil
+}
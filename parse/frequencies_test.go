@@ -0,0 +1,133 @@
+package parse
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseFrequencies(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		content     string
+		frequencies []model.Frequency
+		err         bool
+	}{
+		{
+			"minimal",
+			`
+trip_id,start_time,end_time,headway_secs
+t1,06:00:00,09:00:00,600`,
+			[]model.Frequency{
+				{TripID: "t1", Start: "060000", End: "090000", HeadwaySecs: 600},
+			},
+			false,
+		},
+
+		{
+			"multiple periods, exact times",
+			`
+trip_id,start_time,end_time,headway_secs,exact_times
+t1,6:00:00,9:00:00,600,1
+t1,09:00:00,25:00:00,1200,1
+t2,10:00:00,11:00:00,300,0`,
+			[]model.Frequency{
+				{TripID: "t1", Start: "060000", End: "090000", HeadwaySecs: 600, ExactTimes: true},
+				{TripID: "t1", Start: "090000", End: "250000", HeadwaySecs: 1200, ExactTimes: true},
+				{TripID: "t2", Start: "100000", End: "110000", HeadwaySecs: 300},
+			},
+			false,
+		},
+
+		{
+			"unknown trip",
+			`
+trip_id,start_time,end_time,headway_secs
+t3,06:00:00,09:00:00,600`,
+			nil,
+			true,
+		},
+
+		{
+			"malformed start_time",
+			`
+trip_id,start_time,end_time,headway_secs
+t1,6am,09:00:00,600`,
+			nil,
+			true,
+		},
+
+		{
+			"end before start",
+			`
+trip_id,start_time,end_time,headway_secs
+t1,09:00:00,06:00:00,600`,
+			nil,
+			true,
+		},
+
+		{
+			"zero headway",
+			`
+trip_id,start_time,end_time,headway_secs
+t1,06:00:00,09:00:00,0`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid exact_times",
+			`
+trip_id,start_time,end_time,headway_secs,exact_times
+t1,06:00:00,09:00:00,600,2`,
+			nil,
+			true,
+		},
+
+		{
+			"overlapping periods",
+			`
+trip_id,start_time,end_time,headway_secs
+t1,06:00:00,09:00:00,600
+t1,08:00:00,10:00:00,600`,
+			nil,
+			true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			err = ParseFrequencies(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"t1": true, "t2": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			frequencies, err := reader.Frequencies()
+			require.NoError(t, err)
+			sort.Slice(frequencies, func(i, j int) bool {
+				if frequencies[i].TripID != frequencies[j].TripID {
+					return frequencies[i].TripID < frequencies[j].TripID
+				}
+				return frequencies[i].Start < frequencies[j].Start
+			})
+			assert.Equal(t, tc.frequencies, frequencies)
+		})
+	}
+}
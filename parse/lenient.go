@@ -0,0 +1,172 @@
+package parse
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spkg/bom"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+// Number of dropped rows described in feed metadata. Any beyond this
+// are only counted.
+const MaxDroppedDetails = 100
+
+// Rows dropped when parsing, either leniently or from optional files.
+type dropped struct {
+	count   int
+	details []string
+
+	// Trips dropped along with all their stop times,
+	// frequencies and transfers.
+	trips map[string]bool
+}
+
+func newDropped() *dropped {
+	return &dropped{trips: map[string]bool{}}
+}
+
+// Records a problem with a trip, and drops the trip.
+func (d *dropped) trip(tripID string, err error) {
+	d.count++
+	if len(d.details) < MaxDroppedDetails {
+		d.details = append(d.details, fmt.Sprintf("%s; dropped trip_id '%s'", err, tripID))
+	}
+	d.trips[tripID] = true
+}
+
+// Records a problem with a row, and drops the row.
+func (d *dropped) row(err error) {
+	d.count++
+	if len(d.details) < MaxDroppedDetails {
+		d.details = append(d.details, fmt.Sprintf("%s; dropped row", err))
+	}
+}
+
+// Handles an error from parsing a single row. The error is returned,
+// failing the parse, if d is nil or the error isn't with the row
+// itself. Otherwise the row is recorded as dropped.
+func (d *dropped) skip(err error) error {
+	if err == nil || d == nil {
+		return err
+	}
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) && parseErr.Code == CodeStorage {
+		return err
+	}
+	d.row(err)
+	return nil
+}
+
+// Records a problem with an optional file, and drops the file.
+func (d *dropped) file(err error) {
+	d.count++
+	if len(d.details) < MaxDroppedDetails {
+		d.details = append(d.details, fmt.Sprintf("%s; dropped file", err))
+	}
+}
+
+// A FeedWriter that only accepts trips and stop times, and discards
+// them. Used to scan for bad rows before anything is written.
+type discardWriter struct {
+	storage.FeedWriter
+}
+
+func (discardWriter) WriteTrip(model.Trip) error                 { return nil }
+func (discardWriter) WriteStopTime(model.StopTime) error         { return nil }
+func (discardWriter) WriteFlexStopTime(model.FlexStopTime) error { return nil }
+
+// Scans trips.txt and stop_times.txt for rows that would fail to
+// parse, and drops the trips they belong to. Nothing is written.
+func (d *dropped) scanTrips(
+	tripsData io.Reader,
+	stopTimesData io.Reader,
+	routes map[string]bool,
+	services map[string]bool,
+	shapes map[string]bool,
+	stops map[string]bool,
+	locations map[string]bool,
+	locationGroups map[string]bool,
+	bookingRules map[string]bool,
+) error {
+	trips, err := parseTrips(discardWriter{}, tripsData, routes, services, shapes, d, false)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = parseStopTimes(discardWriter{}, stopTimesData, trips, stops, locations, locationGroups, bookingRules, d)
+	return err
+}
+
+// Streams CSV data, leaving out rows where any of the given columns
+// holds a dropped trip ID. Closing the returned reader closes data.
+func (d *dropped) filterTrips(data io.ReadCloser, columns ...string) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		r := csv.NewReader(bom.NewReader(data))
+		r.LazyQuotes = true
+		r.FieldsPerRecord = -1
+		w := csv.NewWriter(pw)
+
+		header, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			pw.CloseWithError(err)
+			return
+		}
+
+		idx := []int{}
+		for i, column := range header {
+			for _, c := range columns {
+				if strings.TrimSpace(column) == c {
+					idx = append(idx, i)
+				}
+			}
+		}
+
+		err = w.Write(header)
+		for err == nil {
+			var record []string
+			record, err = r.Read()
+			if err != nil {
+				break
+			}
+
+			keep := true
+			for _, i := range idx {
+				if i < len(record) && d.trips[strings.TrimSpace(record[i])] {
+					keep = false
+				}
+			}
+			if keep {
+				err = w.Write(record)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			w.Flush()
+			err = w.Error()
+		}
+
+		pw.CloseWithError(err)
+	}()
+
+	return &filteredReader{PipeReader: pr, data: data}
+}
+
+type filteredReader struct {
+	*io.PipeReader
+	data io.Closer
+}
+
+func (r *filteredReader) Close() error {
+	r.PipeReader.Close()
+	return r.data.Close()
+}
@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type LevelCSV struct {
+	ID    string  `csv:"level_id"`
+	Index float64 `csv:"level_index"`
+	Name  string  `csv:"level_name"`
+}
+
+// Parses levels.txt. Returns the set of level IDs.
+func ParseLevels(writer storage.FeedWriter, data io.Reader) (map[string]bool, error) {
+	return parseLevels(writer, data, nil)
+}
+
+// Parses levels.txt. If drop is non-nil, bad rows are dropped rather
+// than failing the parse, and left out of the returned level IDs.
+func parseLevels(writer storage.FeedWriter, data io.Reader, drop *dropped) (map[string]bool, error) {
+	levelCsv := []*LevelCSV{}
+	if err := gocsv.Unmarshal(data, &levelCsv); err != nil {
+		return nil, errMalformed("levels.txt", err)
+	}
+
+	levels := map[string]bool{}
+	parseRow := func(row int, l *LevelCSV) error {
+		if l.ID == "" {
+			return errMissing("levels.txt", row, "level_id")
+		}
+		if levels[l.ID] {
+			return errDuplicate("levels.txt", row, "level_id", l.ID)
+		}
+
+		err := writer.WriteLevel(model.Level{
+			ID:    l.ID,
+			Index: l.Index,
+			Name:  l.Name,
+		})
+		if err != nil {
+			return errStorage("levels.txt", row, fmt.Errorf("writing level: %w", err))
+		}
+
+		levels[l.ID] = true
+		return nil
+	}
+
+	for i, l := range levelCsv {
+		if err := drop.skip(parseRow(i+1, l)); err != nil {
+			return nil, err
+		}
+	}
+
+	return levels, nil
+}
@@ -0,0 +1,82 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseLevels(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected []model.Level
+		err      bool
+	}{
+		{
+			"basic",
+			`
+level_id,level_index,level_name
+street,0,Street
+mezz,-1,Mezzanine
+platform,-2.5,`,
+			[]model.Level{
+				{ID: "platform", Index: -2.5},
+				{ID: "mezz", Index: -1, Name: "Mezzanine"},
+				{ID: "street", Index: 0, Name: "Street"},
+			},
+			false,
+		},
+
+		{
+			"empty level_id",
+			`
+level_id,level_index
+,0`,
+			nil, true,
+		},
+
+		{
+			"repeated level_id",
+			`
+level_id,level_index
+l,0
+l,1`,
+			nil, true,
+		},
+
+		{
+			"invalid level_index",
+			`
+level_id,level_index
+l,ground`,
+			nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			levelIDs, err := ParseLevels(writer, bytes.NewBufferString(tc.content))
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.expected), len(levelIDs))
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			levels, err := reader.Levels()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, levels)
+		})
+	}
+}
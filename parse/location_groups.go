@@ -0,0 +1,154 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type LocationGroupCSV struct {
+	ID   string `csv:"location_group_id"`
+	Name string `csv:"location_group_name"`
+}
+
+type LocationGroupStopCSV struct {
+	LocationGroupID string `csv:"location_group_id"`
+	StopID          string `csv:"stop_id"`
+}
+
+// Parses location_groups.txt (GTFS-Flex). Location group IDs share
+// a namespace with stop and location IDs, so must not collide with
+// those. Returns the set of location group IDs.
+func ParseLocationGroups(
+	writer storage.FeedWriter,
+	data io.Reader,
+	stops map[string]bool,
+	locations map[string]bool,
+) (map[string]bool, error) {
+	return parseLocationGroups(writer, data, stops, locations, nil)
+}
+
+// Parses location_groups.txt. If drop is non-nil, bad rows are
+// dropped rather than failing the parse, and left out of the returned
+// location group IDs.
+func parseLocationGroups(
+	writer storage.FeedWriter,
+	data io.Reader,
+	stops map[string]bool,
+	locations map[string]bool,
+	drop *dropped,
+) (map[string]bool, error) {
+	groupCsv := []*LocationGroupCSV{}
+	if err := gocsv.Unmarshal(data, &groupCsv); err != nil {
+		return nil, errMalformed("location_groups.txt", err)
+	}
+
+	groups := map[string]bool{}
+	parseRow := func(row int, g *LocationGroupCSV) error {
+		if g.ID == "" {
+			return errMissing("location_groups.txt", row, "location_group_id")
+		}
+		if groups[g.ID] {
+			return errDuplicate("location_groups.txt", row, "location_group_id", g.ID)
+		}
+		if stops[g.ID] || locations[g.ID] {
+			return &ParseError{
+				File:   "location_groups.txt",
+				Row:    row,
+				Column: "location_group_id",
+				Value:  g.ID,
+				Code:   CodeDuplicateKey,
+				Err:    fmt.Errorf("also a stop or location id"),
+			}
+		}
+
+		err := writer.WriteLocationGroup(model.LocationGroup{
+			ID:   g.ID,
+			Name: g.Name,
+		})
+		if err != nil {
+			return errStorage("location_groups.txt", row, fmt.Errorf("writing location group: %w", err))
+		}
+
+		groups[g.ID] = true
+		return nil
+	}
+
+	for i, g := range groupCsv {
+		if err := drop.skip(parseRow(i+1, g)); err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+// Parses location_group_stops.txt (GTFS-Flex).
+func ParseLocationGroupStops(
+	writer storage.FeedWriter,
+	data io.Reader,
+	groups map[string]bool,
+	stops map[string]bool,
+) error {
+	return parseLocationGroupStops(writer, data, groups, stops, nil)
+}
+
+// Parses location_group_stops.txt. If drop is non-nil, bad rows are
+// dropped rather than failing the parse.
+func parseLocationGroupStops(
+	writer storage.FeedWriter,
+	data io.Reader,
+	groups map[string]bool,
+	stops map[string]bool,
+	drop *dropped,
+) error {
+	lgsCsv := []*LocationGroupStopCSV{}
+	if err := gocsv.Unmarshal(data, &lgsCsv); err != nil {
+		return errMalformed("location_group_stops.txt", err)
+	}
+
+	seen := map[model.LocationGroupStop]bool{}
+	parseRow := func(row int, lgs *LocationGroupStopCSV) error {
+		if !groups[lgs.LocationGroupID] {
+			return errUnknown("location_group_stops.txt", row, "location_group_id", lgs.LocationGroupID)
+		}
+		if !stops[lgs.StopID] {
+			return errUnknown("location_group_stops.txt", row, "stop_id", lgs.StopID)
+		}
+
+		groupStop := model.LocationGroupStop{
+			LocationGroupID: lgs.LocationGroupID,
+			StopID:          lgs.StopID,
+		}
+		if seen[groupStop] {
+			return &ParseError{
+				File:   "location_group_stops.txt",
+				Row:    row,
+				Column: "stop_id",
+				Value:  lgs.StopID,
+				Code:   CodeDuplicateKey,
+				Err:    fmt.Errorf("for location_group_id '%s'", lgs.LocationGroupID),
+			}
+		}
+		seen[groupStop] = true
+
+		err := writer.WriteLocationGroupStop(groupStop)
+		if err != nil {
+			return errStorage("location_group_stops.txt", row, fmt.Errorf("writing location group stop: %w", err))
+		}
+
+		return nil
+	}
+
+	for i, lgs := range lgsCsv {
+		if err := drop.skip(parseRow(i+1, lgs)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseLocationGroups(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		groups      string
+		groupStops  string
+		expected    []model.LocationGroup
+		expectedLGS []model.LocationGroupStop
+		err         bool
+	}{
+		{
+			"basic",
+			`
+location_group_id,location_group_name
+g1,Group One
+g2,`,
+			`
+location_group_id,stop_id
+g1,s1
+g1,s2
+g2,s2`,
+			[]model.LocationGroup{{ID: "g1", Name: "Group One"}, {ID: "g2"}},
+			[]model.LocationGroupStop{
+				{LocationGroupID: "g1", StopID: "s1"},
+				{LocationGroupID: "g1", StopID: "s2"},
+				{LocationGroupID: "g2", StopID: "s2"},
+			},
+			false,
+		},
+
+		{
+			"empty location_group_id",
+			"location_group_id,location_group_name\n,Nowhere",
+			"location_group_id,stop_id",
+			nil, nil, true,
+		},
+
+		{
+			"repeated location_group_id",
+			"location_group_id\ng\ng",
+			"location_group_id,stop_id",
+			nil, nil, true,
+		},
+
+		{
+			"location_group_id is a stop_id",
+			"location_group_id\ns1",
+			"location_group_id,stop_id",
+			nil, nil, true,
+		},
+
+		{
+			"location_group_id is a location id",
+			"location_group_id\nzone",
+			"location_group_id,stop_id",
+			nil, nil, true,
+		},
+
+		{
+			"unknown location group",
+			"location_group_id\ng",
+			"location_group_id,stop_id\nh,s1",
+			nil, nil, true,
+		},
+
+		{
+			"unknown stop",
+			"location_group_id\ng",
+			"location_group_id,stop_id\ng,s3",
+			nil, nil, true,
+		},
+
+		{
+			"repeated location group stop",
+			"location_group_id\ng",
+			"location_group_id,stop_id\ng,s1\ng,s1",
+			nil, nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			stops := map[string]bool{"s1": true, "s2": true}
+			groupIDs, err := ParseLocationGroups(
+				writer,
+				bytes.NewBufferString(tc.groups),
+				stops,
+				map[string]bool{"zone": true},
+			)
+			if err == nil {
+				err = ParseLocationGroupStops(
+					writer,
+					bytes.NewBufferString(tc.groupStops),
+					groupIDs,
+					stops,
+				)
+			}
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			groups, err := reader.LocationGroups()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, groups)
+			groupStops, err := reader.LocationGroupStops()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedLGS, groupStops)
+		})
+	}
+}
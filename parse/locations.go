@@ -0,0 +1,153 @@
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type LocationsGeoJSON struct {
+	Type     string                    `json:"type"`
+	Features []LocationsGeoJSONFeature `json:"features"`
+}
+
+type LocationsGeoJSONFeature struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Properties struct {
+		Name string `json:"stop_name"`
+		Desc string `json:"stop_desc"`
+	} `json:"properties"`
+	Geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// Parses locations.geojson (GTFS-Flex). Location IDs share a
+// namespace with stop IDs, so must not collide with those. Returns
+// the set of location IDs.
+func ParseLocations(writer storage.FeedWriter, data io.Reader, stops map[string]bool) (map[string]bool, error) {
+	return parseLocations(writer, data, stops, nil)
+}
+
+// Parses locations.geojson. If drop is non-nil, bad features are
+// dropped rather than failing the parse, and left out of the returned
+// location IDs.
+func parseLocations(writer storage.FeedWriter, data io.Reader, stops map[string]bool, drop *dropped) (map[string]bool, error) {
+	geojson := LocationsGeoJSON{}
+	if err := json.NewDecoder(data).Decode(&geojson); err != nil {
+		return nil, errMalformed("locations.geojson", err)
+	}
+
+	if geojson.Type != "FeatureCollection" {
+		return nil, errInvalid("locations.geojson", 0, "type", geojson.Type, fmt.Errorf("expected FeatureCollection"))
+	}
+
+	locations := map[string]bool{}
+	parseRow := func(row int, f LocationsGeoJSONFeature) error {
+		if f.Type != "Feature" {
+			return errInvalid("locations.geojson", row, "type", f.Type, fmt.Errorf("expected Feature"))
+		}
+		if f.ID == "" {
+			return errMissing("locations.geojson", row, "id")
+		}
+		if locations[f.ID] {
+			return errDuplicate("locations.geojson", row, "id", f.ID)
+		}
+		if stops[f.ID] {
+			return &ParseError{
+				File:   "locations.geojson",
+				Row:    row,
+				Column: "id",
+				Value:  f.ID,
+				Code:   CodeDuplicateKey,
+				Err:    fmt.Errorf("also a stop_id"),
+			}
+		}
+
+		polygons, err := parseLocationGeometry(f.Geometry.Type, f.Geometry.Coordinates)
+		if err != nil {
+			return errInvalid("locations.geojson", row, "geometry", f.Geometry.Type, err)
+		}
+
+		err = writer.WriteLocation(model.Location{
+			ID:       f.ID,
+			Name:     f.Properties.Name,
+			Desc:     f.Properties.Desc,
+			Polygons: polygons,
+		})
+		if err != nil {
+			return errStorage("locations.geojson", row, fmt.Errorf("writing location: %w", err))
+		}
+
+		locations[f.ID] = true
+		return nil
+	}
+
+	for i, f := range geojson.Features {
+		if err := drop.skip(parseRow(i+1, f)); err != nil {
+			return nil, err
+		}
+	}
+
+	return locations, nil
+}
+
+// Parses the coordinates of a Polygon or MultiPolygon geometry into
+// a list of polygons.
+func parseLocationGeometry(geometryType string, coordinates json.RawMessage) ([][][][2]float64, error) {
+	raw := [][][][]float64{}
+	switch geometryType {
+	case "Polygon":
+		polygon := [][][]float64{}
+		if err := json.Unmarshal(coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("unmarshaling coordinates: %w", err)
+		}
+		raw = append(raw, polygon)
+	case "MultiPolygon":
+		if err := json.Unmarshal(coordinates, &raw); err != nil {
+			return nil, fmt.Errorf("unmarshaling coordinates: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid geometry type '%s'", geometryType)
+	}
+
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("no polygons")
+	}
+
+	polygons := make([][][][2]float64, len(raw))
+	for i, rings := range raw {
+		if len(rings) == 0 {
+			return nil, fmt.Errorf("polygon without rings")
+		}
+		polygons[i] = make([][][2]float64, len(rings))
+		for j, ring := range rings {
+			// A linear ring is closed, so has at least 4
+			// positions: a triangle and the first point
+			// repeated.
+			if len(ring) < 4 {
+				return nil, fmt.Errorf("ring with %d positions", len(ring))
+			}
+			polygons[i][j] = make([][2]float64, len(ring))
+			for k, pos := range ring {
+				if len(pos) < 2 {
+					return nil, fmt.Errorf("position with %d coordinates", len(pos))
+				}
+				if pos[0] < -180 || pos[0] > 180 || pos[1] < -90 || pos[1] > 90 {
+					return nil, fmt.Errorf("position [%f, %f] out of range", pos[0], pos[1])
+				}
+				polygons[i][j][k] = [2]float64{pos[0], pos[1]}
+			}
+			if polygons[i][j][0] != polygons[i][j][len(ring)-1] {
+				return nil, fmt.Errorf("ring not closed")
+			}
+		}
+	}
+
+	return polygons, nil
+}
@@ -0,0 +1,166 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseLocations(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected []model.Location
+		err      bool
+	}{
+		{
+			"polygon",
+			`{"type": "FeatureCollection", "features": [{
+  "type": "Feature", "id": "zone",
+  "properties": {"stop_name": "Zone", "stop_desc": "A zone"},
+  "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [2, 0], [2, 2], [0, 2], [0, 0]]]}
+}]}`,
+			[]model.Location{{
+				ID:       "zone",
+				Name:     "Zone",
+				Desc:     "A zone",
+				Polygons: [][][][2]float64{{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}},
+			}},
+			false,
+		},
+
+		{
+			"multipolygon with hole",
+			`{"type": "FeatureCollection", "features": [{
+  "type": "Feature", "id": "zone", "properties": {},
+  "geometry": {"type": "MultiPolygon", "coordinates": [
+    [[[0, 0], [4, 0], [4, 4], [0, 0]], [[1, 1], [2, 1], [2, 2], [1, 1]]],
+    [[[10, 10], [11, 10], [11, 11], [10, 10]]]
+  ]}
+}]}`,
+			[]model.Location{{
+				ID: "zone",
+				Polygons: [][][][2]float64{
+					{{{0, 0}, {4, 0}, {4, 4}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}},
+					{{{10, 10}, {11, 10}, {11, 11}, {10, 10}}},
+				},
+			}},
+			false,
+		},
+
+		{
+			"no features",
+			`{"type": "FeatureCollection", "features": []}`,
+			[]model.Location{},
+			false,
+		},
+
+		{
+			"malformed json",
+			`{"type": "FeatureCollection", "features": [`,
+			nil, true,
+		},
+
+		{
+			"not a feature collection",
+			`{"type": "Feature"}`,
+			nil, true,
+		},
+
+		{
+			"missing id",
+			`{"type": "FeatureCollection", "features": [{
+  "type": "Feature", "properties": {},
+  "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [2, 0], [2, 2], [0, 0]]]}
+}]}`,
+			nil, true,
+		},
+
+		{
+			"id is a stop_id",
+			`{"type": "FeatureCollection", "features": [{
+  "type": "Feature", "id": "s", "properties": {},
+  "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [2, 0], [2, 2], [0, 0]]]}
+}]}`,
+			nil, true,
+		},
+
+		{
+			"repeated id",
+			`{"type": "FeatureCollection", "features": [{
+  "type": "Feature", "id": "z", "properties": {},
+  "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [2, 0], [2, 2], [0, 0]]]}
+}, {
+  "type": "Feature", "id": "z", "properties": {},
+  "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [2, 0], [2, 2], [0, 0]]]}
+}]}`,
+			nil, true,
+		},
+
+		{
+			"point geometry",
+			`{"type": "FeatureCollection", "features": [{
+  "type": "Feature", "id": "z", "properties": {},
+  "geometry": {"type": "Point", "coordinates": [0, 0]}
+}]}`,
+			nil, true,
+		},
+
+		{
+			"ring not closed",
+			`{"type": "FeatureCollection", "features": [{
+  "type": "Feature", "id": "z", "properties": {},
+  "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [2, 0], [2, 2], [0, 2]]]}
+}]}`,
+			nil, true,
+		},
+
+		{
+			"too few positions",
+			`{"type": "FeatureCollection", "features": [{
+  "type": "Feature", "id": "z", "properties": {},
+  "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [2, 0], [0, 0]]]}
+}]}`,
+			nil, true,
+		},
+
+		{
+			"latitude out of range",
+			`{"type": "FeatureCollection", "features": [{
+  "type": "Feature", "id": "z", "properties": {},
+  "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [2, 95], [2, 2], [0, 0]]]}
+}]}`,
+			nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			locationIDs, err := ParseLocations(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"s": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.expected), len(locationIDs))
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			locations, err := reader.Locations()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, locations)
+		})
+	}
+}
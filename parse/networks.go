@@ -0,0 +1,123 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type NetworkCSV struct {
+	ID   string `csv:"network_id"`
+	Name string `csv:"network_name"`
+}
+
+type RouteNetworkCSV struct {
+	NetworkID string `csv:"network_id"`
+	RouteID   string `csv:"route_id"`
+}
+
+// Parses networks.txt. Returns the set of network IDs.
+func ParseNetworks(writer storage.FeedWriter, data io.Reader) (map[string]bool, error) {
+	return parseNetworks(writer, data, nil)
+}
+
+// Parses networks.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse, and left out of the returned network
+// IDs.
+func parseNetworks(writer storage.FeedWriter, data io.Reader, drop *dropped) (map[string]bool, error) {
+	networkCsv := []*NetworkCSV{}
+	if err := gocsv.Unmarshal(data, &networkCsv); err != nil {
+		return nil, errMalformed("networks.txt", err)
+	}
+
+	networks := map[string]bool{}
+	parseRow := func(row int, n *NetworkCSV) error {
+		if n.ID == "" {
+			return errMissing("networks.txt", row, "network_id")
+		}
+		if networks[n.ID] {
+			return errDuplicate("networks.txt", row, "network_id", n.ID)
+		}
+
+		err := writer.WriteNetwork(model.Network{
+			ID:   n.ID,
+			Name: n.Name,
+		})
+		if err != nil {
+			return errStorage("networks.txt", row, fmt.Errorf("writing network: %w", err))
+		}
+
+		networks[n.ID] = true
+		return nil
+	}
+
+	for i, n := range networkCsv {
+		if err := drop.skip(parseRow(i+1, n)); err != nil {
+			return nil, err
+		}
+	}
+
+	return networks, nil
+}
+
+// Parses route_networks.txt. A route can belong to at most one
+// network.
+func ParseRouteNetworks(
+	writer storage.FeedWriter,
+	data io.Reader,
+	networks map[string]bool,
+	routes map[string]bool,
+) error {
+	return parseRouteNetworks(writer, data, networks, routes, nil)
+}
+
+// Parses route_networks.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse.
+func parseRouteNetworks(
+	writer storage.FeedWriter,
+	data io.Reader,
+	networks map[string]bool,
+	routes map[string]bool,
+	drop *dropped,
+) error {
+	rnCsv := []*RouteNetworkCSV{}
+	if err := gocsv.Unmarshal(data, &rnCsv); err != nil {
+		return errMalformed("route_networks.txt", err)
+	}
+
+	seen := map[string]bool{}
+	parseRow := func(row int, rn *RouteNetworkCSV) error {
+		if !networks[rn.NetworkID] {
+			return errUnknown("route_networks.txt", row, "network_id", rn.NetworkID)
+		}
+		if !routes[rn.RouteID] {
+			return errUnknown("route_networks.txt", row, "route_id", rn.RouteID)
+		}
+		if seen[rn.RouteID] {
+			return errDuplicate("route_networks.txt", row, "route_id", rn.RouteID)
+		}
+		seen[rn.RouteID] = true
+
+		err := writer.WriteRouteNetwork(model.RouteNetwork{
+			NetworkID: rn.NetworkID,
+			RouteID:   rn.RouteID,
+		})
+		if err != nil {
+			return errStorage("route_networks.txt", row, fmt.Errorf("writing route network: %w", err))
+		}
+
+		return nil
+	}
+
+	for i, rn := range rnCsv {
+		if err := drop.skip(parseRow(i+1, rn)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
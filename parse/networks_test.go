@@ -0,0 +1,100 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseNetworks(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		networks      string
+		routeNetworks string
+		expected      []model.Network
+		expectedRN    []model.RouteNetwork
+		err           bool
+	}{
+		{
+			"basic",
+			`
+network_id,network_name
+bus,Buses
+rail,`,
+			`
+network_id,route_id
+bus,r1
+rail,r2`,
+			[]model.Network{{ID: "bus", Name: "Buses"}, {ID: "rail"}},
+			[]model.RouteNetwork{{NetworkID: "bus", RouteID: "r1"}, {NetworkID: "rail", RouteID: "r2"}},
+			false,
+		},
+
+		{
+			"repeated network_id",
+			`
+network_id,network_name
+bus,Buses
+bus,More buses`,
+			"network_id,route_id",
+			nil, nil, true,
+		},
+
+		{
+			"unknown network",
+			"network_id\nbus",
+			"network_id,route_id\nrail,r1",
+			nil, nil, true,
+		},
+
+		{
+			"unknown route",
+			"network_id\nbus",
+			"network_id,route_id\nbus,r3",
+			nil, nil, true,
+		},
+
+		{
+			"route in multiple networks",
+			"network_id\nbus\nrail",
+			"network_id,route_id\nbus,r1\nrail,r1",
+			nil, nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			networkIDs, err := ParseNetworks(writer, bytes.NewBufferString(tc.networks))
+			if err == nil {
+				err = ParseRouteNetworks(
+					writer,
+					bytes.NewBufferString(tc.routeNetworks),
+					networkIDs,
+					map[string]bool{"r1": true, "r2": true},
+				)
+			}
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			networks, err := reader.Networks()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, networks)
+			routeNetworks, err := reader.RouteNetworks()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRN, routeNetworks)
+		})
+	}
+}
@@ -3,28 +3,110 @@ package parse
 import (
 	"archive/zip"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"strings"
+	"io/fs"
 
 	"github.com/gocarina/gocsv"
 	"github.com/spkg/bom"
 
+	"tidbyt.dev/gtfs/model"
 	"tidbyt.dev/gtfs/storage"
 )
 
+// Parses a static GTFS feed held in memory as a zip archive.
 func ParseStatic(writer storage.FeedWriter, buf []byte) (*storage.FeedMetadata, error) {
+	return ParseStaticReaderAt(writer, bytes.NewReader(buf), int64(len(buf)))
+}
+
+// Parses a static GTFS feed from a zip archive of the given size,
+// e.g. an *os.File. Files in the archive are read as they're parsed,
+// so the archive is never held in memory in full.
+func ParseStaticReaderAt(writer storage.FeedWriter, r io.ReaderAt, size int64) (*storage.FeedMetadata, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, &ParseError{Code: CodeUnreadableFeed, Err: fmt.Errorf("unzipping: %w", err)}
+	}
+
+	return ParseStaticFS(writer, z)
+}
+
+// Parses a static GTFS feed from a filesystem, e.g. an unzipped feed
+// directory opened with os.DirFS(), or a *zip.Reader.
+func ParseStaticFS(writer storage.FeedWriter, fsys fs.FS) (*storage.FeedMetadata, error) {
+	return ParseStaticFSWithOptions(writer, fsys, Options{})
+}
+
+// Like ParseStaticFS, but bad rows don't fail the parse. Instead,
+// they're dropped along with whatever depends on them. A bad row in
+// trips.txt or stop_times.txt drops the whole trip, with its stop
+// times, frequencies and transfers. A dropped route, service or stop
+// in turn drops the trips and other rows referencing it. What was
+// dropped is recorded in the returned metadata.
+//
+// Problems with agency.txt, and files that can't be read as CSV at
+// all, still fail the parse.
+//
+// trips.txt and stop_times.txt are read twice: once to find bad rows,
+// and once to write what remains.
+func ParseStaticFSLenient(writer storage.FeedWriter, fsys fs.FS) (*storage.FeedMetadata, error) {
+	return ParseStaticFSWithOptions(writer, fsys, Options{Lenient: true})
+}
+
+// Options for parsing a static feed.
+type Options struct {
+	// Parse leniently, as with ParseStaticFSLenient.
+	Lenient bool
+
+	// Character encoding of the feed's files. Detected per file
+	// if not set.
+	Encoding Encoding
+
+	// Capture values in columns not defined by the spec, such as
+	// agency specific extensions, in the Extras of agencies,
+	// stops, routes and trips. They're keyed by column name, and
+	// blank values are left out. Records without any are left
+	// with nil Extras, as are all records when not set.
+	Extras bool
+}
+
+// Parses a static GTFS feed from a filesystem, with options.
+func ParseStaticFSWithOptions(writer storage.FeedWriter, fsys fs.FS, options Options) (*storage.FeedMetadata, error) {
+	if _, err := LookupEncoding(string(options.Encoding)); err != nil {
+		return nil, &ParseError{Code: CodeUnreadableFeed, Err: err}
+	}
+
 	// These are the files we load for static dumps.
-	//
-	// TODO: add feed_info.txt
 	file := map[string]io.ReadCloser{
-		"agency.txt":         nil,
-		"routes.txt":         nil,
-		"stops.txt":          nil,
-		"trips.txt":          nil,
-		"stop_times.txt":     nil,
-		"calendar.txt":       nil,
-		"calendar_dates.txt": nil,
+		"agency.txt":               nil,
+		"routes.txt":               nil,
+		"stops.txt":                nil,
+		"trips.txt":                nil,
+		"stop_times.txt":           nil,
+		"calendar.txt":             nil,
+		"calendar_dates.txt":       nil,
+		"feed_info.txt":            nil,
+		"shapes.txt":               nil,
+		"frequencies.txt":          nil,
+		"transfers.txt":            nil,
+		"fare_attributes.txt":      nil,
+		"fare_rules.txt":           nil,
+		"networks.txt":             nil,
+		"route_networks.txt":       nil,
+		"areas.txt":                nil,
+		"stop_areas.txt":           nil,
+		"fare_media.txt":           nil,
+		"fare_products.txt":        nil,
+		"fare_leg_rules.txt":       nil,
+		"fare_transfer_rules.txt":  nil,
+		"levels.txt":               nil,
+		"pathways.txt":             nil,
+		"translations.txt":         nil,
+		"locations.geojson":        nil,
+		"location_groups.txt":      nil,
+		"location_group_stops.txt": nil,
+		"booking_rules.txt":        nil,
 	}
 
 	defer func() {
@@ -35,59 +117,88 @@ func ParseStatic(writer storage.FeedWriter, buf []byte) (*storage.FeedMetadata,
 		}
 	}()
 
-	r, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
-	if err != nil {
-		return nil, fmt.Errorf("unzipping: %w", err)
-	}
+	paths := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 
-	for _, f := range r.File {
 		// There should not be any subdirectories. But, some
 		// agencies don't care.
-		if f.FileInfo().IsDir() {
-			continue
+		if d.IsDir() {
+			return nil
 		}
-		path := strings.Split(f.Name, "/")
-		fName := path[len(path)-1]
 
-		if _, found := file[fName]; !found {
-			continue
+		rc, found := file[d.Name()]
+		if !found {
+			return nil
+		}
+		if rc != nil {
+			rc.Close()
 		}
 
-		rc, err := f.Open()
+		rc, err = fsys.Open(path)
 		if err != nil {
-			return nil, fmt.Errorf("opening %s: %w", f.Name, err)
+			return &ParseError{File: d.Name(), Code: CodeUnreadableFeed, Err: fmt.Errorf("opening %s: %w", path, err)}
 		}
 
-		file[fName] = rc
+		file[d.Name()] = NewDecoder(rc, options.Encoding)
+		paths[d.Name()] = path
+		return nil
+	})
+	if err != nil {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr
+		}
+		return nil, &ParseError{Code: CodeUnreadableFeed, Err: fmt.Errorf("listing files: %w", err)}
 	}
 
 	if file["calendar.txt"] == nil && file["calendar_dates.txt"] == nil {
-		return nil, fmt.Errorf("missing calendar.txt and calendar_dates.txt")
+		return nil, &ParseError{File: "calendar.txt", Code: CodeMissingFile, Err: fmt.Errorf("missing calendar.txt and calendar_dates.txt")}
 	}
 
 	for _, required := range []string{"agency.txt", "routes.txt", "stops.txt", "trips.txt", "stop_times.txt"} {
 		if file[required] == nil {
-			return nil, fmt.Errorf("missing %s", required)
+			return nil, &ParseError{File: required, Code: CodeMissingFile}
 		}
 	}
 
 	// LazyCSVReader required (at least) to survive sloppy use of
-	// quotes. The BOM reader strips unicode BOMs if present.
+	// quotes. The BOM reader strips unicode BOMs if present, for
+	// data not passed through a decoder.
 	gocsv.SetCSVReader(func(in io.Reader) gocsv.CSVReader {
 		return gocsv.LazyCSVReader(bom.NewReader(in))
 	})
 
+	// Rows dropped from optional files, and when parsing
+	// leniently, from any file.
+	drop := newDropped()
+	var lenient *dropped
+	if options.Lenient {
+		lenient = drop
+	}
+
 	// Parse agency.txt. Extract timezone and set of agency IDs in
 	// the process.
-	agency, timezone, err := ParseAgency(writer, file["agency.txt"])
+	agency, timezone, err := parseAgency(writer, file["agency.txt"], options.Extras)
 	if err != nil {
-		return nil, fmt.Errorf("parsing agency.txt: %w", err)
+		return nil, err
+	}
+
+	// Parse feed_info.txt, if present.
+	var feedInfo *model.FeedInfo
+	if file["feed_info.txt"] != nil {
+		feedInfo, err = ParseFeedInfo(writer, file["feed_info.txt"])
+		if err = lenient.skip(err); err != nil {
+			return nil, err
+		}
 	}
 
 	// Parse routes.txt. Extract route IDs in the process.
-	routes, err := ParseRoutes(writer, file["routes.txt"], agency)
+	routes, err := parseRoutes(writer, file["routes.txt"], agency, lenient, options.Extras)
 	if err != nil {
-		return nil, fmt.Errorf("parsing routes.txt: %w", err)
+		return nil, err
 	}
 
 	// Parse calendar.txt and calendar_dates.txt. Extract set of
@@ -95,15 +206,15 @@ func ParseStatic(writer storage.FeedWriter, buf []byte) (*storage.FeedMetadata,
 	var calendarStart, calendarEnd string
 	services := map[string]bool{}
 	if file["calendar.txt"] != nil {
-		services, calendarStart, calendarEnd, err = ParseCalendar(writer, file["calendar.txt"])
+		services, calendarStart, calendarEnd, err = parseCalendar(writer, file["calendar.txt"], lenient)
 		if err != nil {
-			return nil, fmt.Errorf("parsing calendar.txt: %w", err)
+			return nil, err
 		}
 	}
 	if file["calendar_dates.txt"] != nil {
-		cdServices, minDate, maxDate, err := ParseCalendarDates(writer, file["calendar_dates.txt"])
+		cdServices, minDate, maxDate, err := parseCalendarDates(writer, file["calendar_dates.txt"], lenient)
 		if err != nil {
-			return nil, fmt.Errorf("parsing calendar_dates.txt: %w", err)
+			return nil, err
 		}
 		for serviceID := range cdServices {
 			services[serviceID] = true
@@ -116,53 +227,281 @@ func ParseStatic(writer storage.FeedWriter, buf []byte) (*storage.FeedMetadata,
 		}
 	}
 
+	// Parse shapes.txt, if present. Extract shape IDs in the
+	// process.
+	shapes := map[string]bool{}
+	if file["shapes.txt"] != nil {
+		err = writer.BeginShapes()
+		if err != nil {
+			return nil, errStorage("shapes.txt", 0, fmt.Errorf("beginning shapes: %w", err))
+		}
+		shapes, err = parseShapes(writer, file["shapes.txt"], lenient)
+		if err != nil {
+			return nil, err
+		}
+		err = writer.EndShapes()
+		if err != nil {
+			return nil, errStorage("shapes.txt", 0, fmt.Errorf("ending shapes: %w", err))
+		}
+	}
+
+	// Parse levels.txt, if present.
+	levels := map[string]bool{}
+	if file["levels.txt"] != nil {
+		levels, err = parseLevels(writer, file["levels.txt"], lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// And parse stop_times.txt. Extract stop IDs in the process.
+	stops, zones, err := parseStops(writer, file["stops.txt"], levels, lenient, options.Extras)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the GTFS-Flex files, if present. These are referenced
+	// from stop_times.txt.
+	locations := map[string]bool{}
+	if file["locations.geojson"] != nil {
+		locations, err = parseLocations(writer, file["locations.geojson"], stops, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	locationGroups := map[string]bool{}
+	if file["location_groups.txt"] != nil {
+		locationGroups, err = parseLocationGroups(writer, file["location_groups.txt"], stops, locations, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if file["location_group_stops.txt"] != nil {
+		err = parseLocationGroupStops(writer, file["location_group_stops.txt"], locationGroups, stops, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	bookingRules := map[string]bool{}
+	if file["booking_rules.txt"] != nil {
+		bookingRules, err = parseBookingRules(writer, file["booking_rules.txt"], services, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// When parsing leniently, trips.txt and stop_times.txt are
+	// first scanned for bad rows. Trips with bad rows are then
+	// filtered out of both files, and of the files referencing
+	// trips, before they're parsed.
+	if options.Lenient {
+		err = drop.scanTrips(
+			file["trips.txt"],
+			file["stop_times.txt"],
+			routes,
+			services,
+			shapes,
+			stops,
+			locations,
+			locationGroups,
+			bookingRules,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, columns := range map[string][]string{
+			"trips.txt":       {"trip_id"},
+			"stop_times.txt":  {"trip_id"},
+			"frequencies.txt": {"trip_id"},
+			"transfers.txt":   {"from_trip_id", "to_trip_id"},
+		} {
+			if file[name] == nil {
+				continue
+			}
+			file[name].Close()
+			file[name] = nil
+
+			f, err := fsys.Open(paths[name])
+			if err != nil {
+				return nil, &ParseError{File: name, Code: CodeUnreadableFeed, Err: fmt.Errorf("opening %s: %w", name, err)}
+			}
+			file[name] = drop.filterTrips(NewDecoder(f, options.Encoding), columns...)
+		}
+	}
+
 	// Parse trips.txt. Extract trip IDs in the process.
 	err = writer.BeginTrips()
 	if err != nil {
-		return nil, fmt.Errorf("beginning trips: %w", err)
+		return nil, errStorage("trips.txt", 0, fmt.Errorf("beginning trips: %w", err))
 	}
-	trips, err := ParseTrips(writer, file["trips.txt"], routes, services)
+	trips, err := parseTrips(writer, file["trips.txt"], routes, services, shapes, nil, options.Extras)
 	if err != nil {
-		return nil, fmt.Errorf("parsing trips.txt: %w", err)
+		return nil, err
 	}
 	err = writer.EndTrips()
 	if err != nil {
-		return nil, fmt.Errorf("ending trips: %w", err)
-	}
-
-	// And parse stop_times.txt. Extract stop IDs in the process.
-	stops, err := ParseStops(writer, file["stops.txt"])
-	if err != nil {
-		return nil, fmt.Errorf("parsing stops.txt: %w", err)
+		return nil, errStorage("trips.txt", 0, fmt.Errorf("ending trips: %w", err))
 	}
 
 	// Parse stop_times.txt.
 	err = writer.BeginStopTimes()
 	if err != nil {
-		return nil, fmt.Errorf("beginning stop_times: %w", err)
+		return nil, errStorage("stop_times.txt", 0, fmt.Errorf("beginning stop_times: %w", err))
 	}
-	maxArrival, maxDeparture, err := ParseStopTimes(writer, file["stop_times.txt"], trips, stops)
+	maxArrival, maxDeparture, err := ParseStopTimes(
+		writer,
+		file["stop_times.txt"],
+		trips,
+		stops,
+		locations,
+		locationGroups,
+		bookingRules,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("parsing stop_times.txt: %w", err)
+		return nil, err
 	}
 	err = writer.EndStopTimes()
 	if err != nil {
-		return nil, fmt.Errorf("ending stop_times: %w", err)
+		return nil, errStorage("stop_times.txt", 0, fmt.Errorf("ending stop_times: %w", err))
+	}
+
+	// Parse frequencies.txt, if present.
+	if file["frequencies.txt"] != nil {
+		err = parseFrequencies(writer, file["frequencies.txt"], trips, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse transfers.txt, if present.
+	if file["transfers.txt"] != nil {
+		err = parseTransfers(writer, file["transfers.txt"], stops, routes, trips, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse pathways.txt, if present.
+	if file["pathways.txt"] != nil {
+		err = parsePathways(writer, file["pathways.txt"], stops, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse fare_attributes.txt and fare_rules.txt, if present.
+	fares := map[string]bool{}
+	if file["fare_attributes.txt"] != nil {
+		fares, err = parseFareAttributes(writer, file["fare_attributes.txt"], agency, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if file["fare_rules.txt"] != nil {
+		err = parseFareRules(writer, file["fare_rules.txt"], fares, routes, zones, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse the Fares v2 files, if present.
+	networks := map[string]bool{}
+	if file["networks.txt"] != nil {
+		networks, err = parseNetworks(writer, file["networks.txt"], lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if file["route_networks.txt"] != nil {
+		err = parseRouteNetworks(writer, file["route_networks.txt"], networks, routes, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	areas := map[string]bool{}
+	if file["areas.txt"] != nil {
+		areas, err = parseAreas(writer, file["areas.txt"], lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if file["stop_areas.txt"] != nil {
+		err = parseStopAreas(writer, file["stop_areas.txt"], areas, stops, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fareMedia := map[string]bool{}
+	if file["fare_media.txt"] != nil {
+		fareMedia, err = parseFareMedia(writer, file["fare_media.txt"], lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	fareProducts := map[string]bool{}
+	if file["fare_products.txt"] != nil {
+		fareProducts, err = parseFareProducts(writer, file["fare_products.txt"], fareMedia, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	legGroups := map[string]bool{}
+	if file["fare_leg_rules.txt"] != nil {
+		legGroups, err = parseFareLegRules(writer, file["fare_leg_rules.txt"], networks, areas, fareProducts, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if file["fare_transfer_rules.txt"] != nil {
+		err = parseFareTransferRules(writer, file["fare_transfer_rules.txt"], legGroups, fareProducts, lenient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse translations.txt, if present.
+	if file["translations.txt"] != nil {
+		// Translations of dropped trips are harmless, so
+		// they're kept rather than recorded as bad rows.
+		translatable := map[string]bool{}
+		for tripID := range trips {
+			translatable[tripID] = true
+		}
+		for tripID := range drop.trips {
+			translatable[tripID] = true
+		}
+		err = parseTranslations(writer, file["translations.txt"], agency, stops, routes, translatable, drop)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// All files parsed: close the writer.
 	err = writer.Close()
 	if err != nil {
-		return nil, fmt.Errorf("closing feed writer: %w", err)
+		return nil, errStorage("", 0, fmt.Errorf("closing feed writer: %w", err))
 	}
 
 	// And return a (partial) metadata holding some key
 	// information about the feed.
-	return &storage.FeedMetadata{
+	metadata := &storage.FeedMetadata{
 		CalendarStartDate: calendarStart,
 		CalendarEndDate:   calendarEnd,
 		Timezone:          timezone,
 		MaxArrival:        maxArrival,
 		MaxDeparture:      maxDeparture,
-	}, nil
+	}
+	if feedInfo != nil {
+		metadata.FeedStartDate = feedInfo.StartDate
+		metadata.FeedEndDate = feedInfo.EndDate
+		metadata.FeedVersion = feedInfo.Version
+		metadata.FeedPublisherName = feedInfo.PublisherName
+		metadata.FeedPublisherURL = feedInfo.PublisherURL
+		metadata.FeedLang = feedInfo.Lang
+	}
+	metadata.DroppedCount = drop.count
+	metadata.Dropped = drop.details
+
+	return metadata, nil
 }
@@ -3,8 +3,10 @@ package parse
 import (
 	"archive/zip"
 	"bytes"
+	"os"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -142,6 +144,56 @@ func TestParseValidFeed(t *testing.T) {
 	}}, stopTimes)
 }
 
+func TestParseFeedInfoInMetadata(t *testing.T) {
+	s, err := storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err := s.GetWriter("test")
+	require.NoError(t, err)
+
+	// Without feed_info.txt, the fields are blank
+	metadata, err := ParseStatic(writer, buildZip(t, fixtureSimple()))
+	require.NoError(t, err)
+	assert.Equal(t, "", metadata.FeedStartDate)
+	assert.Equal(t, "", metadata.FeedEndDate)
+	assert.Equal(t, "", metadata.FeedVersion)
+	assert.Equal(t, "", metadata.FeedPublisherName)
+
+	// With it, they're populated
+	files := fixtureSimple()
+	files["feed_info.txt"] = []string{
+		"feed_publisher_name,feed_publisher_url,feed_lang,feed_start_date,feed_end_date,feed_version",
+		"Publisher,http://example.com,en,20190115,20190215,v1.2",
+	}
+	s, err = storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err = s.GetWriter("test")
+	require.NoError(t, err)
+
+	metadata, err = ParseStatic(writer, buildZip(t, files))
+	require.NoError(t, err)
+	assert.Equal(t, "20190101", metadata.CalendarStartDate)
+	assert.Equal(t, "20190302", metadata.CalendarEndDate)
+	assert.Equal(t, "20190115", metadata.FeedStartDate)
+	assert.Equal(t, "20190215", metadata.FeedEndDate)
+	assert.Equal(t, "v1.2", metadata.FeedVersion)
+	assert.Equal(t, "Publisher", metadata.FeedPublisherName)
+	assert.Equal(t, "http://example.com", metadata.FeedPublisherURL)
+	assert.Equal(t, "en", metadata.FeedLang)
+
+	reader, err := s.GetReader("test")
+	require.NoError(t, err)
+	feedInfo, err := reader.FeedInfo()
+	require.NoError(t, err)
+	assert.Equal(t, []model.FeedInfo{{
+		PublisherName: "Publisher",
+		PublisherURL:  "http://example.com",
+		Lang:          "en",
+		StartDate:     "20190115",
+		EndDate:       "20190215",
+		Version:       "v1.2",
+	}}, feedInfo)
+}
+
 func TestParseMissingRequiredFile(t *testing.T) {
 
 	for _, file := range []string{
@@ -269,3 +321,335 @@ func TestParseUnorthodoxArchiveStructure(t *testing.T) {
 		URL:      "http://agency/index.html",
 	}}, agency)
 }
+
+func TestParseStaticReaderAt(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "feed-*.zip")
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.Write(buildZip(t, fixtureSimple()))
+	require.NoError(t, err)
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	s, err := storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err := s.GetWriter("test")
+	require.NoError(t, err)
+
+	metadata, err := ParseStaticReaderAt(writer, f, info.Size())
+	require.NoError(t, err)
+	assert.Equal(t, "America/Los_Angeles", metadata.Timezone)
+	assert.Equal(t, "120000", metadata.MaxDeparture)
+
+	reader, err := s.GetReader("test")
+	require.NoError(t, err)
+	stopTimes, err := reader.StopTimes()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(stopTimes))
+
+	// Truncated archive fails
+	s, err = storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err = s.GetWriter("test")
+	require.NoError(t, err)
+	_, err = ParseStaticReaderAt(writer, f, info.Size()/2)
+	assert.Error(t, err)
+}
+
+func TestParseStaticFS(t *testing.T) {
+	// Files may be nested in a subdirectory
+	fsys := fstest.MapFS{}
+	for name, content := range fixtureSimple() {
+		fsys["gtfs/"+name] = &fstest.MapFile{Data: []byte(strings.Join(content, "\n"))}
+	}
+	fsys["README"] = &fstest.MapFile{Data: []byte("not gtfs")}
+
+	s, err := storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err := s.GetWriter("test")
+	require.NoError(t, err)
+
+	metadata, err := ParseStaticFS(writer, fsys)
+	require.NoError(t, err)
+	assert.Equal(t, "America/Los_Angeles", metadata.Timezone)
+
+	reader, err := s.GetReader("test")
+	require.NoError(t, err)
+	stopTimes, err := reader.StopTimes()
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(stopTimes))
+
+	// Missing required file fails
+	delete(fsys, "gtfs/stops.txt")
+	s, err = storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err = s.GetWriter("test")
+	require.NoError(t, err)
+	_, err = ParseStaticFS(writer, fsys)
+	assert.Error(t, err)
+}
+
+func TestParseStaticLenient(t *testing.T) {
+	files := fixtureSimple()
+	files["trips.txt"] = []string{
+		"route_id,service_id,trip_id",
+		"r,mondays,t",
+		"r,mondays,t2",
+		"unknown,mondays,t3",
+		"r,mondays,t4",
+	}
+	files["stop_times.txt"] = []string{
+		"trip_id,arrival_time,departure_time,stop_id,stop_sequence",
+		"t,12:00:00,12:00:00,s,1",
+		"t2,12:00:00,12:00:00,s,1",
+		"t2,12:10:00,12:10:00,unknown,2",
+		"t3,12:00:00,12:00:00,s,1",
+		"t4,13:00:00,13:00:00,s,1",
+		"t4,,,s,2",
+	}
+	files["frequencies.txt"] = []string{
+		"trip_id,start_time,end_time,headway_secs",
+		"t,08:00:00,09:00:00,600",
+		"t2,08:00:00,09:00:00,600",
+	}
+	files["transfers.txt"] = []string{
+		"from_trip_id,to_trip_id,transfer_type",
+		"t,t3,4",
+	}
+	files["translations.txt"] = []string{
+		"table_name,field_name,language,translation,record_id",
+		"trips,trip_headsign,fr,Nulle part,t2",
+	}
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(strings.Join(content, "\n"))}
+	}
+
+	// Rejected when strict
+	s, err := storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err := s.GetWriter("test")
+	require.NoError(t, err)
+	_, err = ParseStaticFS(writer, fsys)
+	require.Error(t, err)
+
+	// But parses leniently, with the bad trips dropped
+	s, err = storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err = s.GetWriter("test")
+	require.NoError(t, err)
+	metadata, err := ParseStaticFSLenient(writer, fsys)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, metadata.DroppedCount)
+	assert.Equal(t, []string{
+		"trips.txt: unknown route_id 'unknown' (row 3); dropped trip_id 't3'",
+		"stop_times.txt: unknown stop_id 'unknown' (row 3); dropped trip_id 't2'",
+		"stop_times.txt: missing arrival_time: first and last stop of trip_id 't4' must be timed (row 6); dropped trip_id 't4'",
+	}, metadata.Dropped)
+	assert.Equal(t, "120000", metadata.MaxArrival)
+
+	reader, err := s.GetReader("test")
+	require.NoError(t, err)
+	trips, err := reader.Trips()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trips))
+	assert.Equal(t, "t", trips[0].ID)
+	stopTimes, err := reader.StopTimes()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stopTimes))
+	assert.Equal(t, "t", stopTimes[0].TripID)
+	frequencies, err := reader.Frequencies()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(frequencies))
+	assert.Equal(t, "t", frequencies[0].TripID)
+	transfers, err := reader.Transfers(storage.TransferFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(transfers))
+
+	// Feeds without bad rows drop nothing
+	s, err = storage.NewSQLiteStorage()
+	require.NoError(t, err)
+	writer, err = s.GetWriter("test")
+	require.NoError(t, err)
+	fsys = fstest.MapFS{}
+	for name, content := range fixtureSimple() {
+		fsys[name] = &fstest.MapFile{Data: []byte(strings.Join(content, "\n"))}
+	}
+	metadata, err = ParseStaticFSLenient(writer, fsys)
+	require.NoError(t, err)
+	assert.Equal(t, 0, metadata.DroppedCount)
+	assert.Equal(t, 0, len(metadata.Dropped))
+}
+
+func TestParseStaticLenientAllFiles(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		modify  func(files map[string][]string)
+		dropped []string
+	}{
+		{
+			"stops",
+			func(files map[string][]string) {
+				files["stops.txt"] = []string{
+					"stop_id,stop_name,stop_lat,stop_lon,parent_station",
+					"s,S,12,34,",
+					"station,,12,34,",
+					"platform,Platform,12,34,station",
+				}
+				files["trips.txt"] = append(files["trips.txt"], "r,mondays,t2")
+				files["stop_times.txt"] = append(
+					files["stop_times.txt"],
+					"t2,12:00:00,12:00:00,s,1",
+					"t2,12:10:00,12:10:00,platform,2",
+				)
+			},
+			[]string{
+				"stops.txt: missing stop_name (row 2); dropped row",
+				"stops.txt: unknown parent_station 'station' (row 3); dropped row",
+				"stop_times.txt: unknown stop_id 'platform' (row 3); dropped trip_id 't2'",
+			},
+		},
+		{
+			"routes",
+			func(files map[string][]string) {
+				files["routes.txt"] = append(files["routes.txt"], "r2,R2,x")
+				files["trips.txt"] = append(files["trips.txt"], "r2,mondays,t2")
+			},
+			[]string{
+				"routes.txt: invalid route_type 'x': strconv.Atoi: parsing \"x\": invalid syntax (row 2); dropped row",
+				"trips.txt: unknown route_id 'r2' (row 2); dropped trip_id 't2'",
+			},
+		},
+		{
+			"calendar",
+			func(files map[string][]string) {
+				files["calendar.txt"] = append(files["calendar.txt"], "sundays,2,20190101,20190301")
+				files["calendar_dates.txt"] = append(files["calendar_dates.txt"], "mondays,2019-03-09,1")
+			},
+			[]string{
+				"calendar.txt: invalid monday '2' (row 2); dropped row",
+				"calendar_dates.txt: invalid date '2019-03-09': parsing time \"2019-03-09\" as \"20060102\": cannot parse \"-03-09\" as \"01\" (row 2); dropped row",
+			},
+		},
+		{
+			"frequencies",
+			func(files map[string][]string) {
+				files["frequencies.txt"] = []string{
+					"trip_id,start_time,end_time,headway_secs",
+					"t,08:00:00,09:00:00,600",
+					"unknown,08:00:00,09:00:00,600",
+					"t,08:30:00,10:00:00,600",
+					"t,08:00:00,09:00:00,600",
+					"t,08:00:00,09:00:00,600",
+				}
+			},
+			[]string{
+				"frequencies.txt: unknown trip_id 'unknown' (row 2); dropped row",
+				"frequencies.txt: invalid start_time '080000': overlaps another frequency of trip_id 't' (row 4); dropped row",
+				"frequencies.txt: invalid start_time '080000': overlaps another frequency of trip_id 't' (row 5); dropped row",
+				"frequencies.txt: invalid start_time '083000': overlaps another frequency of trip_id 't' (row 3); dropped row",
+			},
+		},
+		{
+			"transfers and pathways",
+			func(files map[string][]string) {
+				files["transfers.txt"] = []string{
+					"from_stop_id,to_stop_id,transfer_type",
+					"s,unknown,0",
+				}
+				files["pathways.txt"] = []string{
+					"pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional",
+					"p,s,unknown,1,0",
+				}
+			},
+			[]string{
+				"transfers.txt: unknown to_stop_id 'unknown' (row 1); dropped row",
+				"pathways.txt: unknown to_stop_id 'unknown' (row 1); dropped row",
+			},
+		},
+		{
+			"fares",
+			func(files map[string][]string) {
+				files["fare_attributes.txt"] = []string{
+					"fare_id,price,currency_type,payment_method,transfers",
+					"f,2.5,dollars,0,",
+				}
+				files["fare_rules.txt"] = []string{
+					"fare_id,route_id",
+					"f,r",
+				}
+				files["fare_products.txt"] = []string{
+					"fare_product_id,amount,currency",
+					"p,free,USD",
+				}
+				files["fare_leg_rules.txt"] = []string{
+					"leg_group_id,fare_product_id",
+					"g,p",
+				}
+			},
+			[]string{
+				"fare_attributes.txt: invalid currency_type 'dollars' (row 1); dropped row",
+				"fare_rules.txt: unknown fare_id 'f' (row 1); dropped row",
+				"fare_products.txt: invalid amount 'free': strconv.ParseFloat: parsing \"free\": invalid syntax (row 1); dropped row",
+				"fare_leg_rules.txt: unknown fare_product_id 'p' (row 1); dropped row",
+			},
+		},
+		{
+			"shapes and feed_info",
+			func(files map[string][]string) {
+				files["shapes.txt"] = []string{
+					"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence,shape_dist_traveled",
+					"sh,1,1,1,10",
+					"sh,2,2,2,5",
+				}
+				files["trips.txt"] = []string{
+					"route_id,service_id,trip_id,shape_id",
+					"r,mondays,t,sh",
+				}
+				files["feed_info.txt"] = []string{
+					"feed_publisher_name,feed_publisher_url,feed_lang",
+					"Publisher,,en",
+				}
+			},
+			[]string{
+				"feed_info.txt: missing feed_publisher_url (row 1); dropped row",
+				"shapes.txt: invalid shape_dist_traveled '5': decreasing along shape_id 'sh' (row 2); dropped row",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files := fixtureSimple()
+			tc.modify(files)
+			fsys := fstest.MapFS{}
+			for name, content := range files {
+				fsys[name] = &fstest.MapFile{Data: []byte(strings.Join(content, "\n"))}
+			}
+
+			// Rejected when strict
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+			_, err = ParseStaticFS(writer, fsys)
+			require.Error(t, err)
+
+			// But parses leniently, keeping the good trip
+			s, err = storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err = s.GetWriter("test")
+			require.NoError(t, err)
+			metadata, err := ParseStaticFSLenient(writer, fsys)
+			require.NoError(t, err)
+			assert.Equal(t, tc.dropped, metadata.Dropped)
+			assert.Equal(t, len(tc.dropped), metadata.DroppedCount)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			trips, err := reader.Trips()
+			require.NoError(t, err)
+			require.Equal(t, 1, len(trips))
+			assert.Equal(t, "t", trips[0].ID)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type PathwayCSV struct {
+	ID                   string  `csv:"pathway_id"`
+	FromStopID           string  `csv:"from_stop_id"`
+	ToStopID             string  `csv:"to_stop_id"`
+	Mode                 int8    `csv:"pathway_mode"`
+	IsBidirectional      int8    `csv:"is_bidirectional"`
+	Length               float64 `csv:"length"`
+	TraversalTime        int     `csv:"traversal_time"`
+	StairCount           int     `csv:"stair_count"`
+	MaxSlope             float64 `csv:"max_slope"`
+	MinWidth             float64 `csv:"min_width"`
+	SignpostedAs         string  `csv:"signposted_as"`
+	ReversedSignpostedAs string  `csv:"reversed_signposted_as"`
+}
+
+// Parses pathways.txt.
+func ParsePathways(writer storage.FeedWriter, data io.Reader, stops map[string]bool) error {
+	return parsePathways(writer, data, stops, nil)
+}
+
+// Parses pathways.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse.
+func parsePathways(writer storage.FeedWriter, data io.Reader, stops map[string]bool, drop *dropped) error {
+	pathwayCsv := []*PathwayCSV{}
+	if err := gocsv.Unmarshal(data, &pathwayCsv); err != nil {
+		return errMalformed("pathways.txt", err)
+	}
+
+	pathways := map[string]bool{}
+	parseRow := func(row int, p *PathwayCSV) error {
+		if p.ID == "" {
+			return errMissing("pathways.txt", row, "pathway_id")
+		}
+		if pathways[p.ID] {
+			return errDuplicate("pathways.txt", row, "pathway_id", p.ID)
+		}
+
+		if !stops[p.FromStopID] {
+			return errUnknown("pathways.txt", row, "from_stop_id", p.FromStopID)
+		}
+		if !stops[p.ToStopID] {
+			return errUnknown("pathways.txt", row, "to_stop_id", p.ToStopID)
+		}
+
+		mode := model.PathwayMode(p.Mode)
+		if mode < model.PathwayModeWalkway || mode > model.PathwayModeExitGate {
+			return errInvalid("pathways.txt", row, "pathway_mode", strconv.Itoa(int(p.Mode)), nil)
+		}
+
+		if p.IsBidirectional != 0 && p.IsBidirectional != 1 {
+			return errInvalid("pathways.txt", row, "is_bidirectional", strconv.Itoa(int(p.IsBidirectional)), nil)
+		}
+
+		if p.Length < 0 {
+			return errInvalid("pathways.txt", row, "length", strconv.FormatFloat(p.Length, 'f', -1, 64), fmt.Errorf("negative"))
+		}
+		if p.TraversalTime < 0 {
+			return errInvalid("pathways.txt", row, "traversal_time", strconv.Itoa(p.TraversalTime), fmt.Errorf("negative"))
+		}
+		if p.MinWidth < 0 {
+			return errInvalid("pathways.txt", row, "min_width", strconv.FormatFloat(p.MinWidth, 'f', -1, 64), fmt.Errorf("negative"))
+		}
+
+		err := writer.WritePathway(model.Pathway{
+			ID:                   p.ID,
+			FromStopID:           p.FromStopID,
+			ToStopID:             p.ToStopID,
+			Mode:                 mode,
+			IsBidirectional:      p.IsBidirectional == 1,
+			Length:               p.Length,
+			TraversalTime:        uint32(p.TraversalTime),
+			StairCount:           p.StairCount,
+			MaxSlope:             p.MaxSlope,
+			MinWidth:             p.MinWidth,
+			SignpostedAs:         p.SignpostedAs,
+			ReversedSignpostedAs: p.ReversedSignpostedAs,
+		})
+		if err != nil {
+			return errStorage("pathways.txt", row, fmt.Errorf("writing pathway: %w", err))
+		}
+
+		pathways[p.ID] = true
+		return nil
+	}
+
+	for i, p := range pathwayCsv {
+		if err := drop.skip(parseRow(i+1, p)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
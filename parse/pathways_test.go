@@ -0,0 +1,147 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParsePathways(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected []model.Pathway
+		err      bool
+	}{
+		{
+			"minimal",
+			`
+pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional
+p,e,s,1,0`,
+			[]model.Pathway{{
+				ID:         "p",
+				FromStopID: "e",
+				ToStopID:   "s",
+				Mode:       model.PathwayModeWalkway,
+			}},
+			false,
+		},
+
+		{
+			"maximal",
+			`
+pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional,length,traversal_time,stair_count,max_slope,min_width,signposted_as,reversed_signposted_as
+p1,e,g,2,1,12.5,30,-20,0,1.5,Down to trains,Exit
+p2,g,s,7,0,,,,,,Platform,`,
+			[]model.Pathway{
+				{
+					ID:                   "p1",
+					FromStopID:           "e",
+					ToStopID:             "g",
+					Mode:                 model.PathwayModeStairs,
+					IsBidirectional:      true,
+					Length:               12.5,
+					TraversalTime:        30,
+					StairCount:           -20,
+					MinWidth:             1.5,
+					SignpostedAs:         "Down to trains",
+					ReversedSignpostedAs: "Exit",
+				},
+				{
+					ID:           "p2",
+					FromStopID:   "g",
+					ToStopID:     "s",
+					Mode:         model.PathwayModeExitGate,
+					SignpostedAs: "Platform",
+				},
+			},
+			false,
+		},
+
+		{
+			"empty pathway_id",
+			`
+pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional
+,e,s,1,0`,
+			nil, true,
+		},
+
+		{
+			"repeated pathway_id",
+			`
+pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional
+p,e,s,1,0
+p,s,e,1,0`,
+			nil, true,
+		},
+
+		{
+			"unknown from_stop_id",
+			`
+pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional
+p,x,s,1,0`,
+			nil, true,
+		},
+
+		{
+			"unknown to_stop_id",
+			`
+pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional
+p,e,x,1,0`,
+			nil, true,
+		},
+
+		{
+			"invalid pathway_mode",
+			`
+pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional
+p,e,s,8,0`,
+			nil, true,
+		},
+
+		{
+			"invalid is_bidirectional",
+			`
+pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional
+p,e,s,1,2`,
+			nil, true,
+		},
+
+		{
+			"negative traversal_time",
+			`
+pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional,traversal_time
+p,e,s,1,0,-5`,
+			nil, true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			err = ParsePathways(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"e": true, "g": true, "s": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			pathways, err := reader.Pathways()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, pathways)
+		})
+	}
+}
@@ -6,35 +6,26 @@ import (
 	"io"
 	"strconv"
 
-	"github.com/gocarina/gocsv"
-
 	"tidbyt.dev/gtfs/model"
 	"tidbyt.dev/gtfs/storage"
 )
 
 type RouteCSV struct {
-	ID        string `csv:"route_id"`
-	AgencyID  string `csv:"agency_id"`
-	ShortName string `csv:"route_short_name"`
-	LongName  string `csv:"route_long_name"`
-	Desc      string `csv:"route_desc"`
-	Type      string `csv:"route_type"`
-	URL       string `csv:"route_url"`
-	Color     string `csv:"route_color"`
-	TextColor string `csv:"route_text_color"`
-	// SortOrder string `csv:"route_sort_order"`
-	// ContinuousPickup string `csv:"continuous_pickup"`
-	// ContinuousDropOff string `csv:"continuous_drop_off"`
-}
-
-func legalRouteType(t model.RouteType) bool {
-	if t >= 0 && t <= 7 {
-		return true
-	}
-	if t >= 11 || t <= 12 {
-		return true
-	}
-	return false
+	ID                string `csv:"route_id"`
+	AgencyID          string `csv:"agency_id"`
+	ShortName         string `csv:"route_short_name"`
+	LongName          string `csv:"route_long_name"`
+	Desc              string `csv:"route_desc"`
+	Type              string `csv:"route_type"`
+	URL               string `csv:"route_url"`
+	Color             string `csv:"route_color"`
+	TextColor         string `csv:"route_text_color"`
+	SortOrder         string `csv:"route_sort_order"`
+	ContinuousPickup  string `csv:"continuous_pickup"`
+	ContinuousDropOff string `csv:"continuous_drop_off"`
+	NetworkID         string `csv:"network_id"`
+
+	Extras map[string]string `csv:"-"`
 }
 
 func validRouteColor(color string) bool {
@@ -47,66 +38,112 @@ func validRouteColor(color string) bool {
 	return true
 }
 
+// Parses a continuous_pickup or continuous_drop_off value. Also
+// returns whether the value was set at all.
+func parseContinuousStopping(value string) (model.ContinuousStopping, bool, error) {
+	switch value {
+	case "":
+		return model.ContinuousStoppingNone, false, nil
+	case "0":
+		return model.ContinuousStoppingRegular, true, nil
+	case "1":
+		return model.ContinuousStoppingNone, true, nil
+	case "2":
+		return model.ContinuousStoppingPhoneAgency, true, nil
+	case "3":
+		return model.ContinuousStoppingCoordinateWithDriver, true, nil
+	}
+	return model.ContinuousStoppingNone, false, fmt.Errorf("invalid value '%s'", value)
+}
+
 func ParseRoutes(writer storage.FeedWriter, data io.Reader, agency map[string]bool) (map[string]bool, error) {
+	return parseRoutes(writer, data, agency, nil, false)
+}
+
+// Parses routes.txt. If drop is non-nil, bad rows are dropped rather
+// than failing the parse, and left out of the returned route IDs. If
+// extras is set, values in extension columns are captured.
+func parseRoutes(writer storage.FeedWriter, data io.Reader, agency map[string]bool, drop *dropped, extras bool) (map[string]bool, error) {
 	routeCsv := []*RouteCSV{}
-	if err := gocsv.Unmarshal(data, &routeCsv); err != nil {
-		return nil, fmt.Errorf("unmarshaling routes: %v", err)
+	if err := unmarshalExtras(data, &routeCsv, "routes.txt", extras); err != nil {
+		return nil, errMalformed("routes.txt", err)
 	}
 
 	routes := map[string]bool{}
 
-	for _, r := range routeCsv {
+	parseRow := func(row int, r *RouteCSV) error {
 		if routes[r.ID] {
-			return nil, fmt.Errorf("repeated route_id: '%s'", r.ID)
+			return errDuplicate("routes.txt", row, "route_id", r.ID)
 		}
-		routes[r.ID] = true
 
 		// If multiple agencies, agency_id is required
 		if len(agency) > 1 {
 			if r.AgencyID == "" {
-				return nil, fmt.Errorf("route_id '%s' has no agency_id", r.ID)
+				return errMissing("routes.txt", row, "agency_id")
 			}
 		}
 
 		// Agency (if set) must be known from agency.txt
 		if r.AgencyID != "" && !agency[r.AgencyID] {
-			return nil, fmt.Errorf("unknown agency_id: '%s'", r.AgencyID)
+			return errUnknown("routes.txt", row, "agency_id", r.AgencyID)
 		}
 
 		// ID is required
 		if r.ID == "" {
-			return nil, fmt.Errorf("route has no route_id")
+			return errMissing("routes.txt", row, "route_id")
 		}
 
 		// ShortName or LongName is required
 		if r.ShortName == "" && r.LongName == "" {
-			return nil, fmt.Errorf("route_id '%s' has no short_name or long_name", r.ID)
+			return &ParseError{
+				File:   "routes.txt",
+				Row:    row,
+				Column: "route_short_name",
+				Code:   CodeMissingValue,
+				Err:    fmt.Errorf("route_long_name is also missing"),
+			}
 		}
 
 		// RouteType is required
 		if r.Type == "" {
-			return nil, fmt.Errorf("route_id '%s' has no route_type", r.ID)
+			return errMissing("routes.txt", row, "route_type")
 		}
 		routeType, err := strconv.Atoi(r.Type)
 		if err != nil {
-			return nil, fmt.Errorf("route_id '%s' has invalid route_type: %w", r.ID, err)
+			return errInvalid("routes.txt", row, "route_type", r.Type, err)
 		}
 
-		// RouteType must be valid
-		if !legalRouteType(model.RouteType(routeType)) {
-			return nil, fmt.Errorf("route_id '%s' has invalid route_type: %d", r.ID, routeType)
-		}
+		// Route types other than the basic and extended ones
+		// are kept as is. They're rare enough, and not worth
+		// rejecting the feed over.
 
 		// Defaults from the GTFS spec
 		if r.Color == "" {
 			r.Color = "FFFFFF"
 		} else if !validRouteColor(r.Color) {
-			return nil, fmt.Errorf("route_id '%s' has invalid route_color: %s", r.ID, r.Color)
+			return errInvalid("routes.txt", row, "route_color", r.Color, nil)
 		}
 		if r.TextColor == "" {
 			r.TextColor = "000000"
 		} else if !validRouteColor(r.TextColor) {
-			return nil, fmt.Errorf("route_id '%s' has invalid route_text_color: %s", r.ID, r.TextColor)
+			return errInvalid("routes.txt", row, "route_text_color", r.TextColor, nil)
+		}
+
+		continuousPickup, _, err := parseContinuousStopping(r.ContinuousPickup)
+		if err != nil {
+			return errInvalid("routes.txt", row, "continuous_pickup", r.ContinuousPickup, nil)
+		}
+		continuousDropOff, _, err := parseContinuousStopping(r.ContinuousDropOff)
+		if err != nil {
+			return errInvalid("routes.txt", row, "continuous_drop_off", r.ContinuousDropOff, nil)
+		}
+
+		sortOrder := 0
+		if r.SortOrder != "" {
+			sortOrder, err = strconv.Atoi(r.SortOrder)
+			if err != nil || sortOrder < 0 {
+				return errInvalid("routes.txt", row, "route_sort_order", r.SortOrder, nil)
+			}
 		}
 
 		err = writer.WriteRoute(model.Route{
@@ -119,9 +156,25 @@ func ParseRoutes(writer storage.FeedWriter, data io.Reader, agency map[string]bo
 			URL:       r.URL,
 			Color:     r.Color,
 			TextColor: r.TextColor,
+
+			ContinuousPickup:  continuousPickup,
+			ContinuousDropOff: continuousDropOff,
+			SortOrder:         sortOrder,
+			HasSortOrder:      r.SortOrder != "",
+			NetworkID:         r.NetworkID,
+			Extras:            r.Extras,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("writing route: %v", err)
+			return errStorage("routes.txt", row, fmt.Errorf("writing route: %w", err))
+		}
+
+		routes[r.ID] = true
+		return nil
+	}
+
+	for i, r := range routeCsv {
+		if err := drop.skip(parseRow(i+1, r)); err != nil {
+			return nil, err
 		}
 	}
 
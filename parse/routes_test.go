@@ -86,6 +86,82 @@ r2,a2,two,Route Two,Description2,3,http://two/,FFFFF1,00000E`,
 			false,
 		},
 
+		{
+			"continuous pickup and drop off",
+			`
+route_id,route_short_name,route_type,continuous_pickup,continuous_drop_off
+r1,one,3,0,2
+r2,two,3,1,3
+r3,three,3,,`,
+			map[string]bool{},
+			[]model.Route{
+				model.Route{
+					ID:                "r1",
+					ShortName:         "one",
+					Type:              model.RouteType(3),
+					Color:             "FFFFFF",
+					TextColor:         "000000",
+					ContinuousPickup:  model.ContinuousStoppingRegular,
+					ContinuousDropOff: model.ContinuousStoppingPhoneAgency,
+				},
+				model.Route{
+					ID:                "r2",
+					ShortName:         "two",
+					Type:              model.RouteType(3),
+					Color:             "FFFFFF",
+					TextColor:         "000000",
+					ContinuousPickup:  model.ContinuousStoppingNone,
+					ContinuousDropOff: model.ContinuousStoppingCoordinateWithDriver,
+				},
+				model.Route{
+					ID:        "r3",
+					ShortName: "three",
+					Type:      model.RouteType(3),
+					Color:     "FFFFFF",
+					TextColor: "000000",
+				},
+			},
+			false,
+		},
+
+		{
+			"route_sort_order",
+			`
+route_id,route_short_name,route_type,route_sort_order
+r1,one,3,10
+r2,two,3,0
+r3,three,3,`,
+			map[string]bool{},
+			[]model.Route{
+				model.Route{
+					ID:           "r1",
+					ShortName:    "one",
+					Type:         model.RouteType(3),
+					Color:        "FFFFFF",
+					TextColor:    "000000",
+					SortOrder:    10,
+					HasSortOrder: true,
+				},
+				model.Route{
+					ID:           "r2",
+					ShortName:    "two",
+					Type:         model.RouteType(3),
+					Color:        "FFFFFF",
+					TextColor:    "000000",
+					SortOrder:    0,
+					HasSortOrder: true,
+				},
+				model.Route{
+					ID:        "r3",
+					ShortName: "three",
+					Type:      model.RouteType(3),
+					Color:     "FFFFFF",
+					TextColor: "000000",
+				},
+			},
+			false,
+		},
+
 		{
 			"record with missing route_id",
 			`
@@ -127,6 +203,54 @@ r1,one,invalid`,
 			true,
 		},
 
+		{
+			"extended route types",
+			`
+route_id,route_short_name,route_type
+r1,one,109
+r2,two,700
+r3,three,1100
+r4,four,750
+r5,five,1600`,
+			map[string]bool{},
+			[]model.Route{
+				{ID: "r1", ShortName: "one", Type: 109, Color: "FFFFFF", TextColor: "000000"},
+				{ID: "r2", ShortName: "two", Type: 700, Color: "FFFFFF", TextColor: "000000"},
+				{ID: "r3", ShortName: "three", Type: 1100, Color: "FFFFFF", TextColor: "000000"},
+				{ID: "r4", ShortName: "four", Type: 750, Color: "FFFFFF", TextColor: "000000"},
+				{ID: "r5", ShortName: "five", Type: 1600, Color: "FFFFFF", TextColor: "000000"},
+			},
+			false,
+		},
+
+		{
+			"unknown route types",
+			`
+route_id,route_short_name,route_type
+r1,one,8
+r2,two,2000`,
+			map[string]bool{},
+			[]model.Route{
+				{ID: "r1", ShortName: "one", Type: 8, Color: "FFFFFF", TextColor: "000000"},
+				{ID: "r2", ShortName: "two", Type: 2000, Color: "FFFFFF", TextColor: "000000"},
+			},
+			false,
+		},
+
+		{
+			"network_id",
+			`
+route_id,route_short_name,route_type,network_id
+r1,one,3,bus
+r2,two,3,`,
+			map[string]bool{},
+			[]model.Route{
+				{ID: "r1", ShortName: "one", Type: 3, Color: "FFFFFF", TextColor: "000000", NetworkID: "bus"},
+				{ID: "r2", ShortName: "two", Type: 3, Color: "FFFFFF", TextColor: "000000"},
+			},
+			false,
+		},
+
 		{
 			"record with invalid route_color",
 			`
@@ -147,6 +271,36 @@ r1,one,3,invalid`,
 			true,
 		},
 
+		{
+			"record with invalid continuous_pickup",
+			`
+route_id,route_short_name,route_type,continuous_pickup
+r1,one,3,4`,
+			map[string]bool{},
+			nil,
+			true,
+		},
+
+		{
+			"record with invalid continuous_drop_off",
+			`
+route_id,route_short_name,route_type,continuous_drop_off
+r1,one,3,x`,
+			map[string]bool{},
+			nil,
+			true,
+		},
+
+		{
+			"record with invalid route_sort_order",
+			`
+route_id,route_short_name,route_type,route_sort_order
+r1,one,3,-1`,
+			map[string]bool{},
+			nil,
+			true,
+		},
+
 		{
 			"repeated route_id",
 			`
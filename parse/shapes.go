@@ -0,0 +1,138 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type ShapeCSV struct {
+	ID           string  `csv:"shape_id"`
+	Lat          float64 `csv:"shape_pt_lat"`
+	Lon          float64 `csv:"shape_pt_lon"`
+	Sequence     uint32  `csv:"shape_pt_sequence"`
+	DistTraveled string  `csv:"shape_dist_traveled"`
+}
+
+// Parses shapes.txt. Returns set of all shape IDs.
+func ParseShapes(writer storage.FeedWriter, data io.Reader) (map[string]bool, error) {
+	return parseShapes(writer, data, nil)
+}
+
+// Parses shapes.txt. If drop is non-nil, bad rows are dropped rather
+// than failing the parse. Shapes with inconsistent sequences or
+// distances are left out of the returned shape IDs, leaving their
+// points unreferenced.
+func parseShapes(writer storage.FeedWriter, data io.Reader, drop *dropped) (map[string]bool, error) {
+	type seqDist struct {
+		row     int
+		seq     uint32
+		dist    float64
+		hasDist bool
+	}
+	points := map[string][]seqDist{}
+
+	row := 0
+	parseRow := func(s *ShapeCSV) error {
+		if s.ID == "" {
+			return errMissing("shapes.txt", row, "shape_id")
+		}
+
+		if s.Lat < -90 || s.Lat > 90 {
+			return errInvalid("shapes.txt", row, "shape_pt_lat", strconv.FormatFloat(s.Lat, 'f', -1, 64), nil)
+		}
+		if s.Lon < -180 || s.Lon > 180 {
+			return errInvalid("shapes.txt", row, "shape_pt_lon", strconv.FormatFloat(s.Lon, 'f', -1, 64), nil)
+		}
+
+		point := model.ShapePoint{
+			Lat:      s.Lat,
+			Lon:      s.Lon,
+			Sequence: s.Sequence,
+		}
+
+		if s.DistTraveled != "" {
+			dist, err := strconv.ParseFloat(s.DistTraveled, 64)
+			if err != nil {
+				return errInvalid("shapes.txt", row, "shape_dist_traveled", s.DistTraveled, nil)
+			}
+			if dist < 0 {
+				return errInvalid("shapes.txt", row, "shape_dist_traveled", s.DistTraveled, fmt.Errorf("negative"))
+			}
+			point.DistTraveled = dist
+			point.HasDistTraveled = true
+		}
+
+		points[s.ID] = append(points[s.ID], seqDist{
+			row:     row,
+			seq:     point.Sequence,
+			dist:    point.DistTraveled,
+			hasDist: point.HasDistTraveled,
+		})
+
+		err := writer.WriteShapePoint(s.ID, point)
+		if err != nil {
+			return errStorage("shapes.txt", row, fmt.Errorf("writing shape point: %w", err))
+		}
+
+		return nil
+	}
+
+	err := gocsv.UnmarshalToCallbackWithError(data, func(s *ShapeCSV) error {
+		row += 1
+		return drop.skip(parseRow(s))
+	})
+	if err != nil {
+		return nil, errMalformed("shapes.txt", err)
+	}
+
+	// Verify that shape_pt_sequence is unique for each shape,
+	// and that shape_dist_traveled doesn't decrease along the
+	// shape.
+	verify := func(shapeID string, pts []seqDist) error {
+		sort.Slice(pts, func(i, j int) bool {
+			return pts[i].seq < pts[j].seq
+		})
+
+		lastDist := -1.0
+		for i, p := range pts {
+			if i > 0 && pts[i-1].seq == p.seq {
+				return &ParseError{
+					File:   "shapes.txt",
+					Row:    p.row,
+					Column: "shape_pt_sequence",
+					Value:  strconv.FormatUint(uint64(p.seq), 10),
+					Code:   CodeDuplicateKey,
+					Err:    fmt.Errorf("for shape_id '%s'", shapeID),
+				}
+			}
+			if !p.hasDist {
+				continue
+			}
+			if p.dist < lastDist {
+				return errInvalid("shapes.txt", p.row, "shape_dist_traveled", strconv.FormatFloat(p.dist, 'f', -1, 64), fmt.Errorf("decreasing along shape_id '%s'", shapeID))
+			}
+			lastDist = p.dist
+		}
+
+		return nil
+	}
+
+	shapes := map[string]bool{}
+	for shapeID, pts := range points {
+		err := verify(shapeID, pts)
+		if err == nil {
+			shapes[shapeID] = true
+		} else if err = drop.skip(err); err != nil {
+			return nil, err
+		}
+	}
+
+	return shapes, nil
+}
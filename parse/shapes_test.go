@@ -0,0 +1,154 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseShapes(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		shapes  map[string]*model.Shape
+		err     bool
+	}{
+		{
+			"minimal",
+			`
+shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence
+a,1.5,2.5,1`,
+			map[string]*model.Shape{
+				"a": &model.Shape{
+					ID: "a",
+					Points: []model.ShapePoint{
+						{Lat: 1.5, Lon: 2.5, Sequence: 1},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"multiple shapes, unordered, with distances",
+			`
+shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence,shape_dist_traveled
+a,3,3,30,2.5
+b,1,1,1,
+a,1,1,10,0
+b,2,2,2,
+a,2,2,20,1.0`,
+			map[string]*model.Shape{
+				"a": &model.Shape{
+					ID: "a",
+					Points: []model.ShapePoint{
+						{Lat: 1, Lon: 1, Sequence: 10, DistTraveled: 0, HasDistTraveled: true},
+						{Lat: 2, Lon: 2, Sequence: 20, DistTraveled: 1, HasDistTraveled: true},
+						{Lat: 3, Lon: 3, Sequence: 30, DistTraveled: 2.5, HasDistTraveled: true},
+					},
+				},
+				"b": &model.Shape{
+					ID: "b",
+					Points: []model.ShapePoint{
+						{Lat: 1, Lon: 1, Sequence: 1},
+						{Lat: 2, Lon: 2, Sequence: 2},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"missing shape_id",
+			`
+shape_pt_lat,shape_pt_lon,shape_pt_sequence
+1,2,1`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid lat",
+			`
+shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence
+a,91,2,1`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid lon",
+			`
+shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence
+a,1,-181,1`,
+			nil,
+			true,
+		},
+
+		{
+			"duplicate sequence",
+			`
+shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence
+a,1,1,1
+a,2,2,1`,
+			nil,
+			true,
+		},
+
+		{
+			"decreasing distance",
+			`
+shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence,shape_dist_traveled
+a,1,1,1,0
+a,2,2,2,5
+a,3,3,3,4`,
+			nil,
+			true,
+		},
+
+		{
+			"malformed distance",
+			`
+shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence,shape_dist_traveled
+a,1,1,1,far`,
+			nil,
+			true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			require.NoError(t, writer.BeginShapes())
+			shapeIDs, err := ParseShapes(writer, bytes.NewBufferString(tc.content))
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, writer.EndShapes())
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+
+			assert.Equal(t, len(tc.shapes), len(shapeIDs))
+			for shapeID, expected := range tc.shapes {
+				assert.True(t, shapeIDs[shapeID])
+				shape, err := reader.Shape(shapeID)
+				require.NoError(t, err)
+				assert.Equal(t, expected, shape)
+			}
+
+			shape, err := reader.Shape("unknown")
+			require.NoError(t, err)
+			assert.Nil(t, shape)
+		})
+	}
+}
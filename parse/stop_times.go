@@ -3,12 +3,13 @@ package parse
 import (
 	"fmt"
 	"io"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gocarina/gocsv"
-	"github.com/pkg/errors"
 
 	"tidbyt.dev/gtfs/model"
 	"tidbyt.dev/gtfs/storage"
@@ -21,6 +22,32 @@ type StopTimeCSV struct {
 	ArrivalTime   string `csv:"arrival_time"`
 	DepartureTime string `csv:"departure_time"`
 	Headsign      string `csv:"stop_headsign"`
+	PickupType    int8   `csv:"pickup_type"`
+	DropOffType   int8   `csv:"drop_off_type"`
+	Timepoint     string `csv:"timepoint"`
+	DistTraveled  string `csv:"shape_dist_traveled"`
+
+	ContinuousPickup  string `csv:"continuous_pickup"`
+	ContinuousDropOff string `csv:"continuous_drop_off"`
+
+	// GTFS-Flex
+	LocationGroupID      string `csv:"location_group_id"`
+	LocationID           string `csv:"location_id"`
+	StartWindow          string `csv:"start_pickup_drop_off_window"`
+	EndWindow            string `csv:"end_pickup_drop_off_window"`
+	PickupBookingRuleID  string `csv:"pickup_booking_rule_id"`
+	DropOffBookingRuleID string `csv:"drop_off_booking_rule_id"`
+}
+
+// A stop on a trip, as needed for interpolating times.
+type tripStop struct {
+	row       int
+	seq       uint32
+	dist      float64
+	hasDist   bool
+	timed     bool
+	arrival   time.Duration
+	departure time.Duration
 }
 
 func parseStopTimeTime(s string) (string, error) {
@@ -53,44 +80,191 @@ func parseStopTimeTime(s string) (string, error) {
 	return fmt.Sprintf("%02d%02d%02d", hms[0], hms[1], hms[2]), nil
 }
 
+// Parses stop_times.txt. Arrival and departure times may be left
+// blank for stops other than the first and last of a trip, in which
+// case they are interpolated from the surrounding timed stops. The
+// interpolation is by shape_dist_traveled where available, and
+// otherwise by the number of stops in between. Interpolated times,
+// and times on stops that aren't timepoints, are marked approximate.
+//
+// Stop times served on demand within a pickup/drop off window, at a
+// GTFS-Flex location, location group or stop, are written as flex
+// stop times.
+//
+// Stop times of a trip with blank times must be grouped together.
+//
+// Returns the maximum arrival and departure times.
 func ParseStopTimes(
 	writer storage.FeedWriter,
 	data io.Reader,
 	trips map[string]bool,
 	stops map[string]bool,
+	locations map[string]bool,
+	locationGroups map[string]bool,
+	bookingRules map[string]bool,
+) (string, string, error) {
+	return parseStopTimes(writer, data, trips, stops, locations, locationGroups, bookingRules, nil)
+}
+
+// Parses stop_times.txt. If drop is non-nil, trips with bad stop times
+// are dropped rather than failing the parse. Stop times preceding the
+// bad row may have been written by then, so bad rows must be found
+// with a scan before anything is written.
+func parseStopTimes(
+	writer storage.FeedWriter,
+	data io.Reader,
+	trips map[string]bool,
+	stops map[string]bool,
+	locations map[string]bool,
+	locationGroups map[string]bool,
+	bookingRules map[string]bool,
+	drop *dropped,
 ) (string, string, error) {
 
-	stopTimes := []model.StopTime{}
+	// Sequence numbers of each trip's stop times.
+	seqs := map[string][]uint32{}
+
+	// Stop times are usually grouped by trip, so blank times are
+	// interpolated a trip at a time. Stops of the trip being read
+	// are kept until the trip ends, with its stop times lacking
+	// arrival and departure times held back until they've been
+	// interpolated.
+	current := ""
+	currentRow := 0
+	recurring := false
+	group := []tripStop{}
+	untimed := []model.StopTime{}
 
-	stopSeq := map[string][]int{}
+	// Trips with blank times, whose stop times can't be
+	// interpolated if spread out across the file.
+	interpolated := map[string]bool{}
 
 	maxArrival := "000000"
 	maxDeparture := "000000"
 
-	i := -1
-	err := gocsv.UnmarshalToCallbackWithError(data, func(st *StopTimeCSV) error {
-		i += 1
+	row := 0
+	parseRow := func(st *StopTimeCSV) error {
 		if !trips[st.TripID] {
-			return fmt.Errorf("unknown trip_id: '%s' (row %d)", st.TripID, i+1)
+			return errUnknown("stop_times.txt", row, "trip_id", st.TripID)
+		}
+		if st.StopID == "" && st.LocationGroupID == "" && st.LocationID == "" {
+			return errMissing("stop_times.txt", row, "stop_id")
+		}
+		if st.StopID != "" && !stops[st.StopID] {
+			return errUnknown("stop_times.txt", row, "stop_id", st.StopID)
+		}
+		if st.PickupBookingRuleID != "" && !bookingRules[st.PickupBookingRuleID] {
+			return errUnknown("stop_times.txt", row, "pickup_booking_rule_id", st.PickupBookingRuleID)
 		}
-		if st.StopID == "" {
-			return fmt.Errorf("missing stop_id (row %d)", i+1)
+		if st.DropOffBookingRuleID != "" && !bookingRules[st.DropOffBookingRuleID] {
+			return errUnknown("stop_times.txt", row, "drop_off_booking_rule_id", st.DropOffBookingRuleID)
 		}
-		if !stops[st.StopID] {
-			return fmt.Errorf("unknown stop_id: '%s' (row %d)", st.StopID, i+1)
+
+		if st.PickupType < 0 || st.PickupType > 3 {
+			return errInvalid("stop_times.txt", row, "pickup_type", strconv.Itoa(int(st.PickupType)), nil)
+		}
+		if st.DropOffType < 0 || st.DropOffType > 3 {
+			return errInvalid("stop_times.txt", row, "drop_off_type", strconv.Itoa(int(st.DropOffType)), nil)
+		}
+		if st.Timepoint != "" && st.Timepoint != "0" && st.Timepoint != "1" {
+			return errInvalid("stop_times.txt", row, "timepoint", st.Timepoint, nil)
+		}
+
+		if st.LocationGroupID != "" || st.LocationID != "" || st.StartWindow != "" || st.EndWindow != "" {
+			flexStopTime, err := parseFlexStopTime(row, st, locations, locationGroups)
+			if err != nil {
+				return err
+			}
+			seqs[st.TripID] = append(seqs[st.TripID], st.StopSequence)
+
+			err = writer.WriteFlexStopTime(*flexStopTime)
+			if err != nil {
+				return errStorage("stop_times.txt", row, fmt.Errorf("writing flex stop_time: %w", err))
+			}
+			return nil
+		}
+
+		// If only one of the times is given, it applies to
+		// both.
+		if st.ArrivalTime == "" {
+			st.ArrivalTime = st.DepartureTime
+		}
+		if st.DepartureTime == "" {
+			st.DepartureTime = st.ArrivalTime
+		}
+		timed := st.ArrivalTime != ""
+		if !timed && st.Timepoint == "1" {
+			return &ParseError{
+				File:   "stop_times.txt",
+				Row:    row,
+				Column: "arrival_time",
+				Code:   CodeMissingValue,
+				Err:    fmt.Errorf("timepoint must be timed"),
+			}
+		}
+
+		stopTime := model.StopTime{
+			TripID:       st.TripID,
+			StopID:       st.StopID,
+			Headsign:     st.Headsign,
+			StopSequence: st.StopSequence,
+			PickupType:   model.PickupDropOffType(st.PickupType),
+			DropOffType:  model.PickupDropOffType(st.DropOffType),
+			Approximate:  !timed || st.Timepoint == "0",
+		}
+
+		if st.DistTraveled != "" {
+			dist, err := strconv.ParseFloat(st.DistTraveled, 64)
+			if err != nil {
+				return errInvalid("stop_times.txt", row, "shape_dist_traveled", st.DistTraveled, nil)
+			}
+			if dist < 0 {
+				return errInvalid("stop_times.txt", row, "shape_dist_traveled", st.DistTraveled, fmt.Errorf("negative"))
+			}
+			stopTime.ShapeDistTraveled = dist
+			stopTime.HasShapeDistTraveled = true
+		}
+
+		var err error
+		stopTime.ContinuousPickup, stopTime.HasContinuousPickup, err = parseContinuousStopping(st.ContinuousPickup)
+		if err != nil {
+			return errInvalid("stop_times.txt", row, "continuous_pickup", st.ContinuousPickup, nil)
+		}
+		stopTime.ContinuousDropOff, stopTime.HasContinuousDropOff, err = parseContinuousStopping(st.ContinuousDropOff)
+		if err != nil {
+			return errInvalid("stop_times.txt", row, "continuous_drop_off", st.ContinuousDropOff, nil)
+		}
+
+		seqs[st.TripID] = append(seqs[st.TripID], st.StopSequence)
+		group = append(group, tripStop{
+			row:     row,
+			seq:     st.StopSequence,
+			dist:    stopTime.ShapeDistTraveled,
+			hasDist: stopTime.HasShapeDistTraveled,
+			timed:   timed,
+		})
+
+		if !timed {
+			untimed = append(untimed, stopTime)
+			return nil
 		}
 
 		arrivalTime, err := parseStopTimeTime(st.ArrivalTime)
 		if err != nil {
-			return errors.Wrapf(err, "parsing arrival_time (row %d)", i+1)
+			return errInvalid("stop_times.txt", row, "arrival_time", st.ArrivalTime, err)
 		}
 
 		departureTime, err := parseStopTimeTime(st.DepartureTime)
 		if err != nil {
-			return errors.Wrapf(err, "parsing departure_time (row %d)", i+1)
+			return errInvalid("stop_times.txt", row, "departure_time", st.DepartureTime, err)
 		}
 
-		stopSeq[st.TripID] = append(stopSeq[st.TripID], int(st.StopSequence))
+		stopTime.Arrival = arrivalTime
+		stopTime.Departure = departureTime
+
+		ts := &group[len(group)-1]
+		ts.arrival = stopTime.ArrivalTime()
+		ts.departure = stopTime.DepartureTime()
 
 		if arrivalTime > maxArrival {
 			maxArrival = arrivalTime
@@ -99,52 +273,261 @@ func ParseStopTimes(
 			maxDeparture = departureTime
 		}
 
-		stopTime := model.StopTime{
-			TripID:       st.TripID,
-			StopID:       st.StopID,
-			Headsign:     st.Headsign,
-			StopSequence: st.StopSequence,
-			Arrival:      arrivalTime,
-			Departure:    departureTime,
-		}
-
 		err = writer.WriteStopTime(stopTime)
 		if err != nil {
-			return errors.Wrapf(err, "writing stop_time (row %d)", i+1)
+			return errStorage("stop_times.txt", row, fmt.Errorf("writing stop_time: %w", err))
+		}
+
+		return nil
+	}
+
+	// Interpolates and writes the blank times of the trip just
+	// read.
+	endTrip := func() error {
+		if len(untimed) == 0 && !(recurring && interpolated[current]) {
+			return nil
+		}
+		if recurring {
+			return &ParseError{
+				File:   "stop_times.txt",
+				Row:    currentRow,
+				Column: "trip_id",
+				Value:  current,
+				Code:   CodeInvalidValue,
+				Err:    fmt.Errorf("stop times with blank times must be grouped by trip"),
+			}
 		}
+		interpolated[current] = true
 
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].seq < group[j].seq
+		})
+		for i := 1; i < len(group); i++ {
+			if group[i].seq == group[i-1].seq {
+				return errDuplicateStopSequence(current, group[i].seq)
+			}
+		}
+		for _, edge := range []tripStop{group[0], group[len(group)-1]} {
+			if edge.timed {
+				continue
+			}
+			return &ParseError{
+				File:   "stop_times.txt",
+				Row:    edge.row,
+				Column: "arrival_time",
+				Code:   CodeMissingValue,
+				Err:    fmt.Errorf("first and last stop of trip_id '%s' must be timed", current),
+			}
+		}
+
+		for _, stopTime := range untimed {
+			t := interpolateStopTime(group, stopTime.StopSequence)
+			stopTime.Arrival = formatStopTimeTime(t)
+			stopTime.Departure = stopTime.Arrival
+
+			err := writer.WriteStopTime(stopTime)
+			if err != nil {
+				return errStorage("stop_times.txt", 0, fmt.Errorf("writing stop_time for trip_id '%s': %w", stopTime.TripID, err))
+			}
+		}
+
+		return nil
+	}
+
+	// Stop times of trips that were dropped, here or earlier, are
+	// dropped silently.
+	nextTrip := func(tripID string) error {
+		if current != "" && (drop == nil || !drop.trips[current]) {
+			err := endTrip()
+			if err != nil {
+				if drop == nil {
+					return err
+				}
+				drop.trip(current, err)
+			}
+		}
+		group = group[:0]
+		untimed = untimed[:0]
+		current = tripID
+		currentRow = row
+		recurring = len(seqs[tripID]) > 0
+		return nil
+	}
+
+	err := gocsv.UnmarshalToCallbackWithError(data, func(st *StopTimeCSV) error {
+		row += 1
+		if st.TripID != current {
+			if err := nextTrip(st.TripID); err != nil {
+				return err
+			}
+		}
+		if drop == nil {
+			return parseRow(st)
+		}
+
+		if drop.trips[st.TripID] {
+			return nil
+		}
+		if err := parseRow(st); err != nil {
+			drop.trip(st.TripID, err)
+		}
 		return nil
 	})
+	if err == nil {
+		err = nextTrip("")
+	}
 
 	if err != nil {
-		return "", "", errors.Wrap(err, "unmarshaling stop_times csv")
+		return "", "", errMalformed("stop_times.txt", err)
 	}
 
 	// Verify that stop_sequence is unique for each trip
-	for tripID, seq := range stopSeq {
-		seqSeen := map[int]bool{}
-		for _, i := range seq {
-			if seqSeen[i] {
-				return "", "", fmt.Errorf("duplicate stop_sequence %d for trip_id '%s'", i, tripID)
+	for tripID, tripSeqs := range seqs {
+		if drop != nil && drop.trips[tripID] {
+			continue
+		}
+		seqSeen := map[uint32]bool{}
+		for _, seq := range tripSeqs {
+			if seqSeen[seq] {
+				err := errDuplicateStopSequence(tripID, seq)
+				if drop == nil {
+					return "", "", err
+				}
+				drop.trip(tripID, err)
+				break
 			}
-			seqSeen[i] = true
+			seqSeen[seq] = true
 		}
 	}
 
-	sort.SliceStable(stopTimes, func(i, j int) bool {
-		cmp := strings.Compare(
-			stopTimes[i].TripID,
-			stopTimes[j].TripID,
-		)
+	return maxArrival, maxDeparture, nil
+}
+
+func errDuplicateStopSequence(tripID string, seq uint32) error {
+	return &ParseError{
+		File:   "stop_times.txt",
+		Column: "stop_sequence",
+		Value:  strconv.FormatUint(uint64(seq), 10),
+		Code:   CodeDuplicateKey,
+		Err:    fmt.Errorf("for trip_id '%s'", tripID),
+	}
+}
 
-		if cmp < 0 {
-			return true
+// Builds a flex stop time from a stop_times record with a pickup/drop
+// off window, or at a location or location group.
+func parseFlexStopTime(
+	row int,
+	st *StopTimeCSV,
+	locations map[string]bool,
+	locationGroups map[string]bool,
+) (*model.FlexStopTime, error) {
+	set := 0
+	for _, id := range []string{st.StopID, st.LocationGroupID, st.LocationID} {
+		if id != "" {
+			set++
 		}
-		if cmp == 0 {
-			return stopTimes[i].StopSequence < stopTimes[j].StopSequence
+	}
+	if set != 1 {
+		return nil, &ParseError{
+			File: "stop_times.txt",
+			Row:  row,
+			Code: CodeInvalidValue,
+			Err:  fmt.Errorf("exactly one of stop_id, location_group_id and location_id must be set"),
 		}
-		return false
+	}
+	if st.LocationGroupID != "" && !locationGroups[st.LocationGroupID] {
+		return nil, errUnknown("stop_times.txt", row, "location_group_id", st.LocationGroupID)
+	}
+	if st.LocationID != "" && !locations[st.LocationID] {
+		return nil, errUnknown("stop_times.txt", row, "location_id", st.LocationID)
+	}
+
+	notAllowed := fmt.Errorf("not allowed with pickup/drop off window")
+	if st.ArrivalTime != "" {
+		return nil, errInvalid("stop_times.txt", row, "arrival_time", st.ArrivalTime, notAllowed)
+	}
+	if st.DepartureTime != "" {
+		return nil, errInvalid("stop_times.txt", row, "departure_time", st.DepartureTime, notAllowed)
+	}
+	if st.StartWindow == "" {
+		return nil, errMissing("stop_times.txt", row, "start_pickup_drop_off_window")
+	}
+	if st.EndWindow == "" {
+		return nil, errMissing("stop_times.txt", row, "end_pickup_drop_off_window")
+	}
+	startWindow, err := parseStopTimeTime(st.StartWindow)
+	if err != nil {
+		return nil, errInvalid("stop_times.txt", row, "start_pickup_drop_off_window", st.StartWindow, err)
+	}
+	endWindow, err := parseStopTimeTime(st.EndWindow)
+	if err != nil {
+		return nil, errInvalid("stop_times.txt", row, "end_pickup_drop_off_window", st.EndWindow, err)
+	}
+	if startWindow > endWindow {
+		return nil, errInvalid("stop_times.txt", row, "end_pickup_drop_off_window", st.EndWindow, fmt.Errorf("before start_pickup_drop_off_window"))
+	}
+
+	// Regular pickup and drop off, and coordinating pickup
+	// with the driver, make no sense for on-demand service.
+	pickupType := model.PickupDropOffType(st.PickupType)
+	dropOffType := model.PickupDropOffType(st.DropOffType)
+	if pickupType == model.PickupDropOffRegular || pickupType == model.PickupDropOffCoordinateWithDriver {
+		return nil, errInvalid("stop_times.txt", row, "pickup_type", strconv.Itoa(int(st.PickupType)), notAllowed)
+	}
+	if dropOffType == model.PickupDropOffRegular {
+		return nil, errInvalid("stop_times.txt", row, "drop_off_type", strconv.Itoa(int(st.DropOffType)), notAllowed)
+	}
+	if st.ContinuousPickup != "" {
+		return nil, errInvalid("stop_times.txt", row, "continuous_pickup", st.ContinuousPickup, notAllowed)
+	}
+	if st.ContinuousDropOff != "" {
+		return nil, errInvalid("stop_times.txt", row, "continuous_drop_off", st.ContinuousDropOff, notAllowed)
+	}
+
+	return &model.FlexStopTime{
+		TripID:               st.TripID,
+		StopSequence:         st.StopSequence,
+		StopID:               st.StopID,
+		LocationGroupID:      st.LocationGroupID,
+		LocationID:           st.LocationID,
+		StartWindow:          startWindow,
+		EndWindow:            endWindow,
+		PickupType:           pickupType,
+		DropOffType:          dropOffType,
+		PickupBookingRuleID:  st.PickupBookingRuleID,
+		DropOffBookingRuleID: st.DropOffBookingRuleID,
+	}, nil
+}
+
+// Interpolates the time at stop seq of a trip, given the trip's stops
+// sorted by sequence. The first and last stops must be timed.
+func interpolateStopTime(ts []tripStop, seq uint32) time.Duration {
+	idx := sort.Search(len(ts), func(i int) bool {
+		return ts[i].seq >= seq
 	})
 
-	return maxArrival, maxDeparture, nil
+	prev := idx - 1
+	for !ts[prev].timed {
+		prev--
+	}
+	next := idx + 1
+	for !ts[next].timed {
+		next++
+	}
+
+	start := ts[prev].departure
+	end := ts[next].arrival
+
+	frac := float64(idx-prev) / float64(next-prev)
+	if ts[prev].hasDist && ts[idx].hasDist && ts[next].hasDist && ts[next].dist > ts[prev].dist {
+		frac = (ts[idx].dist - ts[prev].dist) / (ts[next].dist - ts[prev].dist)
+		frac = math.Max(0, math.Min(1, frac))
+	}
+
+	return start + time.Duration(frac*float64(end-start)).Round(time.Second)
+}
+
+func formatStopTimeTime(t time.Duration) string {
+	seconds := int(t / time.Second)
+	return fmt.Sprintf("%02d%02d%02d", seconds/3600, (seconds/60)%60, seconds%60)
 }
@@ -88,6 +88,207 @@ t,25:00:00,25:00:01,s,1`,
 			},
 		},
 
+		{
+			"pickup and drop off types",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,pickup_type,drop_off_type
+t,10:00:00,10:00:01,s1,1,0,1
+t,10:00:02,10:00:03,s2,2,2,3
+t,10:00:04,10:00:05,s3,3,1,0
+`,
+			map[string]bool{"t": true},
+			map[string]bool{"s1": true, "s2": true, "s3": true},
+			false,
+			[]model.StopTime{
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100000",
+					Departure:    "100001",
+					StopID:       "s1",
+					StopSequence: 1,
+					PickupType:   model.PickupDropOffRegular,
+					DropOffType:  model.PickupDropOffNone,
+				},
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100002",
+					Departure:    "100003",
+					StopID:       "s2",
+					StopSequence: 2,
+					PickupType:   model.PickupDropOffPhoneAgency,
+					DropOffType:  model.PickupDropOffCoordinateWithDriver,
+				},
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100004",
+					Departure:    "100005",
+					StopID:       "s3",
+					StopSequence: 3,
+					PickupType:   model.PickupDropOffNone,
+					DropOffType:  model.PickupDropOffRegular,
+				},
+			},
+		},
+
+		{
+			"interpolated by stop count",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,timepoint
+t,10:00:00,10:00:00,s1,1,1
+t,,,s2,2,0
+t,,,s3,3,0
+t,10:03:00,10:03:30,s4,4,1
+t,10:04:00,10:04:00,s5,5,0
+`,
+			map[string]bool{"t": true},
+			map[string]bool{"s1": true, "s2": true, "s3": true, "s4": true, "s5": true},
+			false,
+			[]model.StopTime{
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100000",
+					Departure:    "100000",
+					StopID:       "s1",
+					StopSequence: 1,
+				},
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100100",
+					Departure:    "100100",
+					StopID:       "s2",
+					StopSequence: 2,
+					Approximate:  true,
+				},
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100200",
+					Departure:    "100200",
+					StopID:       "s3",
+					StopSequence: 3,
+					Approximate:  true,
+				},
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100300",
+					Departure:    "100330",
+					StopID:       "s4",
+					StopSequence: 4,
+				},
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100400",
+					Departure:    "100400",
+					StopID:       "s5",
+					StopSequence: 5,
+					Approximate:  true,
+				},
+			},
+		},
+
+		{
+			"interpolated by shape_dist_traveled",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,shape_dist_traveled
+t,,,s2,20,1.0
+t,10:00:00,10:00:00,s1,10,0.0
+t,10:10:00,10:10:00,s3,30,10.0
+`,
+			map[string]bool{"t": true},
+			map[string]bool{"s1": true, "s2": true, "s3": true},
+			false,
+			[]model.StopTime{
+				model.StopTime{
+					TripID:               "t",
+					Arrival:              "100000",
+					Departure:            "100000",
+					StopID:               "s1",
+					StopSequence:         10,
+					HasShapeDistTraveled: true,
+				},
+				model.StopTime{
+					TripID:               "t",
+					Arrival:              "100100",
+					Departure:            "100100",
+					StopID:               "s2",
+					StopSequence:         20,
+					ShapeDistTraveled:    1,
+					HasShapeDistTraveled: true,
+					Approximate:          true,
+				},
+				model.StopTime{
+					TripID:               "t",
+					Arrival:              "101000",
+					Departure:            "101000",
+					StopID:               "s3",
+					StopSequence:         30,
+					ShapeDistTraveled:    10,
+					HasShapeDistTraveled: true,
+				},
+			},
+		},
+
+		{
+			"only one of arrival_time and departure_time",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence
+t,10:00:00,,s1,1
+t,,10:01:00,s2,2
+`,
+			map[string]bool{"t": true},
+			map[string]bool{"s1": true, "s2": true},
+			false,
+			[]model.StopTime{
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100000",
+					Departure:    "100000",
+					StopID:       "s1",
+					StopSequence: 1,
+				},
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100100",
+					Departure:    "100100",
+					StopID:       "s2",
+					StopSequence: 2,
+				},
+			},
+		},
+
+		{
+			"continuous pickup and drop off",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,continuous_pickup,continuous_drop_off
+t,10:00:00,10:00:00,s1,1,0,1
+t,10:01:00,10:01:00,s2,2,,3
+`,
+			map[string]bool{"t": true},
+			map[string]bool{"s1": true, "s2": true},
+			false,
+			[]model.StopTime{
+				model.StopTime{
+					TripID:               "t",
+					Arrival:              "100000",
+					Departure:            "100000",
+					StopID:               "s1",
+					StopSequence:         1,
+					ContinuousPickup:     model.ContinuousStoppingRegular,
+					HasContinuousPickup:  true,
+					ContinuousDropOff:    model.ContinuousStoppingNone,
+					HasContinuousDropOff: true,
+				},
+				model.StopTime{
+					TripID:               "t",
+					Arrival:              "100100",
+					Departure:            "100100",
+					StopID:               "s2",
+					StopSequence:         2,
+					ContinuousDropOff:    model.ContinuousStoppingCoordinateWithDriver,
+					HasContinuousDropOff: true,
+				},
+			},
+		},
+
 		{
 			"missing trip_id",
 			`
@@ -171,6 +372,164 @@ t,10:00:00,10:00:derp,s,1`,
 			true,
 			nil,
 		},
+
+		{
+			"invalid pickup_type",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,pickup_type
+t,10:00:00,10:00:01,s,1,4`,
+			map[string]bool{"t": true},
+			map[string]bool{"s": true},
+			true,
+			nil,
+		},
+
+		{
+			"invalid drop_off_type",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,drop_off_type
+t,10:00:00,10:00:01,s,1,-1`,
+			map[string]bool{"t": true},
+			map[string]bool{"s": true},
+			true,
+			nil,
+		},
+
+		{
+			"blank times on first stop",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence
+t,,,s1,1
+t,10:01:00,10:01:00,s2,2`,
+			map[string]bool{"t": true},
+			map[string]bool{"s1": true, "s2": true},
+			true,
+			nil,
+		},
+
+		{
+			"blank times on last stop",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence
+t,10:00:00,10:00:00,s1,1
+t,,,s2,2`,
+			map[string]bool{"t": true},
+			map[string]bool{"s1": true, "s2": true},
+			true,
+			nil,
+		},
+
+		{
+			"blank times on timepoint",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,timepoint
+t,10:00:00,10:00:00,s1,1,1
+t,,,s2,2,1
+t,10:02:00,10:02:00,s3,3,1`,
+			map[string]bool{"t": true},
+			map[string]bool{"s1": true, "s2": true, "s3": true},
+			true,
+			nil,
+		},
+
+		{
+			"blank times on trip not grouped",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence
+t,10:00:00,10:00:00,s1,1
+t,,,s2,2
+u,10:00:00,10:00:00,s1,1
+t,10:02:00,10:02:00,s3,3`,
+			map[string]bool{"t": true, "u": true},
+			map[string]bool{"s1": true, "s2": true, "s3": true},
+			true,
+			nil,
+		},
+
+		{
+			"timed trip not grouped",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence
+t,10:00:00,10:00:00,s1,1
+u,,,s2,2
+u,10:00:00,10:00:00,s1,1
+u,10:02:00,10:02:00,s3,3
+t,10:02:00,10:02:00,s3,3`,
+			map[string]bool{"t": true, "u": true},
+			map[string]bool{"s1": true, "s2": true, "s3": true},
+			false,
+			[]model.StopTime{
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100000",
+					Departure:    "100000",
+					StopID:       "s1",
+					StopSequence: 1,
+				},
+				model.StopTime{
+					TripID:       "t",
+					Arrival:      "100200",
+					Departure:    "100200",
+					StopID:       "s3",
+					StopSequence: 3,
+				},
+				model.StopTime{
+					TripID:       "u",
+					Arrival:      "100000",
+					Departure:    "100000",
+					StopID:       "s1",
+					StopSequence: 1,
+				},
+				model.StopTime{
+					TripID:       "u",
+					Arrival:      "100100",
+					Departure:    "100100",
+					StopID:       "s2",
+					StopSequence: 2,
+					Approximate:  true,
+				},
+				model.StopTime{
+					TripID:       "u",
+					Arrival:      "100200",
+					Departure:    "100200",
+					StopID:       "s3",
+					StopSequence: 3,
+				},
+			},
+		},
+
+		{
+			"invalid timepoint",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,timepoint
+t,10:00:00,10:00:00,s,1,2`,
+			map[string]bool{"t": true},
+			map[string]bool{"s": true},
+			true,
+			nil,
+		},
+
+		{
+			"invalid shape_dist_traveled",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,shape_dist_traveled
+t,10:00:00,10:00:00,s,1,-1`,
+			map[string]bool{"t": true},
+			map[string]bool{"s": true},
+			true,
+			nil,
+		},
+
+		{
+			"invalid continuous_pickup",
+			`
+trip_id,arrival_time,departure_time,stop_id,stop_sequence,continuous_pickup
+t,10:00:00,10:00:01,s,1,5`,
+			map[string]bool{"t": true},
+			map[string]bool{"s": true},
+			true,
+			nil,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			s, err := storage.NewSQLiteStorage()
@@ -184,6 +543,9 @@ t,10:00:00,10:00:derp,s,1`,
 				bytes.NewBufferString(tc.content),
 				tc.trips,
 				tc.stops,
+				map[string]bool{},
+				map[string]bool{},
+				map[string]bool{},
 			)
 			if tc.err {
 				assert.Error(t, err)
@@ -218,3 +580,208 @@ t,10:00:00,10:00:derp,s,1`,
 		})
 	}
 }
+
+func TestParseFlexStopTimes(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		content       string
+		err           bool
+		stopTimes     []model.StopTime
+		flexStopTimes []model.FlexStopTime
+	}{
+		{
+			"location and location group",
+			`
+trip_id,stop_id,location_id,location_group_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type,pickup_booking_rule_id,drop_off_booking_rule_id
+t,,zone,,1,08:00:00,18:00:00,2,1,br,
+t,,,group,2,08:00:00,18:00:00,1,2,,br`,
+			false,
+			[]model.StopTime{},
+			[]model.FlexStopTime{
+				{
+					TripID:              "t",
+					StopSequence:        1,
+					LocationID:          "zone",
+					StartWindow:         "080000",
+					EndWindow:           "180000",
+					PickupType:          model.PickupDropOffPhoneAgency,
+					DropOffType:         model.PickupDropOffNone,
+					PickupBookingRuleID: "br",
+				},
+				{
+					TripID:               "t",
+					StopSequence:         2,
+					LocationGroupID:      "group",
+					StartWindow:          "080000",
+					EndWindow:            "180000",
+					PickupType:           model.PickupDropOffNone,
+					DropOffType:          model.PickupDropOffPhoneAgency,
+					DropOffBookingRuleID: "br",
+				},
+			},
+		},
+
+		{
+			"mixed with regular and untimed stop times",
+			`
+trip_id,stop_id,location_id,stop_sequence,arrival_time,departure_time,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type
+t,s,,1,10:00:00,10:00:00,,,0,0
+t,s,,2,,,,,0,0
+t,s,,3,10:10:00,10:10:00,,,0,0
+t,,zone,4,,,10:10:00,11:00:00,2,2
+t,s,,5,,,10:15:00,11:00:00,2,2`,
+			false,
+			[]model.StopTime{
+				{TripID: "t", StopID: "s", StopSequence: 1, Arrival: "100000", Departure: "100000"},
+				{TripID: "t", StopID: "s", StopSequence: 2, Arrival: "100500", Departure: "100500", Approximate: true},
+				{TripID: "t", StopID: "s", StopSequence: 3, Arrival: "101000", Departure: "101000"},
+			},
+			[]model.FlexStopTime{
+				{
+					TripID:       "t",
+					StopSequence: 4,
+					LocationID:   "zone",
+					StartWindow:  "101000",
+					EndWindow:    "110000",
+					PickupType:   model.PickupDropOffPhoneAgency,
+					DropOffType:  model.PickupDropOffPhoneAgency,
+				},
+				{
+					TripID:       "t",
+					StopSequence: 5,
+					StopID:       "s",
+					StartWindow:  "101500",
+					EndWindow:    "110000",
+					PickupType:   model.PickupDropOffPhoneAgency,
+					DropOffType:  model.PickupDropOffPhoneAgency,
+				},
+			},
+		},
+
+		{
+			"unknown location",
+			`
+trip_id,location_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type
+t,nowhere,1,08:00:00,18:00:00,2,2`,
+			true, nil, nil,
+		},
+
+		{
+			"unknown location group",
+			`
+trip_id,location_group_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type
+t,nogroup,1,08:00:00,18:00:00,2,2`,
+			true, nil, nil,
+		},
+
+		{
+			"both stop and location",
+			`
+trip_id,stop_id,location_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type
+t,s,zone,1,08:00:00,18:00:00,2,2`,
+			true, nil, nil,
+		},
+
+		{
+			"location without window",
+			`
+trip_id,location_id,stop_sequence,pickup_type,drop_off_type
+t,zone,1,2,2`,
+			true, nil, nil,
+		},
+
+		{
+			"window with arrival_time",
+			`
+trip_id,location_id,stop_sequence,arrival_time,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type
+t,zone,1,08:00:00,08:00:00,18:00:00,2,2`,
+			true, nil, nil,
+		},
+
+		{
+			"window ends before it starts",
+			`
+trip_id,location_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type
+t,zone,1,18:00:00,08:00:00,2,2`,
+			true, nil, nil,
+		},
+
+		{
+			"regular pickup in window",
+			`
+trip_id,location_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type
+t,zone,1,08:00:00,18:00:00,0,2`,
+			true, nil, nil,
+		},
+
+		{
+			"regular drop off in window",
+			`
+trip_id,location_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type
+t,zone,1,08:00:00,18:00:00,2,0`,
+			true, nil, nil,
+		},
+
+		{
+			"unknown booking rule",
+			`
+trip_id,location_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type,pickup_booking_rule_id
+t,zone,1,08:00:00,18:00:00,2,2,nobr`,
+			true, nil, nil,
+		},
+
+		{
+			"duplicate stop_sequence across regular and flex",
+			`
+trip_id,stop_id,location_id,stop_sequence,arrival_time,departure_time,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type
+t,s,,1,10:00:00,10:00:00,,,0,0
+t,,zone,1,,,10:10:00,11:00:00,2,2`,
+			true, nil, nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			// Flex stop times are read back with their trip
+			// and route, so those must exist.
+			require.NoError(t, writer.WriteRoute(model.Route{ID: "r"}))
+			require.NoError(t, writer.BeginTrips())
+			require.NoError(t, writer.WriteTrip(model.Trip{ID: "t", RouteID: "r"}))
+			require.NoError(t, writer.EndTrips())
+
+			require.NoError(t, writer.BeginStopTimes())
+			_, _, err = ParseStopTimes(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"t": true},
+				map[string]bool{"s": true},
+				map[string]bool{"zone": true},
+				map[string]bool{"group": true},
+				map[string]bool{"br": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, writer.EndStopTimes())
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			stopTimes, err := reader.StopTimes()
+			require.NoError(t, err)
+			assert.Equal(t, tc.stopTimes, stopTimes)
+
+			events, err := reader.FlexStopTimes(storage.FlexStopTimeFilter{})
+			require.NoError(t, err)
+			flexStopTimes := []model.FlexStopTime{}
+			for _, e := range events {
+				flexStopTimes = append(flexStopTimes, e.StopTime)
+			}
+			assert.Equal(t, tc.flexStopTimes, flexStopTimes)
+		})
+	}
+}
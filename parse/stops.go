@@ -3,46 +3,60 @@ package parse
 import (
 	"fmt"
 	"io"
-
-	"github.com/gocarina/gocsv"
+	"strconv"
+	"time"
 
 	"tidbyt.dev/gtfs/model"
 	"tidbyt.dev/gtfs/storage"
 )
 
 type StopCSV struct {
-	ID   string  `csv:"stop_id"`
-	Code string  `csv:"stop_code"`
-	Name string  `csv:"stop_name"`
-	Desc string  `csv:"stop_desc"`
-	Lat  float64 `csv:"stop_lat"`
-	Lon  float64 `csv:"stop_lon"`
-	// ZoneID        string  `csv:"zone_id"`
-	URL           string `csv:"stop_url"`
-	LocationType  int8   `csv:"location_type"`
-	ParentStation string `csv:"parent_station"`
-	// Timezone      string  `csv:"stop_timezone"`
-	// WheelchairBoarding string `csv:"wheelchair_boarding"`
-	// LevelID       string  `csv:"level_id"`
-	PlatformCode string `csv:"platform_code"`
+	ID                 string  `csv:"stop_id"`
+	Code               string  `csv:"stop_code"`
+	Name               string  `csv:"stop_name"`
+	Desc               string  `csv:"stop_desc"`
+	Lat                float64 `csv:"stop_lat"`
+	Lon                float64 `csv:"stop_lon"`
+	ZoneID             string  `csv:"zone_id"`
+	URL                string  `csv:"stop_url"`
+	LocationType       int8    `csv:"location_type"`
+	ParentStation      string  `csv:"parent_station"`
+	Timezone           string  `csv:"stop_timezone"`
+	WheelchairBoarding int8    `csv:"wheelchair_boarding"`
+	LevelID            string  `csv:"level_id"`
+	PlatformCode       string  `csv:"platform_code"`
+
+	Extras map[string]string `csv:"-"`
+}
+
+// Parses stops.txt. Returns the set of stop IDs, and the set of zone
+// IDs referenced by stops. Level IDs must be among levels.
+func ParseStops(writer storage.FeedWriter, data io.Reader, levels map[string]bool) (map[string]bool, map[string]bool, error) {
+	return parseStops(writer, data, levels, nil, false)
 }
 
-func ParseStops(writer storage.FeedWriter, data io.Reader) (map[string]bool, error) {
+// Parses stops.txt. If drop is non-nil, bad rows are dropped rather
+// than failing the parse, along with any stops within dropped
+// stations, and left out of the returned stop IDs. If extras is set,
+// values in extension columns are captured.
+func parseStops(writer storage.FeedWriter, data io.Reader, levels map[string]bool, drop *dropped, extras bool) (map[string]bool, map[string]bool, error) {
 	stopCsv := []*StopCSV{}
-	if err := gocsv.Unmarshal(data, &stopCsv); err != nil {
-		return nil, fmt.Errorf("unmarshaling stops csv: %w", err)
+	if err := unmarshalExtras(data, &stopCsv, "stops.txt", extras); err != nil {
+		return nil, nil, errMalformed("stops.txt", err)
 	}
 
+	// Stops are only written once parent_station references
+	// have been verified.
 	stopIDs := map[string]bool{}
-	parentRef := map[string]string{}
-	for _, st := range stopCsv {
+	stops := []model.Stop{}
+	rows := []int{}
+	parseRow := func(row int, st *StopCSV) error {
 		if stopIDs[st.ID] {
-			return nil, fmt.Errorf("repeated stop_id '%s'", st.ID)
+			return errDuplicate("stops.txt", row, "stop_id", st.ID)
 		}
-		stopIDs[st.ID] = true
 
 		if st.ID == "" {
-			return nil, fmt.Errorf("empty stop_id")
+			return errMissing("stops.txt", row, "stop_id")
 		}
 
 		locationType := model.LocationType(st.LocationType)
@@ -52,19 +66,36 @@ func ParseStops(writer storage.FeedWriter, data io.Reader) (map[string]bool, err
 			// generic nodes (location_type=3) or boarding areas
 			// (location_type=4)" and otherwise required
 			if st.Name == "" {
-				return nil, fmt.Errorf("empty stop_name for stop_id '%s'", st.ID)
+				return errMissing("stops.txt", row, "stop_name")
 			}
 
 			// stop_lat and stop_lon are "[o]ptional for
 			// locations which are generic nodes
 			// (location_type=3) or boarding areas
 			// (location_type=4)" and otherwise required.
-			if st.Lat == 0 || st.Lon == 0 {
-				return nil, fmt.Errorf("empty stop_lat or stop_lon for stop_id '%s'", st.ID)
+			if st.Lat == 0 {
+				return errMissing("stops.txt", row, "stop_lat")
+			}
+			if st.Lon == 0 {
+				return errMissing("stops.txt", row, "stop_lon")
 			}
 		}
 
-		stop := model.Stop{
+		if st.LevelID != "" && !levels[st.LevelID] {
+			return errUnknown("stops.txt", row, "level_id", st.LevelID)
+		}
+
+		if st.Timezone != "" {
+			if _, err := time.LoadLocation(st.Timezone); err != nil {
+				return errInvalid("stops.txt", row, "stop_timezone", st.Timezone, err)
+			}
+		}
+
+		if st.WheelchairBoarding < 0 || st.WheelchairBoarding > 2 {
+			return errInvalid("stops.txt", row, "wheelchair_boarding", strconv.Itoa(int(st.WheelchairBoarding)), nil)
+		}
+
+		stops = append(stops, model.Stop{
 			ID:            st.ID,
 			Code:          st.Code,
 			Name:          st.Name,
@@ -75,24 +106,58 @@ func ParseStops(writer storage.FeedWriter, data io.Reader) (map[string]bool, err
 			LocationType:  locationType,
 			ParentStation: st.ParentStation,
 			PlatformCode:  st.PlatformCode,
-		}
+			ZoneID:        st.ZoneID,
+			LevelID:       st.LevelID,
+			Timezone:      st.Timezone,
 
-		if st.ParentStation != "" {
-			parentRef[st.ID] = st.ParentStation
+			WheelchairBoarding: model.WheelchairAccessibility(st.WheelchairBoarding),
+			Extras:             st.Extras,
+		})
+		rows = append(rows, row)
+		stopIDs[st.ID] = true
+
+		return nil
+	}
+
+	for i, st := range stopCsv {
+		if err := drop.skip(parseRow(i+1, st)); err != nil {
+			return nil, nil, err
 		}
+	}
 
-		err := writer.WriteStop(stop)
-		if err != nil {
-			return nil, fmt.Errorf("writing stop '%s': %w", st.ID, err)
+	// verify stops referenced by parent_station exist. Dropping
+	// a station drops the stops within it, so repeat until no
+	// more are dropped.
+	for found := true; found; {
+		found = false
+		for i, stop := range stops {
+			if !stopIDs[stop.ID] || stop.ParentStation == "" || stopIDs[stop.ParentStation] {
+				continue
+			}
+			err := drop.skip(errUnknown("stops.txt", rows[i], "parent_station", stop.ParentStation))
+			if err != nil {
+				return nil, nil, err
+			}
+			delete(stopIDs, stop.ID)
+			found = true
 		}
 	}
 
-	// verify stops referenced by parent_station exist
-	for stopID, parentID := range parentRef {
-		if !stopIDs[parentID] {
-			return nil, fmt.Errorf("stop '%s' references unknown parent_station '%s'", stopID, parentID)
+	zoneIDs := map[string]bool{}
+	for i, stop := range stops {
+		if !stopIDs[stop.ID] {
+			continue
+		}
+
+		if stop.ZoneID != "" {
+			zoneIDs[stop.ZoneID] = true
+		}
+
+		err := writer.WriteStop(stop)
+		if err != nil {
+			return nil, nil, errStorage("stops.txt", rows[i], fmt.Errorf("writing stop: %w", err))
 		}
 	}
 
-	return stopIDs, nil
+	return stopIDs, zoneIDs, nil
 }
@@ -36,12 +36,12 @@ s,name,1.1,2.2`,
 		{
 			"maximal_stop",
 			`
-location_type,stop_id,stop_code,stop_name,stop_desc,stop_lat,stop_lon,stop_url,parent_station,platform_code
-0,s,code_s,Stop,desc_s,1.1,2.2,url_s,ps,platform
-1,ps,code_ps,Station,desc_ps,3.3,4.4,url_ps,,
-2,e,code_e,Entrance,desc_e,5.5,6.6,url_se,ps,
-3,g,code_g,Generic,desc_g,,,url_g,ps,
-4,b,code_b,Boarding,desc_b,,,url_b,ps,
+location_type,stop_id,stop_code,stop_name,stop_desc,stop_lat,stop_lon,stop_url,parent_station,platform_code,zone_id,level_id,stop_timezone,wheelchair_boarding
+0,s,code_s,Stop,desc_s,1.1,2.2,url_s,ps,platform,z1,l1,,2
+1,ps,code_ps,Station,desc_ps,3.3,4.4,url_ps,,,z2,,America/Chicago,1
+2,e,code_e,Entrance,desc_e,5.5,6.6,url_se,ps,,,l1,,
+3,g,code_g,Generic,desc_g,,,url_g,ps,,,,,
+4,b,code_b,Boarding,desc_b,,,url_b,ps,,,,,
 `,
 			[]model.Stop{
 				model.Stop{
@@ -63,6 +63,7 @@ location_type,stop_id,stop_code,stop_name,stop_desc,stop_lat,stop_lon,stop_url,p
 					URL:           "url_se",
 					ParentStation: "ps",
 					LocationType:  model.LocationTypeEntranceExit,
+					LevelID:       "l1",
 				},
 				model.Stop{
 					ID:            "g",
@@ -82,6 +83,10 @@ location_type,stop_id,stop_code,stop_name,stop_desc,stop_lat,stop_lon,stop_url,p
 					Lon:          4.4,
 					URL:          "url_ps",
 					LocationType: model.LocationTypeStation,
+					ZoneID:       "z2",
+					Timezone:     "America/Chicago",
+
+					WheelchairBoarding: model.WheelchairAccessible,
 				},
 				model.Stop{
 					ID:            "s",
@@ -94,11 +99,42 @@ location_type,stop_id,stop_code,stop_name,stop_desc,stop_lat,stop_lon,stop_url,p
 					ParentStation: "ps",
 					PlatformCode:  "platform",
 					LocationType:  model.LocationTypeStop,
+					ZoneID:        "z1",
+					LevelID:       "l1",
+
+					WheelchairBoarding: model.WheelchairInaccessible,
 				},
 			},
 			false,
 		},
 
+		{
+			"unknown level_id",
+			`
+stop_id,stop_name,stop_lat,stop_lon,level_id
+s,name,1.1,2.2,l2`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid wheelchair_boarding",
+			`
+stop_id,stop_name,stop_lat,stop_lon,wheelchair_boarding
+s,name,1.1,2.2,3`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid stop_timezone",
+			`
+stop_id,stop_name,stop_lat,stop_lon,stop_timezone
+s,name,1.1,2.2,America/Gotham`,
+			nil,
+			true,
+		},
+
 		{
 			"blank stop_id",
 			`
@@ -214,7 +250,7 @@ s,1.1,2.2,1`,
 			writer, err := s.GetWriter("test")
 			require.NoError(t, err)
 
-			stopIDs, err := ParseStops(writer, bytes.NewBufferString(tc.content))
+			stopIDs, zoneIDs, err := ParseStops(writer, bytes.NewBufferString(tc.content), map[string]bool{"l1": true})
 			if tc.err {
 				assert.Error(t, err)
 				return
@@ -233,6 +269,9 @@ s,1.1,2.2,1`,
 			assert.Equal(t, tc.stops, stops)
 			for _, s := range stops {
 				assert.True(t, stopIDs[s.ID])
+				if s.ZoneID != "" {
+					assert.True(t, zoneIDs[s.ZoneID])
+				}
 			}
 		})
 	}
@@ -0,0 +1,116 @@
+package parse
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type TransferCSV struct {
+	FromStopID      string `csv:"from_stop_id"`
+	ToStopID        string `csv:"to_stop_id"`
+	FromRouteID     string `csv:"from_route_id"`
+	ToRouteID       string `csv:"to_route_id"`
+	FromTripID      string `csv:"from_trip_id"`
+	ToTripID        string `csv:"to_trip_id"`
+	TransferType    int8   `csv:"transfer_type"`
+	MinTransferTime int    `csv:"min_transfer_time"`
+}
+
+func ParseTransfers(
+	writer storage.FeedWriter,
+	data io.Reader,
+	stops map[string]bool,
+	routes map[string]bool,
+	trips map[string]bool,
+) error {
+	return parseTransfers(writer, data, stops, routes, trips, nil)
+}
+
+// Parses transfers.txt. If drop is non-nil, bad rows are dropped
+// rather than failing the parse.
+func parseTransfers(
+	writer storage.FeedWriter,
+	data io.Reader,
+	stops map[string]bool,
+	routes map[string]bool,
+	trips map[string]bool,
+	drop *dropped,
+) error {
+	transferCsv := []*TransferCSV{}
+	if err := gocsv.Unmarshal(data, &transferCsv); err != nil {
+		return errMalformed("transfers.txt", err)
+	}
+
+	parseRow := func(row int, t *TransferCSV) error {
+		transferType := model.TransferType(t.TransferType)
+		if transferType < model.TransferTypeRecommended || transferType > model.TransferTypeInSeatNotAllowed {
+			return errInvalid("transfers.txt", row, "transfer_type", strconv.Itoa(int(t.TransferType)), nil)
+		}
+
+		// In-seat transfers are between trips, and may leave
+		// out the stops. All other types require both stops.
+		if transferType == model.TransferTypeInSeat || transferType == model.TransferTypeInSeatNotAllowed {
+			if t.FromTripID == "" {
+				return errMissing("transfers.txt", row, "from_trip_id")
+			}
+			if t.ToTripID == "" {
+				return errMissing("transfers.txt", row, "to_trip_id")
+			}
+		} else if t.FromStopID == "" {
+			return errMissing("transfers.txt", row, "from_stop_id")
+		} else if t.ToStopID == "" {
+			return errMissing("transfers.txt", row, "to_stop_id")
+		}
+
+		for _, ref := range []struct {
+			column string
+			id     string
+			known  map[string]bool
+		}{
+			{"from_stop_id", t.FromStopID, stops},
+			{"to_stop_id", t.ToStopID, stops},
+			{"from_route_id", t.FromRouteID, routes},
+			{"to_route_id", t.ToRouteID, routes},
+			{"from_trip_id", t.FromTripID, trips},
+			{"to_trip_id", t.ToTripID, trips},
+		} {
+			if ref.id != "" && !ref.known[ref.id] {
+				return errUnknown("transfers.txt", row, ref.column, ref.id)
+			}
+		}
+
+		if t.MinTransferTime < 0 {
+			return errInvalid("transfers.txt", row, "min_transfer_time", strconv.Itoa(t.MinTransferTime), nil)
+		}
+
+		err := writer.WriteTransfer(model.Transfer{
+			FromStopID:      t.FromStopID,
+			ToStopID:        t.ToStopID,
+			FromRouteID:     t.FromRouteID,
+			ToRouteID:       t.ToRouteID,
+			FromTripID:      t.FromTripID,
+			ToTripID:        t.ToTripID,
+			Type:            transferType,
+			MinTransferTime: uint32(t.MinTransferTime),
+		})
+		if err != nil {
+			return errStorage("transfers.txt", row, fmt.Errorf("writing transfer: %w", err))
+		}
+
+		return nil
+	}
+
+	for i, t := range transferCsv {
+		if err := drop.skip(parseRow(i+1, t)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseTransfers(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		content   string
+		transfers []model.Transfer
+		err       bool
+	}{
+		{
+			"minimal",
+			`
+from_stop_id,to_stop_id,transfer_type
+s1,s2,0`,
+			[]model.Transfer{
+				{FromStopID: "s1", ToStopID: "s2", Type: model.TransferTypeRecommended},
+			},
+			false,
+		},
+
+		{
+			"all the fields",
+			`
+from_stop_id,to_stop_id,from_route_id,to_route_id,from_trip_id,to_trip_id,transfer_type,min_transfer_time
+s1,s2,r1,r2,,,2,180
+s2,s1,,,t2,t1,1,
+s1,s1,,,,,3,`,
+			[]model.Transfer{
+				{FromStopID: "s1", ToStopID: "s1", Type: model.TransferTypeNotPossible},
+				{FromStopID: "s1", ToStopID: "s2", FromRouteID: "r1", ToRouteID: "r2", Type: model.TransferTypeMinTime, MinTransferTime: 180},
+				{FromStopID: "s2", ToStopID: "s1", FromTripID: "t2", ToTripID: "t1", Type: model.TransferTypeTimed},
+			},
+			false,
+		},
+
+		{
+			"in-seat transfers without stops",
+			`
+from_stop_id,to_stop_id,from_trip_id,to_trip_id,transfer_type
+,,t1,t2,4
+s2,s2,t2,t1,5`,
+			[]model.Transfer{
+				{FromTripID: "t1", ToTripID: "t2", Type: model.TransferTypeInSeat},
+				{FromStopID: "s2", ToStopID: "s2", FromTripID: "t2", ToTripID: "t1", Type: model.TransferTypeInSeatNotAllowed},
+			},
+			false,
+		},
+
+		{
+			"in-seat transfer without trips",
+			`
+from_stop_id,to_stop_id,from_trip_id,to_trip_id,transfer_type
+s1,s2,t1,,4`,
+			nil,
+			true,
+		},
+
+		{
+			"missing stop",
+			`
+from_stop_id,to_stop_id,transfer_type
+s1,,0`,
+			nil,
+			true,
+		},
+
+		{
+			"unknown stop",
+			`
+from_stop_id,to_stop_id,transfer_type
+s1,s3,0`,
+			nil,
+			true,
+		},
+
+		{
+			"unknown route",
+			`
+from_stop_id,to_stop_id,from_route_id,transfer_type
+s1,s2,r3,0`,
+			nil,
+			true,
+		},
+
+		{
+			"unknown trip",
+			`
+from_stop_id,to_stop_id,from_trip_id,to_trip_id,transfer_type
+s1,s2,t1,t3,1`,
+			nil,
+			true,
+		},
+
+		{
+			"invalid transfer_type",
+			`
+from_stop_id,to_stop_id,transfer_type
+s1,s2,6`,
+			nil,
+			true,
+		},
+
+		{
+			"negative min_transfer_time",
+			`
+from_stop_id,to_stop_id,transfer_type,min_transfer_time
+s1,s2,2,-60`,
+			nil,
+			true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			err = ParseTransfers(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"s1": true, "s2": true},
+				map[string]bool{"r1": true, "r2": true},
+				map[string]bool{"t1": true, "t2": true},
+			)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			transfers, err := reader.Transfers(storage.TransferFilter{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.transfers, transfers)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package parse
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gocarina/gocsv"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+type TranslationCSV struct {
+	TableName   string `csv:"table_name"`
+	FieldName   string `csv:"field_name"`
+	Language    string `csv:"language"`
+	Translation string `csv:"translation"`
+	RecordID    string `csv:"record_id"`
+	RecordSubID string `csv:"record_sub_id"`
+	FieldValue  string `csv:"field_value"`
+}
+
+var translatableTables = map[string]bool{
+	"agency":       true,
+	"stops":        true,
+	"routes":       true,
+	"trips":        true,
+	"stop_times":   true,
+	"pathways":     true,
+	"levels":       true,
+	"feed_info":    true,
+	"attributions": true,
+}
+
+// Reports whether a CSV header line holds the given column.
+func hasColumn(header string, column string) bool {
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(header, "\ufeff")))
+	r.LazyQuotes = true
+	columns, err := r.Read()
+	if err != nil {
+		return false
+	}
+	for _, c := range columns {
+		if strings.TrimSpace(c) == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Reads the header line of a CSV file, skipping blank lines. Returns
+// it along with a reader of the whole file, header included.
+func readHeader(data io.Reader) (string, io.Reader) {
+	br := bufio.NewReader(data)
+	header := ""
+	for strings.TrimSpace(header) == "" {
+		line, err := br.ReadString('\n')
+		header += line
+		if err != nil {
+			break
+		}
+	}
+	return header, io.MultiReader(strings.NewReader(header), br)
+}
+
+// Parses translations.txt.
+//
+// Record IDs referencing agencies, stops, routes and trips are
+// checked against the provided sets. As translations are optional,
+// bad rows are skipped rather than failing the parse, and files in
+// the legacy Google format (trans_id, lang, translation) are
+// skipped entirely. Only storage errors are returned.
+func ParseTranslations(
+	writer storage.FeedWriter,
+	data io.Reader,
+	agencies map[string]bool,
+	stops map[string]bool,
+	routes map[string]bool,
+	trips map[string]bool,
+) error {
+	return parseTranslations(writer, data, agencies, stops, routes, trips, newDropped())
+}
+
+// Like ParseTranslations, with skipped rows recorded in drop.
+func parseTranslations(
+	writer storage.FeedWriter,
+	data io.Reader,
+	agencies map[string]bool,
+	stops map[string]bool,
+	routes map[string]bool,
+	trips map[string]bool,
+	drop *dropped,
+) error {
+	// The legacy format has no table_name column.
+	header, data := readHeader(data)
+	if !hasColumn(header, "table_name") {
+		return nil
+	}
+
+	translationCsv := []*TranslationCSV{}
+	err := gocsv.Unmarshal(data, &translationCsv)
+	if err != nil {
+		drop.file(errMalformed("translations.txt", err))
+		return nil
+	}
+
+	for i, t := range translationCsv {
+		err := drop.skip(parseTranslation(writer, i+1, t, agencies, stops, routes, trips))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseTranslation(
+	writer storage.FeedWriter,
+	row int,
+	t *TranslationCSV,
+	agencies map[string]bool,
+	stops map[string]bool,
+	routes map[string]bool,
+	trips map[string]bool,
+) error {
+	if !translatableTables[t.TableName] {
+		return errInvalid("translations.txt", row, "table_name", t.TableName, nil)
+	}
+	if t.FieldName == "" {
+		return errMissing("translations.txt", row, "field_name")
+	}
+	if t.Language == "" {
+		return errMissing("translations.txt", row, "language")
+	}
+	if t.Translation == "" {
+		return errMissing("translations.txt", row, "translation")
+	}
+
+	if t.TableName == "feed_info" {
+		notAllowed := fmt.Errorf("not allowed for table_name feed_info")
+		if t.RecordID != "" {
+			return errInvalid("translations.txt", row, "record_id", t.RecordID, notAllowed)
+		}
+		if t.RecordSubID != "" {
+			return errInvalid("translations.txt", row, "record_sub_id", t.RecordSubID, notAllowed)
+		}
+		if t.FieldValue != "" {
+			return errInvalid("translations.txt", row, "field_value", t.FieldValue, notAllowed)
+		}
+	} else if t.RecordID != "" {
+		if t.FieldValue != "" {
+			return errInvalid("translations.txt", row, "field_value", t.FieldValue, fmt.Errorf("not allowed with record_id"))
+		}
+
+		var known map[string]bool
+		switch t.TableName {
+		case "agency":
+			known = agencies
+		case "stops":
+			known = stops
+		case "routes":
+			known = routes
+		case "trips", "stop_times":
+			known = trips
+		}
+		if known != nil && !known[t.RecordID] {
+			return &ParseError{
+				File:   "translations.txt",
+				Row:    row,
+				Column: "record_id",
+				Value:  t.RecordID,
+				Code:   CodeUnknownReference,
+				Err:    fmt.Errorf("in table_name '%s'", t.TableName),
+			}
+		}
+
+		if t.TableName == "stop_times" {
+			// record_sub_id is a stop_sequence
+			seq, err := strconv.ParseUint(t.RecordSubID, 10, 32)
+			if err != nil {
+				return errInvalid("translations.txt", row, "record_sub_id", t.RecordSubID, nil)
+			}
+			t.RecordSubID = strconv.FormatUint(seq, 10)
+		} else if t.RecordSubID != "" {
+			return errInvalid("translations.txt", row, "record_sub_id", t.RecordSubID, fmt.Errorf("not allowed for table_name %s", t.TableName))
+		}
+	} else {
+		if t.FieldValue == "" {
+			return &ParseError{
+				File:   "translations.txt",
+				Row:    row,
+				Column: "field_value",
+				Code:   CodeMissingValue,
+				Err:    fmt.Errorf("record_id is also missing"),
+			}
+		}
+		if t.RecordSubID != "" {
+			return errInvalid("translations.txt", row, "record_sub_id", t.RecordSubID, fmt.Errorf("not allowed without record_id"))
+		}
+	}
+
+	err := writer.WriteTranslation(model.Translation{
+		TableName:   t.TableName,
+		FieldName:   t.FieldName,
+		Language:    t.Language,
+		Translation: t.Translation,
+		RecordID:    t.RecordID,
+		RecordSubID: t.RecordSubID,
+		FieldValue:  t.FieldValue,
+	})
+	if err != nil {
+		return errStorage("translations.txt", row, fmt.Errorf("writing translation: %w", err))
+	}
+
+	return nil
+}
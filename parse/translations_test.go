@@ -0,0 +1,207 @@
+package parse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+func TestParseTranslations(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected []model.Translation
+		dropped  int
+	}{
+		{
+			"record_id and field_value",
+			`
+table_name,field_name,language,translation,record_id,record_sub_id,field_value
+stops,stop_name,fr,Gare,s,,
+routes,route_long_name,fr,Ligne rouge,,,Red Line
+stop_times,stop_headsign,fr,Nord,t,005,
+agency,agency_name,fr,Agence,a,,
+feed_info,feed_publisher_name,fr,Éditeur,,,`,
+			[]model.Translation{
+				{TableName: "agency", FieldName: "agency_name", Language: "fr", Translation: "Agence", RecordID: "a"},
+				{TableName: "feed_info", FieldName: "feed_publisher_name", Language: "fr", Translation: "Éditeur"},
+				{TableName: "routes", FieldName: "route_long_name", Language: "fr", Translation: "Ligne rouge", FieldValue: "Red Line"},
+				{TableName: "stop_times", FieldName: "stop_headsign", Language: "fr", Translation: "Nord", RecordID: "t", RecordSubID: "5"},
+				{TableName: "stops", FieldName: "stop_name", Language: "fr", Translation: "Gare", RecordID: "s"},
+			},
+			0,
+		},
+
+		{
+			"bad rows among good",
+			`
+table_name,field_name,language,translation,record_id
+stops,stop_name,fr,Gare,s
+stops,stop_name,fr,Inconnue,x
+routes,route_long_name,,Ligne,r
+routes,route_long_name,fr,Ligne,r`,
+			[]model.Translation{
+				{TableName: "routes", FieldName: "route_long_name", Language: "fr", Translation: "Ligne", RecordID: "r"},
+				{TableName: "stops", FieldName: "stop_name", Language: "fr", Translation: "Gare", RecordID: "s"},
+			},
+			2,
+		},
+
+		{
+			"legacy format",
+			`
+trans_id,lang,translation
+Gare,fr,Gare`,
+			nil, 0,
+		},
+
+		{
+			"invalid table_name",
+			`
+table_name,field_name,language,translation,record_id
+shapes,shape_id,fr,Forme,s`,
+			nil, 1,
+		},
+
+		{
+			"missing language",
+			`
+table_name,field_name,language,translation,record_id
+stops,stop_name,,Gare,s`,
+			nil, 1,
+		},
+
+		{
+			"missing translation",
+			`
+table_name,field_name,language,translation,record_id
+stops,stop_name,fr,,s`,
+			nil, 1,
+		},
+
+		{
+			"both record_id and field_value",
+			`
+table_name,field_name,language,translation,record_id,field_value
+stops,stop_name,fr,Gare,s,Station`,
+			nil, 1,
+		},
+
+		{
+			"neither record_id nor field_value",
+			`
+table_name,field_name,language,translation,record_id,field_value
+stops,stop_name,fr,Gare,,`,
+			nil, 1,
+		},
+
+		{
+			"unknown record_id",
+			`
+table_name,field_name,language,translation,record_id
+stops,stop_name,fr,Gare,x`,
+			nil, 1,
+		},
+
+		{
+			"stop_times without record_sub_id",
+			`
+table_name,field_name,language,translation,record_id,record_sub_id
+stop_times,stop_headsign,fr,Nord,t,`,
+			nil, 1,
+		},
+
+		{
+			"record_sub_id outside stop_times",
+			`
+table_name,field_name,language,translation,record_id,record_sub_id
+stops,stop_name,fr,Gare,s,1`,
+			nil, 1,
+		},
+
+		{
+			"record_id for feed_info",
+			`
+table_name,field_name,language,translation,record_id
+feed_info,feed_publisher_name,fr,Éditeur,f`,
+			nil, 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			// Bad rows are dropped, never failing the parse
+			drop := newDropped()
+			err = parseTranslations(
+				writer,
+				bytes.NewBufferString(tc.content),
+				map[string]bool{"a": true},
+				map[string]bool{"s": true},
+				map[string]bool{"r": true},
+				map[string]bool{"t": true},
+				drop,
+			)
+			require.NoError(t, err)
+			assert.Equal(t, tc.dropped, drop.count)
+
+			reader, err := s.GetReader("test")
+			require.NoError(t, err)
+			translations, err := reader.Translations(storage.TranslationFilter{})
+			require.NoError(t, err)
+			if len(tc.expected) == 0 {
+				assert.Empty(t, translations)
+			} else {
+				assert.Equal(t, tc.expected, translations)
+			}
+		})
+	}
+}
+
+func TestParseStaticBadTranslations(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		translations []string
+		dropped      int
+	}{
+		{
+			"unknown table_name and record_id",
+			[]string{
+				"table_name,field_name,language,translation,record_id",
+				"shapes,shape_id,fr,Forme,x",
+				"stops,stop_name,fr,Gare,x",
+				"stops,stop_name,fr,Gare,s",
+			},
+			2,
+		},
+		{
+			"legacy format",
+			[]string{
+				"trans_id,lang,translation",
+				"S,fr,Gare",
+			},
+			0,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := storage.NewSQLiteStorage()
+			require.NoError(t, err)
+			writer, err := s.GetWriter("test")
+			require.NoError(t, err)
+
+			files := fixtureSimple()
+			files["translations.txt"] = tc.translations
+			metadata, err := ParseStatic(writer, buildZip(t, files))
+			require.NoError(t, err)
+			assert.Equal(t, tc.dropped, metadata.DroppedCount)
+			assert.Equal(t, tc.dropped, len(metadata.Dropped))
+		})
+	}
+}
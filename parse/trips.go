@@ -3,24 +3,26 @@ package parse
 import (
 	"fmt"
 	"io"
-
-	"github.com/gocarina/gocsv"
+	"strconv"
 
 	"tidbyt.dev/gtfs/model"
 	"tidbyt.dev/gtfs/storage"
 )
 
 type TripCSV struct {
-	ID          string `csv:"trip_id"`
-	RouteID     string `csv:"route_id"`
-	ServiceID   string `csv:"service_id"`
-	Headsign    string `csv:"trip_headsign"`
-	ShortName   string `csv:"trip_short_name"`
-	DirectionID int8   `csv:"direction_id"`
-	// BlockID              string `csv:"block_id"`
-	// ShapeID              string `csv:"shape_id"`
-	// WheelchairAccessible int8   `csv:"wheelchair_accessible"`
-	// BikesAllowed         int8   `csv:"bikes_allowed"`
+	ID                   string `csv:"trip_id"`
+	RouteID              string `csv:"route_id"`
+	ServiceID            string `csv:"service_id"`
+	Headsign             string `csv:"trip_headsign"`
+	ShortName            string `csv:"trip_short_name"`
+	DirectionID          int8   `csv:"direction_id"`
+	ShapeID              string `csv:"shape_id"`
+	WheelchairAccessible int8   `csv:"wheelchair_accessible"`
+	BlockID              string `csv:"block_id"`
+	BikesAllowed         int8   `csv:"bikes_allowed"`
+	CarsAllowed          int8   `csv:"cars_allowed"`
+
+	Extras map[string]string `csv:"-"`
 }
 
 func ParseTrips(
@@ -28,49 +30,117 @@ func ParseTrips(
 	data io.Reader,
 	routes map[string]bool,
 	services map[string]bool,
+	shapes map[string]bool,
+) (map[string]bool, error) {
+	return parseTrips(writer, data, routes, services, shapes, nil, false)
+}
+
+// Parses trips.txt. If drop is non-nil, bad rows are dropped rather
+// than failing the parse, and left out of the returned trip IDs. If
+// extras is set, values in extension columns are captured.
+func parseTrips(
+	writer storage.FeedWriter,
+	data io.Reader,
+	routes map[string]bool,
+	services map[string]bool,
+	shapes map[string]bool,
+	drop *dropped,
+	extras bool,
 ) (map[string]bool, error) {
 	tripCsv := []*TripCSV{}
-	if err := gocsv.Unmarshal(data, &tripCsv); err != nil {
-		return nil, fmt.Errorf("unmarshaling trips csv: %w", err)
+	if err := unmarshalExtras(data, &tripCsv, "trips.txt", extras); err != nil {
+		return nil, errMalformed("trips.txt", err)
 	}
 
 	trips := map[string]bool{}
-	for _, t := range tripCsv {
-		if trips[t.ID] {
-			return nil, fmt.Errorf("repeated trip_id '%s'", t.ID)
+	for i, t := range tripCsv {
+		err := parseTrip(writer, i+1, t, trips, routes, services, shapes)
+		if err != nil {
+			if drop == nil {
+				return nil, err
+			}
+			drop.trip(t.ID, err)
 		}
-		trips[t.ID] = true
+	}
 
-		if t.ID == "" {
-			return nil, fmt.Errorf("empty trip_id")
-		}
-		if t.RouteID == "" {
-			return nil, fmt.Errorf("empty route_id")
+	if drop != nil {
+		for tripID := range drop.trips {
+			delete(trips, tripID)
 		}
+	}
 
-		if !routes[t.RouteID] {
-			return nil, fmt.Errorf("unknown route_id '%s'", t.RouteID)
-		}
-		if !services[t.ServiceID] {
-			return nil, fmt.Errorf("unknown service_id '%s'", t.ServiceID)
-		}
+	return trips, nil
+}
 
-		if t.DirectionID != 0 && t.DirectionID != 1 {
-			return nil, fmt.Errorf("invalid direction_id '%d'", t.DirectionID)
-		}
+func parseTrip(
+	writer storage.FeedWriter,
+	row int,
+	t *TripCSV,
+	trips map[string]bool,
+	routes map[string]bool,
+	services map[string]bool,
+	shapes map[string]bool,
+) error {
+	if trips[t.ID] {
+		return errDuplicate("trips.txt", row, "trip_id", t.ID)
+	}
+	trips[t.ID] = true
 
-		err := writer.WriteTrip(model.Trip{
-			ID:          t.ID,
-			RouteID:     t.RouteID,
-			ServiceID:   t.ServiceID,
-			Headsign:    t.Headsign,
-			ShortName:   t.ShortName,
-			DirectionID: t.DirectionID,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("writing trip: %w", err)
-		}
+	if t.ID == "" {
+		return errMissing("trips.txt", row, "trip_id")
+	}
+	if t.RouteID == "" {
+		return errMissing("trips.txt", row, "route_id")
 	}
 
-	return trips, nil
+	if !routes[t.RouteID] {
+		return errUnknown("trips.txt", row, "route_id", t.RouteID)
+	}
+	if !services[t.ServiceID] {
+		return errUnknown("trips.txt", row, "service_id", t.ServiceID)
+	}
+
+	if t.DirectionID != 0 && t.DirectionID != 1 {
+		return errInvalid("trips.txt", row, "direction_id", strconv.Itoa(int(t.DirectionID)), nil)
+	}
+
+	// Dangling shape references are common, and the shape can
+	// be derived from the stops instead, so these are dropped
+	// rather than rejected.
+	if t.ShapeID != "" && !shapes[t.ShapeID] {
+		t.ShapeID = ""
+	}
+
+	if t.WheelchairAccessible < 0 || t.WheelchairAccessible > 2 {
+		return errInvalid("trips.txt", row, "wheelchair_accessible", strconv.Itoa(int(t.WheelchairAccessible)), nil)
+	}
+
+	if t.BikesAllowed < 0 || t.BikesAllowed > 2 {
+		return errInvalid("trips.txt", row, "bikes_allowed", strconv.Itoa(int(t.BikesAllowed)), nil)
+	}
+
+	if t.CarsAllowed < 0 || t.CarsAllowed > 2 {
+		return errInvalid("trips.txt", row, "cars_allowed", strconv.Itoa(int(t.CarsAllowed)), nil)
+	}
+
+	err := writer.WriteTrip(model.Trip{
+		ID:          t.ID,
+		RouteID:     t.RouteID,
+		ServiceID:   t.ServiceID,
+		Headsign:    t.Headsign,
+		ShortName:   t.ShortName,
+		DirectionID: t.DirectionID,
+		ShapeID:     t.ShapeID,
+		BlockID:     t.BlockID,
+
+		WheelchairAccessible: model.WheelchairAccessibility(t.WheelchairAccessible),
+		BikesAllowed:         model.AmenityAllowance(t.BikesAllowed),
+		CarsAllowed:          model.AmenityAllowance(t.CarsAllowed),
+		Extras:               t.Extras,
+	})
+	if err != nil {
+		return errStorage("trips.txt", row, fmt.Errorf("writing trip: %w", err))
+	}
+
+	return nil
 }
@@ -39,17 +39,22 @@ t,r,s`,
 		{
 			"all_fields_set",
 			`
-trip_id,route_id,service_id,trip_headsign,trip_short_name,direction_id
-t,r,s,head,short,1`,
+trip_id,route_id,service_id,trip_headsign,trip_short_name,direction_id,shape_id,wheelchair_accessible,block_id,bikes_allowed,cars_allowed
+t,r,s,head,short,1,sh,1,b,1,2`,
 			map[string]bool{"r": true},
 			map[string]bool{"s": true},
 			[]model.Trip{model.Trip{
-				ID:          "t",
-				RouteID:     "r",
-				ServiceID:   "s",
-				Headsign:    "head",
-				ShortName:   "short",
-				DirectionID: 1,
+				ID:                   "t",
+				RouteID:              "r",
+				ServiceID:            "s",
+				Headsign:             "head",
+				ShortName:            "short",
+				DirectionID:          1,
+				ShapeID:              "sh",
+				BlockID:              "b",
+				WheelchairAccessible: model.WheelchairAccessible,
+				BikesAllowed:         model.AmenityAllowed,
+				CarsAllowed:          model.AmenityNotAllowed,
 			}},
 			false,
 		},
@@ -156,6 +161,54 @@ t,r,s,2`,
 			nil,
 			true,
 		},
+
+		{
+			"invalid wheelchair_accessible",
+			`
+trip_id,route_id,service_id,wheelchair_accessible
+t,r,s,3`,
+			map[string]bool{"r": true},
+			map[string]bool{"s": true},
+			nil,
+			true,
+		},
+
+		{
+			"invalid bikes_allowed",
+			`
+trip_id,route_id,service_id,bikes_allowed
+t,r,s,3`,
+			map[string]bool{"r": true},
+			map[string]bool{"s": true},
+			nil,
+			true,
+		},
+
+		{
+			"invalid cars_allowed",
+			`
+trip_id,route_id,service_id,cars_allowed
+t,r,s,-1`,
+			map[string]bool{"r": true},
+			map[string]bool{"s": true},
+			nil,
+			true,
+		},
+
+		{
+			"unknown shape_id",
+			`
+trip_id,route_id,service_id,shape_id
+t,r,s,unknown`,
+			map[string]bool{"r": true},
+			map[string]bool{"s": true},
+			[]model.Trip{model.Trip{
+				ID:        "t",
+				RouteID:   "r",
+				ServiceID: "s",
+			}},
+			false,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 
@@ -165,7 +218,13 @@ t,r,s,2`,
 			require.NoError(t, err)
 
 			require.NoError(t, writer.BeginTrips())
-			tripIDs, err := ParseTrips(writer, bytes.NewBufferString(tc.content), tc.routes, tc.services)
+			tripIDs, err := ParseTrips(
+				writer,
+				bytes.NewBufferString(tc.content),
+				tc.routes,
+				tc.services,
+				map[string]bool{"sh": true},
+			)
 			if tc.err {
 				assert.Error(t, err)
 				return
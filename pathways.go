@@ -0,0 +1,181 @@
+package gtfs
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+
+	"tidbyt.dev/gtfs/model"
+)
+
+// Walking speed used to estimate traversal time of pathways lacking
+// traversal_time, in meters per second.
+const pathwayWalkingSpeed = 1.3
+
+// Traversal time assumed for pathways lacking both traversal_time
+// and length, in seconds.
+const pathwayDefaultTraversalTime = 60
+
+// Returns the quickest walking path along pathways from one location
+// to another, e.g. from an entrance to a platform. Returns nil if
+// there's no such path, and a path without steps if the locations are
+// the same.
+//
+// Traversal time is taken from pathways.txt, estimated from the
+// pathway's length when missing.
+func (s Static) WalkingPath(fromStopID string, toStopID string) (*model.WalkingPath, error) {
+	return s.walkingPath(fromStopID, toStopID, false)
+}
+
+// Like WalkingPath, but avoids stairs and escalators.
+func (s Static) AccessibleWalkingPath(fromStopID string, toStopID string) (*model.WalkingPath, error) {
+	return s.walkingPath(fromStopID, toStopID, true)
+}
+
+// An edge in the pathway graph, i.e. a pathway in one of the
+// directions it can be traversed.
+type pathwayEdge struct {
+	pathway  *model.Pathway
+	to       string
+	reversed bool
+	cost     uint32
+}
+
+func (s Static) walkingPath(fromStopID string, toStopID string, accessibleOnly bool) (*model.WalkingPath, error) {
+	if fromStopID == toStopID {
+		return &model.WalkingPath{Steps: []model.WalkingPathStep{}}, nil
+	}
+
+	pathways, err := s.Reader.Pathways()
+	if err != nil {
+		return nil, fmt.Errorf("getting pathways: %w", err)
+	}
+
+	edges := map[string][]pathwayEdge{}
+	for i := range pathways {
+		p := &pathways[i]
+		if accessibleOnly && (p.Mode == model.PathwayModeStairs || p.Mode == model.PathwayModeEscalator) {
+			continue
+		}
+
+		cost := p.TraversalTime
+		if cost == 0 && p.Length > 0 {
+			cost = uint32(math.Ceil(p.Length / pathwayWalkingSpeed))
+		} else if cost == 0 {
+			cost = pathwayDefaultTraversalTime
+		}
+
+		edges[p.FromStopID] = append(edges[p.FromStopID], pathwayEdge{pathway: p, to: p.ToStopID, cost: cost})
+		if p.IsBidirectional {
+			edges[p.ToStopID] = append(edges[p.ToStopID], pathwayEdge{pathway: p, to: p.FromStopID, reversed: true, cost: cost})
+		}
+	}
+
+	// Dijkstra's
+	dist := map[string]uint32{fromStopID: 0}
+	prev := map[string]pathwayEdge{}
+	prevStop := map[string]string{}
+	visited := map[string]bool{}
+	queue := &pathwayQueue{{stopID: fromStopID}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(pathwayQueueItem)
+		if visited[item.stopID] {
+			continue
+		}
+		visited[item.stopID] = true
+		if item.stopID == toStopID {
+			break
+		}
+
+		for _, edge := range edges[item.stopID] {
+			d := item.dist + edge.cost
+			if current, found := dist[edge.to]; found && current <= d {
+				continue
+			}
+			dist[edge.to] = d
+			prev[edge.to] = edge
+			prevStop[edge.to] = item.stopID
+			heap.Push(queue, pathwayQueueItem{stopID: edge.to, dist: d})
+		}
+	}
+
+	if !visited[toStopID] {
+		return nil, nil
+	}
+
+	// Walk back from destination
+	route := []pathwayEdge{}
+	from := []string{}
+	for stopID := toStopID; stopID != fromStopID; stopID = prevStop[stopID] {
+		route = append([]pathwayEdge{prev[stopID]}, route...)
+		from = append([]string{prevStop[stopID]}, from...)
+	}
+
+	levels, err := s.Reader.Levels()
+	if err != nil {
+		return nil, fmt.Errorf("getting levels: %w", err)
+	}
+	levelByID := map[string]*model.Level{}
+	for i := range levels {
+		levelByID[levels[i].ID] = &levels[i]
+	}
+	stopLevel := func(stopID string) (*model.Level, error) {
+		stop, err := s.Reader.Stop(stopID)
+		if err != nil {
+			return nil, fmt.Errorf("getting stop: %w", err)
+		}
+		if stop == nil {
+			return nil, nil
+		}
+		return levelByID[stop.LevelID], nil
+	}
+
+	path := &model.WalkingPath{TraversalTime: dist[toStopID]}
+	for i, edge := range route {
+		signpost := edge.pathway.SignpostedAs
+		if edge.reversed {
+			signpost = edge.pathway.ReversedSignpostedAs
+		}
+
+		fromLevel, err := stopLevel(from[i])
+		if err != nil {
+			return nil, err
+		}
+		toLevel, err := stopLevel(edge.to)
+		if err != nil {
+			return nil, err
+		}
+
+		path.Steps = append(path.Steps, model.WalkingPathStep{
+			PathwayID:     edge.pathway.ID,
+			FromStopID:    from[i],
+			ToStopID:      edge.to,
+			Mode:          edge.pathway.Mode,
+			TraversalTime: edge.cost,
+			SignpostedAs:  signpost,
+			FromLevel:     fromLevel,
+			ToLevel:       toLevel,
+		})
+	}
+
+	return path, nil
+}
+
+type pathwayQueueItem struct {
+	stopID string
+	dist   uint32
+}
+
+// Priority queue of stops by distance, for use with container/heap.
+type pathwayQueue []pathwayQueueItem
+
+func (q pathwayQueue) Len() int            { return len(q) }
+func (q pathwayQueue) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q pathwayQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathwayQueue) Push(x interface{}) { *q = append(*q, x.(pathwayQueueItem)) }
+func (q *pathwayQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
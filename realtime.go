@@ -106,7 +106,8 @@ func (rt *Realtime) Departures(
 	numDepartures int,
 	routeID string,
 	directionID int8,
-	routeTypes []model.RouteType) ([]model.Departure, error) {
+	routeTypes []model.RouteType,
+	langs ...string) ([]model.Departure, error) {
 
 	// Get the scheduled departures. Extend the window so that
 	// delayed (or early) departures are included.
@@ -118,6 +119,7 @@ func (rt *Realtime) Departures(
 		routeID,
 		directionID,
 		routeTypes,
+		langs...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("getting static departures: %w", err)
@@ -55,14 +55,16 @@ func TestRealtimeIntegrationNYCFerryDelaysOnER(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{ // delayed 391s
-			RouteID:      "ER",
-			TripID:       "321",
-			StopID:       "8",
-			StopSequence: 5,
-			DirectionID:  0,
-			Time:         time.Date(2020, 3, 2, 11, 7, 31, 0, tz),
-			Headsign:     "Wall St./Pier 11",
-			Delay:        391 * time.Second,
+			RouteID:        "ER",
+			RouteShortName: "ER",
+			RouteLongName:  "East River",
+			TripID:         "321",
+			StopID:         "8",
+			StopSequence:   5,
+			DirectionID:    0,
+			Time:           time.Date(2020, 3, 2, 11, 7, 31, 0, tz),
+			Headsign:       "Wall St./Pier 11",
+			Delay:          391 * time.Second,
 		},
 	}, departures)
 
@@ -81,14 +83,16 @@ func TestRealtimeIntegrationNYCFerryDelaysOnER(t *testing.T) {
 	require.Equal(t, 1, len(departures))
 	assert.Equal(t, []model.Departure{
 		{ // delayed 391s
-			RouteID:      "ER",
-			TripID:       "321",
-			StopID:       "20",
-			StopSequence: 6,
-			DirectionID:  0,
-			Time:         time.Date(2020, 3, 2, 11, 19, 31, 0, tz),
-			Headsign:     "Wall St./Pier 11",
-			Delay:        391 * time.Second,
+			RouteID:        "ER",
+			RouteShortName: "ER",
+			RouteLongName:  "East River",
+			TripID:         "321",
+			StopID:         "20",
+			StopSequence:   6,
+			DirectionID:    0,
+			Time:           time.Date(2020, 3, 2, 11, 19, 31, 0, tz),
+			Headsign:       "Wall St./Pier 11",
+			Delay:          391 * time.Second,
 		},
 	}, departures)
 
@@ -133,14 +137,16 @@ func TestRealtimeIntegrationNYCFerryDelayWithRecoveryOnSB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "SB",
-			TripID:       "526",
-			StopID:       "23",
-			StopSequence: 1,
-			DirectionID:  1,
-			Time:         time.Date(2020, 3, 2, 15, 51, 27, 0, tz),
-			Headsign:     "Wall St./Pier 11",
-			Delay:        87 * time.Second,
+			RouteID:        "SB",
+			RouteShortName: "SB",
+			RouteLongName:  "South Brooklyn",
+			TripID:         "526",
+			StopID:         "23",
+			StopSequence:   1,
+			DirectionID:    1,
+			Time:           time.Date(2020, 3, 2, 15, 51, 27, 0, tz),
+			Headsign:       "Wall St./Pier 11",
+			Delay:          87 * time.Second,
 		},
 	}, departures)
 
@@ -160,13 +166,15 @@ func TestRealtimeIntegrationNYCFerryDelayWithRecoveryOnSB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "SB",
-			TripID:       "526",
-			StopID:       "118",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 3, 2, 15, 59, 0, 0, tz),
-			Headsign:     "Wall St./Pier 11",
+			RouteID:        "SB",
+			RouteShortName: "SB",
+			RouteLongName:  "South Brooklyn",
+			TripID:         "526",
+			StopID:         "118",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 3, 2, 15, 59, 0, 0, tz),
+			Headsign:       "Wall St./Pier 11",
 		},
 	}, departures)
 }
@@ -201,18 +201,20 @@ func TestRealtimeNoChanges(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 0, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 0, 0, 0, time.UTC),
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 10, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 10, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -221,18 +223,20 @@ func TestRealtimeNoChanges(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 1, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 1, 0, 0, time.UTC),
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 11, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 11, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -241,18 +245,20 @@ func TestRealtimeNoChanges(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s3",
-			StopSequence: 3,
-			Time:         time.Date(2020, 1, 15, 23, 2, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s3",
+			StopSequence:   3,
+			Time:           time.Date(2020, 1, 15, 23, 2, 0, 0, time.UTC),
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s3",
-			StopSequence: 3,
-			Time:         time.Date(2020, 1, 15, 23, 12, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s3",
+			StopSequence:   3,
+			Time:           time.Date(2020, 1, 15, 23, 12, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -267,11 +273,12 @@ func TestRealtimeNoChanges(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R2",
-			TripID:       "t3",
-			StopID:       "z1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 5, 0, 0, time.UTC),
+			RouteID:        "R2",
+			RouteShortName: "R_2",
+			TripID:         "t3",
+			StopID:         "z1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 5, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -322,18 +329,20 @@ func TestRealtimeDelayWithPropagation(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 0, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 0, 0, 0, time.UTC),
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 10, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 10, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -342,20 +351,22 @@ func TestRealtimeDelayWithPropagation(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 1, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 1, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 11, 45, 0, time.UTC),
-			Delay:        45 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 11, 45, 0, time.UTC),
+			Delay:          45 * time.Second,
 		},
 	}, departures)
 
@@ -364,19 +375,21 @@ func TestRealtimeDelayWithPropagation(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s3",
-			StopSequence: 3,
-			Time:         time.Date(2020, 1, 15, 23, 2, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s3",
+			StopSequence:   3,
+			Time:           time.Date(2020, 1, 15, 23, 2, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s3",
-			StopSequence: 3,
-			Time:         time.Date(2020, 1, 15, 23, 12, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s3",
+			StopSequence:   3,
+			Time:           time.Date(2020, 1, 15, 23, 12, 0, 0, time.UTC),
 		},
 	}, departures)
 }
@@ -444,19 +457,21 @@ func TestRealtimeNoData(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 0, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 0, 0, 0, time.UTC),
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 10, 45, 0, time.UTC),
-			Delay:        45 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 10, 45, 0, time.UTC),
+			Delay:          45 * time.Second,
 		},
 	}, departures)
 
@@ -465,18 +480,20 @@ func TestRealtimeNoData(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 1, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 1, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s2",
-			StopSequence: 2,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s2",
+			StopSequence:   2,
 			// delay not propagated due to NO_DATA
 			Time: time.Date(2020, 1, 15, 23, 11, 0, 0, time.UTC),
 		},
@@ -487,19 +504,21 @@ func TestRealtimeNoData(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s3",
-			StopSequence: 3,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s3",
+			StopSequence:   3,
 			// delay propagated from s2
 			Time:  time.Date(2020, 1, 15, 23, 2, 30, 0, time.UTC),
 			Delay: 30 * time.Second,
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s3",
-			StopSequence: 3,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s3",
+			StopSequence:   3,
 			// and t2 remains on schedule due to NO_DATA at s2
 			Time: time.Date(2020, 1, 15, 23, 12, 0, 0, time.UTC),
 		},
@@ -553,12 +572,13 @@ func TestRealtimeSkippedStop(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 10, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 10, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 	}, departures)
 
@@ -567,11 +587,12 @@ func TestRealtimeSkippedStop(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 1, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 1, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -580,12 +601,13 @@ func TestRealtimeSkippedStop(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s3",
-			StopSequence: 3,
-			Time:         time.Date(2020, 1, 15, 23, 12, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s3",
+			StopSequence:   3,
+			Time:           time.Date(2020, 1, 15, 23, 12, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 	}, departures)
 }
@@ -618,11 +640,12 @@ func TestRealtimeCanceledTrip(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 10, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 10, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -630,12 +653,13 @@ func TestRealtimeCanceledTrip(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 11, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 11, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 	}, departures)
 
@@ -643,12 +667,13 @@ func TestRealtimeCanceledTrip(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s3",
-			StopSequence: 3,
-			Time:         time.Date(2020, 1, 15, 23, 12, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s3",
+			StopSequence:   3,
+			Time:           time.Date(2020, 1, 15, 23, 12, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 	}, departures)
 
@@ -656,11 +681,12 @@ func TestRealtimeCanceledTrip(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R2",
-			TripID:       "t3",
-			StopID:       "z1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 5, 0, 0, time.UTC),
+			RouteID:        "R2",
+			RouteShortName: "R_2",
+			TripID:         "t3",
+			StopID:         "z1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 5, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -750,12 +776,13 @@ func TestRealtimeTimeWindowing(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 1, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 1, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 	}, departures)
 
@@ -769,20 +796,22 @@ func TestRealtimeTimeWindowing(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 1, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 1, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 13, 0, 0, time.UTC),
-			Delay:        120 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 13, 0, 0, time.UTC),
+			Delay:          120 * time.Second,
 		},
 	}, departures)
 
@@ -796,12 +825,13 @@ func TestRealtimeTimeWindowing(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 13, 0, 0, time.UTC),
-			Delay:        120 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 13, 0, 0, time.UTC),
+			Delay:          120 * time.Second,
 		},
 	}, departures)
 }
@@ -885,11 +915,12 @@ func TestRealtimeTripWithLoop(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 0, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 0, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -897,11 +928,12 @@ func TestRealtimeTripWithLoop(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 1, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 1, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -909,27 +941,30 @@ func TestRealtimeTripWithLoop(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s3",
-			StopSequence: 3,
-			Time:         time.Date(2020, 1, 15, 23, 2, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s3",
+			StopSequence:   3,
+			Time:           time.Date(2020, 1, 15, 23, 2, 0, 0, time.UTC),
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s3",
-			StopSequence: 6,
-			Time:         time.Date(2020, 1, 15, 23, 5, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s3",
+			StopSequence:   6,
+			Time:           time.Date(2020, 1, 15, 23, 5, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s3",
-			StopSequence: 9,
-			Time:         time.Date(2020, 1, 15, 23, 8, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s3",
+			StopSequence:   9,
+			Time:           time.Date(2020, 1, 15, 23, 8, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 	}, departures)
 
@@ -937,27 +972,30 @@ func TestRealtimeTripWithLoop(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s4",
-			StopSequence: 4,
-			Time:         time.Date(2020, 1, 15, 23, 3, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s4",
+			StopSequence:   4,
+			Time:           time.Date(2020, 1, 15, 23, 3, 0, 0, time.UTC),
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s4",
-			StopSequence: 7,
-			Time:         time.Date(2020, 1, 15, 23, 6, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s4",
+			StopSequence:   7,
+			Time:           time.Date(2020, 1, 15, 23, 6, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s4",
-			StopSequence: 10,
-			Time:         time.Date(2020, 1, 15, 23, 9, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s4",
+			StopSequence:   10,
+			Time:           time.Date(2020, 1, 15, 23, 9, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 	}, departures)
 
@@ -965,19 +1003,21 @@ func TestRealtimeTripWithLoop(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s5",
-			StopSequence: 5,
-			Time:         time.Date(2020, 1, 15, 23, 4, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s5",
+			StopSequence:   5,
+			Time:           time.Date(2020, 1, 15, 23, 4, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s5",
-			StopSequence: 11,
-			Time:         time.Date(2020, 1, 15, 23, 10, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R one",
+			TripID:         "t1",
+			StopID:         "s5",
+			StopSequence:   11,
+			Time:           time.Date(2020, 1, 15, 23, 10, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -1065,19 +1105,21 @@ func TestRealtimeDepartureFiltering(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "BusSouth",
-			TripID:       "ts",
-			StopID:       "center",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
-			Delay:        1 * time.Second,
+			RouteID:       "BusSouth",
+			RouteLongName: "Bus South",
+			TripID:        "ts",
+			StopID:        "center",
+			StopSequence:  1,
+			Time:          time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
+			Delay:         1 * time.Second,
 		},
 		{
-			RouteID:      "RailEast",
-			TripID:       "te",
-			StopID:       "center",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 16, 3, 0, 0, 0, time.UTC),
+			RouteID:       "RailEast",
+			RouteLongName: "Rail East",
+			TripID:        "te",
+			StopID:        "center",
+			StopSequence:  1,
+			Time:          time.Date(2020, 1, 16, 3, 0, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -1090,12 +1132,13 @@ func TestRealtimeDepartureFiltering(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "BusSouth",
-			TripID:       "ts",
-			StopID:       "center",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
-			Delay:        1 * time.Second,
+			RouteID:       "BusSouth",
+			RouteLongName: "Bus South",
+			TripID:        "ts",
+			StopID:        "center",
+			StopSequence:  1,
+			Time:          time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
+			Delay:         1 * time.Second,
 		},
 	}, departures)
 
@@ -1108,11 +1151,12 @@ func TestRealtimeDepartureFiltering(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "RailEast",
-			TripID:       "te",
-			StopID:       "center",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 16, 3, 0, 0, 0, time.UTC),
+			RouteID:       "RailEast",
+			RouteLongName: "Rail East",
+			TripID:        "te",
+			StopID:        "center",
+			StopSequence:  1,
+			Time:          time.Date(2020, 1, 16, 3, 0, 0, 0, time.UTC),
 		},
 	}, departures)
 	departures, err = rt.Departures(
@@ -1123,12 +1167,13 @@ func TestRealtimeDepartureFiltering(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "BusSouth",
-			TripID:       "ts",
-			StopID:       "center",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
-			Delay:        1 * time.Second,
+			RouteID:       "BusSouth",
+			RouteLongName: "Bus South",
+			TripID:        "ts",
+			StopID:        "center",
+			StopSequence:  1,
+			Time:          time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
+			Delay:         1 * time.Second,
 		},
 	}, departures)
 
@@ -1141,12 +1186,13 @@ func TestRealtimeDepartureFiltering(t *testing.T) {
 	assert.Equal(t, nil, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "BusSouth",
-			TripID:       "ts",
-			StopID:       "center",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
-			Delay:        1 * time.Second,
+			RouteID:       "BusSouth",
+			RouteLongName: "Bus South",
+			TripID:        "ts",
+			StopID:        "center",
+			StopSequence:  1,
+			Time:          time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
+			Delay:         1 * time.Second,
 		},
 	}, departures)
 	departures, err = rt.Departures(
@@ -1166,12 +1212,13 @@ func TestRealtimeDepartureFiltering(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "BusSouth",
-			TripID:       "ts",
-			StopID:       "center",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
-			Delay:        1 * time.Second,
+			RouteID:       "BusSouth",
+			RouteLongName: "Bus South",
+			TripID:        "ts",
+			StopID:        "center",
+			StopSequence:  1,
+			Time:          time.Date(2020, 1, 16, 1, 0, 1, 0, time.UTC),
+			Delay:         1 * time.Second,
 		},
 	}, departures)
 	departures, err = rt.Departures(
@@ -1182,11 +1229,12 @@ func TestRealtimeDepartureFiltering(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "RailEast",
-			TripID:       "te",
-			StopID:       "center",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 16, 3, 0, 0, 0, time.UTC),
+			RouteID:       "RailEast",
+			RouteLongName: "Rail East",
+			TripID:        "te",
+			StopID:        "center",
+			StopSequence:  1,
+			Time:          time.Date(2020, 1, 16, 3, 0, 0, 0, time.UTC),
 		},
 	}, departures)
 	departures, err = rt.Departures(
@@ -1361,20 +1409,22 @@ func TestRealtimeArrivalRecovery(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 0, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 0, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 10, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 10, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 	}, departures)
 
@@ -1382,12 +1432,13 @@ func TestRealtimeArrivalRecovery(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R2",
-			TripID:       "t3",
-			StopID:       "z1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 1, 15, 23, 5, 30, 0, time.UTC),
-			Delay:        30 * time.Second,
+			RouteID:        "R2",
+			RouteShortName: "R_2",
+			TripID:         "t3",
+			StopID:         "z1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 1, 15, 23, 5, 30, 0, time.UTC),
+			Delay:          30 * time.Second,
 		},
 	}, departures)
 
@@ -1396,18 +1447,20 @@ func TestRealtimeArrivalRecovery(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 1, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 1, 0, 0, time.UTC),
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 11, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 11, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -1415,11 +1468,12 @@ func TestRealtimeArrivalRecovery(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R2",
-			TripID:       "t3",
-			StopID:       "z2",
-			StopSequence: 2,
-			Time:         time.Date(2020, 1, 15, 23, 6, 0, 0, time.UTC),
+			RouteID:        "R2",
+			RouteShortName: "R_2",
+			TripID:         "t3",
+			StopID:         "z2",
+			StopSequence:   2,
+			Time:           time.Date(2020, 1, 15, 23, 6, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -1429,18 +1483,20 @@ func TestRealtimeArrivalRecovery(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			RouteID:      "R1",
-			TripID:       "t1",
-			StopID:       "s3",
-			StopSequence: 3,
-			Time:         time.Date(2020, 1, 15, 23, 2, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t1",
+			StopID:         "s3",
+			StopSequence:   3,
+			Time:           time.Date(2020, 1, 15, 23, 2, 0, 0, time.UTC),
 		},
 		{
-			RouteID:      "R1",
-			TripID:       "t2",
-			StopID:       "s3",
-			StopSequence: 3,
-			Time:         time.Date(2020, 1, 15, 23, 12, 0, 0, time.UTC),
+			RouteID:        "R1",
+			RouteShortName: "R_1",
+			TripID:         "t2",
+			StopID:         "s3",
+			StopSequence:   3,
+			Time:           time.Date(2020, 1, 15, 23, 12, 0, 0, time.UTC),
 		},
 	}, departures)
 }
@@ -1575,6 +1631,93 @@ func TestRealtimeDelayCrossingMidnight(t *testing.T) {
 	}
 }
 
+func TestRealtimeStopTimezone(t *testing.T) {
+	// A single trip from New York to Chicago. Times in
+	// stop_times.txt are in the agency's timezone.
+	static := testutil.BuildStatic(t, "sqlite", map[string][]string{
+		"agency.txt": {
+			"agency_timezone,agency_name,agency_url",
+			"America/New_York,Agency,http://example.com",
+		},
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"everyday,20200101,20210101,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {
+			"route_id,route_short_name,route_type",
+			"R1,R_1,2",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,stop_timezone",
+			"nyc,NYC,1,1,",
+			"chi,Chicago,2,2,America/Chicago",
+			"end,End,3,3,America/Chicago",
+		},
+		"trips.txt": {
+			"service_id,trip_id,route_id",
+			"everyday,t1,R1",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"t1,nyc,1,10:00:00,10:00:00",
+			"t1,chi,2,20:00:00,20:00:00",
+			"t1,end,3,21:00:00,21:00:00",
+		},
+	})
+
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	// Delay at the first stop propagates to Chicago, where the
+	// departure is given in local time.
+	feed := buildFeed(t, []TripUpdate{
+		{
+			TripID: "t1",
+			StopUpdates: []StopUpdate{
+				{StopID: "nyc", DepartureSet: true, DepartureDelay: 300},
+			},
+		},
+	})
+	rt, err := gtfs.NewRealtime(context.Background(), static, feed)
+	require.NoError(t, err)
+
+	departures, err := rt.Departures(
+		"chi",
+		time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		2*time.Hour,
+		-1, "", -1, nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(departures))
+	assert.Equal(t, time.Date(2020, 1, 2, 1, 5, 0, 0, time.UTC), departures[0].Time.UTC())
+	assert.Equal(t, chicago, departures[0].Time.Location())
+	assert.Equal(t, "19:05", departures[0].Time.Format("15:04"))
+	assert.Equal(t, 5*time.Minute, departures[0].Delay)
+
+	// Absolute times are instants, unaffected by stop_timezone
+	feed = buildFeed(t, []TripUpdate{
+		{
+			TripID: "t1",
+			StopUpdates: []StopUpdate{
+				{StopID: "chi", DepartureSet: true, DepartureTime: time.Date(2020, 1, 2, 1, 10, 0, 0, time.UTC)},
+			},
+		},
+	})
+	rt, err = gtfs.NewRealtime(context.Background(), static, feed)
+	require.NoError(t, err)
+
+	departures, err = rt.Departures(
+		"chi",
+		time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		2*time.Hour,
+		-1, "", -1, nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(departures))
+	assert.Equal(t, "19:10", departures[0].Time.Format("15:04"))
+	assert.Equal(t, 10*time.Minute, departures[0].Delay)
+}
+
 func TestRealtimeDelayCrossingDSTBoundaryOnCurrentDay(t *testing.T) {
 	// Delays on trips crossing a DST boundary
 	static := testutil.BuildStatic(t, "sqlite", map[string][]string{
@@ -14,9 +14,18 @@ type Static struct {
 	Metadata *storage.FeedMetadata
 	Reader   storage.FeedReader
 
+	// If set, Departures includes the last stop of trips that
+	// continue as another trip in the same block.
+	BlockContinuations bool
+
 	minMaxStopSeqByTripID map[string][2]uint32
 	location              *time.Location
 	maxDeparture          time.Duration
+
+	// Frequency based trips, and the first departure time of
+	// their template stop_times.
+	frequenciesByTripID map[string][]model.Frequency
+	tripStartByTripID   map[string]time.Duration
 }
 
 func NewStatic(reader storage.FeedReader, metadata *storage.FeedMetadata) (*Static, error) {
@@ -39,12 +48,63 @@ func NewStatic(reader storage.FeedReader, metadata *storage.FeedMetadata) (*Stat
 	}
 	maxDeparture := time.Duration(mH)*time.Hour + time.Duration(mM)*time.Minute + time.Duration(mS)*time.Second
 
+	frequencies, err := reader.Frequencies()
+	if err != nil {
+		return nil, fmt.Errorf("getting frequencies: %w", err)
+	}
+	frequenciesByTripID := map[string][]model.Frequency{}
+	for _, f := range frequencies {
+		frequenciesByTripID[f.TripID] = append(frequenciesByTripID[f.TripID], f)
+	}
+
+	// The stop_times of frequency based trips are relative to the
+	// trip's first departure. Find that, and extend max departure
+	// to cover the last instance of each trip.
+	tripStartByTripID := map[string]time.Duration{}
+	if len(frequenciesByTripID) > 0 {
+		tripIDs := make([]string, 0, len(frequenciesByTripID))
+		for tripID := range frequenciesByTripID {
+			tripIDs = append(tripIDs, tripID)
+		}
+
+		events, err := reader.StopTimeEvents(storage.StopTimeEventFilter{
+			TripIDs:     tripIDs,
+			DirectionID: -1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting frequency based stop times: %w", err)
+		}
+
+		tripEndByTripID := map[string]time.Duration{}
+		for _, event := range events {
+			tripID := event.Trip.ID
+			if event.StopTime.StopSequence == minMaxStopSeqByTripID[tripID][0] {
+				tripStartByTripID[tripID] = event.StopTime.DepartureTime()
+			}
+			if event.StopTime.DepartureTime() > tripEndByTripID[tripID] {
+				tripEndByTripID[tripID] = event.StopTime.DepartureTime()
+			}
+		}
+
+		for tripID, freqs := range frequenciesByTripID {
+			duration := tripEndByTripID[tripID] - tripStartByTripID[tripID]
+			for _, f := range freqs {
+				lastStart := f.StartTime() + (f.EndTime()-f.StartTime()-1)/f.Headway()*f.Headway()
+				if lastStart+duration > maxDeparture {
+					maxDeparture = lastStart + duration
+				}
+			}
+		}
+	}
+
 	return &Static{
 		Metadata:              metadata,
 		Reader:                reader,
 		minMaxStopSeqByTripID: minMaxStopSeqByTripID,
 		location:              location,
 		maxDeparture:          maxDeparture,
+		frequenciesByTripID:   frequenciesByTripID,
+		tripStartByTripID:     tripStartByTripID,
 	}, nil
 }
 
@@ -54,16 +114,44 @@ func NewStatic(reader storage.FeedReader, metadata *storage.FeedMetadata) (*Stat
 //
 // If types is provided, then only stops along routes of at least one
 // of the types is returned. E.g., pass []model.RouteType{model.RouteTypeBus} to
-// only receive bus stops.
+// only receive bus stops. Basic types also match the extended route
+// types falling under them, so this includes stops served by e.g.
+// 700 (bus service) and 702 (express bus).
 //
 // Only stations (location_type=1) and stops (location_type=0)
 // _without_ parent station are returned.
-func (s Static) NearbyStops(lat float64, lon float64, limit int, types []model.RouteType) ([]model.Stop, error) {
+//
+// If langs are provided, stop names are translated into the first of
+// these languages for which translations.txt has a translation.
+func (s Static) NearbyStops(lat float64, lon float64, limit int, types []model.RouteType, langs ...string) ([]model.Stop, error) {
 	stops, err := s.Reader.NearbyStops(lat, lon, limit, types)
 	if err != nil {
 		return nil, fmt.Errorf("getting nearby stops: %w", err)
 	}
-	return stops, nil
+
+	return s.translateStopNames(stops, langs)
+}
+
+// Like NearbyStops, but only includes stops and stations known to
+// be wheelchair accessible.
+func (s Static) AccessibleNearbyStops(lat float64, lon float64, limit int, types []model.RouteType, langs ...string) ([]model.Stop, error) {
+	stops, err := s.Reader.NearbyStops(lat, lon, 0, types)
+	if err != nil {
+		return nil, fmt.Errorf("getting nearby stops: %w", err)
+	}
+
+	accessible := []model.Stop{}
+	for _, stop := range stops {
+		if stop.WheelchairBoarding != model.WheelchairAccessible {
+			continue
+		}
+		accessible = append(accessible, stop)
+		if limit > 0 && len(accessible) == limit {
+			break
+		}
+	}
+
+	return s.translateStopNames(accessible, langs)
 }
 
 // Returns all routes and direction for a stop
@@ -74,14 +162,369 @@ func (s Static) NearbyStops(lat float64, lon float64, limit int, types []model.R
 //
 // NOTE: Headsign can also be set on stop_time, which messes this up
 // quite a bit.
-func (s Static) RouteDirections(stopID string) ([]model.RouteDirection, error) {
+//
+// Results are ordered by route, as per route_sort_order where
+// provided, and direction.
+//
+// If langs are provided, headsigns and route names are translated as
+// in Departures.
+func (s Static) RouteDirections(stopID string, langs ...string) ([]model.RouteDirection, error) {
 	rds, err := s.Reader.RouteDirections(stopID)
 	if err != nil {
 		return nil, err
 	}
+
+	routes, err := s.Reader.StopRoutes(stopID)
+	if err != nil {
+		return nil, fmt.Errorf("getting routes: %w", err)
+	}
+	routeByID := map[string]model.Route{}
+	for _, route := range routes {
+		routeByID[route.ID] = route
+	}
+	sort.SliceStable(rds, func(i, j int) bool {
+		if rds[i].RouteID != rds[j].RouteID {
+			return routeLess(
+				routeByID[rds[i].RouteID],
+				routeByID[rds[j].RouteID],
+			)
+		}
+		return rds[i].DirectionID < rds[j].DirectionID
+	})
+
+	routeNames, err := s.routeNameTranslator(langs)
+	if err != nil {
+		return nil, err
+	}
+	for i, rd := range rds {
+		route := routeNames.translate(langs, routeByID[rd.RouteID])
+		rds[i].RouteShortName = route.ShortName
+		rds[i].RouteLongName = route.LongName
+	}
+
+	if len(langs) == 0 {
+		return rds, nil
+	}
+
+	headsigns, err := s.headsignTranslator(langs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Translations keyed by record need the trips and stop_times
+	// behind each headsign.
+	type key struct {
+		RouteID     string
+		DirectionID int8
+		Headsign    string
+	}
+	translated := map[key]string{}
+	if headsigns.hasRecords() {
+		events, err := s.Reader.StopTimeEvents(storage.StopTimeEventFilter{
+			StopID:      stopID,
+			DirectionID: -1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting stop time events: %w", err)
+		}
+		for _, event := range events {
+			headsign := event.StopTime.Headsign
+			if headsign == "" {
+				headsign = event.Trip.Headsign
+			}
+			k := key{event.Trip.RouteID, event.Trip.DirectionID, headsign}
+			if _, found := translated[k]; !found {
+				translated[k] = headsigns.translate(langs, event.Trip.ID, event.StopTime.StopSequence, event.StopTime.Headsign, event.Trip.Headsign)
+			}
+		}
+	}
+
+	for i, rd := range rds {
+		for j, headsign := range rd.Headsigns {
+			if tr, found := translated[key{rd.RouteID, rd.DirectionID, headsign}]; found {
+				rds[i].Headsigns[j] = tr
+			} else {
+				rds[i].Headsigns[j] = headsigns.translateValue(langs, headsign)
+			}
+		}
+	}
+
 	return rds, nil
 }
 
+// Returns all routes serving a stop, or any stop within it if it's a
+// station. Routes are ordered by route_sort_order where provided,
+// followed by those without, in order of route_id.
+//
+// If langs are provided, route names are translated into the first
+// language for which a translation is available.
+func (s Static) StopRoutes(stopID string, langs ...string) ([]model.Route, error) {
+	routes, err := s.Reader.StopRoutes(stopID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(langs) > 0 {
+		routeNames, err := s.routeNameTranslator(langs)
+		if err != nil {
+			return nil, err
+		}
+		for i, route := range routes {
+			routes[i] = routeNames.translate(langs, route)
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routeLess(routes[i], routes[j])
+	})
+
+	return routes, nil
+}
+
+// Orders routes as intended by the agency: by route_sort_order, with
+// routes lacking it last, and then by route_id.
+func routeLess(a, b model.Route) bool {
+	if a.HasSortOrder != b.HasSortOrder {
+		return a.HasSortOrder
+	}
+	if a.SortOrder != b.SortOrder {
+		return a.SortOrder < b.SortOrder
+	}
+	return a.ID < b.ID
+}
+
+// Returns the shape of a trip.
+//
+// If the trip lacks a shape_id, a shape is constructed from the
+// locations of the stops along the trip. Returns nil if the trip
+// doesn't exist.
+func (s Static) TripShape(tripID string) (*model.Shape, error) {
+	events, err := s.Reader.StopTimeEvents(storage.StopTimeEventFilter{
+		TripIDs:     []string{tripID},
+		DirectionID: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting stop time events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	if shapeID := events[0].Trip.ShapeID; shapeID != "" {
+		shape, err := s.Reader.Shape(shapeID)
+		if err != nil {
+			return nil, fmt.Errorf("getting shape: %w", err)
+		}
+		if shape != nil {
+			return shape, nil
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].StopTime.StopSequence < events[j].StopTime.StopSequence
+	})
+
+	shape := &model.Shape{}
+	for _, event := range events {
+		shape.Points = append(shape.Points, model.ShapePoint{
+			Lat:      event.Stop.Lat,
+			Lon:      event.Stop.Lon,
+			Sequence: event.StopTime.StopSequence,
+		})
+	}
+
+	return shape, nil
+}
+
+// Returns all shapes used by trips on a route, in a given direction
+// (pass -1 for all directions). The most commonly used shape comes
+// first.
+func (s Static) RouteShapes(routeID string, directionID int8) ([]*model.Shape, error) {
+	shapeIDs, err := s.Reader.RouteShapeIDs(routeID, int(directionID))
+	if err != nil {
+		return nil, fmt.Errorf("getting shape IDs: %w", err)
+	}
+
+	shapes := []*model.Shape{}
+	for _, shapeID := range shapeIDs {
+		shape, err := s.Reader.Shape(shapeID)
+		if err != nil {
+			return nil, fmt.Errorf("getting shape %s: %w", shapeID, err)
+		}
+		if shape != nil {
+			shapes = append(shapes, shape)
+		}
+	}
+
+	return shapes, nil
+}
+
+// Returns all transfers from a stop. Transfers from the stop's parent
+// station are included.
+func (s Static) TransfersFromStop(stopID string) ([]model.Transfer, error) {
+	transfers, err := s.Reader.Transfers(storage.TransferFilter{FromStopID: stopID})
+	if err != nil {
+		return nil, fmt.Errorf("getting transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// Returns all transfers from a trip. This includes in-seat
+// transfers (transfer_type 4), where riders can remain on board
+// while the vehicle continues as another trip, and transfers where
+// doing so is explicitly not allowed (transfer_type 5).
+func (s Static) TransfersFromTrip(tripID string) ([]model.Transfer, error) {
+	transfers, err := s.Reader.Transfers(storage.TransferFilter{FromTripID: tripID})
+	if err != nil {
+		return nil, fmt.Errorf("getting transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// Returns the fares (as per fare_attributes.txt and fare_rules.txt)
+// applicable for riding a route from origin to destination at time
+// t, cheapest first. No fares apply outside the period the feed is
+// valid for, as given by feed_info.txt or else by its calendar.
+//
+// Stops lacking a zone_id inherit the zone of their parent
+// station. Since only the origin and destination are known, fares
+// using contains_id apply when their set of contained zones equals
+// that of the origin and destination. Fares without any rules apply
+// to all routes of their agency.
+func (s Static) Fares(originStopID string, destinationStopID string, routeID string, t time.Time) ([]model.FareAttribute, error) {
+	originZone, err := s.stopZone(originStopID)
+	if err != nil {
+		return nil, err
+	}
+	destinationZone, err := s.stopZone(destinationStopID)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := s.Reader.Routes()
+	if err != nil {
+		return nil, fmt.Errorf("getting routes: %w", err)
+	}
+	var route *model.Route
+	for i := range routes {
+		if routes[i].ID == routeID {
+			route = &routes[i]
+			break
+		}
+	}
+	if route == nil {
+		return nil, fmt.Errorf("unknown route '%s'", routeID)
+	}
+
+	active, err := feedActive(s.Metadata, t)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return []model.FareAttribute{}, nil
+	}
+
+	fares, err := s.Reader.FareAttributes()
+	if err != nil {
+		return nil, fmt.Errorf("getting fare attributes: %w", err)
+	}
+	rules, err := s.Reader.FareRules()
+	if err != nil {
+		return nil, fmt.Errorf("getting fare rules: %w", err)
+	}
+
+	rulesByFare := map[string][]model.FareRule{}
+	containsByFare := map[string]map[string]bool{}
+	for _, rule := range rules {
+		rulesByFare[rule.FareID] = append(rulesByFare[rule.FareID], rule)
+		if rule.ContainsID != "" {
+			if containsByFare[rule.FareID] == nil {
+				containsByFare[rule.FareID] = map[string]bool{}
+			}
+			containsByFare[rule.FareID][rule.ContainsID] = true
+		}
+	}
+
+	legZones := map[string]bool{}
+	for _, zone := range []string{originZone, destinationZone} {
+		if zone != "" {
+			legZones[zone] = true
+		}
+	}
+
+	applicable := []model.FareAttribute{}
+	for _, fare := range fares {
+		if fare.AgencyID != "" && route.AgencyID != "" && fare.AgencyID != route.AgencyID {
+			continue
+		}
+
+		fareRules := rulesByFare[fare.ID]
+		matched := len(fareRules) == 0
+		for _, rule := range fareRules {
+			if rule.RouteID != "" && rule.RouteID != routeID {
+				continue
+			}
+			if rule.OriginID != "" && rule.OriginID != originZone {
+				continue
+			}
+			if rule.DestinationID != "" && rule.DestinationID != destinationZone {
+				continue
+			}
+			if rule.ContainsID != "" && !sameZones(containsByFare[fare.ID], legZones) {
+				continue
+			}
+			matched = true
+			break
+		}
+
+		if matched {
+			applicable = append(applicable, fare)
+		}
+	}
+
+	sort.SliceStable(applicable, func(i, j int) bool {
+		return applicable[i].Price < applicable[j].Price
+	})
+
+	return applicable, nil
+}
+
+// Returns the zone ID of a stop, falling back to that of its parent
+// station.
+func (s Static) stopZone(stopID string) (string, error) {
+	stop, err := s.Reader.Stop(stopID)
+	if err != nil {
+		return "", fmt.Errorf("getting stop: %w", err)
+	}
+	if stop == nil {
+		return "", fmt.Errorf("unknown stop '%s'", stopID)
+	}
+
+	if stop.ZoneID == "" && stop.ParentStation != "" {
+		parent, err := s.Reader.Stop(stop.ParentStation)
+		if err != nil {
+			return "", fmt.Errorf("getting parent station: %w", err)
+		}
+		if parent != nil {
+			return parent.ZoneID, nil
+		}
+	}
+
+	return stop.ZoneID, nil
+}
+
+func sameZones(a map[string]bool, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for zone := range a {
+		if !b[zone] {
+			return false
+		}
+	}
+	return true
+}
+
 // Translates a time offset into a GTFS style HHMMSS string.
 func gtfsDate(offset time.Duration) string {
 	h := int(offset.Hours())
@@ -163,6 +606,36 @@ func rangePerDate(start time.Time, window time.Duration, maxTrip time.Duration)
 // - numDepartures (if >= 0) limits the number of results
 // - routeID (if != "") limits results to a route
 // - directionID (if >= 0) limits results to a directionID
+// - routeTypes (if provided) limits results to routes of these types
+//
+// Basic route types in routeTypes also match the extended route
+// types falling under them, e.g. model.RouteTypeRail matches 109
+// (suburban railway).
+//
+// Stop times where pickup is unavailable are excluded. Where pickup
+// must be arranged with the agency or the driver, PickupType says
+// so.
+//
+// If BlockContinuations is set, the last stop of a trip is included
+// when the vehicle continues as the next trip in the same block. The
+// departure then carries the next trip's headsign, and its trip and
+// route IDs are given in ContinuesAsTripID and ContinuesAsRouteID.
+//
+// Departures at stops that aren't timepoints, including those with
+// times interpolated during parsing, have Approximate set.
+//
+// Frequency based trips are expanded into one departure per
+// headway. For trips without exact times, these departures have
+// HeadwayBased set.
+//
+// Departure times are given in windowStart's timezone, except at
+// stops with a stop_timezone, where that timezone is used. As per
+// the spec, stop_times are relative to the agency's timezone
+// regardless.
+//
+// If langs are provided, headsigns and route names are translated
+// into the first of these languages for which translations.txt has a
+// translation.
 func (s Static) Departures(
 	stopID string,
 	windowStart time.Time,
@@ -171,6 +644,66 @@ func (s Static) Departures(
 	routeID string,
 	directionID int8,
 	routeTypes []model.RouteType,
+	langs ...string,
+) ([]model.Departure, error) {
+	return s.departures(stopID, windowStart, windowLength, numDepartures, routeID, directionID, routeTypes, AmenityFilter{}, langs)
+}
+
+// Restricts departures to those with certain amenities. Departures
+// lacking information on an amenity are excluded when it's required.
+type AmenityFilter struct {
+	// Both stop and trip must be wheelchair accessible. A stop
+	// within a station lacking wheelchair_boarding inherits the
+	// station's.
+	WheelchairAccessible bool
+
+	// The trip must allow bikes, or cars.
+	BikesAllowed bool
+	CarsAllowed  bool
+}
+
+// Like Departures, but only includes departures with the amenities
+// required by the filter.
+func (s Static) DeparturesWithAmenities(
+	stopID string,
+	windowStart time.Time,
+	windowLength time.Duration,
+	numDepartures int,
+	routeID string,
+	directionID int8,
+	routeTypes []model.RouteType,
+	amenities AmenityFilter,
+	langs ...string,
+) ([]model.Departure, error) {
+	return s.departures(stopID, windowStart, windowLength, numDepartures, routeID, directionID, routeTypes, amenities, langs)
+}
+
+// Like Departures, but only includes departures where both the stop
+// and the trip are known to be wheelchair accessible. A stop within
+// a station lacking wheelchair_boarding inherits the station's.
+func (s Static) AccessibleDepartures(
+	stopID string,
+	windowStart time.Time,
+	windowLength time.Duration,
+	numDepartures int,
+	routeID string,
+	directionID int8,
+	routeTypes []model.RouteType,
+	langs ...string,
+) ([]model.Departure, error) {
+	return s.departures(stopID, windowStart, windowLength, numDepartures, routeID, directionID, routeTypes, AmenityFilter{WheelchairAccessible: true}, langs)
+}
+
+func (s Static) departures(
+	stopID string,
+	windowStart time.Time,
+	windowLength time.Duration,
+	numDepartures int,
+	routeID string,
+	directionID int8,
+	routeTypes []model.RouteType,
+	amenities AmenityFilter,
+	langs []string,
 ) ([]model.Departure, error) {
 
 	departures := []model.Departure{}
@@ -181,11 +714,24 @@ func (s Static) Departures(
 
 	// All computations are done in the GTFS timezone, but
 	// Departure.Time will be returned in the timezone used by
-	// caller.
+	// caller, or the stop's timezone if it has one.
 	origTz := windowStart.Location()
+	stopTimezones := map[string]string{}
 	startTime := windowStart.In(s.location)
 	endTime := startTime.Add(windowLength)
 
+	// Headsigns and route names are translated as departures are
+	// found, since a headsign may come from the next trip in the
+	// block. Nothing is loaded without langs.
+	headsigns, err := s.headsignTranslator(langs)
+	if err != nil {
+		return nil, err
+	}
+	routeNames, err := s.routeNameTranslator(langs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Query for departures for each day in the window
 	for _, span := range rangePerDate(startTime, windowLength, s.maxDeparture) {
 
@@ -216,41 +762,116 @@ func (s Static) Departures(
 			return events[i].StopTime.DepartureTime() < events[j].StopTime.DepartureTime()
 		})
 
+		date, _ := time.ParseInLocation("20060102", span.Date, s.location)
+		dateNoon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, s.location)
+
 		for _, event := range events {
 
+			// Frequency based trips are handled below
+			if s.frequenciesByTripID[event.Trip.ID] != nil {
+				continue
+			}
+
 			// Compute the departure time in original timezone
-			date, _ := time.ParseInLocation("20060102", span.Date, s.location)
-			dateNoon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, s.location)
 			departureTime := dateNoon.Add(-12 * time.Hour).Add(event.StopTime.DepartureTime()).In(origTz)
 			if departureTime.After(endTime) {
 				// TODO: this shouldn't be possible
 				break
 			}
 
-			// Ignore the last stop on a trip, since it's not a
-			// boardable departure.
+			// Ignore the last stop on a trip, and stops where
+			// pickup isn't available, since they're not
+			// boardable departures. The exception is when
+			// the vehicle continues as the next trip in
+			// the block.
+			var continuation *model.Trip
 			minMaxSeq := s.minMaxStopSeqByTripID[event.Trip.ID]
 			if event.StopTime.StopSequence >= uint32(minMaxSeq[1]) {
+				if !s.BlockContinuations || event.Trip.BlockID == "" {
+					continue
+				}
+				continuation, err = s.Reader.NextTripInBlock(event.Trip.ID, serviceIDs)
+				if err != nil {
+					return nil, fmt.Errorf("getting next trip in block: %w", err)
+				}
+				if continuation == nil {
+					continue
+				}
+			}
+			if event.StopTime.PickupType == model.PickupDropOffNone {
 				continue
 			}
 
-			headsign := event.StopTime.Headsign
-			if headsign == "" {
-				headsign = event.Trip.Headsign
+			if !startTime.After(departureTime) {
+				headsign := headsigns.translate(langs, event.Trip.ID, event.StopTime.StopSequence, event.StopTime.Headsign, event.Trip.Headsign)
+				if continuation != nil && continuation.Headsign != "" {
+					headsign = headsigns.translateTrip(langs, continuation.ID, continuation.Headsign)
+				}
+				route := routeNames.translate(langs, event.Route)
+
+				stopTimezones[event.Stop.ID] = stopTimezone(event)
+				departure := model.Departure{
+					StopID:         event.Stop.ID,
+					RouteID:        event.Trip.RouteID,
+					TripID:         event.Trip.ID,
+					StopSequence:   event.StopTime.StopSequence,
+					DirectionID:    event.Trip.DirectionID,
+					Time:           departureTime,
+					Headsign:       headsign,
+					RouteShortName: route.ShortName,
+					RouteLongName:  route.LongName,
+					Approximate:    event.StopTime.Approximate,
+					PickupType:     event.StopTime.PickupType,
+
+					WheelchairBoarding:   stopWheelchairBoarding(event),
+					WheelchairAccessible: event.Trip.WheelchairAccessible,
+					BikesAllowed:         event.Trip.BikesAllowed,
+					CarsAllowed:          event.Trip.CarsAllowed,
+
+					ContinuousPickup:  continuousPickup(event),
+					ContinuousDropOff: continuousDropOff(event),
+				}
+				if continuation != nil {
+					departure.ContinuesAsTripID = continuation.ID
+					departure.ContinuesAsRouteID = continuation.RouteID
+				}
+				departures = append(departures, departure)
 			}
+		}
 
-			if !startTime.After(departureTime) {
-				departures = append(departures, model.Departure{
-					StopID:       event.Stop.ID,
-					RouteID:      event.Trip.RouteID,
-					TripID:       event.Trip.ID,
-					StopSequence: event.StopTime.StopSequence,
-					DirectionID:  event.Trip.DirectionID,
-					Time:         departureTime,
-					Headsign:     headsign,
-				})
+		freqDepartures, err := s.frequencyDepartures(
+			storage.StopTimeEventFilter{
+				StopID:      stopID,
+				DirectionID: int(directionID),
+				ServiceIDs:  serviceIDs,
+				RouteID:     routeID,
+				RouteTypes:  routeTypes,
+			},
+			dateNoon,
+			startTime,
+			endTime,
+			stopTimezones,
+			headsigns,
+			routeNames,
+			langs,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range freqDepartures {
+			d.Time = d.Time.In(origTz)
+			departures = append(departures, d)
+		}
+	}
+
+	if amenities != (AmenityFilter{}) {
+		filtered := []model.Departure{}
+		for _, d := range departures {
+			if amenities.match(d) {
+				filtered = append(filtered, d)
 			}
 		}
+		departures = filtered
 	}
 
 	// Sort by departure time
@@ -262,5 +883,158 @@ func (s Static) Departures(
 		departures = departures[:numDepartures]
 	}
 
+	// Departures from stops with a stop_timezone are presented in
+	// that timezone.
+	locations := map[string]*time.Location{}
+	for i, d := range departures {
+		tz := stopTimezones[d.StopID]
+		if tz == "" {
+			continue
+		}
+		if locations[tz] == nil {
+			location, err := time.LoadLocation(tz)
+			if err != nil {
+				return nil, fmt.Errorf("loading stop timezone: %w", err)
+			}
+			locations[tz] = location
+		}
+		departures[i].Time = d.Time.In(locations[tz])
+	}
+
 	return departures, nil
 }
+
+// Expands the frequency based trips matching filter into departures
+// on the day of dateNoon, within [startTime, endTime]. The
+// timezones of stops departed from are recorded in stopTimezones.
+// Headsigns and route names are translated into langs.
+func (s Static) frequencyDepartures(
+	filter storage.StopTimeEventFilter,
+	dateNoon time.Time,
+	startTime time.Time,
+	endTime time.Time,
+	stopTimezones map[string]string,
+	headsigns *headsignTranslator,
+	routeNames *routeNameTranslator,
+	langs []string,
+) ([]model.Departure, error) {
+	departures := []model.Departure{}
+
+	if len(s.frequenciesByTripID) == 0 {
+		return departures, nil
+	}
+
+	filter.TripIDs = make([]string, 0, len(s.frequenciesByTripID))
+	for tripID := range s.frequenciesByTripID {
+		filter.TripIDs = append(filter.TripIDs, tripID)
+	}
+
+	events, err := s.Reader.StopTimeEvents(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		minMaxSeq := s.minMaxStopSeqByTripID[event.Trip.ID]
+		if event.StopTime.StopSequence >= minMaxSeq[1] {
+			continue
+		}
+		if event.StopTime.PickupType == model.PickupDropOffNone {
+			continue
+		}
+
+		headsign := headsigns.translate(langs, event.Trip.ID, event.StopTime.StopSequence, event.StopTime.Headsign, event.Trip.Headsign)
+		route := routeNames.translate(langs, event.Route)
+
+		offset := event.StopTime.DepartureTime() - s.tripStartByTripID[event.Trip.ID]
+
+		for _, f := range s.frequenciesByTripID[event.Trip.ID] {
+			for tripStart := f.StartTime(); tripStart < f.EndTime(); tripStart += f.Headway() {
+				departureTime := dateNoon.Add(-12 * time.Hour).Add(tripStart + offset)
+				if departureTime.Before(startTime) {
+					continue
+				}
+				if departureTime.After(endTime) {
+					break
+				}
+				stopTimezones[event.Stop.ID] = stopTimezone(event)
+				departures = append(departures, model.Departure{
+					StopID:         event.Stop.ID,
+					RouteID:        event.Trip.RouteID,
+					TripID:         event.Trip.ID,
+					StopSequence:   event.StopTime.StopSequence,
+					DirectionID:    event.Trip.DirectionID,
+					Time:           departureTime,
+					Headsign:       headsign,
+					RouteShortName: route.ShortName,
+					RouteLongName:  route.LongName,
+					HeadwayBased:   !f.ExactTimes,
+					Approximate:    event.StopTime.Approximate,
+					PickupType:     event.StopTime.PickupType,
+
+					WheelchairBoarding:   stopWheelchairBoarding(event),
+					WheelchairAccessible: event.Trip.WheelchairAccessible,
+					BikesAllowed:         event.Trip.BikesAllowed,
+					CarsAllowed:          event.Trip.CarsAllowed,
+
+					ContinuousPickup:  continuousPickup(event),
+					ContinuousDropOff: continuousDropOff(event),
+				})
+			}
+		}
+	}
+
+	return departures, nil
+}
+
+func (f AmenityFilter) match(d model.Departure) bool {
+	if f.WheelchairAccessible {
+		if d.WheelchairBoarding != model.WheelchairAccessible ||
+			d.WheelchairAccessible != model.WheelchairAccessible {
+			return false
+		}
+	}
+	if f.BikesAllowed && d.BikesAllowed != model.AmenityAllowed {
+		return false
+	}
+	if f.CarsAllowed && d.CarsAllowed != model.AmenityAllowed {
+		return false
+	}
+	return true
+}
+
+// Returns the continuous_pickup applying after the stop of a stop
+// time event. The stop time's overrides the route's.
+func continuousPickup(event *storage.StopTimeEvent) model.ContinuousStopping {
+	if event.StopTime.HasContinuousPickup {
+		return event.StopTime.ContinuousPickup
+	}
+	return event.Route.ContinuousPickup
+}
+
+// Like continuousPickup, but for continuous_drop_off.
+func continuousDropOff(event *storage.StopTimeEvent) model.ContinuousStopping {
+	if event.StopTime.HasContinuousDropOff {
+		return event.StopTime.ContinuousDropOff
+	}
+	return event.Route.ContinuousDropOff
+}
+
+// Returns the stop_timezone applying to a stop time event, or "" if
+// the agency's timezone applies. Stops within a station use the
+// station's timezone rather than their own.
+func stopTimezone(event *storage.StopTimeEvent) string {
+	if event.Stop.ParentStation != "" {
+		return event.ParentStation.Timezone
+	}
+	return event.Stop.Timezone
+}
+
+// Returns the wheelchair_boarding applying to a stop time event.
+// Stops within a station lacking the field inherit the station's.
+func stopWheelchairBoarding(event *storage.StopTimeEvent) model.WheelchairAccessibility {
+	if event.Stop.WheelchairBoarding == model.WheelchairNoInfo && event.Stop.ParentStation != "" {
+		return event.ParentStation.WheelchairBoarding
+	}
+	return event.Stop.WheelchairBoarding
+}
@@ -2,12 +2,14 @@ package gtfs_test
 
 import (
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"tidbyt.dev/gtfs"
 	"tidbyt.dev/gtfs/model"
 	"tidbyt.dev/gtfs/testutil"
 )
@@ -76,19 +78,21 @@ func testStaticDeparturesWindowing(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LW1",
-			DirectionID:  1,
-			StopSequence: 2,
-			Time:         time.Date(2020, 2, 4, 6, 12, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LW1",
+			DirectionID:    1,
+			StopSequence:   2,
+			Time:           time.Date(2020, 2, 4, 6, 12, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LE2",
-			StopSequence: 102,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 4, 6, 24, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE2",
+			StopSequence:   102,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 4, 6, 24, 0, 0, time.UTC)},
 	}, departures)
 
 	// Extend the window to 50 minutes and we capture 2 extra L
@@ -98,47 +102,53 @@ func testStaticDeparturesWindowing(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LW1",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 2, 4, 6, 12, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LW1",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 2, 4, 6, 12, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LE2",
-			StopSequence: 102,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 4, 6, 24, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE2",
+			StopSequence:   102,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 4, 6, 24, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LW2",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 2, 4, 6, 32, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LW2",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 2, 4, 6, 32, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "F",
-			TripID:       "FN1",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 2, 4, 6, 35, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "F",
+			RouteShortName: "f",
+			TripID:         "FN1",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 2, 4, 6, 35, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LE3",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 4, 6, 44, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE3",
+			StopSequence:   2,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 4, 6, 44, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "F",
-			TripID:       "FS1",
-			StopSequence: 11,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 4, 6, 50, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "F",
+			RouteShortName: "f",
+			TripID:         "FS1",
+			StopSequence:   11,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 4, 6, 50, 0, 0, time.UTC)},
 	}, departures)
 
 	// Start window at 6:30 and earlier departures are cut
@@ -146,33 +156,37 @@ func testStaticDeparturesWindowing(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LW2",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 2, 4, 6, 32, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LW2",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 2, 4, 6, 32, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "F",
-			TripID:       "FN1",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 2, 4, 6, 35, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "F",
+			RouteShortName: "f",
+			TripID:         "FN1",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 2, 4, 6, 35, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LE3",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 4, 6, 44, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE3",
+			StopSequence:   2,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 4, 6, 44, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "F",
-			TripID:       "FS1",
-			StopSequence: 11,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 4, 6, 50, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "F",
+			RouteShortName: "f",
+			TripID:         "FS1",
+			StopSequence:   11,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 4, 6, 50, 0, 0, time.UTC)},
 	}, departures)
 
 	// Push window past last departure and we get nothing
@@ -190,33 +204,37 @@ func testStaticDeparturesWindowing(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LW1",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 2, 5, 6, 12, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LW1",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 2, 5, 6, 12, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LE2",
-			StopSequence: 102,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 5, 6, 24, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE2",
+			StopSequence:   102,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 5, 6, 24, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LW2",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 2, 5, 6, 32, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LW2",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 2, 5, 6, 32, 0, 0, time.UTC)},
 		{
-			StopID:       "14",
-			RouteID:      "F",
-			TripID:       "FN1",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 2, 5, 6, 35, 0, 0, time.UTC)},
+			StopID:         "14",
+			RouteID:        "F",
+			RouteShortName: "f",
+			TripID:         "FN1",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 2, 5, 6, 35, 0, 0, time.UTC)},
 	}, departures)
 
 	// Outside of calendar, we get nothing (Jan 1st 2021 was a Friday)
@@ -273,12 +291,13 @@ func testStaticDeparturesWeekendSchedule(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "6a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 14, 9, 5, 0, 0, time.UTC),
+			StopID:        "6a",
+			RouteID:       "L",
+			RouteLongName: "The ELL",
+			TripID:        "LE1",
+			StopSequence:  2,
+			DirectionID:   0,
+			Time:          time.Date(2020, 2, 14, 9, 5, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -287,12 +306,13 @@ func testStaticDeparturesWeekendSchedule(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "6a",
-			RouteID:      "L",
-			TripID:       "LE2",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 15, 9, 6, 0, 0, time.UTC),
+			StopID:        "6a",
+			RouteID:       "L",
+			RouteLongName: "The ELL",
+			TripID:        "LE2",
+			StopSequence:  2,
+			DirectionID:   0,
+			Time:          time.Date(2020, 2, 15, 9, 6, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -301,20 +321,22 @@ func testStaticDeparturesWeekendSchedule(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "6a",
-			RouteID:      "L",
-			TripID:       "LW1",
-			StopSequence: 3,
-			DirectionID:  1,
-			Time:         time.Date(2020, 2, 14, 9, 30, 0, 0, time.UTC),
+			StopID:        "6a",
+			RouteID:       "L",
+			RouteLongName: "The ELL",
+			TripID:        "LW1",
+			StopSequence:  3,
+			DirectionID:   1,
+			Time:          time.Date(2020, 2, 14, 9, 30, 0, 0, time.UTC),
 		},
 		{
-			StopID:       "6a",
-			RouteID:      "L",
-			TripID:       "LE2",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 15, 9, 6, 0, 0, time.UTC),
+			StopID:        "6a",
+			RouteID:       "L",
+			RouteLongName: "The ELL",
+			TripID:        "LE2",
+			StopSequence:  2,
+			DirectionID:   0,
+			Time:          time.Date(2020, 2, 15, 9, 6, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -358,12 +380,13 @@ func testStaticDeparturesTimezones(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "6a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 3, 9, 5, 0, 0, tzNYC),
+			StopID:         "6a",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE1",
+			StopSequence:   2,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 3, 9, 5, 0, 0, tzNYC),
 		},
 	}, departures)
 
@@ -372,12 +395,13 @@ func testStaticDeparturesTimezones(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "6a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 3, 14, 5, 0, 0, time.UTC),
+			StopID:         "6a",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE1",
+			StopSequence:   2,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 3, 14, 5, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -387,12 +411,13 @@ func testStaticDeparturesTimezones(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "6a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 3, 14, 5, 0, 0, time.UTC),
+			StopID:         "6a",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE1",
+			StopSequence:   2,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 3, 14, 5, 0, 0, time.UTC),
 		},
 	}, departures)
 }
@@ -435,12 +460,13 @@ func testStaticDeparturesOvernightTrip(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "3a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 4,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 10, 0, 30, 0, 0, tzNYC),
+			StopID:         "3a",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE1",
+			StopSequence:   4,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 10, 0, 30, 0, 0, tzNYC),
 		},
 	}, departures)
 
@@ -449,12 +475,13 @@ func testStaticDeparturesOvernightTrip(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "3a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 4,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 10, 0, 30, 0, 0, tzNYC),
+			StopID:         "3a",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE1",
+			StopSequence:   4,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 10, 0, 30, 0, 0, tzNYC),
 		},
 	}, departures)
 
@@ -464,24 +491,26 @@ func testStaticDeparturesOvernightTrip(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "3a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 4,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 10, 5, 30, 0, 0, time.UTC),
+			StopID:         "3a",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE1",
+			StopSequence:   4,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 10, 5, 30, 0, 0, time.UTC),
 		},
 	}, departures)
 	departures, err = g.Departures("3a", time.Date(2020, 2, 10, 5, 15, 0, 0, time.UTC), 20*time.Minute, -1, "", -1, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "3a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 4,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 10, 5, 30, 0, 0, time.UTC),
+			StopID:         "3a",
+			RouteID:        "L",
+			RouteShortName: "l",
+			TripID:         "LE1",
+			StopSequence:   4,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 10, 5, 30, 0, 0, time.UTC),
 		},
 	}, departures)
 
@@ -535,12 +564,13 @@ func testStaticDeparturesCalendarDateOverride(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "8a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			DirectionID:  0,
-			StopSequence: 1,
-			Time:         time.Date(2020, 2, 9, 23, 0, 0, 0, tzNYC)},
+			StopID:         "8a",
+			RouteID:        "L",
+			RouteShortName: "L",
+			TripID:         "LE1",
+			DirectionID:    0,
+			StopSequence:   1,
+			Time:           time.Date(2020, 2, 9, 23, 0, 0, 0, tzNYC)},
 	}, departures)
 	departures, err = g.Departures("8a", time.Date(2020, 2, 8, 22, 0, 0, 0, tzNYC), 5*time.Hour, -1, "", -1, nil)
 	assert.NoError(t, err)
@@ -563,22 +593,24 @@ func testStaticDeparturesCalendarDateOverride(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "8a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 1,
-			Time:         time.Date(2020, 2, 15, 23, 0, 0, 0, tzNYC),
+			StopID:         "8a",
+			RouteID:        "L",
+			RouteShortName: "L",
+			TripID:         "LE1",
+			StopSequence:   1,
+			Time:           time.Date(2020, 2, 15, 23, 0, 0, 0, tzNYC),
 		},
 	}, departures)
 	departures, err = g.Departures("3a", time.Date(2020, 2, 15, 22, 0, 0, 0, tzNYC), 5*time.Hour, -1, "", -1, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "3a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 4,
-			Time:         time.Date(2020, 2, 16, 0, 30, 0, 0, tzNYC),
+			StopID:         "3a",
+			RouteID:        "L",
+			RouteShortName: "L",
+			TripID:         "LE1",
+			StopSequence:   4,
+			Time:           time.Date(2020, 2, 16, 0, 30, 0, 0, tzNYC),
 		},
 	}, departures)
 
@@ -588,23 +620,25 @@ func testStaticDeparturesCalendarDateOverride(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "8a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 1,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 24, 23, 0, 0, 0, tzNYC)},
+			StopID:         "8a",
+			RouteID:        "L",
+			RouteShortName: "L",
+			TripID:         "LE1",
+			StopSequence:   1,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 24, 23, 0, 0, 0, tzNYC)},
 	}, departures)
 	departures, err = g.Departures("3a", time.Date(2020, 2, 24, 22, 0, 0, 0, tzNYC), 5*time.Hour, -1, "", -1, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "3a",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 4,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 25, 0, 30, 0, 0, tzNYC)},
+			StopID:         "3a",
+			RouteID:        "L",
+			RouteShortName: "L",
+			TripID:         "LE1",
+			StopSequence:   4,
+			DirectionID:    0,
+			Time:           time.Date(2020, 2, 25, 0, 30, 0, 0, tzNYC)},
 	}, departures)
 	departures, err = g.Departures("8a", time.Date(2020, 2, 25, 22, 0, 0, 0, tzNYC), 5*time.Hour, -1, "", -1, nil)
 	assert.NoError(t, err)
@@ -654,12 +688,13 @@ func testStaticDeparturesNoDepartureFromFinalStop(t *testing.T, backend string)
 
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "14",
-			RouteID:      "L",
-			TripID:       "LE1",
-			StopSequence: 3,
-			DirectionID:  0,
-			Time:         time.Date(2020, 2, 10, 0, 0, 0, 0, tzNYC)},
+			StopID:        "14",
+			RouteID:       "L",
+			RouteLongName: "The L",
+			TripID:        "LE1",
+			StopSequence:  3,
+			DirectionID:   0,
+			Time:          time.Date(2020, 2, 10, 0, 0, 0, 0, tzNYC)},
 	}, departures)
 
 	departures, err = g.Departures("3a", time.Date(2020, 2, 9, 23, 0, 0, 0, tzNYC), 2*time.Hour, -1, "", -1, nil)
@@ -732,12 +767,13 @@ func testStaticDeparturesFiltering(t *testing.T, backend string) {
 	assert.Equal(t, 1, len(departures))
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "alpha",
-			RouteID:      "RouteA",
-			TripID:       "A1",
-			StopSequence: 1,
-			DirectionID:  0,
-			Time:         time.Date(2020, 3, 14, 5, 30, 0, 0, tzNYC)},
+			StopID:         "alpha",
+			RouteID:        "RouteA",
+			RouteShortName: "a",
+			TripID:         "A1",
+			StopSequence:   1,
+			DirectionID:    0,
+			Time:           time.Date(2020, 3, 14, 5, 30, 0, 0, tzNYC)},
 	}, departures)
 
 	// Specifying non-existent route and/or direction -> no results
@@ -756,24 +792,26 @@ func testStaticDeparturesFiltering(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "beta",
-			RouteID:      "RouteA",
-			TripID:       "A1",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 3, 14, 6, 0, 0, 0, tzNYC)},
+			StopID:         "beta",
+			RouteID:        "RouteA",
+			RouteShortName: "a",
+			TripID:         "A1",
+			StopSequence:   2,
+			DirectionID:    0,
+			Time:           time.Date(2020, 3, 14, 6, 0, 0, 0, tzNYC)},
 	}, departures)
 
 	departures, err = g.Departures("beta", time.Date(2020, 3, 14, 0, 0, 0, 0, tzNYC), longDuration, 1, "", 1, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "beta",
-			RouteID:      "RouteA",
-			TripID:       "a1",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 3, 14, 6, 1, 0, 0, tzNYC)},
+			StopID:         "beta",
+			RouteID:        "RouteA",
+			RouteShortName: "a",
+			TripID:         "a1",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 3, 14, 6, 1, 0, 0, tzNYC)},
 	}, departures)
 
 	// Pushing start time back discards earlier departures
@@ -781,23 +819,25 @@ func testStaticDeparturesFiltering(t *testing.T, backend string) {
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "beta",
-			RouteID:      "RouteA",
-			TripID:       "A2",
-			StopSequence: 2,
-			DirectionID:  0,
-			Time:         time.Date(2020, 3, 14, 13, 0, 0, 0, tzNYC)},
+			StopID:         "beta",
+			RouteID:        "RouteA",
+			RouteShortName: "a",
+			TripID:         "A2",
+			StopSequence:   2,
+			DirectionID:    0,
+			Time:           time.Date(2020, 3, 14, 13, 0, 0, 0, tzNYC)},
 	}, departures)
 	departures, err = g.Departures("beta", time.Date(2020, 3, 14, 12, 0, 0, 0, tzNYC), longDuration, 1, "RouteA", 1, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, []model.Departure{
 		{
-			StopID:       "beta",
-			RouteID:      "RouteA",
-			TripID:       "a2",
-			StopSequence: 2,
-			DirectionID:  1,
-			Time:         time.Date(2020, 3, 14, 13, 1, 0, 0, tzNYC)},
+			StopID:         "beta",
+			RouteID:        "RouteA",
+			RouteShortName: "a",
+			TripID:         "a2",
+			StopSequence:   2,
+			DirectionID:    1,
+			Time:           time.Date(2020, 3, 14, 13, 1, 0, 0, tzNYC)},
 	}, departures)
 
 	// Requesting a whole lot of departures results in a whole lot of departures
@@ -1102,6 +1142,1363 @@ func testStaticDeparturesDaylightsSavings(t *testing.T, backend string) {
 
 }
 
+func testStaticShapes(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "R,r,3"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"a,a,10,10",
+			"b,b,20,20",
+			"c,c,30,30",
+		},
+		"shapes.txt": {
+			"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence",
+			"abc,10,10,1",
+			"abc,15,16,2",
+			"abc,20,20,3",
+			"abc,30,30,4",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id,direction_id,shape_id",
+			"t1,R,all,0,abc",
+			"t2,R,all,0,abc",
+			"t3,R,all,1,",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,departure_time,arrival_time,stop_sequence",
+			"t1,a,6:00:00,6:00:00,1",
+			"t1,b,6:10:00,6:10:00,2",
+			"t1,c,6:20:00,6:20:00,3",
+			"t2,a,7:00:00,7:00:00,1",
+			"t2,b,7:10:00,7:10:00,2",
+			"t2,c,7:20:00,7:20:00,3",
+			"t3,c,8:00:00,8:00:00,5",
+			"t3,a,8:20:00,8:20:00,7",
+		},
+	})
+
+	abc := &model.Shape{
+		ID: "abc",
+		Points: []model.ShapePoint{
+			{Lat: 10, Lon: 10, Sequence: 1},
+			{Lat: 15, Lon: 16, Sequence: 2},
+			{Lat: 20, Lon: 20, Sequence: 3},
+			{Lat: 30, Lon: 30, Sequence: 4},
+		},
+	}
+
+	// Trip with a shape_id gets the shape
+	shape, err := g.TripShape("t1")
+	require.NoError(t, err)
+	assert.Equal(t, abc, shape)
+
+	// Trip without one gets a shape built from its stops
+	shape, err = g.TripShape("t3")
+	require.NoError(t, err)
+	assert.Equal(t, &model.Shape{
+		Points: []model.ShapePoint{
+			{Lat: 30, Lon: 30, Sequence: 5},
+			{Lat: 10, Lon: 10, Sequence: 7},
+		},
+	}, shape)
+
+	// Unknown trip has no shape
+	shape, err = g.TripShape("nope")
+	require.NoError(t, err)
+	assert.Nil(t, shape)
+
+	// Shapes per route and direction
+	shapes, err := g.RouteShapes("R", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Shape{abc}, shapes)
+
+	shapes, err = g.RouteShapes("R", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Shape{}, shapes)
+}
+
+func testStaticDeparturesFrequencies(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"agency.txt": {"agency_timezone,agency_name,agency_url", "America/Los_Angeles,Agency,http://example.com"},
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"everyday,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "R,r,3"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"a,a,1,1",
+			"b,b,2,2",
+			"c,c,3,3",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id",
+			"exact,R,everyday",
+			"approx,R,everyday",
+			"regular,R,everyday",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"exact,a,1,00:00:00,00:00:00",
+			"exact,b,2,00:05:00,00:05:00",
+			"exact,c,3,00:10:00,00:10:00",
+			"approx,a,1,10:00:00,10:00:00",
+			"approx,b,2,10:03:00,10:03:00",
+			"approx,c,3,10:06:00,10:06:00",
+			"regular,a,1,08:12:00,08:12:00",
+			"regular,b,2,08:17:00,08:17:00",
+			"regular,c,3,08:22:00,08:22:00",
+		},
+		"frequencies.txt": {
+			"trip_id,start_time,end_time,headway_secs,exact_times",
+			"exact,08:00:00,08:30:00,900,1",
+			"exact,23:30:00,24:30:00,1800,1",
+			"approx,09:00:00,09:20:00,600,0",
+		},
+	})
+
+	tz, _ := time.LoadLocation("America/Los_Angeles")
+
+	// Template times of frequency based trips are ignored, and
+	// instances are generated every headway.
+	departures, err := g.Departures("b", time.Date(2020, 3, 2, 0, 0, 0, 0, tz), 12*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Departure{
+		{StopID: "b", RouteID: "R", RouteShortName: "r", TripID: "exact", StopSequence: 2, Time: time.Date(2020, 3, 2, 0, 5, 0, 0, tz)},
+		{StopID: "b", RouteID: "R", RouteShortName: "r", TripID: "exact", StopSequence: 2, Time: time.Date(2020, 3, 2, 8, 5, 0, 0, tz)},
+		{StopID: "b", RouteID: "R", RouteShortName: "r", TripID: "regular", StopSequence: 2, Time: time.Date(2020, 3, 2, 8, 17, 0, 0, tz)},
+		{StopID: "b", RouteID: "R", RouteShortName: "r", TripID: "exact", StopSequence: 2, Time: time.Date(2020, 3, 2, 8, 20, 0, 0, tz)},
+		{StopID: "b", RouteID: "R", RouteShortName: "r", TripID: "approx", StopSequence: 2, Time: time.Date(2020, 3, 2, 9, 3, 0, 0, tz), HeadwayBased: true},
+		{StopID: "b", RouteID: "R", RouteShortName: "r", TripID: "approx", StopSequence: 2, Time: time.Date(2020, 3, 2, 9, 13, 0, 0, tz), HeadwayBased: true},
+	}, departures)
+
+	// Windowing applies to the generated instances, and the final
+	// stop is not a departure.
+	departures, err = g.Departures("a", time.Date(2020, 3, 2, 8, 10, 0, 0, tz), 10*time.Minute, -1, "", -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Departure{
+		{StopID: "a", RouteID: "R", RouteShortName: "r", TripID: "regular", StopSequence: 1, Time: time.Date(2020, 3, 2, 8, 12, 0, 0, tz)},
+		{StopID: "a", RouteID: "R", RouteShortName: "r", TripID: "exact", StopSequence: 1, Time: time.Date(2020, 3, 2, 8, 15, 0, 0, tz)},
+	}, departures)
+
+	departures, err = g.Departures("c", time.Date(2020, 3, 2, 0, 0, 0, 0, tz), 24*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Departure{}, departures)
+
+	// Instances running past midnight are found on the next day
+	departures, err = g.Departures("b", time.Date(2020, 3, 2, 23, 30, 0, 0, tz), 50*time.Minute, -1, "", -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Departure{
+		{StopID: "b", RouteID: "R", RouteShortName: "r", TripID: "exact", StopSequence: 2, Time: time.Date(2020, 3, 2, 23, 35, 0, 0, tz)},
+		{StopID: "b", RouteID: "R", RouteShortName: "r", TripID: "exact", StopSequence: 2, Time: time.Date(2020, 3, 3, 0, 5, 0, 0, tz)},
+	}, departures)
+}
+
+func testStaticFares(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"agency.txt": {
+			"agency_id,agency_timezone,agency_name,agency_url",
+			"A,UTC,Agency A,http://a.example.com",
+			"B,UTC,Agency B,http://b.example.com",
+		},
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {
+			"route_id,agency_id,route_short_name,route_type",
+			"local,A,L,3",
+			"express,A,X,3",
+			"other,B,O,3",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,zone_id,location_type,parent_station",
+			"a,a,1,1,1,0,",
+			"b,b,2,2,2,0,",
+			"station,c,3,3,3,1,",
+			"c,c,3,3,,0,station",
+		},
+		"trips.txt": {"trip_id,route_id,service_id", "t,local,all"},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"t,a,1,12:00:00,12:00:00",
+			"t,b,2,12:10:00,12:10:00",
+		},
+		"fare_attributes.txt": {
+			"fare_id,price,currency_type,payment_method,transfers,agency_id,transfer_duration",
+			"same_zone,1.50,USD,0,0,A,",
+			"one_to_two,2.00,USD,0,1,A,3600",
+			"express,5.00,USD,1,,A,",
+			"two_zones,2.50,USD,0,0,A,",
+			"flat_b,1.00,USD,0,,B,",
+		},
+		"fare_rules.txt": {
+			"fare_id,route_id,origin_id,destination_id,contains_id",
+			"same_zone,local,1,1,",
+			"same_zone,local,2,2,",
+			"one_to_two,local,1,2,",
+			"express,express,,,",
+			"two_zones,local,,,2",
+			"two_zones,local,,,3",
+		},
+	})
+
+	at := time.Date(2020, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	// Origin/destination zones and route
+	fares, err := g.Fares("a", "b", "local", at)
+	require.NoError(t, err)
+	assert.Equal(t, []model.FareAttribute{{
+		ID:               "one_to_two",
+		Price:            2,
+		CurrencyType:     "USD",
+		Transfers:        1,
+		AgencyID:         "A",
+		TransferDuration: 3600,
+	}}, fares)
+
+	fares, err = g.Fares("b", "b", "local", at)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"same_zone"}, fareIDs(fares))
+
+	// Zone inherited from parent station, matched by contains_id
+	fares, err = g.Fares("b", "c", "local", at)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"two_zones"}, fareIDs(fares))
+
+	// Route only rule
+	fares, err = g.Fares("c", "a", "express", at)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"express"}, fareIDs(fares))
+
+	// No matching rule
+	fares, err = g.Fares("b", "a", "local", at)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, fareIDs(fares))
+
+	// Fare without rules applies to all routes of its agency
+	fares, err = g.Fares("a", "b", "other", at)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"flat_b"}, fareIDs(fares))
+
+	// Unknown stops and routes
+	_, err = g.Fares("nope", "b", "local", at)
+	assert.Error(t, err)
+	_, err = g.Fares("a", "b", "nope", at)
+	assert.Error(t, err)
+
+	// Nothing applies outside the feed's calendar
+	fares, err = g.Fares("a", "b", "local", time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, fareIDs(fares))
+	fares, err = g.Fares("a", "b", "local", time.Date(2019, 12, 31, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, fareIDs(fares))
+}
+
+func fareIDs(fares []model.FareAttribute) []string {
+	ids := []string{}
+	for _, f := range fares {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+func testStaticFareCalculation(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {
+			"route_id,route_short_name,route_type",
+			"bus1,1,3",
+			"bus2,2,3",
+			"rail,R,2",
+			"ferry,F,4",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station",
+			"a,a,1,1,0,",
+			"b,b,2,2,0,",
+			"c,c,3,3,0,",
+			"d,d,4,4,1,",
+			"d1,d1,4,4,0,d",
+		},
+		"trips.txt": {"trip_id,route_id,service_id", "t,bus1,all"},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"t,a,1,12:00:00,12:00:00",
+			"t,b,2,12:10:00,12:10:00",
+		},
+		"networks.txt": {"network_id,network_name", "bus,Bus", "rail,Rail"},
+		"route_networks.txt": {
+			"network_id,route_id",
+			"bus,bus1",
+			"bus,bus2",
+			"rail,rail",
+		},
+		"areas.txt":      {"area_id,area_name", "downtown,Downtown", "uptown,Uptown"},
+		"stop_areas.txt": {"area_id,stop_id", "downtown,a", "downtown,b", "uptown,c", "uptown,d"},
+		"fare_media.txt": {"fare_media_id,fare_media_name,fare_media_type", "card,Card,2"},
+		"fare_products.txt": {
+			"fare_product_id,fare_product_name,fare_media_id,amount,currency",
+			"bus_single,Bus,,2.00,USD",
+			"bus_single,Bus,card,1.75,USD",
+			"rail_down_up,Rail,,4.00,USD",
+			"rail_any,Rail,,5.00,USD",
+			"combo,Bus and rail,,5.00,USD",
+			"transfer,Transfer,,0.25,USD",
+		},
+		"fare_leg_rules.txt": {
+			"leg_group_id,network_id,from_area_id,to_area_id,fare_product_id",
+			"bus,bus,,,bus_single",
+			"rail,rail,downtown,uptown,rail_down_up",
+			"rail,rail,,,rail_any",
+		},
+		"fare_transfer_rules.txt": {
+			"from_leg_group_id,to_leg_group_id,transfer_count,duration_limit,duration_limit_type,fare_transfer_type,fare_product_id",
+			"bus,bus,1,3600,1,0,",
+			"bus,rail,,,,2,combo",
+			"rail,bus,,,,1,transfer",
+		},
+	})
+
+	at := func(h, m int) time.Time {
+		return time.Date(2020, 3, 2, h, m, 0, 0, time.UTC)
+	}
+
+	// Single bus leg, cheapest media
+	calc, err := g.CalculateFare([]model.FareLeg{
+		{RouteID: "bus1", FromStopID: "a", ToStopID: "b", Departure: at(12, 0), Arrival: at(12, 10)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1.75, calc.Amount)
+	assert.Equal(t, "USD", calc.Currency)
+	assert.Equal(t, []model.FareCalculationLeg{{
+		LegGroupID: "bus",
+		Product:    model.FareProduct{ID: "bus_single", Name: "Bus", FareMediaID: "card", Amount: 1.75, Currency: "USD"},
+		Amount:     1.75,
+	}}, calc.Legs)
+
+	// Rail between areas, with area inherited from parent
+	// station. Falls back to rule without areas otherwise.
+	calc, err = g.CalculateFare([]model.FareLeg{
+		{RouteID: "rail", FromStopID: "a", ToStopID: "d1", Departure: at(12, 0), Arrival: at(12, 10)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, calc.Amount)
+	calc, err = g.CalculateFare([]model.FareLeg{
+		{RouteID: "rail", FromStopID: "c", ToStopID: "a", Departure: at(12, 0), Arrival: at(12, 10)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, calc.Amount)
+
+	// Bus to bus within the hour is free, but only once
+	calc, err = g.CalculateFare([]model.FareLeg{
+		{RouteID: "bus1", FromStopID: "a", ToStopID: "b", Departure: at(12, 0), Arrival: at(12, 10)},
+		{RouteID: "bus2", FromStopID: "b", ToStopID: "c", Departure: at(12, 20), Arrival: at(12, 30)},
+		{RouteID: "bus1", FromStopID: "c", ToStopID: "a", Departure: at(12, 40), Arrival: at(12, 50)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3.5, calc.Amount)
+	assert.Equal(t, []float64{1.75, 0, 1.75}, []float64{calc.Legs[0].Amount, calc.Legs[1].Amount, calc.Legs[2].Amount})
+	assert.Nil(t, calc.Legs[0].Transfer)
+	assert.NotNil(t, calc.Legs[1].Transfer)
+	assert.Nil(t, calc.Legs[2].Transfer)
+
+	// But not after the duration limit
+	calc, err = g.CalculateFare([]model.FareLeg{
+		{RouteID: "bus1", FromStopID: "a", ToStopID: "b", Departure: at(12, 0), Arrival: at(12, 10)},
+		{RouteID: "bus2", FromStopID: "b", ToStopID: "c", Departure: at(13, 20), Arrival: at(13, 30)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3.5, calc.Amount)
+
+	// Bus to rail is priced as a single combo product
+	calc, err = g.CalculateFare([]model.FareLeg{
+		{RouteID: "bus1", FromStopID: "a", ToStopID: "b", Departure: at(12, 0), Arrival: at(12, 10)},
+		{RouteID: "rail", FromStopID: "b", ToStopID: "d1", Departure: at(12, 20), Arrival: at(12, 30)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, calc.Amount)
+	assert.Equal(t, 0.0, calc.Legs[0].Amount)
+	assert.Equal(t, 0.0, calc.Legs[1].Amount)
+	assert.Equal(t, 5.0, calc.Legs[1].TransferAmount)
+	assert.Equal(t, model.FareTransferTypeAB, calc.Legs[1].Transfer.FareTransferType)
+
+	// Rail to bus is charged a transfer on top of both fares. The
+	// bus fare is then replaced by the combo, but the transfer
+	// charge stays.
+	calc, err = g.CalculateFare([]model.FareLeg{
+		{RouteID: "rail", FromStopID: "c", ToStopID: "a", Departure: at(12, 0), Arrival: at(12, 10)},
+		{RouteID: "bus1", FromStopID: "a", ToStopID: "b", Departure: at(12, 20), Arrival: at(12, 30)},
+		{RouteID: "rail", FromStopID: "b", ToStopID: "d1", Departure: at(12, 40), Arrival: at(12, 50)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 10.25, calc.Amount)
+	assert.Equal(t, []float64{5, 0, 0}, []float64{calc.Legs[0].Amount, calc.Legs[1].Amount, calc.Legs[2].Amount})
+	assert.Equal(t, []float64{0, 0.25, 5}, []float64{calc.Legs[0].TransferAmount, calc.Legs[1].TransferAmount, calc.Legs[2].TransferAmount})
+
+	// No rule for the ferry
+	_, err = g.CalculateFare([]model.FareLeg{
+		{RouteID: "ferry", FromStopID: "a", ToStopID: "b", Departure: at(12, 0), Arrival: at(12, 10)},
+	})
+	assert.Error(t, err)
+}
+
+func testStaticFareCalculationRouteNetworkID(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {
+			"route_id,route_short_name,route_type,network_id",
+			"bus,1,3,bus",
+			"rail,R,2,rail",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"a,a,1,1",
+			"b,b,2,2",
+		},
+		"trips.txt": {"trip_id,route_id,service_id", "t,bus,all"},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"t,a,1,12:00:00,12:00:00",
+			"t,b,2,12:10:00,12:10:00",
+		},
+		"networks.txt": {"network_id,network_name", "bus,Bus", "rail,Rail"},
+		"fare_products.txt": {
+			"fare_product_id,fare_product_name,amount,currency",
+			"bus_single,Bus,2.00,USD",
+			"rail_single,Rail,4.00,USD",
+		},
+		"fare_leg_rules.txt": {
+			"leg_group_id,network_id,fare_product_id",
+			"bus,bus,bus_single",
+			"rail,rail,rail_single",
+		},
+	})
+
+	at := func(h, m int) time.Time {
+		return time.Date(2020, 3, 2, h, m, 0, 0, time.UTC)
+	}
+
+	// Without route_networks.txt, networks come from routes.txt
+	calc, err := g.CalculateFare([]model.FareLeg{
+		{RouteID: "bus", FromStopID: "a", ToStopID: "b", Departure: at(12, 0), Arrival: at(12, 10)},
+		{RouteID: "rail", FromStopID: "b", ToStopID: "a", Departure: at(12, 20), Arrival: at(12, 30)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, calc.Amount)
+	assert.Equal(t, "bus", calc.Legs[0].LegGroupID)
+	assert.Equal(t, "rail", calc.Legs[1].LegGroupID)
+}
+
+func testStaticWalkingPath(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "r,R,1"},
+		"levels.txt": {
+			"level_id,level_index,level_name",
+			"street,0,Street",
+			"mezz,-1,Mezzanine",
+			"platform,-2,Platform",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station,level_id",
+			"station,Station,1,1,1,,",
+			"e,Entrance,1,1,2,station,street",
+			"m,Mezzanine,,,3,station,mezz",
+			"p,Platform,1,1,0,station,platform",
+			"x,Elsewhere,2,2,0,,",
+		},
+		"trips.txt": {"trip_id,route_id,service_id", "t,r,all"},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"t,p,1,12:00:00,12:00:00",
+			"t,x,2,12:10:00,12:10:00",
+		},
+		"pathways.txt": {
+			"pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional,length,traversal_time,signposted_as,reversed_signposted_as",
+			"stairs,e,m,2,1,,30,Trains,Street",
+			"elevator,e,m,5,1,,90,Trains (elevator),Street (elevator)",
+			"walkway,m,p,1,1,39,,Platform,Exit",
+			"escalator,p,m,4,0,,20,Exit,",
+		},
+	})
+
+	// Quickest path takes the stairs
+	path, err := g.WalkingPath("e", "p")
+	require.NoError(t, err)
+	require.NotNil(t, path)
+	assert.Equal(t, uint32(60), path.TraversalTime)
+	require.Equal(t, 2, len(path.Steps))
+	assert.Equal(t, model.WalkingPathStep{
+		PathwayID:     "stairs",
+		FromStopID:    "e",
+		ToStopID:      "m",
+		Mode:          model.PathwayModeStairs,
+		TraversalTime: 30,
+		SignpostedAs:  "Trains",
+		FromLevel:     &model.Level{ID: "street", Index: 0, Name: "Street"},
+		ToLevel:       &model.Level{ID: "mezz", Index: -1, Name: "Mezzanine"},
+	}, path.Steps[0])
+	assert.Equal(t, model.WalkingPathStep{
+		PathwayID:     "walkway",
+		FromStopID:    "m",
+		ToStopID:      "p",
+		Mode:          model.PathwayModeWalkway,
+		TraversalTime: 30,
+		SignpostedAs:  "Platform",
+		FromLevel:     &model.Level{ID: "mezz", Index: -1, Name: "Mezzanine"},
+		ToLevel:       &model.Level{ID: "platform", Index: -2, Name: "Platform"},
+	}, path.Steps[1])
+
+	// Accessible path takes the elevator
+	path, err = g.AccessibleWalkingPath("e", "p")
+	require.NoError(t, err)
+	require.NotNil(t, path)
+	assert.Equal(t, uint32(120), path.TraversalTime)
+	assert.Equal(t, []string{"elevator", "walkway"}, pathwayIDs(path))
+
+	// On the way out, the escalator is one-way and reversed
+	// pathways are signposted accordingly
+	path, err = g.WalkingPath("p", "e")
+	require.NoError(t, err)
+	require.NotNil(t, path)
+	assert.Equal(t, uint32(50), path.TraversalTime)
+	assert.Equal(t, []string{"escalator", "stairs"}, pathwayIDs(path))
+	assert.Equal(t, "Exit", path.Steps[0].SignpostedAs)
+	assert.Equal(t, "Street", path.Steps[1].SignpostedAs)
+
+	path, err = g.AccessibleWalkingPath("p", "e")
+	require.NoError(t, err)
+	require.NotNil(t, path)
+	assert.Equal(t, []string{"walkway", "elevator"}, pathwayIDs(path))
+	assert.Equal(t, "Exit", path.Steps[0].SignpostedAs)
+	assert.Equal(t, "Street (elevator)", path.Steps[1].SignpostedAs)
+
+	// No path
+	path, err = g.WalkingPath("e", "x")
+	require.NoError(t, err)
+	assert.Nil(t, path)
+
+	// Nowhere to walk when already there
+	path, err = g.WalkingPath("e", "e")
+	require.NoError(t, err)
+	require.NotNil(t, path)
+	assert.Equal(t, &model.WalkingPath{Steps: []model.WalkingPathStep{}}, path)
+	path, err = g.AccessibleWalkingPath("p", "p")
+	require.NoError(t, err)
+	require.NotNil(t, path)
+	assert.Equal(t, 0, len(path.Steps))
+	assert.Equal(t, uint32(0), path.TraversalTime)
+}
+
+func pathwayIDs(path *model.WalkingPath) []string {
+	ids := []string{}
+	for _, step := range path.Steps {
+		ids = append(ids, step.PathwayID)
+	}
+	return ids
+}
+
+func testStaticTranslations(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"mondays,20200101,20201231,1,0,0,0,0,0,0",
+		},
+		"routes.txt": {"route_id,route_short_name,route_long_name,route_type", "r,R,Red Line,3"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"A,A,1,1",
+			"B,B,2,2",
+			"C,C,3,3",
+			"D,D,4,4",
+			"E,E,5,5",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id,direction_id,trip_headsign",
+			"t1,r,mondays,0,To Z",
+			"t2,r,mondays,0,To Z",
+			"t3,r,mondays,0,To Y",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,departure_time,arrival_time,stop_headsign,stop_sequence",
+			"t1,A,6:10:00,6:10:00,,1",
+			"t1,B,6:11:00,6:11:00,,2",
+			"t1,C,6:12:00,6:12:00,,3",
+			"t2,A,6:20:00,6:20:00,,1",
+			"t2,B,6:21:00,6:21:00,Express,2",
+			"t2,C,6:22:00,6:22:00,,3",
+			"t3,D,6:15:00,6:15:00,Local,1",
+			"t3,E,6:16:00,6:16:00,,2",
+		},
+		"translations.txt": {
+			"table_name,field_name,language,translation,record_id,record_sub_id,field_value",
+			"trips,trip_headsign,fr,Vers Z,,,To Z",
+			"trips,trip_headsign,de,Nach Z,,,To Z",
+			"stop_times,stop_headsign,fr,Rapide,t2,2,",
+			"trips,trip_headsign,fr,Vers Y,t3,,",
+			"stops,stop_name,fr,Rue A,A,,",
+			"stops,stop_name,de,Haltestelle,,,B",
+			"routes,route_long_name,fr,Ligne rouge,r,,",
+			"routes,route_long_name,de,Rote Linie,,,Red Line",
+			"routes,route_short_name,de,RR,r,,",
+		},
+	})
+
+	// Feb 3rd is a Monday.
+	headsigns := func(stopID string, langs ...string) []string {
+		departures, err := g.Departures(
+			stopID,
+			time.Date(2020, 2, 3, 6, 0, 0, 0, time.UTC),
+			30*time.Minute,
+			-1,
+			"",
+			-1,
+			nil,
+			langs...,
+		)
+		require.NoError(t, err)
+		hs := []string{}
+		for _, d := range departures {
+			hs = append(hs, d.Headsign)
+		}
+		return hs
+	}
+
+	assert.Equal(t, []string{"To Z", "To Z"}, headsigns("A"))
+	assert.Equal(t, []string{"Vers Z", "Vers Z"}, headsigns("A", "fr"))
+	assert.Equal(t, []string{"To Z", "Express"}, headsigns("B"))
+	assert.Equal(t, []string{"Vers Z", "Rapide"}, headsigns("B", "fr"))
+
+	// Falls back through preferred languages, then to the
+	// original
+	assert.Equal(t, []string{"Nach Z", "Express"}, headsigns("B", "es", "de"))
+	assert.Equal(t, []string{"To Z", "Express"}, headsigns("B", "es"))
+
+	// A stop_headsign isn't given the translation of the trip's
+	// trip_headsign
+	assert.Equal(t, []string{"Local"}, headsigns("D", "fr"))
+	rds, err := g.RouteDirections("D", "fr")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(rds))
+	assert.Equal(t, []string{"Local"}, rds[0].Headsigns)
+
+	// Route directions
+	rds, err = g.RouteDirections("B", "fr")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(rds))
+	sort.Strings(rds[0].Headsigns)
+	assert.Equal(t, []string{"Rapide", "Vers Z"}, rds[0].Headsigns)
+
+	rds, err = g.RouteDirections("A", "de")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(rds))
+	assert.Equal(t, []string{"Nach Z"}, rds[0].Headsigns)
+
+	rds, err = g.RouteDirections("B")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(rds))
+	sort.Strings(rds[0].Headsigns)
+	assert.Equal(t, []string{"Express", "To Z"}, rds[0].Headsigns)
+
+	// Route names
+	departures, err := g.Departures("A", time.Date(2020, 2, 3, 6, 0, 0, 0, time.UTC), 30*time.Minute, 1, "", -1, nil, "es", "de")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(departures))
+	assert.Equal(t, "RR", departures[0].RouteShortName)
+	assert.Equal(t, "Rote Linie", departures[0].RouteLongName)
+
+	rds, err = g.RouteDirections("A", "fr")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(rds))
+	assert.Equal(t, "R", rds[0].RouteShortName)
+	assert.Equal(t, "Ligne rouge", rds[0].RouteLongName)
+
+	routes, err := g.StopRoutes("A", "de")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(routes))
+	assert.Equal(t, "RR", routes[0].ShortName)
+	assert.Equal(t, "Rote Linie", routes[0].LongName)
+
+	routes, err = g.StopRoutes("A")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(routes))
+	assert.Equal(t, "R", routes[0].ShortName)
+	assert.Equal(t, "Red Line", routes[0].LongName)
+
+	// Stop names
+	stops, err := g.NearbyStops(0, 0, 0, nil, "de", "fr")
+	require.NoError(t, err)
+	names := []string{}
+	for _, stop := range stops {
+		names = append(names, stop.Name)
+	}
+	assert.Equal(t, []string{"Rue A", "Haltestelle", "C", "D", "E"}, names)
+}
+
+func testStaticDeparturesStopTimezone(t *testing.T, backend string) {
+	// A trip leaving New York, calling at a station in Chicago
+	// and a stop in Denver. All stop_times are in the agency's
+	// timezone.
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"agency.txt": {
+			"agency_timezone,agency_name,agency_url",
+			"America/New_York,Agency,http://example.com",
+		},
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"everyday,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "r,R,2"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station,stop_timezone",
+			"nyc,NYC,1,1,0,,",
+			"chi,Chicago,2,2,1,,America/Chicago",
+			"chi_1,Chicago Track 1,2,2,0,chi,America/Los_Angeles",
+			"den,Denver,3,3,0,,America/Denver",
+			"end,End,4,4,0,,",
+		},
+		"trips.txt": {"trip_id,route_id,service_id", "t,r,everyday"},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"t,nyc,1,10:00:00,10:00:00",
+			"t,chi_1,2,20:00:00,20:00:00",
+			"t,den,3,26:00:00,26:00:00",
+			"t,end,4,27:00:00,27:00:00",
+		},
+	})
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	departureAt := func(stopID string) time.Time {
+		departures, err := g.Departures(
+			stopID,
+			time.Date(2020, 3, 2, 0, 0, 0, 0, newYork),
+			24*time.Hour,
+			-1,
+			"",
+			-1,
+			nil,
+		)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(departures))
+		return departures[0].Time
+	}
+
+	// No stop_timezone, so caller's timezone is used
+	dep := departureAt("nyc")
+	assert.Equal(t, time.Date(2020, 3, 2, 10, 0, 0, 0, newYork), dep)
+	assert.Equal(t, newYork, dep.Location())
+
+	// Stop inherits the timezone of its parent station, and
+	// the time is the same instant
+	dep = departureAt("chi_1")
+	assert.True(t, time.Date(2020, 3, 2, 20, 0, 0, 0, newYork).Equal(dep))
+	assert.Equal(t, "America/Chicago", dep.Location().String())
+	assert.Equal(t, "19:00", dep.Format("15:04"))
+
+	dep = departureAt("chi")
+	assert.Equal(t, "America/Chicago", dep.Location().String())
+
+	dep = departureAt("den")
+	assert.True(t, time.Date(2020, 3, 2, 2, 0, 0, 0, newYork).Equal(dep))
+	assert.Equal(t, "America/Denver", dep.Location().String())
+	assert.Equal(t, "00:00", dep.Format("15:04"))
+}
+
+func testStaticWheelchairAccessibility(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "r,R,3"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station,wheelchair_boarding",
+			"S,Station,1,1,1,,1",
+			"S1,Platform 1,1,1,0,S,",
+			"S2,Platform 2,1,1,0,S,2",
+			"X,X,2,2,0,,1",
+			"Y,Y,3,3,0,,",
+			"Z,Z,4,4,0,,2",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id,wheelchair_accessible",
+			"t1,r,all,1",
+			"t2,r,all,",
+			"t3,r,all,1",
+			"t4,r,all,1",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"t1,S1,1,10:00:00,10:00:00",
+			"t1,Z,2,11:00:00,11:00:00",
+			"t2,S1,1,10:10:00,10:10:00",
+			"t2,Z,2,11:00:00,11:00:00",
+			"t3,S2,1,10:20:00,10:20:00",
+			"t3,Z,2,11:00:00,11:00:00",
+			"t4,X,1,10:30:00,10:30:00",
+			"t4,Z,2,11:00:00,11:00:00",
+		},
+	})
+
+	start := time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// Platform 1 inherits the station's wheelchair_boarding
+	departures, err := g.Departures("S", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(departures))
+	assert.Equal(t, model.WheelchairAccessible, departures[0].WheelchairBoarding)
+	assert.Equal(t, model.WheelchairAccessible, departures[0].WheelchairAccessible)
+	assert.Equal(t, model.WheelchairAccessible, departures[1].WheelchairBoarding)
+	assert.Equal(t, model.WheelchairNoInfo, departures[1].WheelchairAccessible)
+	assert.Equal(t, model.WheelchairInaccessible, departures[2].WheelchairBoarding)
+	assert.Equal(t, model.WheelchairAccessible, departures[2].WheelchairAccessible)
+
+	// Only t1 has both an accessible stop and trip
+	departures, err = g.AccessibleDepartures("S", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(departures))
+	assert.Equal(t, "t1", departures[0].TripID)
+
+	departures, err = g.AccessibleDepartures("Y", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(departures))
+
+	// Nearby stops
+	stops, err := g.NearbyStops(0, 0, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"S", "X", "Y", "Z"}, stopIDs(stops))
+
+	stops, err = g.AccessibleNearbyStops(0, 0, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"S", "X"}, stopIDs(stops))
+
+	stops, err = g.AccessibleNearbyStops(0, 0, 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"S"}, stopIDs(stops))
+}
+
+func testStaticDeparturesPickupType(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "r,R,3"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"s1,S1,1,1",
+			"s2,S2,2,2",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id",
+			"t1,r,all",
+			"t2,r,all",
+			"t3,r,all",
+			"t4,r,all",
+			"f1,r,all",
+		},
+		"frequencies.txt": {
+			"trip_id,start_time,end_time,headway_secs",
+			"f1,10:00:00,11:00:00,1800",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time,pickup_type,drop_off_type",
+			"t1,s1,1,10:00:00,10:00:00,,",
+			"t1,s2,2,11:00:00,11:00:00,,",
+			"t2,s1,1,10:10:00,10:10:00,1,0",
+			"t2,s2,2,11:00:00,11:00:00,0,0",
+			"t3,s1,1,10:20:00,10:20:00,2,1",
+			"t3,s2,2,11:00:00,11:00:00,0,0",
+			"t4,s1,1,10:30:00,10:30:00,3,0",
+			"t4,s2,2,11:00:00,11:00:00,0,0",
+			"f1,s1,1,10:00:00,10:00:00,1,0",
+			"f1,s2,2,10:05:00,10:05:00,0,0",
+		},
+	})
+
+	start := time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// t2 and f1 don't pick up at s1. Phone agency and coordinate
+	// with driver pickups are included, with pickup type set.
+	departures, err := g.Departures("s1", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(departures))
+	assert.Equal(t, "t1", departures[0].TripID)
+	assert.Equal(t, model.PickupDropOffRegular, departures[0].PickupType)
+	assert.Equal(t, "t3", departures[1].TripID)
+	assert.Equal(t, model.PickupDropOffPhoneAgency, departures[1].PickupType)
+	assert.Equal(t, "t4", departures[2].TripID)
+	assert.Equal(t, model.PickupDropOffCoordinateWithDriver, departures[2].PickupType)
+
+	// s2 is the final stop for all trips
+	departures, err = g.Departures("s2", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(departures))
+}
+
+func testStaticDeparturesInterpolated(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "r,R,3"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"s1,S1,1,1",
+			"s2,S2,2,2",
+			"s3,S3,3,3",
+			"s4,S4,4,4",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id",
+			"t1,r,all",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time,timepoint",
+			"t1,s1,1,10:00:00,10:00:00,1",
+			"t1,s2,2,,,0",
+			"t1,s3,3,10:20:00,10:20:00,0",
+			"t1,s4,4,10:30:00,10:30:00,1",
+		},
+	})
+
+	start := time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	departures, err := g.Departures("s1", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(departures))
+	assert.Equal(t, time.Date(2020, 3, 2, 10, 0, 0, 0, time.UTC), departures[0].Time)
+	assert.False(t, departures[0].Approximate)
+
+	// Interpolated time
+	departures, err = g.Departures("s2", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(departures))
+	assert.Equal(t, time.Date(2020, 3, 2, 10, 10, 0, 0, time.UTC), departures[0].Time)
+	assert.True(t, departures[0].Approximate)
+
+	// Given time, but not a timepoint
+	departures, err = g.Departures("s3", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(departures))
+	assert.Equal(t, time.Date(2020, 3, 2, 10, 20, 0, 0, time.UTC), departures[0].Time)
+	assert.True(t, departures[0].Approximate)
+}
+
+func testStaticDeparturesBlockContinuation(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "r1,R1,3", "r2,R2,3"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"s1,S1,1,1",
+			"s2,S2,2,2",
+			"s3,S3,3,3",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id,block_id,trip_headsign",
+			"t1,r1,all,b,To S2",
+			"t2,r2,all,b,To S3",
+			"t3,r1,all,,To S2",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"t1,s1,1,10:00:00,10:00:00",
+			"t1,s2,2,10:30:00,10:30:00",
+			"t2,s2,1,10:35:00,10:35:00",
+			"t2,s3,2,11:00:00,11:00:00",
+			"t3,s1,1,10:10:00,10:10:00",
+			"t3,s2,2,10:40:00,10:40:00",
+		},
+		"translations.txt": {
+			"table_name,field_name,language,translation,record_id,record_sub_id,field_value",
+			"trips,trip_headsign,fr,Vers S2,t1,,",
+			"trips,trip_headsign,fr,Vers S3,t2,,",
+		},
+	})
+
+	start := time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// By default, the last stop of t1 is not a departure
+	departures, err := g.Departures("s2", start, 3*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(departures))
+	assert.Equal(t, "t2", departures[0].TripID)
+	assert.Equal(t, "", departures[0].ContinuesAsTripID)
+
+	// With block continuations, it is, carrying t2's
+	// headsign. t3 has no block, so still excluded.
+	g.BlockContinuations = true
+	departures, err = g.Departures("s2", start, 3*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(departures))
+	assert.Equal(t, model.Departure{
+		StopID:             "s2",
+		RouteID:            "r1",
+		RouteShortName:     "R1",
+		TripID:             "t1",
+		StopSequence:       2,
+		Time:               time.Date(2020, 3, 2, 10, 30, 0, 0, time.UTC),
+		Headsign:           "To S3",
+		ContinuesAsTripID:  "t2",
+		ContinuesAsRouteID: "r2",
+	}, departures[0])
+	assert.Equal(t, "t2", departures[1].TripID)
+
+	// The continuation's headsign is translated as that of t2
+	departures, err = g.Departures("s2", start, 3*time.Hour, 1, "", -1, nil, "fr")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(departures))
+	assert.Equal(t, "t1", departures[0].TripID)
+	assert.Equal(t, "Vers S3", departures[0].Headsign)
+
+	// Last trip in the block ends at s3
+	departures, err = g.Departures("s3", start, 3*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(departures))
+}
+
+func testStaticDeparturesAmenities(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "r,R,4"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,wheelchair_boarding",
+			"s1,S1,1,1,1",
+			"s2,S2,2,2,1",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id,wheelchair_accessible,bikes_allowed,cars_allowed",
+			"t1,r,all,1,1,1",
+			"t2,r,all,1,1,2",
+			"t3,r,all,2,2,1",
+			"t4,r,all,,,",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"t1,s1,1,10:00:00,10:00:00",
+			"t1,s2,2,11:00:00,11:00:00",
+			"t2,s1,1,10:10:00,10:10:00",
+			"t2,s2,2,11:00:00,11:00:00",
+			"t3,s1,1,10:20:00,10:20:00",
+			"t3,s2,2,11:00:00,11:00:00",
+			"t4,s1,1,10:30:00,10:30:00",
+			"t4,s2,2,11:00:00,11:00:00",
+		},
+	})
+
+	start := time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	tripIDs := func(departures []model.Departure) []string {
+		ids := []string{}
+		for _, d := range departures {
+			ids = append(ids, d.TripID)
+		}
+		return ids
+	}
+
+	departures, err := g.Departures("s1", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2", "t3", "t4"}, tripIDs(departures))
+	assert.Equal(t, model.AmenityAllowed, departures[1].BikesAllowed)
+	assert.Equal(t, model.AmenityNotAllowed, departures[1].CarsAllowed)
+	assert.Equal(t, model.AmenityNoInfo, departures[3].BikesAllowed)
+	assert.Equal(t, model.AmenityNoInfo, departures[3].CarsAllowed)
+
+	departures, err = g.DeparturesWithAmenities("s1", start, 2*time.Hour, -1, "", -1, nil, gtfs.AmenityFilter{BikesAllowed: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2"}, tripIDs(departures))
+
+	departures, err = g.DeparturesWithAmenities("s1", start, 2*time.Hour, -1, "", -1, nil, gtfs.AmenityFilter{CarsAllowed: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t3"}, tripIDs(departures))
+
+	departures, err = g.DeparturesWithAmenities("s1", start, 2*time.Hour, -1, "", -1, nil, gtfs.AmenityFilter{
+		WheelchairAccessible: true,
+		BikesAllowed:         true,
+		CarsAllowed:          true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1"}, tripIDs(departures))
+
+	// Limit applies after filtering
+	departures, err = g.DeparturesWithAmenities("s1", start, 2*time.Hour, 1, "", -1, nil, gtfs.AmenityFilter{CarsAllowed: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1"}, tripIDs(departures))
+}
+
+func testStaticDeparturesContinuousStopping(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {
+			"route_id,route_short_name,route_type,continuous_pickup,continuous_drop_off",
+			"flag,F,3,0,0",
+			"regular,R,3,,",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"s1,S1,1,1",
+			"s2,S2,2,2",
+			"s3,S3,3,3",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id",
+			"t1,flag,all",
+			"t2,regular,all",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time,continuous_pickup,continuous_drop_off",
+			"t1,s1,1,10:00:00,10:00:00,,",
+			"t1,s2,2,10:10:00,10:10:00,1,3",
+			"t1,s3,3,10:20:00,10:20:00,,",
+			"t2,s1,1,10:30:00,10:30:00,,",
+			"t2,s2,2,10:40:00,10:40:00,2,",
+			"t2,s3,3,10:50:00,10:50:00,,",
+		},
+	})
+
+	start := time.Date(2020, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// Route's continuous stopping applies unless overridden by
+	// the stop time
+	departures, err := g.Departures("s1", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(departures))
+	assert.Equal(t, model.ContinuousStoppingRegular, departures[0].ContinuousPickup)
+	assert.Equal(t, model.ContinuousStoppingRegular, departures[0].ContinuousDropOff)
+	assert.Equal(t, model.ContinuousStoppingNone, departures[1].ContinuousPickup)
+	assert.Equal(t, model.ContinuousStoppingNone, departures[1].ContinuousDropOff)
+
+	departures, err = g.Departures("s2", start, 2*time.Hour, -1, "", -1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(departures))
+	assert.Equal(t, model.ContinuousStoppingNone, departures[0].ContinuousPickup)
+	assert.Equal(t, model.ContinuousStoppingCoordinateWithDriver, departures[0].ContinuousDropOff)
+	assert.Equal(t, model.ContinuousStoppingPhoneAgency, departures[1].ContinuousPickup)
+	assert.Equal(t, model.ContinuousStoppingNone, departures[1].ContinuousDropOff)
+}
+
+func testStaticRouteSortOrder(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"all,20200101,20201231,1,1,1,1,1,1,1",
+		},
+		"routes.txt": {
+			"route_id,route_short_name,route_type,route_sort_order",
+			"a,A,3,",
+			"b,B,3,20",
+			"c,C,3,10",
+			"d,D,3,",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"s1,S1,1,1",
+			"s2,S2,2,2",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id,direction_id,trip_headsign",
+			"ta0,a,all,0,A0",
+			"tb0,b,all,0,B0",
+			"tb1,b,all,1,B1",
+			"tc0,c,all,0,C0",
+			"td0,d,all,0,D0",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,departure_time,arrival_time",
+			"ta0,s1,1,10:00:00,10:00:00",
+			"ta0,s2,2,10:10:00,10:10:00",
+			"tb0,s1,1,10:00:00,10:00:00",
+			"tb0,s2,2,10:10:00,10:10:00",
+			"tb1,s1,1,10:00:00,10:00:00",
+			"tb1,s2,2,10:10:00,10:10:00",
+			"tc0,s1,1,10:00:00,10:00:00",
+			"tc0,s2,2,10:10:00,10:10:00",
+			"td0,s1,1,10:00:00,10:00:00",
+			"td0,s2,2,10:10:00,10:10:00",
+		},
+	})
+
+	// Routes with sort order first, then the others by ID
+	routes, err := g.StopRoutes("s1")
+	require.NoError(t, err)
+	routeIDs := []string{}
+	for _, r := range routes {
+		routeIDs = append(routeIDs, r.ID)
+	}
+	assert.Equal(t, []string{"c", "b", "a", "d"}, routeIDs)
+
+	rds, err := g.RouteDirections("s1")
+	require.NoError(t, err)
+	assert.Equal(t, []model.RouteDirection{
+		{StopID: "s1", RouteID: "c", RouteShortName: "C", DirectionID: 0, Headsigns: []string{"C0"}},
+		{StopID: "s1", RouteID: "b", RouteShortName: "B", DirectionID: 0, Headsigns: []string{"B0"}},
+		{StopID: "s1", RouteID: "b", RouteShortName: "B", DirectionID: 1, Headsigns: []string{"B1"}},
+		{StopID: "s1", RouteID: "a", RouteShortName: "A", DirectionID: 0, Headsigns: []string{"A0"}},
+		{StopID: "s1", RouteID: "d", RouteShortName: "D", DirectionID: 0, Headsigns: []string{"D0"}},
+	}, rds)
+}
+
+func stopIDs(stops []model.Stop) []string {
+	ids := []string{}
+	for _, stop := range stops {
+		ids = append(ids, stop.ID)
+	}
+	return ids
+}
+
+func testStaticOnDemandServices(t *testing.T, backend string) {
+	g := testutil.BuildStatic(t, backend, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date,monday,tuesday,wednesday,thursday,friday,saturday,sunday",
+			"weekdays,20200101,20201231,1,1,1,1,1,0,0",
+		},
+		"routes.txt": {
+			"route_id,route_short_name,route_type",
+			"dial,Dial-a-Ride,3",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"clinic,Clinic,45,-70",
+			"store,Store,46,-71",
+			"depot,Depot,47,-72",
+		},
+		"trips.txt": {
+			"trip_id,route_id,service_id",
+			"zone_trip,dial,weekdays",
+			"group_trip,dial,weekdays",
+			"stop_trip,dial,weekdays",
+		},
+		// A square zone with a hole in the middle
+		"locations.geojson": {`{"type": "FeatureCollection", "features": [{
+			"type": "Feature", "id": "town", "properties": {"stop_name": "Town"},
+			"geometry": {"type": "Polygon", "coordinates": [
+				[[-75, 40], [-74, 40], [-74, 41], [-75, 41], [-75, 40]],
+				[[-74.6, 40.4], [-74.4, 40.4], [-74.4, 40.6], [-74.6, 40.6], [-74.6, 40.4]]
+			]}}]}`},
+		"location_groups.txt": {
+			"location_group_id,location_group_name",
+			"errands,Errands",
+		},
+		"location_group_stops.txt": {
+			"location_group_id,stop_id",
+			"errands,clinic",
+			"errands,store",
+		},
+		"booking_rules.txt": {
+			"booking_rule_id,booking_type,prior_notice_duration_min,phone_number,booking_url",
+			"call,1,60,555-1234,",
+			"app,0,,,http://example.com/book",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,location_id,location_group_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type,pickup_booking_rule_id",
+			"zone_trip,,town,,1,08:00:00,18:00:00,2,1,call",
+			"zone_trip,,town,,2,08:00:00,18:00:00,1,2,",
+			"group_trip,,,errands,1,20:00:00,26:00:00,2,1,app",
+			"group_trip,,,errands,2,20:00:00,26:00:00,1,2,",
+			"stop_trip,depot,,,1,09:00:00,12:00:00,2,1,call",
+			"stop_trip,depot,,,2,09:00:00,12:00:00,1,2,",
+		},
+	})
+
+	// Monday 10:00, in the zone
+	services, err := g.OnDemandServices(40.2, -74.8, time.Date(2020, 1, 6, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []model.OnDemandService{{
+		RouteID:      "dial",
+		TripID:       "zone_trip",
+		StopSequence: 1,
+		LocationID:   "town",
+		WindowStart:  time.Date(2020, 1, 6, 8, 0, 0, 0, time.UTC),
+		WindowEnd:    time.Date(2020, 1, 6, 18, 0, 0, 0, time.UTC),
+		PickupType:   model.PickupDropOffPhoneAgency,
+		BookingRule: &model.BookingRule{
+			ID:                     "call",
+			Type:                   model.BookingTypeSameDay,
+			PriorNoticeDurationMin: 60,
+			PhoneNumber:            "555-1234",
+		},
+	}}, services)
+
+	// Outside the window, in the zone's hole, outside the zone,
+	// and on a Saturday there's nothing
+	services, err = g.OnDemandServices(40.2, -74.8, time.Date(2020, 1, 6, 19, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []model.OnDemandService{}, services)
+	services, err = g.OnDemandServices(40.5, -74.5, time.Date(2020, 1, 6, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []model.OnDemandService{}, services)
+	services, err = g.OnDemandServices(39, -74.8, time.Date(2020, 1, 6, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []model.OnDemandService{}, services)
+	services, err = g.OnDemandServices(40.2, -74.8, time.Date(2020, 1, 11, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []model.OnDemandService{}, services)
+
+	// Near a stop of the location group, past midnight, served by
+	// Monday's trip
+	services, err = g.OnDemandServices(45.001, -70.001, time.Date(2020, 1, 7, 1, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(services))
+	assert.Equal(t, "group_trip", services[0].TripID)
+	assert.Equal(t, "errands", services[0].LocationGroupID)
+	assert.Equal(t, time.Date(2020, 1, 6, 20, 0, 0, 0, time.UTC), services[0].WindowStart)
+	assert.Equal(t, time.Date(2020, 1, 7, 2, 0, 0, 0, time.UTC), services[0].WindowEnd)
+	assert.Equal(t, "http://example.com/book", services[0].BookingRule.BookingURL)
+
+	// Too far from the stops
+	services, err = g.OnDemandServices(45.1, -70, time.Date(2020, 1, 7, 1, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []model.OnDemandService{}, services)
+
+	// Near a stop with a pickup window of its own
+	services, err = g.OnDemandServices(47.001, -72.001, time.Date(2020, 1, 6, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(services))
+	assert.Equal(t, "stop_trip", services[0].TripID)
+	assert.Equal(t, "depot", services[0].StopID)
+	assert.Equal(t, "", services[0].LocationID)
+	assert.Equal(t, "", services[0].LocationGroupID)
+	assert.Equal(t, time.Date(2020, 1, 6, 9, 0, 0, 0, time.UTC), services[0].WindowStart)
+	assert.Equal(t, time.Date(2020, 1, 6, 12, 0, 0, 0, time.UTC), services[0].WindowEnd)
+
+	// Windows are given in the timezone of the time passed
+	nyc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	services, err = g.OnDemandServices(40.2, -74.8, time.Date(2020, 1, 6, 5, 0, 0, 0, nyc))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(services))
+	assert.Equal(t, time.Date(2020, 1, 6, 3, 0, 0, 0, nyc), services[0].WindowStart)
+	assert.Equal(t, nyc, services[0].WindowStart.Location())
+}
+
 func TestStatic(t *testing.T) {
 	for _, test := range []struct {
 		Name string
@@ -1117,6 +2514,22 @@ func TestStatic(t *testing.T) {
 		{"StaticDeparturesStopTimeWithHeadsignOverride", testStaticDeparturesStopTimeWithHeadsignOverride},
 		{"StaticDeparturesWithParentStations", testStaticDeparturesWithParentStations},
 		{"StaticDeparturesDaylightsSavings", testStaticDeparturesDaylightsSavings},
+		{"StaticDeparturesFrequencies", testStaticDeparturesFrequencies},
+		{"StaticDeparturesStopTimezone", testStaticDeparturesStopTimezone},
+		{"StaticShapes", testStaticShapes},
+		{"StaticFares", testStaticFares},
+		{"StaticFareCalculation", testStaticFareCalculation},
+		{"StaticFareCalculationRouteNetworkID", testStaticFareCalculationRouteNetworkID},
+		{"StaticWalkingPath", testStaticWalkingPath},
+		{"StaticTranslations", testStaticTranslations},
+		{"StaticWheelchairAccessibility", testStaticWheelchairAccessibility},
+		{"StaticDeparturesPickupType", testStaticDeparturesPickupType},
+		{"StaticDeparturesInterpolated", testStaticDeparturesInterpolated},
+		{"StaticDeparturesBlockContinuation", testStaticDeparturesBlockContinuation},
+		{"StaticDeparturesAmenities", testStaticDeparturesAmenities},
+		{"StaticDeparturesContinuousStopping", testStaticDeparturesContinuousStopping},
+		{"StaticRouteSortOrder", testStaticRouteSortOrder},
+		{"StaticOnDemandServices", testStaticOnDemandServices},
 	} {
 		t.Run(fmt.Sprintf("%s SQLite", test.Name), func(t *testing.T) {
 			test.Test(t, "sqlite")
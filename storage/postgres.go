@@ -2,6 +2,7 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -15,6 +16,7 @@ import (
 const (
 	PSQLTripBatchSize     = 10000
 	PSQLStopTimeBatchSize = 5000
+	PSQLShapeBatchSize    = 10000
 )
 
 type PSQLStorage struct {
@@ -26,6 +28,12 @@ type PSQLFeedWriter struct {
 	db          *sql.DB
 	tripBuf     []model.Trip
 	stopTimeBuf []model.StopTime
+	shapeBuf    []psqlShapePoint
+}
+
+type psqlShapePoint struct {
+	shapeID string
+	point   model.ShapePoint
 }
 
 type PSQLFeedReader struct {
@@ -54,12 +62,34 @@ DROP TABLE IF EXISTS feed;
 DROP TABLE IF EXISTS feed_request;
 DROP TABLE IF EXISTS feed_consumer;
 DROP TABLE IF EXISTS agency;
+DROP TABLE IF EXISTS feed_info;
 DROP TABLE IF EXISTS calendar;
 DROP TABLE IF EXISTS calendar_dates;
 DROP TABLE IF EXISTS stops;
 DROP TABLE IF EXISTS stop_times;
 DROP TABLE IF EXISTS routes;
 DROP TABLE IF EXISTS trips;
+DROP TABLE IF EXISTS shapes;
+DROP TABLE IF EXISTS frequencies;
+DROP TABLE IF EXISTS transfers;
+DROP TABLE IF EXISTS fare_attributes;
+DROP TABLE IF EXISTS fare_rules;
+DROP TABLE IF EXISTS networks;
+DROP TABLE IF EXISTS route_networks;
+DROP TABLE IF EXISTS areas;
+DROP TABLE IF EXISTS stop_areas;
+DROP TABLE IF EXISTS fare_media;
+DROP TABLE IF EXISTS fare_products;
+DROP TABLE IF EXISTS fare_leg_rules;
+DROP TABLE IF EXISTS fare_transfer_rules;
+DROP TABLE IF EXISTS levels;
+DROP TABLE IF EXISTS pathways;
+DROP TABLE IF EXISTS translations;
+DROP TABLE IF EXISTS locations;
+DROP TABLE IF EXISTS location_groups;
+DROP TABLE IF EXISTS location_group_stops;
+DROP TABLE IF EXISTS booking_rules;
+DROP TABLE IF EXISTS flex_stop_times;
 `)
 		if err != nil {
 			return nil, fmt.Errorf("clearing db: %w", err)
@@ -77,12 +107,24 @@ CREATE TABLE IF NOT EXISTS feed (
     timezone TEXT NOT NULL,
     max_arrival TEXT NOT NULL,
     max_departure TEXT NOT NULL,
+    feed_start_date TEXT NOT NULL DEFAULT '',
+    feed_end_date TEXT NOT NULL DEFAULT '',
+    feed_version TEXT NOT NULL DEFAULT '',
+    feed_publisher_name TEXT NOT NULL DEFAULT '',
+    feed_publisher_url TEXT NOT NULL DEFAULT '',
+    feed_lang TEXT NOT NULL DEFAULT '',
+    dropped_count INTEGER NOT NULL DEFAULT 0,
+    dropped TEXT NOT NULL DEFAULT '',
     PRIMARY KEY (hash, url)
 );
 
 CREATE TABLE IF NOT EXISTS feed_request (
     url TEXT NOT NULL,
     refreshed_at TIMESTAMPTZ NOT NULL,
+    lenient BOOLEAN NOT NULL DEFAULT FALSE,
+    encoding TEXT NOT NULL DEFAULT '',
+    extras BOOLEAN NOT NULL DEFAULT FALSE,
+    last_failure TEXT NOT NULL DEFAULT '',
     PRIMARY KEY (url)
 );
 
@@ -98,6 +140,69 @@ CREATE TABLE IF NOT EXISTS feed_consumer (
 		return nil, fmt.Errorf("creating feed table: %w", err)
 	}
 
+	// Tables created by earlier versions lack columns added
+	// since. CREATE TABLE IF NOT EXISTS leaves those tables as
+	// they are, so the columns are added here, with defaults for
+	// the rows already stored. Feed tables not yet created are
+	// left to GetWriter(). Readers COALESCE nullable text columns,
+	// which older rows may hold NULL in.
+	_, err = db.Exec(`
+ALTER TABLE feed
+    ADD COLUMN IF NOT EXISTS feed_start_date TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS feed_end_date TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS feed_version TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS feed_publisher_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS feed_publisher_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS feed_lang TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS dropped_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS dropped TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE feed_request
+    ADD COLUMN IF NOT EXISTS lenient BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS encoding TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS extras BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS last_failure TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE IF EXISTS agency
+    ADD COLUMN IF NOT EXISTS lang TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS phone TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS fare_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS email TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS extras TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE IF EXISTS stops
+    ADD COLUMN IF NOT EXISTS zone_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS level_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS wheelchair_boarding INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS extras TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE IF EXISTS routes
+    ADD COLUMN IF NOT EXISTS continuous_pickup INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS continuous_drop_off INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS sort_order INTEGER,
+    ADD COLUMN IF NOT EXISTS network_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS extras TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE IF EXISTS trips
+    ADD COLUMN IF NOT EXISTS shape_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS wheelchair_accessible INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS block_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS bikes_allowed INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cars_allowed INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS extras TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE IF EXISTS stop_times
+    ADD COLUMN IF NOT EXISTS pickup_type INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS drop_off_type INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS approximate BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS shape_dist_traveled DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS continuous_pickup INTEGER,
+    ADD COLUMN IF NOT EXISTS continuous_drop_off INTEGER;`)
+	if err != nil {
+		return nil, fmt.Errorf("adding columns: %w", err)
+	}
+
 	return &PSQLStorage{
 		db: db,
 	}, nil
@@ -121,7 +226,15 @@ SELECT
     calendar_end,
     timezone,
     max_arrival,
-    max_departure
+    max_departure,
+    feed_start_date,
+    feed_end_date,
+    feed_version,
+    feed_publisher_name,
+    feed_publisher_url,
+    feed_lang,
+    dropped_count,
+    dropped
 FROM feed`
 
 	conditions := []string{}
@@ -153,6 +266,7 @@ FROM feed`
 	var feeds []*FeedMetadata
 	for rows.Next() {
 		var feed FeedMetadata
+		var dropped string
 		err := rows.Scan(
 			&feed.Hash,
 			&feed.URL,
@@ -162,10 +276,24 @@ FROM feed`
 			&feed.Timezone,
 			&feed.MaxArrival,
 			&feed.MaxDeparture,
+			&feed.FeedStartDate,
+			&feed.FeedEndDate,
+			&feed.FeedVersion,
+			&feed.FeedPublisherName,
+			&feed.FeedPublisherURL,
+			&feed.FeedLang,
+			&feed.DroppedCount,
+			&dropped,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning feed: %w", err)
 		}
+		if dropped != "" {
+			err = json.Unmarshal([]byte(dropped), &feed.Dropped)
+			if err != nil {
+				return nil, fmt.Errorf("unmarshaling dropped: %w", err)
+			}
+		}
 		feed.RetrievedAt = feed.RetrievedAt.UTC()
 		feeds = append(feeds, &feed)
 	}
@@ -178,6 +306,10 @@ func (s *PSQLStorage) ListFeedRequests(url string) ([]FeedRequest, error) {
 SELECT
     req.url,
     req.refreshed_at,
+    req.lenient,
+    req.encoding,
+    req.extras,
+    req.last_failure,
     con.name,
     con.headers,
     con.created_at,
@@ -205,9 +337,14 @@ LEFT JOIN feed_consumer con ON req.url = con.url`
 		var headers sql.NullString
 		var createdAt sql.NullTime
 		var updatedAt sql.NullTime
+		var lastFailure string
 		err := rows.Scan(
 			&req.URL,
 			&req.RefreshedAt,
+			&req.Lenient,
+			&req.Encoding,
+			&req.Extras,
+			&lastFailure,
 			&name,
 			&headers,
 			&createdAt,
@@ -216,6 +353,12 @@ LEFT JOIN feed_consumer con ON req.url = con.url`
 		if err != nil {
 			return nil, fmt.Errorf("scanning feed request: %w", err)
 		}
+		if lastFailure != "" {
+			err = json.Unmarshal([]byte(lastFailure), &req.LastFailure)
+			if err != nil {
+				return nil, fmt.Errorf("unmarshaling last failure: %w", err)
+			}
+		}
 		req.RefreshedAt = req.RefreshedAt.UTC()
 
 		if _, ok := requests[req.URL]; !ok {
@@ -239,7 +382,12 @@ LEFT JOIN feed_consumer con ON req.url = con.url`
 }
 
 func (s *PSQLStorage) WriteFeedMetadata(feed *FeedMetadata) error {
-	_, err := s.db.Exec(`
+	dropped, err := json.Marshal(feed.Dropped)
+	if err != nil {
+		return fmt.Errorf("marshaling dropped: %w", err)
+	}
+
+	_, err = s.db.Exec(`
 INSERT INTO feed (
     hash,
     url,
@@ -248,16 +396,32 @@ INSERT INTO feed (
     calendar_end,
     timezone,
     max_arrival,
-    max_departure
+    max_departure,
+    feed_start_date,
+    feed_end_date,
+    feed_version,
+    feed_publisher_name,
+    feed_publisher_url,
+    feed_lang,
+    dropped_count,
+    dropped
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 ON CONFLICT (hash, url) DO UPDATE SET
     retrieved_at = excluded.retrieved_at,
     calendar_start = excluded.calendar_start,
     calendar_end = excluded.calendar_end,
     timezone = excluded.timezone,
     max_arrival = excluded.max_arrival,
-    max_departure = excluded.max_departure
+    max_departure = excluded.max_departure,
+    feed_start_date = excluded.feed_start_date,
+    feed_end_date = excluded.feed_end_date,
+    feed_version = excluded.feed_version,
+    feed_publisher_name = excluded.feed_publisher_name,
+    feed_publisher_url = excluded.feed_publisher_url,
+    feed_lang = excluded.feed_lang,
+    dropped_count = excluded.dropped_count,
+    dropped = excluded.dropped
 `,
 		feed.Hash,
 		feed.URL,
@@ -267,6 +431,14 @@ ON CONFLICT (hash, url) DO UPDATE SET
 		feed.Timezone,
 		feed.MaxArrival,
 		feed.MaxDeparture,
+		feed.FeedStartDate,
+		feed.FeedEndDate,
+		feed.FeedVersion,
+		feed.FeedPublisherName,
+		feed.FeedPublisherURL,
+		feed.FeedLang,
+		feed.DroppedCount,
+		string(dropped),
 	)
 	if err != nil {
 		return fmt.Errorf("writing feed metadata: %w", err)
@@ -280,18 +452,28 @@ func (s *PSQLStorage) WriteFeedRequest(req FeedRequest) error {
 		return fmt.Errorf("starting transaction: %w", err)
 	}
 
+	lastFailure := ""
+	if req.LastFailure != nil {
+		buf, err := json.Marshal(req.LastFailure)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("marshaling last failure: %w", err)
+		}
+		lastFailure = string(buf)
+	}
+
 	query := `
-INSERT INTO feed_request (url, refreshed_at)
-VALUES ($1, $2)
+INSERT INTO feed_request (url, refreshed_at, lenient, encoding, extras, last_failure)
+VALUES ($1, $2, $3, $4, $5, $6)
 ON CONFLICT (url)`
 
 	if req.RefreshedAt.IsZero() {
 		query += " DO NOTHING"
 	} else {
-		query += " DO UPDATE SET refreshed_at = excluded.refreshed_at"
+		query += " DO UPDATE SET refreshed_at = excluded.refreshed_at, lenient = excluded.lenient, encoding = excluded.encoding, extras = excluded.extras, last_failure = excluded.last_failure"
 	}
 
-	_, err = tx.Exec(query, req.URL, req.RefreshedAt)
+	_, err = tx.Exec(query, req.URL, req.RefreshedAt, req.Lenient, req.Encoding, req.Extras, lastFailure)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("inserting feed request: %w", err)
@@ -339,7 +521,26 @@ CREATE TABLE IF NOT EXISTS agency (
     name TEXT NOT NULL,
     url TEXT NOT NULL,
     timezone TEXT NOT NULL,
+    lang TEXT,
+    phone TEXT,
+    fare_url TEXT,
+    email TEXT,
+    extras TEXT NOT NULL DEFAULT '',
     PRIMARY KEY(hash, id)
+);`,
+		"feed_info": `
+CREATE TABLE IF NOT EXISTS feed_info (
+    hash TEXT NOT NULL,
+    publisher_name TEXT NOT NULL,
+    publisher_url TEXT NOT NULL,
+    lang TEXT NOT NULL,
+    default_lang TEXT,
+    start_date TEXT,
+    end_date TEXT,
+    version TEXT,
+    contact_email TEXT,
+    contact_url TEXT,
+    PRIMARY KEY(hash)
 );`,
 		"stops": `
 CREATE TABLE IF NOT EXISTS stops (
@@ -354,6 +555,11 @@ CREATE TABLE IF NOT EXISTS stops (
     location_type INTEGER NOT NULL,
     parent_station TEXT,
     platform_code TEXT,
+    zone_id TEXT,
+    level_id TEXT,
+    timezone TEXT,
+    wheelchair_boarding INTEGER NOT NULL,
+    extras TEXT NOT NULL DEFAULT '',
     PRIMARY KEY(hash, id)
 );
 CREATE INDEX IF NOT EXISTS stops_parent_station ON stops (parent_station);
@@ -370,6 +576,11 @@ CREATE TABLE IF NOT EXISTS routes (
     url TEXT,
     color TEXT,
     text_color TEXT,
+    continuous_pickup INTEGER NOT NULL,
+    continuous_drop_off INTEGER NOT NULL,
+    sort_order INTEGER,
+    network_id TEXT NOT NULL DEFAULT '',
+    extras TEXT NOT NULL DEFAULT '',
     PRIMARY KEY(hash, id)
 );`,
 		"trips": `
@@ -381,10 +592,17 @@ CREATE TABLE IF NOT EXISTS trips (
     headsign TEXT,
     short_name TEXT,
     direction_id INTEGER,
+    shape_id TEXT,
+    wheelchair_accessible INTEGER NOT NULL,
+    block_id TEXT,
+    bikes_allowed INTEGER NOT NULL,
+    cars_allowed INTEGER NOT NULL,
+    extras TEXT NOT NULL DEFAULT '',
     PRIMARY KEY(hash, id)
 );
 CREATE INDEX IF NOT EXISTS trips_route_id ON trips (route_id);
 CREATE INDEX IF NOT EXISTS trips_service_id ON trips (service_id);
+CREATE INDEX IF NOT EXISTS trips_block_id ON trips (block_id);
 `,
 		"stop_times": `
 CREATE TABLE IF NOT EXISTS stop_times (
@@ -395,12 +613,260 @@ CREATE TABLE IF NOT EXISTS stop_times (
     arrival_time TEXT NOT NULL,
     departure_time TEXT NOT NULL,
     headsign TEXT,
+    pickup_type INTEGER NOT NULL,
+    drop_off_type INTEGER NOT NULL,
+    approximate BOOLEAN NOT NULL,
+    shape_dist_traveled DOUBLE PRECISION,
+    continuous_pickup INTEGER,
+    continuous_drop_off INTEGER,
     PRIMARY KEY(hash, trip_id, stop_id, stop_sequence)
 );
 CREATE INDEX IF NOT EXISTS stop_times_trip_id ON stop_times (trip_id);
 CREATE INDEX IF NOT EXISTS stop_times_stop_id ON stop_times (stop_id);
 CREATE INDEX IF NOT EXISTS stop_times_arrival_time ON stop_times (arrival_time);
 CREATE INDEX IF NOT EXISTS stop_times_departure_time ON stop_times (departure_time);
+`,
+		"shapes": `
+CREATE TABLE IF NOT EXISTS shapes (
+    hash TEXT NOT NULL,
+    shape_id TEXT NOT NULL,
+    lat DOUBLE PRECISION NOT NULL,
+    lon DOUBLE PRECISION NOT NULL,
+    sequence INTEGER NOT NULL,
+    dist_traveled DOUBLE PRECISION,
+    PRIMARY KEY(hash, shape_id, sequence)
+);
+`,
+		"frequencies": `
+CREATE TABLE IF NOT EXISTS frequencies (
+    hash TEXT NOT NULL,
+    trip_id TEXT NOT NULL,
+    start_time TEXT NOT NULL,
+    end_time TEXT NOT NULL,
+    headway_secs INTEGER NOT NULL,
+    exact_times BOOLEAN NOT NULL,
+    PRIMARY KEY(hash, trip_id, start_time)
+);
+`,
+		"transfers": `
+CREATE TABLE IF NOT EXISTS transfers (
+    hash TEXT NOT NULL,
+    from_stop_id TEXT NOT NULL,
+    to_stop_id TEXT NOT NULL,
+    from_route_id TEXT NOT NULL,
+    to_route_id TEXT NOT NULL,
+    from_trip_id TEXT NOT NULL,
+    to_trip_id TEXT NOT NULL,
+    transfer_type INTEGER NOT NULL,
+    min_transfer_time INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transfers_from_stop_id ON transfers (hash, from_stop_id);
+CREATE INDEX IF NOT EXISTS transfers_from_trip_id ON transfers (hash, from_trip_id);
+`,
+		"fare_attributes": `
+CREATE TABLE IF NOT EXISTS fare_attributes (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    currency_type TEXT NOT NULL,
+    payment_method INTEGER NOT NULL,
+    transfers INTEGER NOT NULL,
+    agency_id TEXT NOT NULL,
+    transfer_duration INTEGER NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+`,
+		"fare_rules": `
+CREATE TABLE IF NOT EXISTS fare_rules (
+    hash TEXT NOT NULL,
+    fare_id TEXT NOT NULL,
+    route_id TEXT NOT NULL,
+    origin_id TEXT NOT NULL,
+    destination_id TEXT NOT NULL,
+    contains_id TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS fare_rules_hash ON fare_rules (hash);
+`,
+		"networks": `
+CREATE TABLE IF NOT EXISTS networks (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+`,
+		"route_networks": `
+CREATE TABLE IF NOT EXISTS route_networks (
+    hash TEXT NOT NULL,
+    network_id TEXT NOT NULL,
+    route_id TEXT NOT NULL,
+    PRIMARY KEY(hash, route_id)
+);
+`,
+		"areas": `
+CREATE TABLE IF NOT EXISTS areas (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+`,
+		"stop_areas": `
+CREATE TABLE IF NOT EXISTS stop_areas (
+    hash TEXT NOT NULL,
+    area_id TEXT NOT NULL,
+    stop_id TEXT NOT NULL,
+    PRIMARY KEY(hash, area_id, stop_id)
+);
+`,
+		"fare_media": `
+CREATE TABLE IF NOT EXISTS fare_media (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    type INTEGER NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+`,
+		"fare_products": `
+CREATE TABLE IF NOT EXISTS fare_products (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    fare_media_id TEXT NOT NULL,
+    amount DOUBLE PRECISION NOT NULL,
+    currency TEXT NOT NULL,
+    PRIMARY KEY(hash, id, fare_media_id)
+);
+`,
+		"fare_leg_rules": `
+CREATE TABLE IF NOT EXISTS fare_leg_rules (
+    hash TEXT NOT NULL,
+    leg_group_id TEXT NOT NULL,
+    network_id TEXT NOT NULL,
+    from_area_id TEXT NOT NULL,
+    to_area_id TEXT NOT NULL,
+    fare_product_id TEXT NOT NULL,
+    rule_priority INTEGER
+);
+CREATE INDEX IF NOT EXISTS fare_leg_rules_hash ON fare_leg_rules (hash);
+`,
+		"fare_transfer_rules": `
+CREATE TABLE IF NOT EXISTS fare_transfer_rules (
+    hash TEXT NOT NULL,
+    from_leg_group_id TEXT NOT NULL,
+    to_leg_group_id TEXT NOT NULL,
+    transfer_count INTEGER NOT NULL,
+    duration_limit INTEGER NOT NULL,
+    duration_limit_type INTEGER NOT NULL,
+    fare_transfer_type INTEGER NOT NULL,
+    fare_product_id TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS fare_transfer_rules_hash ON fare_transfer_rules (hash);
+`,
+		"levels": `
+CREATE TABLE IF NOT EXISTS levels (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    level_index DOUBLE PRECISION NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+`,
+		"pathways": `
+CREATE TABLE IF NOT EXISTS pathways (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    from_stop_id TEXT NOT NULL,
+    to_stop_id TEXT NOT NULL,
+    mode INTEGER NOT NULL,
+    is_bidirectional BOOLEAN NOT NULL,
+    length DOUBLE PRECISION NOT NULL,
+    traversal_time INTEGER NOT NULL,
+    stair_count INTEGER NOT NULL,
+    max_slope DOUBLE PRECISION NOT NULL,
+    min_width DOUBLE PRECISION NOT NULL,
+    signposted_as TEXT NOT NULL,
+    reversed_signposted_as TEXT NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+`,
+		"translations": `
+CREATE TABLE IF NOT EXISTS translations (
+    hash TEXT NOT NULL,
+    table_name TEXT NOT NULL,
+    field_name TEXT NOT NULL,
+    language TEXT NOT NULL,
+    translation TEXT NOT NULL,
+    record_id TEXT NOT NULL,
+    record_sub_id TEXT NOT NULL,
+    field_value TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS translations_hash_table_field ON translations (hash, table_name, field_name);
+`,
+		"locations": `
+CREATE TABLE IF NOT EXISTS locations (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    geometry TEXT NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+`,
+		"location_groups": `
+CREATE TABLE IF NOT EXISTS location_groups (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+`,
+		"location_group_stops": `
+CREATE TABLE IF NOT EXISTS location_group_stops (
+    hash TEXT NOT NULL,
+    location_group_id TEXT NOT NULL,
+    stop_id TEXT NOT NULL,
+    PRIMARY KEY(hash, location_group_id, stop_id)
+);
+`,
+		"booking_rules": `
+CREATE TABLE IF NOT EXISTS booking_rules (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    booking_type INTEGER NOT NULL,
+    prior_notice_duration_min INTEGER NOT NULL,
+    prior_notice_duration_max INTEGER NOT NULL,
+    prior_notice_last_day INTEGER NOT NULL,
+    prior_notice_last_time TEXT NOT NULL,
+    prior_notice_start_day INTEGER NOT NULL,
+    prior_notice_start_time TEXT NOT NULL,
+    prior_notice_service_id TEXT NOT NULL,
+    message TEXT NOT NULL,
+    pickup_message TEXT NOT NULL,
+    drop_off_message TEXT NOT NULL,
+    phone_number TEXT NOT NULL,
+    info_url TEXT NOT NULL,
+    booking_url TEXT NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+`,
+		"flex_stop_times": `
+CREATE TABLE IF NOT EXISTS flex_stop_times (
+    hash TEXT NOT NULL,
+    trip_id TEXT NOT NULL,
+    stop_sequence INTEGER NOT NULL,
+    stop_id TEXT NOT NULL,
+    location_group_id TEXT NOT NULL,
+    location_id TEXT NOT NULL,
+    start_window TEXT NOT NULL,
+    end_window TEXT NOT NULL,
+    pickup_type INTEGER NOT NULL,
+    drop_off_type INTEGER NOT NULL,
+    pickup_booking_rule_id TEXT NOT NULL,
+    drop_off_booking_rule_id TEXT NOT NULL,
+    PRIMARY KEY(hash, trip_id, stop_sequence)
+);
 `,
 		"calendar": `
 CREATE TABLE IF NOT EXISTS calendar (
@@ -453,13 +919,18 @@ CREATE TABLE IF NOT EXISTS calendar_dates (
 
 func (w *PSQLFeedWriter) WriteAgency(a model.Agency) error {
 	_, err := w.db.Exec(`
-INSERT INTO agency (hash, id, name, url, timezone)
-VALUES ($1, $2, $3, $4, $5)`,
+INSERT INTO agency (hash, id, name, url, timezone, lang, phone, fare_url, email, extras)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
 		w.id,
 		a.ID,
 		a.Name,
 		a.URL,
 		a.Timezone,
+		a.Lang,
+		a.Phone,
+		a.FareURL,
+		a.Email,
+		encodeExtras(a.Extras),
 	)
 	if err != nil {
 		return fmt.Errorf("inserting agency: %w", err)
@@ -467,6 +938,27 @@ VALUES ($1, $2, $3, $4, $5)`,
 	return nil
 }
 
+func (w *PSQLFeedWriter) WriteFeedInfo(fi model.FeedInfo) error {
+	_, err := w.db.Exec(`
+INSERT INTO feed_info (hash, publisher_name, publisher_url, lang, default_lang, start_date, end_date, version, contact_email, contact_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		w.id,
+		fi.PublisherName,
+		fi.PublisherURL,
+		fi.Lang,
+		fi.DefaultLang,
+		fi.StartDate,
+		fi.EndDate,
+		fi.Version,
+		fi.ContactEmail,
+		fi.ContactURL,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting feed info: %w", err)
+	}
+	return nil
+}
+
 func (w *PSQLFeedWriter) WriteStop(stop model.Stop) error {
 	var parentStation sql.NullString
 	if stop.ParentStation != "" {
@@ -476,8 +968,8 @@ func (w *PSQLFeedWriter) WriteStop(stop model.Stop) error {
 		}
 	}
 	_, err := w.db.Exec(`
-INSERT INTO stops (hash, id, code, name, description, lat, lon, url, location_type, parent_station, platform_code)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+INSERT INTO stops (hash, id, code, name, description, lat, lon, url, location_type, parent_station, platform_code, zone_id, level_id, timezone, wheelchair_boarding, extras)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
 		w.id,
 		stop.ID,
 		stop.Code,
@@ -489,6 +981,11 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
 		stop.LocationType,
 		parentStation,
 		stop.PlatformCode,
+		stop.ZoneID,
+		stop.LevelID,
+		stop.Timezone,
+		stop.WheelchairBoarding,
+		encodeExtras(stop.Extras),
 	)
 	if err != nil {
 		return fmt.Errorf("inserting stop: %w", err)
@@ -498,8 +995,8 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
 
 func (w *PSQLFeedWriter) WriteRoute(route model.Route) error {
 	_, err := w.db.Exec(`
-INSERT INTO routes (hash, id, agency_id, short_name, long_name, description, type, url, color, text_color)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+INSERT INTO routes (hash, id, agency_id, short_name, long_name, description, type, url, color, text_color, continuous_pickup, continuous_drop_off, sort_order, network_id, extras)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
 		w.id,
 		route.ID,
 		route.AgencyID,
@@ -510,6 +1007,14 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
 		route.URL,
 		route.Color,
 		route.TextColor,
+		route.ContinuousPickup,
+		route.ContinuousDropOff,
+		sql.NullInt64{
+			Int64: int64(route.SortOrder),
+			Valid: route.HasSortOrder,
+		},
+		route.NetworkID,
+		encodeExtras(route.Extras),
 	)
 	if err != nil {
 		return fmt.Errorf("inserting route: %w", err)
@@ -552,7 +1057,7 @@ func (w *PSQLFeedWriter) flushTrips() error {
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(pq.CopyIn(
-		"trips", "hash", "id", "route_id", "service_id", "headsign", "short_name", "direction_id",
+		"trips", "hash", "id", "route_id", "service_id", "headsign", "short_name", "direction_id", "shape_id", "wheelchair_accessible", "block_id", "bikes_allowed", "cars_allowed", "extras",
 	))
 	if err != nil {
 		return fmt.Errorf("preparing statement: %w", err)
@@ -561,7 +1066,7 @@ func (w *PSQLFeedWriter) flushTrips() error {
 
 	for _, trip := range w.tripBuf {
 		_, err = stmt.Exec(
-			w.id, trip.ID, trip.RouteID, trip.ServiceID, trip.Headsign, trip.ShortName, trip.DirectionID,
+			w.id, trip.ID, trip.RouteID, trip.ServiceID, trip.Headsign, trip.ShortName, trip.DirectionID, trip.ShapeID, trip.WheelchairAccessible, trip.BlockID, trip.BikesAllowed, trip.CarsAllowed, encodeExtras(trip.Extras),
 		)
 		if err != nil {
 			return fmt.Errorf("COPY trip: %w", err)
@@ -583,91 +1088,34 @@ func (w *PSQLFeedWriter) flushTrips() error {
 	return nil
 }
 
-func (w *PSQLFeedWriter) WriteCalendar(cal model.Calendar) error {
-	mon, tue, wed, thu, fri, sat, sun := 0, 0, 0, 0, 0, 0, 0
-	if cal.Weekday&(1<<time.Monday) != 0 {
-		mon = 1
-	}
-	if cal.Weekday&(1<<time.Tuesday) != 0 {
-		tue = 1
-	}
-	if cal.Weekday&(1<<time.Wednesday) != 0 {
-		wed = 1
-	}
-	if cal.Weekday&(1<<time.Thursday) != 0 {
-		thu = 1
-	}
-	if cal.Weekday&(1<<time.Friday) != 0 {
-		fri = 1
-	}
-	if cal.Weekday&(1<<time.Saturday) != 0 {
-		sat = 1
-	}
-	if cal.Weekday&(1<<time.Sunday) != 0 {
-		sun = 1
-	}
-
-	_, err := w.db.Exec(`
-INSERT INTO calendar (hash, service_id, start_date, end_date, monday, tuesday, wednesday, thursday, friday, saturday, sunday)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
-		w.id,
-		cal.ServiceID,
-		cal.StartDate,
-		cal.EndDate,
-		mon, tue, wed, thu, fri, sat, sun,
-	)
-	if err != nil {
-		return fmt.Errorf("inserting calendar: %w", err)
-	}
-
-	return nil
-}
-
-func (w *PSQLFeedWriter) WriteCalendarDate(cd model.CalendarDate) error {
-	_, err := w.db.Exec(`
-INSERT INTO calendar_dates (hash, service_id, date, exception_type)
-VALUES ($1, $2, $3, $4)`,
-		w.id,
-		cd.ServiceID,
-		cd.Date,
-		cd.ExceptionType,
-	)
-	if err != nil {
-		return fmt.Errorf("inserting calendar date: %w", err)
-	}
-
-	return nil
-
-}
-
-func (w *PSQLFeedWriter) BeginStopTimes() error {
+func (w *PSQLFeedWriter) BeginShapes() error {
 	return nil
 }
 
-func (w *PSQLFeedWriter) WriteStopTime(stopTime model.StopTime) error {
-	w.stopTimeBuf = append(w.stopTimeBuf, stopTime)
+func (w *PSQLFeedWriter) WriteShapePoint(shapeID string, point model.ShapePoint) error {
+	w.shapeBuf = append(w.shapeBuf, psqlShapePoint{shapeID, point})
 
-	if len(w.stopTimeBuf) >= PSQLStopTimeBatchSize {
-		err := w.flushStopTimes()
+	if len(w.shapeBuf) >= PSQLShapeBatchSize {
+		err := w.flushShapes()
 		if err != nil {
-			return fmt.Errorf("flushing stop_times: %w", err)
+			return fmt.Errorf("flushing shapes: %w", err)
 		}
 	}
 
 	return nil
 }
 
-func (w *PSQLFeedWriter) EndStopTimes() error {
-	if len(w.stopTimeBuf) > 0 {
-		err := w.flushStopTimes()
+func (w *PSQLFeedWriter) EndShapes() error {
+	if len(w.shapeBuf) > 0 {
+		err := w.flushShapes()
 		if err != nil {
-			return fmt.Errorf("flushing stop_times: %w", err)
+			return fmt.Errorf("flushing shapes: %w", err)
 		}
 	}
 	return nil
 }
 
-func (w *PSQLFeedWriter) flushStopTimes() error {
+func (w *PSQLFeedWriter) flushShapes() error {
 	tx, err := w.db.Begin()
 	if err != nil {
 		return fmt.Errorf("starting transaction: %w", err)
@@ -675,25 +1123,23 @@ func (w *PSQLFeedWriter) flushStopTimes() error {
 	defer tx.Rollback()
 
 	stmt, err := tx.Prepare(pq.CopyIn(
-		"stop_times", "hash", "trip_id", "stop_id", "stop_sequence", "arrival_time", "departure_time", "headsign",
+		"shapes", "hash", "shape_id", "lat", "lon", "sequence", "dist_traveled",
 	))
 	if err != nil {
 		return fmt.Errorf("preparing statement: %w", err)
 	}
 	defer stmt.Close()
 
-	for _, stopTime := range w.stopTimeBuf {
+	for _, sp := range w.shapeBuf {
+		dist := sql.NullFloat64{
+			Float64: sp.point.DistTraveled,
+			Valid:   sp.point.HasDistTraveled,
+		}
 		_, err = stmt.Exec(
-			w.id,
-			stopTime.TripID,
-			stopTime.StopID,
-			stopTime.StopSequence,
-			stopTime.Arrival,
-			stopTime.Departure,
-			stopTime.Headsign,
+			w.id, sp.shapeID, sp.point.Lat, sp.point.Lon, sp.point.Sequence, dist,
 		)
 		if err != nil {
-			return fmt.Errorf("COPY stop_time: %w", err)
+			return fmt.Errorf("COPY shape: %w", err)
 		}
 	}
 
@@ -707,328 +1153,1910 @@ func (w *PSQLFeedWriter) flushStopTimes() error {
 		return fmt.Errorf("committing: %w", err)
 	}
 
-	w.stopTimeBuf = nil
+	w.shapeBuf = nil
 
 	return nil
 }
 
-func (s *PSQLFeedWriter) Close() error {
-	_, err := s.db.Exec(`ANALYZE`)
+func (w *PSQLFeedWriter) WriteFrequency(freq model.Frequency) error {
+	_, err := w.db.Exec(`
+INSERT INTO frequencies (hash, trip_id, start_time, end_time, headway_secs, exact_times)
+VALUES ($1, $2, $3, $4, $5, $6)`,
+		w.id,
+		freq.TripID,
+		freq.Start,
+		freq.End,
+		freq.HeadwaySecs,
+		freq.ExactTimes,
+	)
 	if err != nil {
-		return fmt.Errorf("analyzing: %w", err)
+		return fmt.Errorf("inserting frequency: %w", err)
 	}
+
 	return nil
 }
 
-func (r *PSQLFeedReader) Agencies() ([]model.Agency, error) {
-	rows, err := r.db.Query(`
-SELECT id, name, url, timezone
-FROM agency
-WHERE hash = $1`, r.id)
+func (w *PSQLFeedWriter) WriteTransfer(transfer model.Transfer) error {
+	_, err := w.db.Exec(`
+INSERT INTO transfers (hash, from_stop_id, to_stop_id, from_route_id, to_route_id, from_trip_id, to_trip_id, transfer_type, min_transfer_time)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		w.id,
+		transfer.FromStopID,
+		transfer.ToStopID,
+		transfer.FromRouteID,
+		transfer.ToRouteID,
+		transfer.FromTripID,
+		transfer.ToTripID,
+		transfer.Type,
+		transfer.MinTransferTime,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("querying agencies: %w", err)
+		return fmt.Errorf("inserting transfer: %w", err)
 	}
-	defer rows.Close()
 
-	agencies := []model.Agency{}
-	for rows.Next() {
-		a := model.Agency{}
-		err := rows.Scan(&a.ID, &a.Name, &a.URL, &a.Timezone)
-		if err != nil {
-			return nil, fmt.Errorf("scanning agency: %w", err)
-		}
-		agencies = append(agencies, a)
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteFareAttribute(fare model.FareAttribute) error {
+	_, err := w.db.Exec(`
+INSERT INTO fare_attributes (hash, id, price, currency_type, payment_method, transfers, agency_id, transfer_duration)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		w.id,
+		fare.ID,
+		fare.Price,
+		fare.CurrencyType,
+		fare.PaymentMethod,
+		fare.Transfers,
+		fare.AgencyID,
+		fare.TransferDuration,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting fare attribute: %w", err)
 	}
 
-	return agencies, nil
+	return nil
 }
 
-func (r *PSQLFeedReader) Stops() ([]model.Stop, error) {
-	rows, err := r.db.Query(`
-SELECT id, code, name, description, lat, lon, url, location_type, parent_station, platform_code
-FROM stops
-WHERE hash = $1`, r.id)
+func (w *PSQLFeedWriter) WriteFareRule(rule model.FareRule) error {
+	_, err := w.db.Exec(`
+INSERT INTO fare_rules (hash, fare_id, route_id, origin_id, destination_id, contains_id)
+VALUES ($1, $2, $3, $4, $5, $6)`,
+		w.id,
+		rule.FareID,
+		rule.RouteID,
+		rule.OriginID,
+		rule.DestinationID,
+		rule.ContainsID,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("querying stops: %w", err)
+		return fmt.Errorf("inserting fare rule: %w", err)
 	}
-	defer rows.Close()
 
-	stops := []model.Stop{}
-	for rows.Next() {
-		s := model.Stop{}
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteNetwork(network model.Network) error {
+	_, err := w.db.Exec(`
+INSERT INTO networks (hash, id, name)
+VALUES ($1, $2, $3)`,
+		w.id,
+		network.ID,
+		network.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting network: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteRouteNetwork(routeNetwork model.RouteNetwork) error {
+	_, err := w.db.Exec(`
+INSERT INTO route_networks (hash, network_id, route_id)
+VALUES ($1, $2, $3)`,
+		w.id,
+		routeNetwork.NetworkID,
+		routeNetwork.RouteID,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting route network: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteArea(area model.Area) error {
+	_, err := w.db.Exec(`
+INSERT INTO areas (hash, id, name)
+VALUES ($1, $2, $3)`,
+		w.id,
+		area.ID,
+		area.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting area: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteStopArea(stopArea model.StopArea) error {
+	_, err := w.db.Exec(`
+INSERT INTO stop_areas (hash, area_id, stop_id)
+VALUES ($1, $2, $3)`,
+		w.id,
+		stopArea.AreaID,
+		stopArea.StopID,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting stop area: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteFareMedia(media model.FareMedia) error {
+	_, err := w.db.Exec(`
+INSERT INTO fare_media (hash, id, name, type)
+VALUES ($1, $2, $3, $4)`,
+		w.id,
+		media.ID,
+		media.Name,
+		media.Type,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting fare media: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteFareProduct(product model.FareProduct) error {
+	_, err := w.db.Exec(`
+INSERT INTO fare_products (hash, id, name, fare_media_id, amount, currency)
+VALUES ($1, $2, $3, $4, $5, $6)`,
+		w.id,
+		product.ID,
+		product.Name,
+		product.FareMediaID,
+		product.Amount,
+		product.Currency,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting fare product: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteFareLegRule(rule model.FareLegRule) error {
+	_, err := w.db.Exec(`
+INSERT INTO fare_leg_rules (hash, leg_group_id, network_id, from_area_id, to_area_id, fare_product_id, rule_priority)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		w.id,
+		rule.LegGroupID,
+		rule.NetworkID,
+		rule.FromAreaID,
+		rule.ToAreaID,
+		rule.FareProductID,
+		sql.NullInt64{
+			Int64: int64(rule.RulePriority),
+			Valid: rule.HasRulePriority,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("inserting fare leg rule: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteFareTransferRule(rule model.FareTransferRule) error {
+	_, err := w.db.Exec(`
+INSERT INTO fare_transfer_rules (hash, from_leg_group_id, to_leg_group_id, transfer_count, duration_limit, duration_limit_type, fare_transfer_type, fare_product_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		w.id,
+		rule.FromLegGroupID,
+		rule.ToLegGroupID,
+		rule.TransferCount,
+		rule.DurationLimit,
+		rule.DurationLimitType,
+		rule.FareTransferType,
+		rule.FareProductID,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting fare transfer rule: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteLevel(level model.Level) error {
+	_, err := w.db.Exec(`
+INSERT INTO levels (hash, id, level_index, name)
+VALUES ($1, $2, $3, $4)`,
+		w.id,
+		level.ID,
+		level.Index,
+		level.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting level: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WritePathway(pathway model.Pathway) error {
+	_, err := w.db.Exec(`
+INSERT INTO pathways (hash, id, from_stop_id, to_stop_id, mode, is_bidirectional, length, traversal_time, stair_count, max_slope, min_width, signposted_as, reversed_signposted_as)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		w.id,
+		pathway.ID,
+		pathway.FromStopID,
+		pathway.ToStopID,
+		pathway.Mode,
+		pathway.IsBidirectional,
+		pathway.Length,
+		pathway.TraversalTime,
+		pathway.StairCount,
+		pathway.MaxSlope,
+		pathway.MinWidth,
+		pathway.SignpostedAs,
+		pathway.ReversedSignpostedAs,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting pathway: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteTranslation(translation model.Translation) error {
+	_, err := w.db.Exec(`
+INSERT INTO translations (hash, table_name, field_name, language, translation, record_id, record_sub_id, field_value)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		w.id,
+		translation.TableName,
+		translation.FieldName,
+		translation.Language,
+		translation.Translation,
+		translation.RecordID,
+		translation.RecordSubID,
+		translation.FieldValue,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting translation: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteLocation(location model.Location) error {
+	geometry, err := json.Marshal(location.Polygons)
+	if err != nil {
+		return fmt.Errorf("marshaling location geometry: %w", err)
+	}
+
+	_, err = w.db.Exec(`
+INSERT INTO locations (hash, id, name, description, geometry)
+VALUES ($1, $2, $3, $4, $5)`,
+		w.id,
+		location.ID,
+		location.Name,
+		location.Desc,
+		string(geometry),
+	)
+	if err != nil {
+		return fmt.Errorf("inserting location: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteLocationGroup(group model.LocationGroup) error {
+	_, err := w.db.Exec(`
+INSERT INTO location_groups (hash, id, name)
+VALUES ($1, $2, $3)`,
+		w.id,
+		group.ID,
+		group.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting location group: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteLocationGroupStop(lgs model.LocationGroupStop) error {
+	_, err := w.db.Exec(`
+INSERT INTO location_group_stops (hash, location_group_id, stop_id)
+VALUES ($1, $2, $3)`,
+		w.id,
+		lgs.LocationGroupID,
+		lgs.StopID,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting location group stop: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteBookingRule(rule model.BookingRule) error {
+	_, err := w.db.Exec(`
+INSERT INTO booking_rules (hash, id, booking_type, prior_notice_duration_min, prior_notice_duration_max, prior_notice_last_day, prior_notice_last_time, prior_notice_start_day, prior_notice_start_time, prior_notice_service_id, message, pickup_message, drop_off_message, phone_number, info_url, booking_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		w.id,
+		rule.ID,
+		rule.Type,
+		rule.PriorNoticeDurationMin,
+		rule.PriorNoticeDurationMax,
+		rule.PriorNoticeLastDay,
+		rule.PriorNoticeLastTime,
+		rule.PriorNoticeStartDay,
+		rule.PriorNoticeStartTime,
+		rule.PriorNoticeServiceID,
+		rule.Message,
+		rule.PickupMessage,
+		rule.DropOffMessage,
+		rule.PhoneNumber,
+		rule.InfoURL,
+		rule.BookingURL,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting booking rule: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteFlexStopTime(stopTime model.FlexStopTime) error {
+	_, err := w.db.Exec(`
+INSERT INTO flex_stop_times (hash, trip_id, stop_sequence, stop_id, location_group_id, location_id, start_window, end_window, pickup_type, drop_off_type, pickup_booking_rule_id, drop_off_booking_rule_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		w.id,
+		stopTime.TripID,
+		stopTime.StopSequence,
+		stopTime.StopID,
+		stopTime.LocationGroupID,
+		stopTime.LocationID,
+		stopTime.StartWindow,
+		stopTime.EndWindow,
+		stopTime.PickupType,
+		stopTime.DropOffType,
+		stopTime.PickupBookingRuleID,
+		stopTime.DropOffBookingRuleID,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting flex stop_time: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteCalendar(cal model.Calendar) error {
+	mon, tue, wed, thu, fri, sat, sun := 0, 0, 0, 0, 0, 0, 0
+	if cal.Weekday&(1<<time.Monday) != 0 {
+		mon = 1
+	}
+	if cal.Weekday&(1<<time.Tuesday) != 0 {
+		tue = 1
+	}
+	if cal.Weekday&(1<<time.Wednesday) != 0 {
+		wed = 1
+	}
+	if cal.Weekday&(1<<time.Thursday) != 0 {
+		thu = 1
+	}
+	if cal.Weekday&(1<<time.Friday) != 0 {
+		fri = 1
+	}
+	if cal.Weekday&(1<<time.Saturday) != 0 {
+		sat = 1
+	}
+	if cal.Weekday&(1<<time.Sunday) != 0 {
+		sun = 1
+	}
+
+	_, err := w.db.Exec(`
+INSERT INTO calendar (hash, service_id, start_date, end_date, monday, tuesday, wednesday, thursday, friday, saturday, sunday)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		w.id,
+		cal.ServiceID,
+		cal.StartDate,
+		cal.EndDate,
+		mon, tue, wed, thu, fri, sat, sun,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting calendar: %w", err)
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteCalendarDate(cd model.CalendarDate) error {
+	_, err := w.db.Exec(`
+INSERT INTO calendar_dates (hash, service_id, date, exception_type)
+VALUES ($1, $2, $3, $4)`,
+		w.id,
+		cd.ServiceID,
+		cd.Date,
+		cd.ExceptionType,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting calendar date: %w", err)
+	}
+
+	return nil
+
+}
+
+func (w *PSQLFeedWriter) BeginStopTimes() error {
+	return nil
+}
+
+func (w *PSQLFeedWriter) WriteStopTime(stopTime model.StopTime) error {
+	w.stopTimeBuf = append(w.stopTimeBuf, stopTime)
+
+	if len(w.stopTimeBuf) >= PSQLStopTimeBatchSize {
+		err := w.flushStopTimes()
+		if err != nil {
+			return fmt.Errorf("flushing stop_times: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *PSQLFeedWriter) EndStopTimes() error {
+	if len(w.stopTimeBuf) > 0 {
+		err := w.flushStopTimes()
+		if err != nil {
+			return fmt.Errorf("flushing stop_times: %w", err)
+		}
+	}
+	return nil
+}
+
+func (w *PSQLFeedWriter) flushStopTimes() error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(pq.CopyIn(
+		"stop_times", "hash", "trip_id", "stop_id", "stop_sequence", "arrival_time", "departure_time", "headsign", "pickup_type", "drop_off_type", "approximate", "shape_dist_traveled", "continuous_pickup", "continuous_drop_off",
+	))
+	if err != nil {
+		return fmt.Errorf("preparing statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, stopTime := range w.stopTimeBuf {
+		_, err = stmt.Exec(
+			w.id,
+			stopTime.TripID,
+			stopTime.StopID,
+			stopTime.StopSequence,
+			stopTime.Arrival,
+			stopTime.Departure,
+			stopTime.Headsign,
+			stopTime.PickupType,
+			stopTime.DropOffType,
+			stopTime.Approximate,
+			sql.NullFloat64{
+				Float64: stopTime.ShapeDistTraveled,
+				Valid:   stopTime.HasShapeDistTraveled,
+			},
+			sql.NullInt64{
+				Int64: int64(stopTime.ContinuousPickup),
+				Valid: stopTime.HasContinuousPickup,
+			},
+			sql.NullInt64{
+				Int64: int64(stopTime.ContinuousDropOff),
+				Valid: stopTime.HasContinuousDropOff,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("COPY stop_time: %w", err)
+		}
+	}
+
+	_, err = stmt.Exec()
+	if err != nil {
+		return fmt.Errorf("executing statement: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing: %w", err)
+	}
+
+	w.stopTimeBuf = nil
+
+	return nil
+}
+
+func (s *PSQLFeedWriter) Close() error {
+	_, err := s.db.Exec(`ANALYZE`)
+	if err != nil {
+		return fmt.Errorf("analyzing: %w", err)
+	}
+	return nil
+}
+
+func (r *PSQLFeedReader) Agencies() ([]model.Agency, error) {
+	rows, err := r.db.Query(`
+SELECT id, name, url, timezone, COALESCE(lang, ''), COALESCE(phone, ''), COALESCE(fare_url, ''), COALESCE(email, ''), extras
+FROM agency
+WHERE hash = $1`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying agencies: %w", err)
+	}
+	defer rows.Close()
+
+	agencies := []model.Agency{}
+	for rows.Next() {
+		a := model.Agency{}
+		extras := ""
+		err := rows.Scan(&a.ID, &a.Name, &a.URL, &a.Timezone, &a.Lang, &a.Phone, &a.FareURL, &a.Email, &extras)
+		if err != nil {
+			return nil, fmt.Errorf("scanning agency: %w", err)
+		}
+		a.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
+		agencies = append(agencies, a)
+	}
+
+	return agencies, nil
+}
+
+func (r *PSQLFeedReader) FeedInfo() ([]model.FeedInfo, error) {
+	rows, err := r.db.Query(`
+SELECT publisher_name, publisher_url, lang, default_lang, start_date, end_date, version, contact_email, contact_url
+FROM feed_info
+WHERE hash = $1`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying feed info: %w", err)
+	}
+	defer rows.Close()
+
+	feedInfo := []model.FeedInfo{}
+	for rows.Next() {
+		fi := model.FeedInfo{}
+		err := rows.Scan(
+			&fi.PublisherName,
+			&fi.PublisherURL,
+			&fi.Lang,
+			&fi.DefaultLang,
+			&fi.StartDate,
+			&fi.EndDate,
+			&fi.Version,
+			&fi.ContactEmail,
+			&fi.ContactURL,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning feed info: %w", err)
+		}
+		feedInfo = append(feedInfo, fi)
+	}
+
+	return feedInfo, nil
+}
+
+func (r *PSQLFeedReader) Stops() ([]model.Stop, error) {
+	rows, err := r.db.Query(`
+SELECT id, COALESCE(code, ''), name, COALESCE(description, ''), lat, lon, COALESCE(url, ''), location_type, parent_station, COALESCE(platform_code, ''), COALESCE(zone_id, ''), COALESCE(level_id, ''), COALESCE(timezone, ''), wheelchair_boarding, extras
+FROM stops
+WHERE hash = $1`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying stops: %w", err)
+	}
+	defer rows.Close()
+
+	stops := []model.Stop{}
+	for rows.Next() {
+		s := model.Stop{}
 		parentStation := sql.NullString{}
+		extras := ""
+		err := rows.Scan(
+			&s.ID,
+			&s.Code,
+			&s.Name,
+			&s.Desc,
+			&s.Lat,
+			&s.Lon,
+			&s.URL,
+			&s.LocationType,
+			&parentStation,
+			&s.PlatformCode,
+			&s.ZoneID,
+			&s.LevelID,
+			&s.Timezone,
+			&s.WheelchairBoarding,
+			&extras,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning stop: %w", err)
+		}
+		s.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
+
+		if parentStation.Valid {
+			s.ParentStation = parentStation.String
+		}
+
+		stops = append(stops, s)
+	}
+
+	return stops, nil
+}
+
+func (r *PSQLFeedReader) Stop(stopID string) (*model.Stop, error) {
+	row := r.db.QueryRow(`
+SELECT id, COALESCE(code, ''), name, COALESCE(description, ''), lat, lon, COALESCE(url, ''), location_type, parent_station, COALESCE(platform_code, ''), COALESCE(zone_id, ''), COALESCE(level_id, ''), COALESCE(timezone, ''), wheelchair_boarding, extras
+FROM stops
+WHERE hash = $1 AND id = $2`, r.id, stopID)
+
+	s := &model.Stop{}
+	parentStation := sql.NullString{}
+	extras := ""
+	err := row.Scan(
+		&s.ID,
+		&s.Code,
+		&s.Name,
+		&s.Desc,
+		&s.Lat,
+		&s.Lon,
+		&s.URL,
+		&s.LocationType,
+		&parentStation,
+		&s.PlatformCode,
+		&s.ZoneID,
+		&s.LevelID,
+		&s.Timezone,
+		&s.WheelchairBoarding,
+		&extras,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning stop: %w", err)
+	}
+	s.Extras, err = decodeExtras(extras)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentStation.Valid {
+		s.ParentStation = parentStation.String
+	}
+
+	return s, nil
+}
+
+func (r *PSQLFeedReader) Routes() ([]model.Route, error) {
+	return r.routes(`
+SELECT id, COALESCE(agency_id, ''), COALESCE(short_name, ''), long_name, COALESCE(description, ''), type, COALESCE(url, ''), COALESCE(color, ''), COALESCE(text_color, ''), continuous_pickup, continuous_drop_off, sort_order, network_id, extras
+FROM routes
+WHERE hash = $1`, r.id)
+}
+
+func (r *PSQLFeedReader) StopRoutes(stopID string) ([]model.Route, error) {
+	return r.routes(`
+SELECT id, COALESCE(agency_id, ''), COALESCE(short_name, ''), long_name, COALESCE(description, ''), type, COALESCE(url, ''), COALESCE(color, ''), COALESCE(text_color, ''), continuous_pickup, continuous_drop_off, sort_order, network_id, extras
+FROM routes
+WHERE hash = $1 AND id IN (
+    SELECT DISTINCT trips.route_id
+    FROM stop_times
+    INNER JOIN trips ON trips.hash = stop_times.hash AND trips.id = stop_times.trip_id
+    WHERE stop_times.hash = $1 AND stop_times.stop_id IN (
+        SELECT id FROM stops WHERE hash = $1 AND (id = $2 OR parent_station = $2)
+    )
+)
+ORDER BY id`, r.id, stopID)
+}
+
+func (r *PSQLFeedReader) routes(query string, args ...interface{}) ([]model.Route, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying routes: %w", err)
+	}
+	defer rows.Close()
+
+	routes := []model.Route{}
+	for rows.Next() {
+		route := model.Route{}
+		sortOrder := sql.NullInt64{}
+		extras := ""
+		err := rows.Scan(
+			&route.ID,
+			&route.AgencyID,
+			&route.ShortName,
+			&route.LongName,
+			&route.Desc,
+			&route.Type,
+			&route.URL,
+			&route.Color,
+			&route.TextColor,
+			&route.ContinuousPickup,
+			&route.ContinuousDropOff,
+			&sortOrder,
+			&route.NetworkID,
+			&extras,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning route: %w", err)
+		}
+		route.SortOrder = int(sortOrder.Int64)
+		route.HasSortOrder = sortOrder.Valid
+		route.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+func (r *PSQLFeedReader) Trips() ([]model.Trip, error) {
+	rows, err := r.db.Query(`
+SELECT id, route_id, service_id, COALESCE(headsign, ''), COALESCE(short_name, ''), direction_id, COALESCE(shape_id, ''), wheelchair_accessible, COALESCE(block_id, ''), bikes_allowed, cars_allowed, extras
+FROM trips
+WHERE hash = $1`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying trips: %w", err)
+	}
+	defer rows.Close()
+
+	trips := []model.Trip{}
+	for rows.Next() {
+		t := model.Trip{}
+		extras := ""
+		err := rows.Scan(
+			&t.ID,
+			&t.RouteID,
+			&t.ServiceID,
+			&t.Headsign,
+			&t.ShortName,
+			&t.DirectionID,
+			&t.ShapeID,
+			&t.WheelchairAccessible,
+			&t.BlockID,
+			&t.BikesAllowed,
+			&t.CarsAllowed,
+			&extras,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning trip: %w", err)
+		}
+		t.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, t)
+	}
+
+	return trips, nil
+}
+
+func (r *PSQLFeedReader) StopTimes() ([]model.StopTime, error) {
+	rows, err := r.db.Query(`
+SELECT trip_id, stop_id, COALESCE(headsign, ''), stop_sequence, arrival_time, departure_time, pickup_type, drop_off_type, approximate, shape_dist_traveled, continuous_pickup, continuous_drop_off
+FROM stop_times
+WHERE hash = $1
+ORDER BY trip_id, stop_sequence`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying stop times: %w", err)
+	}
+	defer rows.Close()
+
+	stopTimes := []model.StopTime{}
+	for rows.Next() {
+		st := model.StopTime{}
+		shapeDist := sql.NullFloat64{}
+		continuousPickup := sql.NullInt64{}
+		continuousDropOff := sql.NullInt64{}
+		err := rows.Scan(
+			&st.TripID,
+			&st.StopID,
+			&st.Headsign,
+			&st.StopSequence,
+			&st.Arrival,
+			&st.Departure,
+			&st.PickupType,
+			&st.DropOffType,
+			&st.Approximate,
+			&shapeDist,
+			&continuousPickup,
+			&continuousDropOff,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning stop time: %w", err)
+		}
+		st.ShapeDistTraveled = shapeDist.Float64
+		st.HasShapeDistTraveled = shapeDist.Valid
+		st.ContinuousPickup = model.ContinuousStopping(continuousPickup.Int64)
+		st.HasContinuousPickup = continuousPickup.Valid
+		st.ContinuousDropOff = model.ContinuousStopping(continuousDropOff.Int64)
+		st.HasContinuousDropOff = continuousDropOff.Valid
+		stopTimes = append(stopTimes, st)
+	}
+
+	return stopTimes, nil
+}
+
+func (r *PSQLFeedReader) Calendars() ([]model.Calendar, error) {
+	rows, err := r.db.Query(`
+SELECT service_id, start_date, end_date, monday, tuesday, wednesday, thursday, friday, saturday, sunday
+FROM calendar
+WHERE hash = $1`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying calendar: %w", err)
+	}
+	defer rows.Close()
+
+	calendars := []model.Calendar{}
+	for rows.Next() {
+		var serviceID, startDate, endDate string
+		var monday, tuesday, wednesday, thursday, friday, saturday, sunday bool
+		err := rows.Scan(
+			&serviceID,
+			&startDate,
+			&endDate,
+			&monday,
+			&tuesday,
+			&wednesday,
+			&thursday,
+			&friday,
+			&saturday,
+			&sunday,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning calendar: %w", err)
+		}
+		weekday := int8(0)
+		if monday {
+			weekday |= 1 << time.Monday
+		}
+		if tuesday {
+			weekday |= 1 << time.Tuesday
+		}
+		if wednesday {
+			weekday |= 1 << time.Wednesday
+		}
+		if thursday {
+			weekday |= 1 << time.Thursday
+		}
+		if friday {
+			weekday |= 1 << time.Friday
+		}
+		if saturday {
+			weekday |= 1 << time.Saturday
+		}
+		if sunday {
+			weekday |= 1 << time.Sunday
+		}
+		calendars = append(calendars, model.Calendar{
+			ServiceID: serviceID,
+			StartDate: startDate,
+			EndDate:   endDate,
+			Weekday:   weekday,
+		})
+	}
+
+	return calendars, nil
+}
+
+func (r *PSQLFeedReader) CalendarDates() ([]model.CalendarDate, error) {
+	rows, err := r.db.Query(`
+SELECT service_id, date, exception_type
+FROM calendar_dates
+WHERE hash = $1`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying calendar dates: %w", err)
+	}
+	defer rows.Close()
+
+	calendarDates := []model.CalendarDate{}
+	for rows.Next() {
+		cd := model.CalendarDate{}
+		err := rows.Scan(
+			&cd.ServiceID,
+			&cd.Date,
+			&cd.ExceptionType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning calendar date: %w", err)
+		}
+		calendarDates = append(calendarDates, cd)
+	}
+
+	return calendarDates, nil
+}
+
+func (r *PSQLFeedReader) ActiveServices(date string) ([]string, error) {
+	parsedDate, err := time.Parse("20060102", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date: %s", date)
+	}
+
+	var weekday string
+	switch parsedDate.Weekday() {
+	case time.Monday:
+		weekday = "monday"
+	case time.Tuesday:
+		weekday = "tuesday"
+	case time.Wednesday:
+		weekday = "wednesday"
+	case time.Thursday:
+		weekday = "thursday"
+	case time.Friday:
+		weekday = "friday"
+	case time.Saturday:
+		weekday = "saturday"
+	case time.Sunday:
+		weekday = "sunday"
+	}
+
+	rows, err := r.db.Query(`
+WITH
+Exceptions AS (
+        SELECT service_id, exception_type
+        FROM calendar_dates
+        WHERE hash = $1 AND
+              date = $2
+),
+Regular AS (
+        SELECT service_id
+        FROM calendar
+        WHERE hash = $1 AND
+              `+weekday+` = 1 AND
+              start_date <= $2 AND
+              end_date >= $2
+)
+SELECT service_id FROM Regular
+WHERE service_id NOT IN (
+	SELECT service_id FROM Exceptions WHERE exception_type = 2
+)
+UNION
+SELECT service_id FROM Exceptions
+WHERE exception_type = 1
+`, r.id, date)
+	if err != nil {
+		return nil, fmt.Errorf("querying for active services: %w", err)
+	}
+	defer rows.Close()
+
+	activeServices := []string{}
+	for rows.Next() {
+		var serviceID string
+		err = rows.Scan(&serviceID)
+		if err != nil {
+			return nil, fmt.Errorf("scanning active services: %w", err)
+		}
+		activeServices = append(activeServices, serviceID)
+	}
+
+	return activeServices, nil
+}
+
+func (r *PSQLFeedReader) Shape(shapeID string) (*model.Shape, error) {
+	rows, err := r.db.Query(`
+SELECT lat, lon, sequence, dist_traveled
+FROM shapes
+WHERE hash = $1 AND
+      shape_id = $2
+ORDER BY sequence ASC`, r.id, shapeID)
+	if err != nil {
+		return nil, fmt.Errorf("querying shape: %w", err)
+	}
+	defer rows.Close()
+
+	points := []model.ShapePoint{}
+	for rows.Next() {
+		p := model.ShapePoint{}
+		dist := sql.NullFloat64{}
+		err := rows.Scan(&p.Lat, &p.Lon, &p.Sequence, &dist)
+		if err != nil {
+			return nil, fmt.Errorf("scanning shape point: %w", err)
+		}
+		p.DistTraveled = dist.Float64
+		p.HasDistTraveled = dist.Valid
+		points = append(points, p)
+	}
+
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	return &model.Shape{
+		ID:     shapeID,
+		Points: points,
+	}, nil
+}
+
+func (r *PSQLFeedReader) RouteShapeIDs(routeID string, directionID int) ([]string, error) {
+	query := `
+SELECT shape_id, COUNT(*) AS n
+FROM trips
+WHERE hash = $1 AND
+      route_id = $2 AND
+      shape_id != ''`
+	params := []interface{}{r.id, routeID}
+	if directionID > -1 {
+		query += " AND direction_id = $3"
+		params = append(params, directionID)
+	}
+	query += " GROUP BY shape_id ORDER BY n DESC, shape_id ASC"
+
+	rows, err := r.db.Query(query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("querying route shapes: %w", err)
+	}
+	defer rows.Close()
+
+	shapeIDs := []string{}
+	for rows.Next() {
+		var shapeID string
+		var n int
+		err := rows.Scan(&shapeID, &n)
+		if err != nil {
+			return nil, fmt.Errorf("scanning route shape: %w", err)
+		}
+		shapeIDs = append(shapeIDs, shapeID)
+	}
+
+	return shapeIDs, nil
+}
+
+func (r *PSQLFeedReader) NextTripInBlock(tripID string, serviceIDs []string) (*model.Trip, error) {
+	if len(serviceIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders := []string{}
+	params := []interface{}{r.id, tripID}
+	for _, serviceID := range serviceIDs {
+		params = append(params, serviceID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(params)))
+	}
+
+	row := r.db.QueryRow(`
+SELECT trips.id, trips.route_id, trips.service_id, COALESCE(trips.headsign, ''), COALESCE(trips.short_name, ''), trips.direction_id, COALESCE(trips.shape_id, ''), trips.wheelchair_accessible, COALESCE(trips.block_id, ''), trips.bikes_allowed, trips.cars_allowed, trips.extras, MIN(stop_times.departure_time) AS start
+FROM trips
+INNER JOIN trips AS cur ON cur.hash = trips.hash AND cur.block_id = trips.block_id
+INNER JOIN stop_times ON stop_times.hash = trips.hash AND stop_times.trip_id = trips.id
+WHERE trips.hash = $1 AND
+      cur.id = $2 AND
+      cur.block_id != '' AND
+      trips.id != cur.id AND
+      trips.service_id IN (`+strings.Join(placeholders, ", ")+`)
+GROUP BY trips.id, trips.route_id, trips.service_id, trips.headsign, trips.short_name, trips.direction_id, trips.shape_id, trips.wheelchair_accessible, trips.block_id, trips.bikes_allowed, trips.cars_allowed, trips.extras
+HAVING MIN(stop_times.departure_time) > (
+    SELECT MIN(departure_time)
+    FROM stop_times
+    WHERE hash = $1 AND trip_id = $2
+)
+ORDER BY start ASC, trips.id ASC
+LIMIT 1`, params...)
+
+	t := model.Trip{}
+	var extras string
+	var start string
+	err := row.Scan(
+		&t.ID,
+		&t.RouteID,
+		&t.ServiceID,
+		&t.Headsign,
+		&t.ShortName,
+		&t.DirectionID,
+		&t.ShapeID,
+		&t.WheelchairAccessible,
+		&t.BlockID,
+		&t.BikesAllowed,
+		&t.CarsAllowed,
+		&extras,
+		&start,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying next trip in block: %w", err)
+	}
+
+	t.Extras, err = decodeExtras(extras)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *PSQLFeedReader) Frequencies() ([]model.Frequency, error) {
+	rows, err := r.db.Query(`
+SELECT trip_id, start_time, end_time, headway_secs, exact_times
+FROM frequencies
+WHERE hash = $1`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying frequencies: %w", err)
+	}
+	defer rows.Close()
+
+	frequencies := []model.Frequency{}
+	for rows.Next() {
+		freq := model.Frequency{}
 		err := rows.Scan(
-			&s.ID,
-			&s.Code,
-			&s.Name,
-			&s.Desc,
-			&s.Lat,
-			&s.Lon,
-			&s.URL,
-			&s.LocationType,
-			&parentStation,
-			&s.PlatformCode,
+			&freq.TripID,
+			&freq.Start,
+			&freq.End,
+			&freq.HeadwaySecs,
+			&freq.ExactTimes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning frequency: %w", err)
+		}
+		frequencies = append(frequencies, freq)
+	}
+
+	return frequencies, nil
+}
+
+func (r *PSQLFeedReader) Transfers(filter TransferFilter) ([]model.Transfer, error) {
+	whereClauses := []string{"hash = $1"}
+	whereParams := []interface{}{r.id}
+
+	if filter.FromStopID != "" {
+		whereParams = append(whereParams, filter.FromStopID)
+		whereClauses = append(whereClauses, fmt.Sprintf(`(
+    from_stop_id = $%d OR
+    from_stop_id IN (SELECT parent_station FROM stops WHERE hash = $1 AND id = $%d AND parent_station != '')
+)`, len(whereParams), len(whereParams)))
+	}
+	if filter.FromTripID != "" {
+		whereParams = append(whereParams, filter.FromTripID)
+		whereClauses = append(whereClauses, fmt.Sprintf("from_trip_id = $%d", len(whereParams)))
+	}
+
+	rows, err := r.db.Query(fmt.Sprintf(`
+SELECT from_stop_id, to_stop_id, from_route_id, to_route_id, from_trip_id, to_trip_id, transfer_type, min_transfer_time
+FROM transfers
+WHERE %s
+ORDER BY from_stop_id, to_stop_id, from_route_id, to_route_id, from_trip_id, to_trip_id`, strings.Join(whereClauses, " AND ")), whereParams...)
+	if err != nil {
+		return nil, fmt.Errorf("querying transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []model.Transfer{}
+	for rows.Next() {
+		transfer := model.Transfer{}
+		err := rows.Scan(
+			&transfer.FromStopID,
+			&transfer.ToStopID,
+			&transfer.FromRouteID,
+			&transfer.ToRouteID,
+			&transfer.FromTripID,
+			&transfer.ToTripID,
+			&transfer.Type,
+			&transfer.MinTransferTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning transfer: %w", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+func (r *PSQLFeedReader) FareAttributes() ([]model.FareAttribute, error) {
+	rows, err := r.db.Query(`
+SELECT id, price, currency_type, payment_method, transfers, agency_id, transfer_duration
+FROM fare_attributes
+WHERE hash = $1
+ORDER BY id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare attributes: %w", err)
+	}
+	defer rows.Close()
+
+	fares := []model.FareAttribute{}
+	for rows.Next() {
+		fare := model.FareAttribute{}
+		err := rows.Scan(
+			&fare.ID,
+			&fare.Price,
+			&fare.CurrencyType,
+			&fare.PaymentMethod,
+			&fare.Transfers,
+			&fare.AgencyID,
+			&fare.TransferDuration,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fare attribute: %w", err)
+		}
+		fares = append(fares, fare)
+	}
+
+	return fares, nil
+}
+
+func (r *PSQLFeedReader) FareRules() ([]model.FareRule, error) {
+	rows, err := r.db.Query(`
+SELECT fare_id, route_id, origin_id, destination_id, contains_id
+FROM fare_rules
+WHERE hash = $1
+ORDER BY fare_id, route_id, origin_id, destination_id, contains_id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []model.FareRule{}
+	for rows.Next() {
+		rule := model.FareRule{}
+		err := rows.Scan(
+			&rule.FareID,
+			&rule.RouteID,
+			&rule.OriginID,
+			&rule.DestinationID,
+			&rule.ContainsID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fare rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (r *PSQLFeedReader) Networks() ([]model.Network, error) {
+	rows, err := r.db.Query(`
+SELECT id, name
+FROM networks
+WHERE hash = $1
+ORDER BY id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying networks: %w", err)
+	}
+	defer rows.Close()
+
+	networks := []model.Network{}
+	for rows.Next() {
+		network := model.Network{}
+		err := rows.Scan(
+			&network.ID,
+			&network.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning network: %w", err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+func (r *PSQLFeedReader) RouteNetworks() ([]model.RouteNetwork, error) {
+	rows, err := r.db.Query(`
+SELECT network_id, route_id
+FROM route_networks
+WHERE hash = $1
+ORDER BY network_id, route_id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying route networks: %w", err)
+	}
+	defer rows.Close()
+
+	routeNetworks := []model.RouteNetwork{}
+	for rows.Next() {
+		routeNetwork := model.RouteNetwork{}
+		err := rows.Scan(
+			&routeNetwork.NetworkID,
+			&routeNetwork.RouteID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning route network: %w", err)
+		}
+		routeNetworks = append(routeNetworks, routeNetwork)
+	}
+
+	return routeNetworks, nil
+}
+
+func (r *PSQLFeedReader) Areas() ([]model.Area, error) {
+	rows, err := r.db.Query(`
+SELECT id, name
+FROM areas
+WHERE hash = $1
+ORDER BY id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying areas: %w", err)
+	}
+	defer rows.Close()
+
+	areas := []model.Area{}
+	for rows.Next() {
+		area := model.Area{}
+		err := rows.Scan(
+			&area.ID,
+			&area.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning area: %w", err)
+		}
+		areas = append(areas, area)
+	}
+
+	return areas, nil
+}
+
+func (r *PSQLFeedReader) StopAreas() ([]model.StopArea, error) {
+	rows, err := r.db.Query(`
+SELECT area_id, stop_id
+FROM stop_areas
+WHERE hash = $1
+ORDER BY area_id, stop_id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying stop areas: %w", err)
+	}
+	defer rows.Close()
+
+	stopAreas := []model.StopArea{}
+	for rows.Next() {
+		stopArea := model.StopArea{}
+		err := rows.Scan(
+			&stopArea.AreaID,
+			&stopArea.StopID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning stop area: %w", err)
+		}
+		stopAreas = append(stopAreas, stopArea)
+	}
+
+	return stopAreas, nil
+}
+
+func (r *PSQLFeedReader) FareMedia() ([]model.FareMedia, error) {
+	rows, err := r.db.Query(`
+SELECT id, name, type
+FROM fare_media
+WHERE hash = $1
+ORDER BY id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare media: %w", err)
+	}
+	defer rows.Close()
+
+	fareMedia := []model.FareMedia{}
+	for rows.Next() {
+		media := model.FareMedia{}
+		err := rows.Scan(
+			&media.ID,
+			&media.Name,
+			&media.Type,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fare media: %w", err)
+		}
+		fareMedia = append(fareMedia, media)
+	}
+
+	return fareMedia, nil
+}
+
+func (r *PSQLFeedReader) FareProducts() ([]model.FareProduct, error) {
+	rows, err := r.db.Query(`
+SELECT id, name, fare_media_id, amount, currency
+FROM fare_products
+WHERE hash = $1
+ORDER BY id, fare_media_id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []model.FareProduct{}
+	for rows.Next() {
+		product := model.FareProduct{}
+		err := rows.Scan(
+			&product.ID,
+			&product.Name,
+			&product.FareMediaID,
+			&product.Amount,
+			&product.Currency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fare product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	return products, nil
+}
+
+func (r *PSQLFeedReader) FareLegRules() ([]model.FareLegRule, error) {
+	rows, err := r.db.Query(`
+SELECT leg_group_id, network_id, from_area_id, to_area_id, fare_product_id, rule_priority
+FROM fare_leg_rules
+WHERE hash = $1
+ORDER BY leg_group_id, network_id, from_area_id, to_area_id, fare_product_id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare leg rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []model.FareLegRule{}
+	for rows.Next() {
+		rule := model.FareLegRule{}
+		rulePriority := sql.NullInt64{}
+		err := rows.Scan(
+			&rule.LegGroupID,
+			&rule.NetworkID,
+			&rule.FromAreaID,
+			&rule.ToAreaID,
+			&rule.FareProductID,
+			&rulePriority,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fare leg rule: %w", err)
+		}
+		rule.RulePriority = int(rulePriority.Int64)
+		rule.HasRulePriority = rulePriority.Valid
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (r *PSQLFeedReader) FareTransferRules() ([]model.FareTransferRule, error) {
+	rows, err := r.db.Query(`
+SELECT from_leg_group_id, to_leg_group_id, transfer_count, duration_limit, duration_limit_type, fare_transfer_type, fare_product_id
+FROM fare_transfer_rules
+WHERE hash = $1
+ORDER BY from_leg_group_id, to_leg_group_id, fare_product_id`, r.id)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare transfer rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []model.FareTransferRule{}
+	for rows.Next() {
+		rule := model.FareTransferRule{}
+		err := rows.Scan(
+			&rule.FromLegGroupID,
+			&rule.ToLegGroupID,
+			&rule.TransferCount,
+			&rule.DurationLimit,
+			&rule.DurationLimitType,
+			&rule.FareTransferType,
+			&rule.FareProductID,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning stop: %w", err)
-		}
-
-		if parentStation.Valid {
-			s.ParentStation = parentStation.String
+			return nil, fmt.Errorf("scanning fare transfer rule: %w", err)
 		}
-
-		stops = append(stops, s)
+		rules = append(rules, rule)
 	}
 
-	return stops, nil
+	return rules, nil
 }
 
-func (r *PSQLFeedReader) Routes() ([]model.Route, error) {
+func (r *PSQLFeedReader) Levels() ([]model.Level, error) {
 	rows, err := r.db.Query(`
-SELECT id, agency_id, short_name, long_name, description, type, url, color, text_color
-FROM routes
-WHERE hash = $1`, r.id)
+SELECT id, level_index, name
+FROM levels
+WHERE hash = $1
+ORDER BY level_index, id`, r.id)
 	if err != nil {
-		return nil, fmt.Errorf("querying routes: %w", err)
+		return nil, fmt.Errorf("querying levels: %w", err)
 	}
 	defer rows.Close()
 
-	routes := []model.Route{}
+	levels := []model.Level{}
 	for rows.Next() {
-		route := model.Route{}
+		level := model.Level{}
 		err := rows.Scan(
-			&route.ID,
-			&route.AgencyID,
-			&route.ShortName,
-			&route.LongName,
-			&route.Desc,
-			&route.Type,
-			&route.URL,
-			&route.Color,
-			&route.TextColor,
+			&level.ID,
+			&level.Index,
+			&level.Name,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning route: %w", err)
+			return nil, fmt.Errorf("scanning level: %w", err)
 		}
-		routes = append(routes, route)
+		levels = append(levels, level)
 	}
 
-	return routes, nil
+	return levels, nil
 }
 
-func (r *PSQLFeedReader) Trips() ([]model.Trip, error) {
+func (r *PSQLFeedReader) Pathways() ([]model.Pathway, error) {
 	rows, err := r.db.Query(`
-SELECT id, route_id, service_id, headsign, short_name, direction_id
-FROM trips
-WHERE hash = $1`, r.id)
+SELECT id, from_stop_id, to_stop_id, mode, is_bidirectional, length, traversal_time, stair_count, max_slope, min_width, signposted_as, reversed_signposted_as
+FROM pathways
+WHERE hash = $1
+ORDER BY id`, r.id)
 	if err != nil {
-		return nil, fmt.Errorf("querying trips: %w", err)
+		return nil, fmt.Errorf("querying pathways: %w", err)
 	}
 	defer rows.Close()
 
-	trips := []model.Trip{}
+	pathways := []model.Pathway{}
 	for rows.Next() {
-		t := model.Trip{}
+		pathway := model.Pathway{}
 		err := rows.Scan(
-			&t.ID,
-			&t.RouteID,
-			&t.ServiceID,
-			&t.Headsign,
-			&t.ShortName,
-			&t.DirectionID,
+			&pathway.ID,
+			&pathway.FromStopID,
+			&pathway.ToStopID,
+			&pathway.Mode,
+			&pathway.IsBidirectional,
+			&pathway.Length,
+			&pathway.TraversalTime,
+			&pathway.StairCount,
+			&pathway.MaxSlope,
+			&pathway.MinWidth,
+			&pathway.SignpostedAs,
+			&pathway.ReversedSignpostedAs,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning trip: %w", err)
+			return nil, fmt.Errorf("scanning pathway: %w", err)
 		}
-		trips = append(trips, t)
+		pathways = append(pathways, pathway)
 	}
 
-	return trips, nil
+	return pathways, nil
 }
 
-func (r *PSQLFeedReader) StopTimes() ([]model.StopTime, error) {
-	rows, err := r.db.Query(`
-SELECT trip_id, stop_id, headsign, stop_sequence, arrival_time, departure_time
-FROM stop_times
-WHERE hash = $1`, r.id)
+func (r *PSQLFeedReader) Translations(filter TranslationFilter) ([]model.Translation, error) {
+	whereClauses := []string{"hash = $1"}
+	whereParams := []interface{}{r.id}
+
+	if filter.TableName != "" {
+		whereParams = append(whereParams, filter.TableName)
+		whereClauses = append(whereClauses, fmt.Sprintf("table_name = $%d", len(whereParams)))
+	}
+	if filter.FieldName != "" {
+		whereParams = append(whereParams, filter.FieldName)
+		whereClauses = append(whereClauses, fmt.Sprintf("field_name = $%d", len(whereParams)))
+	}
+	if len(filter.Languages) > 0 {
+		placeholders := []string{}
+		for _, lang := range filter.Languages {
+			whereParams = append(whereParams, lang)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(whereParams)))
+		}
+		whereClauses = append(whereClauses, fmt.Sprintf("language IN (%s)", strings.Join(placeholders, ", ")))
+	}
+
+	rows, err := r.db.Query(fmt.Sprintf(`
+SELECT table_name, field_name, language, translation, record_id, record_sub_id, field_value
+FROM translations
+WHERE %s
+ORDER BY table_name, field_name, language, record_id, record_sub_id, field_value`, strings.Join(whereClauses, " AND ")), whereParams...)
 	if err != nil {
-		return nil, fmt.Errorf("querying stop times: %w", err)
+		return nil, fmt.Errorf("querying translations: %w", err)
 	}
 	defer rows.Close()
 
-	stopTimes := []model.StopTime{}
+	translations := []model.Translation{}
 	for rows.Next() {
-		st := model.StopTime{}
+		translation := model.Translation{}
 		err := rows.Scan(
-			&st.TripID,
-			&st.StopID,
-			&st.Headsign,
-			&st.StopSequence,
-			&st.Arrival,
-			&st.Departure,
+			&translation.TableName,
+			&translation.FieldName,
+			&translation.Language,
+			&translation.Translation,
+			&translation.RecordID,
+			&translation.RecordSubID,
+			&translation.FieldValue,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning stop time: %w", err)
+			return nil, fmt.Errorf("scanning translation: %w", err)
 		}
-		stopTimes = append(stopTimes, st)
+		translations = append(translations, translation)
 	}
 
-	return stopTimes, nil
+	return translations, nil
 }
 
-func (r *PSQLFeedReader) Calendars() ([]model.Calendar, error) {
+func (r *PSQLFeedReader) Locations() ([]model.Location, error) {
 	rows, err := r.db.Query(`
-SELECT service_id, start_date, end_date, monday, tuesday, wednesday, thursday, friday, saturday, sunday
-FROM calendar
-WHERE hash = $1`, r.id)
+SELECT id, name, description, geometry
+FROM locations
+WHERE hash = $1
+ORDER BY id`, r.id)
 	if err != nil {
-		return nil, fmt.Errorf("querying calendar: %w", err)
+		return nil, fmt.Errorf("querying location: %w", err)
 	}
 	defer rows.Close()
 
-	calendars := []model.Calendar{}
+	locations := []model.Location{}
 	for rows.Next() {
-		var serviceID, startDate, endDate string
-		var monday, tuesday, wednesday, thursday, friday, saturday, sunday bool
+		location := model.Location{}
+		var geometry string
 		err := rows.Scan(
-			&serviceID,
-			&startDate,
-			&endDate,
-			&monday,
-			&tuesday,
-			&wednesday,
-			&thursday,
-			&friday,
-			&saturday,
-			&sunday,
+			&location.ID,
+			&location.Name,
+			&location.Desc,
+			&geometry,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning calendar: %w", err)
-		}
-		weekday := int8(0)
-		if monday {
-			weekday |= 1 << time.Monday
-		}
-		if tuesday {
-			weekday |= 1 << time.Tuesday
+			return nil, fmt.Errorf("scanning location: %w", err)
 		}
-		if wednesday {
-			weekday |= 1 << time.Wednesday
-		}
-		if thursday {
-			weekday |= 1 << time.Thursday
-		}
-		if friday {
-			weekday |= 1 << time.Friday
-		}
-		if saturday {
-			weekday |= 1 << time.Saturday
-		}
-		if sunday {
-			weekday |= 1 << time.Sunday
+		err = json.Unmarshal([]byte(geometry), &location.Polygons)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshaling location geometry: %w", err)
 		}
-		calendars = append(calendars, model.Calendar{
-			ServiceID: serviceID,
-			StartDate: startDate,
-			EndDate:   endDate,
-			Weekday:   weekday,
-		})
+		locations = append(locations, location)
 	}
 
-	return calendars, nil
+	return locations, nil
 }
 
-func (r *PSQLFeedReader) CalendarDates() ([]model.CalendarDate, error) {
+func (r *PSQLFeedReader) LocationGroups() ([]model.LocationGroup, error) {
 	rows, err := r.db.Query(`
-SELECT service_id, date, exception_type
-FROM calendar_dates
-WHERE hash = $1`, r.id)
+SELECT id, name
+FROM location_groups
+WHERE hash = $1
+ORDER BY id`, r.id)
 	if err != nil {
-		return nil, fmt.Errorf("querying calendar dates: %w", err)
+		return nil, fmt.Errorf("querying location group: %w", err)
 	}
 	defer rows.Close()
 
-	calendarDates := []model.CalendarDate{}
+	groups := []model.LocationGroup{}
 	for rows.Next() {
-		cd := model.CalendarDate{}
+		group := model.LocationGroup{}
 		err := rows.Scan(
-			&cd.ServiceID,
-			&cd.Date,
-			&cd.ExceptionType,
+			&group.ID,
+			&group.Name,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning calendar date: %w", err)
+			return nil, fmt.Errorf("scanning location group: %w", err)
 		}
-		calendarDates = append(calendarDates, cd)
+		groups = append(groups, group)
 	}
 
-	return calendarDates, nil
+	return groups, nil
 }
 
-func (r *PSQLFeedReader) ActiveServices(date string) ([]string, error) {
-	parsedDate, err := time.Parse("20060102", date)
+func (r *PSQLFeedReader) LocationGroupStops() ([]model.LocationGroupStop, error) {
+	rows, err := r.db.Query(`
+SELECT location_group_id, stop_id
+FROM location_group_stops
+WHERE hash = $1
+ORDER BY location_group_id, stop_id`, r.id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date: %s", date)
+		return nil, fmt.Errorf("querying location group stop: %w", err)
 	}
+	defer rows.Close()
 
-	var weekday string
-	switch parsedDate.Weekday() {
-	case time.Monday:
-		weekday = "monday"
-	case time.Tuesday:
-		weekday = "tuesday"
-	case time.Wednesday:
-		weekday = "wednesday"
-	case time.Thursday:
-		weekday = "thursday"
-	case time.Friday:
-		weekday = "friday"
-	case time.Saturday:
-		weekday = "saturday"
-	case time.Sunday:
-		weekday = "sunday"
+	lgss := []model.LocationGroupStop{}
+	for rows.Next() {
+		lgs := model.LocationGroupStop{}
+		err := rows.Scan(
+			&lgs.LocationGroupID,
+			&lgs.StopID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning location group stop: %w", err)
+		}
+		lgss = append(lgss, lgs)
 	}
 
+	return lgss, nil
+}
+
+func (r *PSQLFeedReader) BookingRules() ([]model.BookingRule, error) {
 	rows, err := r.db.Query(`
-WITH
-Exceptions AS (
-        SELECT service_id, exception_type
-        FROM calendar_dates
-        WHERE hash = $1 AND
-              date = $2
-),
-Regular AS (
-        SELECT service_id
-        FROM calendar
-        WHERE hash = $1 AND
-              `+weekday+` = 1 AND
-              start_date <= $2 AND
-              end_date >= $2
-)
-SELECT service_id FROM Regular
-WHERE service_id NOT IN (
-	SELECT service_id FROM Exceptions WHERE exception_type = 2
-)
-UNION
-SELECT service_id FROM Exceptions
-WHERE exception_type = 1
-`, r.id, date)
+SELECT id, booking_type, prior_notice_duration_min, prior_notice_duration_max, prior_notice_last_day, prior_notice_last_time, prior_notice_start_day, prior_notice_start_time, prior_notice_service_id, message, pickup_message, drop_off_message, phone_number, info_url, booking_url
+FROM booking_rules
+WHERE hash = $1
+ORDER BY id`, r.id)
 	if err != nil {
-		return nil, fmt.Errorf("querying for active services: %w", err)
+		return nil, fmt.Errorf("querying booking rule: %w", err)
 	}
 	defer rows.Close()
 
-	activeServices := []string{}
+	rules := []model.BookingRule{}
 	for rows.Next() {
-		var serviceID string
-		err = rows.Scan(&serviceID)
+		rule := model.BookingRule{}
+		err := rows.Scan(
+			&rule.ID,
+			&rule.Type,
+			&rule.PriorNoticeDurationMin,
+			&rule.PriorNoticeDurationMax,
+			&rule.PriorNoticeLastDay,
+			&rule.PriorNoticeLastTime,
+			&rule.PriorNoticeStartDay,
+			&rule.PriorNoticeStartTime,
+			&rule.PriorNoticeServiceID,
+			&rule.Message,
+			&rule.PickupMessage,
+			&rule.DropOffMessage,
+			&rule.PhoneNumber,
+			&rule.InfoURL,
+			&rule.BookingURL,
+		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning active services: %w", err)
+			return nil, fmt.Errorf("scanning booking rule: %w", err)
 		}
-		activeServices = append(activeServices, serviceID)
+		rules = append(rules, rule)
 	}
 
-	return activeServices, nil
+	return rules, nil
+}
+
+func (r *PSQLFeedReader) FlexStopTimes(filter FlexStopTimeFilter) ([]*FlexStopTimeEvent, error) {
+	baseQuery := `
+SELECT
+    flex_stop_times.trip_id,
+    flex_stop_times.stop_sequence,
+    flex_stop_times.stop_id,
+    flex_stop_times.location_group_id,
+    flex_stop_times.location_id,
+    flex_stop_times.start_window,
+    flex_stop_times.end_window,
+    flex_stop_times.pickup_type,
+    flex_stop_times.drop_off_type,
+    flex_stop_times.pickup_booking_rule_id,
+    flex_stop_times.drop_off_booking_rule_id,
+    trips.id,
+    trips.route_id,
+    trips.service_id,
+    COALESCE(trips.headsign, ''),
+    COALESCE(trips.short_name, ''),
+    trips.direction_id,
+    COALESCE(trips.shape_id, ''),
+    trips.wheelchair_accessible,
+    COALESCE(trips.block_id, ''),
+    trips.bikes_allowed,
+    trips.cars_allowed,
+    trips.extras,
+    routes.id,
+    COALESCE(routes.agency_id, ''),
+    COALESCE(routes.short_name, ''),
+    routes.long_name,
+    COALESCE(routes.description, ''),
+    routes.type,
+    COALESCE(routes.url, ''),
+    COALESCE(routes.color, ''),
+    COALESCE(routes.text_color, ''),
+    routes.continuous_pickup,
+    routes.continuous_drop_off,
+    routes.sort_order,
+    routes.network_id,
+    routes.extras
+FROM flex_stop_times
+INNER JOIN trips ON flex_stop_times.trip_id = trips.id
+INNER JOIN routes ON trips.route_id = routes.id
+WHERE flex_stop_times.hash = $1 AND
+      trips.hash = $1 AND
+      routes.hash = $1
+`
+
+	// Apply filters to query
+	fParams, fVals := []string{}, []interface{}{r.id}
+
+	// Locations, location groups and stops are alternatives
+	locParams := []string{}
+	for _, ids := range []struct {
+		column string
+		values []string
+	}{
+		{"flex_stop_times.location_id", filter.LocationIDs},
+		{"flex_stop_times.location_group_id", filter.LocationGroupIDs},
+		{"flex_stop_times.stop_id", filter.StopIDs},
+	} {
+		if len(ids.values) == 0 {
+			continue
+		}
+		placeholders := []string{}
+		for _, id := range ids.values {
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(fVals)+1))
+			fVals = append(fVals, id)
+		}
+		locParams = append(locParams, ids.column+" IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if len(locParams) > 0 {
+		fParams = append(fParams, "("+strings.Join(locParams, " OR ")+")")
+	}
+
+	if len(filter.ServiceIDs) > 0 {
+		placeholders := []string{}
+		for _, id := range filter.ServiceIDs {
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(fVals)+1))
+			fVals = append(fVals, id)
+		}
+		fParams = append(fParams, "trips.service_id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	query := baseQuery
+	if len(fParams) > 0 {
+		query += " AND " + strings.Join(fParams, " AND ")
+	}
+	query += " ORDER BY flex_stop_times.trip_id, flex_stop_times.stop_sequence"
+
+	rows, err := r.db.Query(query, fVals...)
+	if err != nil {
+		return nil, fmt.Errorf("querying flex stop_times: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*FlexStopTimeEvent{}
+	for rows.Next() {
+		event := &FlexStopTimeEvent{}
+		sortOrder := sql.NullInt64{}
+		tripExtras, routeExtras := "", ""
+		err := rows.Scan(
+			&event.StopTime.TripID,
+			&event.StopTime.StopSequence,
+			&event.StopTime.StopID,
+			&event.StopTime.LocationGroupID,
+			&event.StopTime.LocationID,
+			&event.StopTime.StartWindow,
+			&event.StopTime.EndWindow,
+			&event.StopTime.PickupType,
+			&event.StopTime.DropOffType,
+			&event.StopTime.PickupBookingRuleID,
+			&event.StopTime.DropOffBookingRuleID,
+			&event.Trip.ID,
+			&event.Trip.RouteID,
+			&event.Trip.ServiceID,
+			&event.Trip.Headsign,
+			&event.Trip.ShortName,
+			&event.Trip.DirectionID,
+			&event.Trip.ShapeID,
+			&event.Trip.WheelchairAccessible,
+			&event.Trip.BlockID,
+			&event.Trip.BikesAllowed,
+			&event.Trip.CarsAllowed,
+			&tripExtras,
+			&event.Route.ID,
+			&event.Route.AgencyID,
+			&event.Route.ShortName,
+			&event.Route.LongName,
+			&event.Route.Desc,
+			&event.Route.Type,
+			&event.Route.URL,
+			&event.Route.Color,
+			&event.Route.TextColor,
+			&event.Route.ContinuousPickup,
+			&event.Route.ContinuousDropOff,
+			&sortOrder,
+			&event.Route.NetworkID,
+			&routeExtras,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning flex stop_time: %w", err)
+		}
+		event.Route.SortOrder = int(sortOrder.Int64)
+		event.Route.HasSortOrder = sortOrder.Valid
+		event.Trip.Extras, err = decodeExtras(tripExtras)
+		if err != nil {
+			return nil, err
+		}
+		event.Route.Extras, err = decodeExtras(routeExtras)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
 }
 
 func (r *PSQLFeedReader) MinMaxStopSeq() (map[string][2]uint32, error) {
@@ -1062,36 +3090,58 @@ func (r *PSQLFeedReader) StopTimeEvents(filter StopTimeEventFilter) ([]*StopTime
 	baseQuery := `
 SELECT
     stops.id,
-    stops.code,
+    COALESCE(stops.code, ''),
     stops.name,
-    stops.description,
+    COALESCE(stops.description, ''),
     stops.lat,
     stops.lon,
-    stops.url,
+    COALESCE(stops.url, ''),
     stops.location_type,
     stops.parent_station,
-    stops.platform_code,
+    COALESCE(stops.platform_code, ''),
+    COALESCE(stops.zone_id, ''),
+    COALESCE(stops.level_id, ''),
+    COALESCE(stops.timezone, ''),
+    stops.wheelchair_boarding,
+    stops.extras,
     stop_times.trip_id,
     stop_times.stop_id,
     stop_times.stop_sequence,
     stop_times.arrival_time,
     stop_times.departure_time,
-    stop_times.headsign,
+    COALESCE(stop_times.headsign, ''),
+    stop_times.pickup_type,
+    stop_times.drop_off_type,
+    stop_times.approximate,
+    stop_times.shape_dist_traveled,
+    stop_times.continuous_pickup,
+    stop_times.continuous_drop_off,
     trips.id,
     trips.route_id,
     trips.service_id,
-    trips.headsign,
-    trips.short_name,
+    COALESCE(trips.headsign, ''),
+    COALESCE(trips.short_name, ''),
     trips.direction_id,
+    COALESCE(trips.shape_id, ''),
+    trips.wheelchair_accessible,
+    COALESCE(trips.block_id, ''),
+    trips.bikes_allowed,
+    trips.cars_allowed,
+    trips.extras,
     routes.id,
-    routes.agency_id,
-    routes.short_name,
+    COALESCE(routes.agency_id, ''),
+    COALESCE(routes.short_name, ''),
     routes.long_name,
-    routes.description,
+    COALESCE(routes.description, ''),
     routes.type,
-    routes.url,
-    routes.color,
-    routes.text_color
+    COALESCE(routes.url, ''),
+    COALESCE(routes.color, ''),
+    COALESCE(routes.text_color, ''),
+    routes.continuous_pickup,
+    routes.continuous_drop_off,
+    routes.sort_order,
+    routes.network_id,
+    routes.extras
 FROM stop_times
 INNER JOIN stops ON stop_times.stop_id = stops.id
 INNER JOIN trips ON stop_times.trip_id = trips.id
@@ -1172,13 +3222,14 @@ WHERE stop_times.hash = $1 AND
 	}
 
 	if len(filter.RouteTypes) > 0 {
-		placeholders := []string{}
-		for i, rt := range filter.RouteTypes {
-			placeholders = append(placeholders, fmt.Sprintf("$%d", pIdx+i))
+		cond, values := routeTypeCondition(filter.RouteTypes, func(i int) string {
+			return fmt.Sprintf("$%d", pIdx+i)
+		})
+		for _, rt := range values {
 			fVals = append(fVals, fmt.Sprintf("%d", rt))
 		}
-		fParams = append(fParams, "routes.type IN ("+strings.Join(placeholders, ", ")+")")
-		pIdx += len(filter.RouteTypes)
+		fParams = append(fParams, cond)
+		pIdx += len(values)
 	}
 
 	// Run query
@@ -1207,7 +3258,12 @@ WHERE stop_times.hash = $1 AND
 		stopTime := model.StopTime{}
 		trip := model.Trip{}
 		route := model.Route{}
+		shapeDist := sql.NullFloat64{}
+		continuousPickup := sql.NullInt64{}
+		continuousDropOff := sql.NullInt64{}
+		sortOrder := sql.NullInt64{}
 		parentStation := sql.NullString{}
+		stopExtras, tripExtras, routeExtras := "", "", ""
 
 		err = rows.Scan(
 			&stop.ID,
@@ -1220,18 +3276,35 @@ WHERE stop_times.hash = $1 AND
 			&stop.LocationType,
 			&parentStation,
 			&stop.PlatformCode,
+			&stop.ZoneID,
+			&stop.LevelID,
+			&stop.Timezone,
+			&stop.WheelchairBoarding,
+			&stopExtras,
 			&stopTime.TripID,
 			&stopTime.StopID,
 			&stopTime.StopSequence,
 			&stopTime.Arrival,
 			&stopTime.Departure,
 			&stopTime.Headsign,
+			&stopTime.PickupType,
+			&stopTime.DropOffType,
+			&stopTime.Approximate,
+			&shapeDist,
+			&continuousPickup,
+			&continuousDropOff,
 			&trip.ID,
 			&trip.RouteID,
 			&trip.ServiceID,
 			&trip.Headsign,
 			&trip.ShortName,
 			&trip.DirectionID,
+			&trip.ShapeID,
+			&trip.WheelchairAccessible,
+			&trip.BlockID,
+			&trip.BikesAllowed,
+			&trip.CarsAllowed,
+			&tripExtras,
 			&route.ID,
 			&route.AgencyID,
 			&route.ShortName,
@@ -1241,10 +3314,35 @@ WHERE stop_times.hash = $1 AND
 			&route.URL,
 			&route.Color,
 			&route.TextColor,
+			&route.ContinuousPickup,
+			&route.ContinuousDropOff,
+			&sortOrder,
+			&route.NetworkID,
+			&routeExtras,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning stop time event: %w", err)
 		}
+		stopTime.ShapeDistTraveled = shapeDist.Float64
+		stopTime.HasShapeDistTraveled = shapeDist.Valid
+		stopTime.ContinuousPickup = model.ContinuousStopping(continuousPickup.Int64)
+		stopTime.HasContinuousPickup = continuousPickup.Valid
+		stopTime.ContinuousDropOff = model.ContinuousStopping(continuousDropOff.Int64)
+		stopTime.HasContinuousDropOff = continuousDropOff.Valid
+		route.SortOrder = int(sortOrder.Int64)
+		route.HasSortOrder = sortOrder.Valid
+		stop.Extras, err = decodeExtras(stopExtras)
+		if err != nil {
+			return nil, err
+		}
+		trip.Extras, err = decodeExtras(tripExtras)
+		if err != nil {
+			return nil, err
+		}
+		route.Extras, err = decodeExtras(routeExtras)
+		if err != nil {
+			return nil, err
+		}
 
 		if parentStation.Valid {
 			stop.ParentStation = parentStation.String
@@ -1288,7 +3386,7 @@ WHERE stop_times.hash = $1 AND
 	}
 
 	rows, err = r.db.Query(`
-SELECT id, code, name, description, lat, lon, url, location_type, platform_code
+SELECT id, COALESCE(code, ''), name, COALESCE(description, ''), lat, lon, COALESCE(url, ''), location_type, COALESCE(platform_code, ''), COALESCE(zone_id, ''), COALESCE(level_id, ''), COALESCE(timezone, ''), wheelchair_boarding, extras
 FROM stops
 WHERE hash = $1 AND
       id IN (`+strings.Join(placeholders, ", ")+`)
@@ -1300,6 +3398,7 @@ WHERE hash = $1 AND
 
 	for rows.Next() {
 		stop := model.Stop{}
+		extras := ""
 		err = rows.Scan(
 			&stop.ID,
 			&stop.Code,
@@ -1310,10 +3409,19 @@ WHERE hash = $1 AND
 			&stop.URL,
 			&stop.LocationType,
 			&stop.PlatformCode,
+			&stop.ZoneID,
+			&stop.LevelID,
+			&stop.Timezone,
+			&stop.WheelchairBoarding,
+			&extras,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning parent station: %w", err)
 		}
+		stop.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
 
 		parents[stop.ID] = stop
 	}
@@ -1327,7 +3435,7 @@ WHERE hash = $1 AND
 
 func (r *PSQLFeedReader) RouteDirections(stopID string) ([]model.RouteDirection, error) {
 	rows, err := r.db.Query(`
-SELECT DISTINCT trips.route_id, trips.direction_id, trips.headsign, stop_times.headsign
+SELECT DISTINCT trips.route_id, trips.direction_id, COALESCE(trips.headsign, ''), COALESCE(stop_times.headsign, '')
 FROM stop_times
 INNER JOIN trips ON trips.id = stop_times.trip_id
 WHERE stop_times.hash = $1 AND
@@ -1391,15 +3499,20 @@ func (r *PSQLFeedReader) getStops() ([]model.Stop, error) {
 	row, err := r.db.Query(`
 SELECT
     stops.id,
-    stops.code,
+    COALESCE(stops.code, ''),
     stops.name,
-    stops.description,
+    COALESCE(stops.description, ''),
     stops.lat,
     stops.lon,
-    stops.url,
+    COALESCE(stops.url, ''),
     stops.location_type,
     stops.parent_station,
-    stops.platform_code
+    COALESCE(stops.platform_code, ''),
+    COALESCE(stops.zone_id, ''),
+    COALESCE(stops.level_id, ''),
+    COALESCE(stops.timezone, ''),
+    stops.wheelchair_boarding,
+    stops.extras
 FROM
     stops
 WHERE
@@ -1415,6 +3528,7 @@ WHERE
 	for row.Next() {
 		stop := model.Stop{}
 		parentStation := sql.NullString{}
+		extras := ""
 		err = row.Scan(
 			&stop.ID,
 			&stop.Code,
@@ -1426,10 +3540,19 @@ WHERE
 			&stop.LocationType,
 			&parentStation,
 			&stop.PlatformCode,
+			&stop.ZoneID,
+			&stop.LevelID,
+			&stop.Timezone,
+			&stop.WheelchairBoarding,
+			&extras,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning stop: %w", err)
 		}
+		stop.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
 
 		if parentStation.Valid {
 			stop.ParentStation = parentStation.String
@@ -1442,27 +3565,31 @@ WHERE
 }
 
 func (r *PSQLFeedReader) getStopsByRouteType(routeTypes []model.RouteType) ([]model.Stop, error) {
+	routeTypeCond, routeTypeValues := routeTypeCondition(routeTypes, func(i int) string {
+		return fmt.Sprintf("$%d", i+2)
+	})
 	queryValues := []interface{}{r.id}
-	for _, rt := range routeTypes {
+	for _, rt := range routeTypeValues {
 		queryValues = append(queryValues, rt)
 	}
-	routeTypePlaceholders := []string{}
-	for i := range routeTypes {
-		routeTypePlaceholders = append(routeTypePlaceholders, fmt.Sprintf("$%d", i+2))
-	}
 
 	query := `
 SELECT
     stops.id,
-    stops.code,
+    COALESCE(stops.code, ''),
     stops.name,
-    stops.description,
+    COALESCE(stops.description, ''),
     stops.lat,
     stops.lon,
-    stops.url,
+    COALESCE(stops.url, ''),
     stops.location_type,
     stops.parent_station,
-    stops.platform_code,
+    COALESCE(stops.platform_code, ''),
+    COALESCE(stops.zone_id, ''),
+    COALESCE(stops.level_id, ''),
+    COALESCE(stops.timezone, ''),
+    stops.wheelchair_boarding,
+    stops.extras,
     parent.id,
     parent.code,
     parent.name,
@@ -1471,7 +3598,12 @@ SELECT
     parent.lon,
     parent.url,
     parent.location_type,
-    parent.platform_code
+    parent.platform_code,
+    parent.zone_id,
+    parent.level_id,
+    parent.timezone,
+    parent.wheelchair_boarding,
+    parent.extras
 FROM stop_times
 INNER JOIN trips ON stop_times.trip_id = trips.id
 INNER JOIN routes ON trips.route_id = routes.id
@@ -1483,7 +3615,7 @@ WHERE
     routes.hash = $1 AND
     stops.hash = $1 AND
     stops.location_type = 0 AND
-    routes.type IN (` + strings.Join(routeTypePlaceholders, ", ") + `)
+    ` + routeTypeCond + `
 `
 
 	rows, err := r.db.Query(query, queryValues...)
@@ -1505,6 +3637,12 @@ WHERE
 		parentURL := sql.NullString{}
 		parentLocationType := sql.NullInt64{}
 		parentPlatformCode := sql.NullString{}
+		parentZoneID := sql.NullString{}
+		parentLevelID := sql.NullString{}
+		parentWheelchairBoarding := sql.NullInt64{}
+		parentTimezone := sql.NullString{}
+		parentExtras := sql.NullString{}
+		extras := ""
 		err := rows.Scan(
 			&s.ID,
 			&s.Code,
@@ -1516,6 +3654,11 @@ WHERE
 			&s.LocationType,
 			&stopParentStation,
 			&s.PlatformCode,
+			&s.ZoneID,
+			&s.LevelID,
+			&s.Timezone,
+			&s.WheelchairBoarding,
+			&extras,
 			&parentID,
 			&parentCode,
 			&parentName,
@@ -1525,6 +3668,11 @@ WHERE
 			&parentURL,
 			&parentLocationType,
 			&parentPlatformCode,
+			&parentZoneID,
+			&parentLevelID,
+			&parentTimezone,
+			&parentWheelchairBoarding,
+			&parentExtras,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning stop: %w", err)
@@ -1535,18 +3683,31 @@ WHERE
 		}
 
 		if parentID.Valid {
-			allStops[parentID.String] = model.Stop{
-				ID:           parentID.String,
-				Code:         parentCode.String,
-				Name:         parentName.String,
-				Desc:         parentDesc.String,
-				Lat:          parentLat.Float64,
-				Lon:          parentLon.Float64,
-				URL:          parentURL.String,
-				LocationType: model.LocationType(parentLocationType.Int64),
-				PlatformCode: parentPlatformCode.String,
+			parent := model.Stop{
+				ID:                 parentID.String,
+				Code:               parentCode.String,
+				Name:               parentName.String,
+				Desc:               parentDesc.String,
+				Lat:                parentLat.Float64,
+				Lon:                parentLon.Float64,
+				URL:                parentURL.String,
+				LocationType:       model.LocationType(parentLocationType.Int64),
+				PlatformCode:       parentPlatformCode.String,
+				ZoneID:             parentZoneID.String,
+				LevelID:            parentLevelID.String,
+				Timezone:           parentTimezone.String,
+				WheelchairBoarding: model.WheelchairAccessibility(parentWheelchairBoarding.Int64),
 			}
+			parent.Extras, err = decodeExtras(parentExtras.String)
+			if err != nil {
+				return nil, err
+			}
+			allStops[parentID.String] = parent
 		} else {
+			s.Extras, err = decodeExtras(extras)
+			if err != nil {
+				return nil, err
+			}
 			allStops[s.ID] = s
 		}
 	}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -29,6 +30,8 @@ type SQLiteFeedWriter struct {
 	db                  *sql.DB
 	stopTimeInsertQuery *sql.Stmt
 	stopTimeInsertTx    *sql.Tx
+	shapeInsertQuery    *sql.Stmt
+	shapeInsertTx       *sql.Tx
 }
 
 type SQLiteFeedReader struct {
@@ -63,12 +66,24 @@ CREATE TABLE IF NOT EXISTS feed (
     timezone TEXT NOT NULL,
     max_arrival TEXT NOT NULL,
     max_departure TEXT NOT NULL,
+    feed_start_date TEXT NOT NULL DEFAULT '',
+    feed_end_date TEXT NOT NULL DEFAULT '',
+    feed_version TEXT NOT NULL DEFAULT '',
+    feed_publisher_name TEXT NOT NULL DEFAULT '',
+    feed_publisher_url TEXT NOT NULL DEFAULT '',
+    feed_lang TEXT NOT NULL DEFAULT '',
+    dropped_count INTEGER NOT NULL DEFAULT 0,
+    dropped TEXT NOT NULL DEFAULT '',
 PRIMARY KEY (hash, url)
 );
 
 CREATE TABLE IF NOT EXISTS feed_request (
     url TEXT NOT NULL,
     refreshed_at TIMESTAMP NOT NULL,
+    lenient BOOLEAN NOT NULL DEFAULT FALSE,
+    encoding TEXT NOT NULL DEFAULT '',
+    extras BOOLEAN NOT NULL DEFAULT FALSE,
+    last_failure TEXT NOT NULL DEFAULT '',
 PRIMARY KEY (url)
 );
 
@@ -85,6 +100,34 @@ PRIMARY KEY (name, url)
 		return nil, fmt.Errorf("creating feed table: %w", err)
 	}
 
+	// An on-disk database from an earlier version keeps the
+	// tables it was created with, so columns added since are
+	// added here.
+	for table, columns := range map[string][][2]string{
+		"feed": {
+			{"feed_start_date", "TEXT NOT NULL DEFAULT ''"},
+			{"feed_end_date", "TEXT NOT NULL DEFAULT ''"},
+			{"feed_version", "TEXT NOT NULL DEFAULT ''"},
+			{"feed_publisher_name", "TEXT NOT NULL DEFAULT ''"},
+			{"feed_publisher_url", "TEXT NOT NULL DEFAULT ''"},
+			{"feed_lang", "TEXT NOT NULL DEFAULT ''"},
+			{"dropped_count", "INTEGER NOT NULL DEFAULT 0"},
+			{"dropped", "TEXT NOT NULL DEFAULT ''"},
+		},
+		"feed_request": {
+			{"lenient", "BOOLEAN NOT NULL DEFAULT FALSE"},
+			{"encoding", "TEXT NOT NULL DEFAULT ''"},
+			{"extras", "BOOLEAN NOT NULL DEFAULT FALSE"},
+			{"last_failure", "TEXT NOT NULL DEFAULT ''"},
+		},
+	} {
+		err = addSQLiteColumns(db, table, columns)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
 	return &SQLiteStorage{
 		SQLiteConfig: SQLiteConfig{
 			OnDisk:    onDisk,
@@ -105,7 +148,15 @@ SELECT
     calendar_end,
     timezone,
     max_arrival,
-    max_departure
+    max_departure,
+    feed_start_date,
+    feed_end_date,
+    feed_version,
+    feed_publisher_name,
+    feed_publisher_url,
+    feed_lang,
+    dropped_count,
+    dropped
 FROM feed`
 
 	conditions := []string{}
@@ -133,6 +184,7 @@ FROM feed`
 	var feeds []*FeedMetadata
 	for rows.Next() {
 		var feed FeedMetadata
+		var dropped string
 		err := rows.Scan(
 			&feed.Hash,
 			&feed.URL,
@@ -142,10 +194,24 @@ FROM feed`
 			&feed.Timezone,
 			&feed.MaxArrival,
 			&feed.MaxDeparture,
+			&feed.FeedStartDate,
+			&feed.FeedEndDate,
+			&feed.FeedVersion,
+			&feed.FeedPublisherName,
+			&feed.FeedPublisherURL,
+			&feed.FeedLang,
+			&feed.DroppedCount,
+			&dropped,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning feed: %w", err)
 		}
+		if dropped != "" {
+			err = json.Unmarshal([]byte(dropped), &feed.Dropped)
+			if err != nil {
+				return nil, fmt.Errorf("unmarshaling dropped: %w", err)
+			}
+		}
 		feeds = append(feeds, &feed)
 	}
 
@@ -157,6 +223,10 @@ func (s *SQLiteStorage) ListFeedRequests(url string) ([]FeedRequest, error) {
 SELECT
     req.url,
     req.refreshed_at,
+    req.lenient,
+    req.encoding,
+    req.extras,
+    req.last_failure,
     con.name,
     con.headers,
     con.created_at,
@@ -184,9 +254,14 @@ LEFT JOIN feed_consumer con ON req.url = con.url`
 		var headers sql.NullString
 		var createdAt sql.NullTime
 		var updatedAt sql.NullTime
+		var lastFailure string
 		err := rows.Scan(
 			&req.URL,
 			&req.RefreshedAt,
+			&req.Lenient,
+			&req.Encoding,
+			&req.Extras,
+			&lastFailure,
 			&name,
 			&headers,
 			&createdAt,
@@ -195,6 +270,12 @@ LEFT JOIN feed_consumer con ON req.url = con.url`
 		if err != nil {
 			return nil, fmt.Errorf("scanning feed request: %w", err)
 		}
+		if lastFailure != "" {
+			err = json.Unmarshal([]byte(lastFailure), &req.LastFailure)
+			if err != nil {
+				return nil, fmt.Errorf("unmarshaling last failure: %w", err)
+			}
+		}
 
 		if _, ok := requests[req.URL]; !ok {
 			requests[req.URL] = &req
@@ -217,7 +298,12 @@ LEFT JOIN feed_consumer con ON req.url = con.url`
 }
 
 func (s *SQLiteStorage) WriteFeedMetadata(feed *FeedMetadata) error {
-	_, err := s.feedDB.Exec(`
+	dropped, err := json.Marshal(feed.Dropped)
+	if err != nil {
+		return fmt.Errorf("marshaling dropped: %w", err)
+	}
+
+	_, err = s.feedDB.Exec(`
 INSERT INTO feed (
     hash,
     url,
@@ -226,16 +312,32 @@ INSERT INTO feed (
     calendar_end,
     timezone,
     max_arrival,
-    max_departure
+    max_departure,
+    feed_start_date,
+    feed_end_date,
+    feed_version,
+    feed_publisher_name,
+    feed_publisher_url,
+    feed_lang,
+    dropped_count,
+    dropped
 )
-VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT (hash, url) DO UPDATE SET
     retrieved_at = excluded.retrieved_at,
     calendar_start = excluded.calendar_start,
     calendar_end = excluded.calendar_end,
     timezone = excluded.timezone,
     max_arrival = excluded.max_arrival,
-    max_departure = excluded.max_departure
+    max_departure = excluded.max_departure,
+    feed_start_date = excluded.feed_start_date,
+    feed_end_date = excluded.feed_end_date,
+    feed_version = excluded.feed_version,
+    feed_publisher_name = excluded.feed_publisher_name,
+    feed_publisher_url = excluded.feed_publisher_url,
+    feed_lang = excluded.feed_lang,
+    dropped_count = excluded.dropped_count,
+    dropped = excluded.dropped
 `,
 		feed.Hash,
 		feed.URL,
@@ -245,6 +347,14 @@ ON CONFLICT (hash, url) DO UPDATE SET
 		feed.Timezone,
 		feed.MaxArrival,
 		feed.MaxDeparture,
+		feed.FeedStartDate,
+		feed.FeedEndDate,
+		feed.FeedVersion,
+		feed.FeedPublisherName,
+		feed.FeedPublisherURL,
+		feed.FeedLang,
+		feed.DroppedCount,
+		string(dropped),
 	)
 	if err != nil {
 		return fmt.Errorf("writing feed metadata: %w", err)
@@ -258,18 +368,28 @@ func (s *SQLiteStorage) WriteFeedRequest(req FeedRequest) error {
 		return fmt.Errorf("starting transaction: %w", err)
 	}
 
+	lastFailure := ""
+	if req.LastFailure != nil {
+		buf, err := json.Marshal(req.LastFailure)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("marshaling last failure: %w", err)
+		}
+		lastFailure = string(buf)
+	}
+
 	query := `
-INSERT INTO feed_request (url, refreshed_at)
-VALUES (?, ?)
+INSERT INTO feed_request (url, refreshed_at, lenient, encoding, extras, last_failure)
+VALUES (?, ?, ?, ?, ?, ?)
 ON CONFLICT (url)`
 
 	if req.RefreshedAt.IsZero() {
 		query += " DO NOTHING"
 	} else {
-		query += "DO UPDATE SET refreshed_at = excluded.refreshed_at"
+		query += " DO UPDATE SET refreshed_at = excluded.refreshed_at, lenient = excluded.lenient, encoding = excluded.encoding, extras = excluded.extras, last_failure = excluded.last_failure"
 	}
 
-	_, err = tx.Exec(query, req.URL, req.RefreshedAt)
+	_, err = tx.Exec(query, req.URL, req.RefreshedAt, req.Lenient, req.Encoding, req.Extras, lastFailure)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("inserting feed request: %w", err)
@@ -325,6 +445,23 @@ func (s *SQLiteStorage) GetReader(hash string) (FeedReader, error) {
 		return nil, fmt.Errorf("opening database: %w", err)
 	}
 
+	// The feed may have been written by an earlier version,
+	// lacking tables and columns added since.
+	for table, columns := range sqliteFeedColumns {
+		err = addSQLiteColumns(db, table, columns)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	for name, query := range sqliteFeedTables {
+		_, err = db.Exec(query)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("creating %s table: %s", name, err)
+		}
+	}
+
 	s.feeds[hash] = db
 
 	return &SQLiteFeedReader{
@@ -332,34 +469,36 @@ func (s *SQLiteStorage) GetReader(hash string) (FeedReader, error) {
 	}, nil
 }
 
-func (s *SQLiteStorage) GetWriter(hash string) (FeedWriter, error) {
-	sourceName := ":memory:"
-	if s.OnDisk {
-		sourceName = s.Directory + "/" + hash + ".db"
-		// delete file if it exists
-		if _, err := os.Stat(sourceName); err == nil {
-			err := os.Remove(sourceName)
-			if err != nil {
-				return nil, fmt.Errorf("removing existing database: %w", err)
-			}
-		}
-	}
-
-	db, err := sql.Open("sqlite3", sourceName)
-	if err != nil {
-		return nil, fmt.Errorf("opening database: %w", err)
-	}
-
-	for name, query := range map[string]string{
-		"agency": `
-CREATE TABLE agency (
+// Tables of a feed database. Statements are idempotent, so they
+// also bring databases written by earlier versions up to date, once
+// missing columns have been added.
+var sqliteFeedTables = map[string]string{
+	"agency": `
+CREATE TABLE IF NOT EXISTS agency (
     id TEXT PRIMARY KEY,
     name TEXT NOT NULL,
     url TEXT NOT NULL,
-    timezone TEXT NOT NULL
+    timezone TEXT NOT NULL,
+    lang TEXT,
+    phone TEXT,
+    fare_url TEXT,
+    email TEXT,
+    extras TEXT NOT NULL
 );`,
-		"stops": `
-CREATE TABLE stops (
+	"feed_info": `
+CREATE TABLE IF NOT EXISTS feed_info (
+    publisher_name TEXT NOT NULL,
+    publisher_url TEXT NOT NULL,
+    lang TEXT NOT NULL,
+    default_lang TEXT,
+    start_date TEXT,
+    end_date TEXT,
+    version TEXT,
+    contact_email TEXT,
+    contact_url TEXT
+);`,
+	"stops": `
+CREATE TABLE IF NOT EXISTS stops (
     id TEXT PRIMARY KEY,
     code TEXT,
     name TEXT NOT NULL,
@@ -369,12 +508,17 @@ CREATE TABLE stops (
     url TEXT,
     location_type INTEGER NOT NULL,
     parent_station TEXT,
-    platform_code TEXT
+    platform_code TEXT,
+    zone_id TEXT,
+    level_id TEXT,
+    timezone TEXT,
+    wheelchair_boarding INTEGER NOT NULL,
+    extras TEXT NOT NULL
 );
-CREATE INDEX stops_parent_station ON stops (parent_station);
+CREATE INDEX IF NOT EXISTS stops_parent_station ON stops (parent_station);
 `,
-		"routes": `
-CREATE TABLE routes (
+	"routes": `
+CREATE TABLE IF NOT EXISTS routes (
     id TEXT PRIMARY KEY,
     agency_id TEXT,
     short_name TEXT,
@@ -383,36 +527,258 @@ CREATE TABLE routes (
     type INTEGER NOT NULL,
     url TEXT,
     color TEXT,
-    text_color TEXT
+    text_color TEXT,
+    continuous_pickup INTEGER NOT NULL,
+    continuous_drop_off INTEGER NOT NULL,
+    sort_order INTEGER,
+    network_id TEXT,
+    extras TEXT NOT NULL
 );`,
-		"trips": `
-CREATE TABLE trips (
+	"trips": `
+CREATE TABLE IF NOT EXISTS trips (
     id TEXT PRIMARY KEY,
     route_id TEXT NOT NULL,
     service_id TEXT NOT NULL,
     headsign TEXT,
     short_name TEXT,
-    direction_id INTEGER
+    direction_id INTEGER,
+    shape_id TEXT,
+    wheelchair_accessible INTEGER NOT NULL,
+    block_id TEXT,
+    bikes_allowed INTEGER NOT NULL,
+    cars_allowed INTEGER NOT NULL,
+    extras TEXT NOT NULL
 );
-CREATE INDEX trips_route_id ON trips (route_id);
-CREATE INDEX trips_service_id ON trips (service_id);
+CREATE INDEX IF NOT EXISTS trips_route_id ON trips (route_id);
+CREATE INDEX IF NOT EXISTS trips_service_id ON trips (service_id);
+CREATE INDEX IF NOT EXISTS trips_block_id ON trips (block_id);
 `,
-		"stop_times": `
-CREATE TABLE stop_times (
+	"stop_times": `
+CREATE TABLE IF NOT EXISTS stop_times (
     trip_id TEXT NOT NULL,
     stop_id TEXT NOT NULL,
     stop_sequence INTEGER NOT NULL,
     arrival_time TEXT NOT NULL,
     departure_time TEXT NOT NULL,
-    headsign TEXT
+    headsign TEXT,
+    pickup_type INTEGER NOT NULL,
+    drop_off_type INTEGER NOT NULL,
+    approximate INTEGER NOT NULL,
+    shape_dist_traveled REAL,
+    continuous_pickup INTEGER,
+    continuous_drop_off INTEGER
+);
+CREATE INDEX IF NOT EXISTS stop_times_trip_id ON stop_times (trip_id);
+CREATE INDEX IF NOT EXISTS stop_times_stop_id ON stop_times (stop_id);
+CREATE INDEX IF NOT EXISTS stop_times_arrival_time ON stop_times (arrival_time);
+CREATE INDEX IF NOT EXISTS stop_times_departure_time ON stop_times (departure_time);
+`,
+	"shapes": `
+CREATE TABLE IF NOT EXISTS shapes (
+    shape_id TEXT NOT NULL,
+    lat REAL NOT NULL,
+    lon REAL NOT NULL,
+    sequence INTEGER NOT NULL,
+    dist_traveled REAL
+);
+CREATE INDEX IF NOT EXISTS shapes_shape_id ON shapes (shape_id);
+`,
+	"frequencies": `
+CREATE TABLE IF NOT EXISTS frequencies (
+    trip_id TEXT NOT NULL,
+    start_time TEXT NOT NULL,
+    end_time TEXT NOT NULL,
+    headway_secs INTEGER NOT NULL,
+    exact_times INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS frequencies_trip_id ON frequencies (trip_id);
+`,
+	"transfers": `
+CREATE TABLE IF NOT EXISTS transfers (
+    from_stop_id TEXT NOT NULL,
+    to_stop_id TEXT NOT NULL,
+    from_route_id TEXT NOT NULL,
+    to_route_id TEXT NOT NULL,
+    from_trip_id TEXT NOT NULL,
+    to_trip_id TEXT NOT NULL,
+    transfer_type INTEGER NOT NULL,
+    min_transfer_time INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transfers_from_stop_id ON transfers (from_stop_id);
+CREATE INDEX IF NOT EXISTS transfers_from_trip_id ON transfers (from_trip_id);
+`,
+	"fare_attributes": `
+CREATE TABLE IF NOT EXISTS fare_attributes (
+    id TEXT PRIMARY KEY,
+    price REAL NOT NULL,
+    currency_type TEXT NOT NULL,
+    payment_method INTEGER NOT NULL,
+    transfers INTEGER NOT NULL,
+    agency_id TEXT NOT NULL,
+    transfer_duration INTEGER NOT NULL
+);
+`,
+	"fare_rules": `
+CREATE TABLE IF NOT EXISTS fare_rules (
+    fare_id TEXT NOT NULL,
+    route_id TEXT NOT NULL,
+    origin_id TEXT NOT NULL,
+    destination_id TEXT NOT NULL,
+    contains_id TEXT NOT NULL
+);
+`,
+	"networks": `
+CREATE TABLE IF NOT EXISTS networks (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL
+);
+`,
+	"route_networks": `
+CREATE TABLE IF NOT EXISTS route_networks (
+    network_id TEXT NOT NULL,
+    route_id TEXT NOT NULL
+);
+`,
+	"areas": `
+CREATE TABLE IF NOT EXISTS areas (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL
+);
+`,
+	"stop_areas": `
+CREATE TABLE IF NOT EXISTS stop_areas (
+    area_id TEXT NOT NULL,
+    stop_id TEXT NOT NULL
+);
+`,
+	"fare_media": `
+CREATE TABLE IF NOT EXISTS fare_media (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    type INTEGER NOT NULL
+);
+`,
+	"fare_products": `
+CREATE TABLE IF NOT EXISTS fare_products (
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    fare_media_id TEXT NOT NULL,
+    amount REAL NOT NULL,
+    currency TEXT NOT NULL
+);
+`,
+	"fare_leg_rules": `
+CREATE TABLE IF NOT EXISTS fare_leg_rules (
+    leg_group_id TEXT NOT NULL,
+    network_id TEXT NOT NULL,
+    from_area_id TEXT NOT NULL,
+    to_area_id TEXT NOT NULL,
+    fare_product_id TEXT NOT NULL,
+    rule_priority INTEGER
+);
+`,
+	"fare_transfer_rules": `
+CREATE TABLE IF NOT EXISTS fare_transfer_rules (
+    from_leg_group_id TEXT NOT NULL,
+    to_leg_group_id TEXT NOT NULL,
+    transfer_count INTEGER NOT NULL,
+    duration_limit INTEGER NOT NULL,
+    duration_limit_type INTEGER NOT NULL,
+    fare_transfer_type INTEGER NOT NULL,
+    fare_product_id TEXT NOT NULL
+);
+`,
+	"levels": `
+CREATE TABLE IF NOT EXISTS levels (
+    id TEXT PRIMARY KEY,
+    level_index REAL NOT NULL,
+    name TEXT NOT NULL
+);
+`,
+	"pathways": `
+CREATE TABLE IF NOT EXISTS pathways (
+    id TEXT PRIMARY KEY,
+    from_stop_id TEXT NOT NULL,
+    to_stop_id TEXT NOT NULL,
+    mode INTEGER NOT NULL,
+    is_bidirectional INTEGER NOT NULL,
+    length REAL NOT NULL,
+    traversal_time INTEGER NOT NULL,
+    stair_count INTEGER NOT NULL,
+    max_slope REAL NOT NULL,
+    min_width REAL NOT NULL,
+    signposted_as TEXT NOT NULL,
+    reversed_signposted_as TEXT NOT NULL
 );
-CREATE INDEX stop_times_trip_id ON stop_times (trip_id);
-CREATE INDEX stop_times_stop_id ON stop_times (stop_id);
-CREATE INDEX stop_times_arrival_time ON stop_times (arrival_time);
-CREATE INDEX stop_times_departure_time ON stop_times (departure_time);
 `,
-		"calendar": `
-CREATE TABLE calendar (
+	"translations": `
+CREATE TABLE IF NOT EXISTS translations (
+    table_name TEXT NOT NULL,
+    field_name TEXT NOT NULL,
+    language TEXT NOT NULL,
+    translation TEXT NOT NULL,
+    record_id TEXT NOT NULL,
+    record_sub_id TEXT NOT NULL,
+    field_value TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS translations_table_field ON translations (table_name, field_name);
+`,
+	"locations": `
+CREATE TABLE IF NOT EXISTS locations (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    geometry TEXT NOT NULL
+);
+`,
+	"location_groups": `
+CREATE TABLE IF NOT EXISTS location_groups (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL
+);
+`,
+	"location_group_stops": `
+CREATE TABLE IF NOT EXISTS location_group_stops (
+    location_group_id TEXT NOT NULL,
+    stop_id TEXT NOT NULL
+);
+`,
+	"booking_rules": `
+CREATE TABLE IF NOT EXISTS booking_rules (
+    id TEXT PRIMARY KEY,
+    booking_type INTEGER NOT NULL,
+    prior_notice_duration_min INTEGER NOT NULL,
+    prior_notice_duration_max INTEGER NOT NULL,
+    prior_notice_last_day INTEGER NOT NULL,
+    prior_notice_last_time TEXT NOT NULL,
+    prior_notice_start_day INTEGER NOT NULL,
+    prior_notice_start_time TEXT NOT NULL,
+    prior_notice_service_id TEXT NOT NULL,
+    message TEXT NOT NULL,
+    pickup_message TEXT NOT NULL,
+    drop_off_message TEXT NOT NULL,
+    phone_number TEXT NOT NULL,
+    info_url TEXT NOT NULL,
+    booking_url TEXT NOT NULL
+);
+`,
+	"flex_stop_times": `
+CREATE TABLE IF NOT EXISTS flex_stop_times (
+    trip_id TEXT NOT NULL,
+    stop_sequence INTEGER NOT NULL,
+    stop_id TEXT NOT NULL,
+    location_group_id TEXT NOT NULL,
+    location_id TEXT NOT NULL,
+    start_window TEXT NOT NULL,
+    end_window TEXT NOT NULL,
+    pickup_type INTEGER NOT NULL,
+    drop_off_type INTEGER NOT NULL,
+    pickup_booking_rule_id TEXT NOT NULL,
+    drop_off_booking_rule_id TEXT NOT NULL
+);
+`,
+	"calendar": `
+CREATE TABLE IF NOT EXISTS calendar (
     service_id TEXT PRIMARY KEY,
     start_date TEXT NOT NULL,
     end_date TEXT NOT NULL,
@@ -424,13 +790,116 @@ CREATE TABLE calendar (
     saturday integer NOT NULL,
     sunday integer NOT NULL
 );`,
-		"calendar_dates": `
-CREATE TABLE calendar_dates (
+	"calendar_dates": `
+CREATE TABLE IF NOT EXISTS calendar_dates (
     service_id TEXT NOT NULL,
     date TEXT NOT NULL,
     exception_type INTEGER NOT NULL
 );`,
-	} {
+}
+
+// Columns added to feed tables since they were first created, with
+// defaults for rows written by earlier versions.
+var sqliteFeedColumns = map[string][][2]string{
+	"agency": {
+		{"lang", "TEXT NOT NULL DEFAULT ''"},
+		{"phone", "TEXT NOT NULL DEFAULT ''"},
+		{"fare_url", "TEXT NOT NULL DEFAULT ''"},
+		{"email", "TEXT NOT NULL DEFAULT ''"},
+		{"extras", "TEXT NOT NULL DEFAULT ''"},
+	},
+	"stops": {
+		{"zone_id", "TEXT NOT NULL DEFAULT ''"},
+		{"level_id", "TEXT NOT NULL DEFAULT ''"},
+		{"timezone", "TEXT NOT NULL DEFAULT ''"},
+		{"wheelchair_boarding", "INTEGER NOT NULL DEFAULT 0"},
+		{"extras", "TEXT NOT NULL DEFAULT ''"},
+	},
+	"routes": {
+		{"continuous_pickup", "INTEGER NOT NULL DEFAULT 0"},
+		{"continuous_drop_off", "INTEGER NOT NULL DEFAULT 0"},
+		{"sort_order", "INTEGER"},
+		{"network_id", "TEXT NOT NULL DEFAULT ''"},
+		{"extras", "TEXT NOT NULL DEFAULT ''"},
+	},
+	"trips": {
+		{"shape_id", "TEXT NOT NULL DEFAULT ''"},
+		{"wheelchair_accessible", "INTEGER NOT NULL DEFAULT 0"},
+		{"block_id", "TEXT NOT NULL DEFAULT ''"},
+		{"bikes_allowed", "INTEGER NOT NULL DEFAULT 0"},
+		{"cars_allowed", "INTEGER NOT NULL DEFAULT 0"},
+		{"extras", "TEXT NOT NULL DEFAULT ''"},
+	},
+	"stop_times": {
+		{"pickup_type", "INTEGER NOT NULL DEFAULT 0"},
+		{"drop_off_type", "INTEGER NOT NULL DEFAULT 0"},
+		{"approximate", "INTEGER NOT NULL DEFAULT 0"},
+		{"shape_dist_traveled", "REAL"},
+		{"continuous_pickup", "INTEGER"},
+		{"continuous_drop_off", "INTEGER"},
+	},
+}
+
+// Adds columns missing from an existing table. Tables that don't
+// exist are left alone.
+func addSQLiteColumns(db *sql.DB, table string, columns [][2]string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("getting %s columns: %w", table, err)
+	}
+	existing := map[string]bool{}
+	for rows.Next() {
+		var cid int
+		var name, typ string
+		var notNull, pk int
+		var dflt sql.NullString
+		err = rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning %s columns: %w", table, err)
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("getting %s columns: %w", table, err)
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+
+	for _, column := range columns {
+		if existing[column[0]] {
+			continue
+		}
+		_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column[0], column[1]))
+		if err != nil {
+			return fmt.Errorf("adding %s.%s: %w", table, column[0], err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLiteStorage) GetWriter(hash string) (FeedWriter, error) {
+	sourceName := ":memory:"
+	if s.OnDisk {
+		sourceName = s.Directory + "/" + hash + ".db"
+		// delete file if it exists
+		if _, err := os.Stat(sourceName); err == nil {
+			err := os.Remove(sourceName)
+			if err != nil {
+				return nil, fmt.Errorf("removing existing database: %w", err)
+			}
+		}
+	}
+
+	db, err := sql.Open("sqlite3", sourceName)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	for name, query := range sqliteFeedTables {
 		_, err = db.Exec(query)
 		if err != nil {
 			db.Close()
@@ -447,12 +916,17 @@ CREATE TABLE calendar_dates (
 
 func (f *SQLiteFeedWriter) WriteAgency(a model.Agency) error {
 	_, err := f.db.Exec(`
-INSERT INTO agency (id, name, url, timezone)
-VALUES (?, ?, ?, ?)`,
+INSERT INTO agency (id, name, url, timezone, lang, phone, fare_url, email, extras)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		a.ID,
 		a.Name,
 		a.URL,
 		a.Timezone,
+		a.Lang,
+		a.Phone,
+		a.FareURL,
+		a.Email,
+		encodeExtras(a.Extras),
 	)
 	if err != nil {
 		return fmt.Errorf("inserting agency: %w", err)
@@ -460,10 +934,30 @@ VALUES (?, ?, ?, ?)`,
 	return nil
 }
 
+func (f *SQLiteFeedWriter) WriteFeedInfo(fi model.FeedInfo) error {
+	_, err := f.db.Exec(`
+INSERT INTO feed_info (publisher_name, publisher_url, lang, default_lang, start_date, end_date, version, contact_email, contact_url)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		fi.PublisherName,
+		fi.PublisherURL,
+		fi.Lang,
+		fi.DefaultLang,
+		fi.StartDate,
+		fi.EndDate,
+		fi.Version,
+		fi.ContactEmail,
+		fi.ContactURL,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting feed info: %w", err)
+	}
+	return nil
+}
+
 func (f *SQLiteFeedWriter) WriteStop(stop model.Stop) error {
 	_, err := f.db.Exec(`
-INSERT INTO stops (id, code, name, desc, lat, lon, url, location_type, parent_station, platform_code)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+INSERT INTO stops (id, code, name, desc, lat, lon, url, location_type, parent_station, platform_code, zone_id, level_id, timezone, wheelchair_boarding, extras)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		stop.ID,
 		stop.Code,
 		stop.Name,
@@ -474,6 +968,11 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		stop.LocationType,
 		stop.ParentStation,
 		stop.PlatformCode,
+		stop.ZoneID,
+		stop.LevelID,
+		stop.Timezone,
+		stop.WheelchairBoarding,
+		encodeExtras(stop.Extras),
 	)
 	if err != nil {
 		return fmt.Errorf("inserting stop: %w", err)
@@ -483,8 +982,8 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 
 func (f *SQLiteFeedWriter) WriteRoute(route model.Route) error {
 	_, err := f.db.Exec(`
-INSERT INTO routes (id, agency_id, short_name, long_name, desc, type, url, color, text_color)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+INSERT INTO routes (id, agency_id, short_name, long_name, desc, type, url, color, text_color, continuous_pickup, continuous_drop_off, sort_order, network_id, extras)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		route.ID,
 		route.AgencyID,
 		route.ShortName,
@@ -494,6 +993,14 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		route.URL,
 		route.Color,
 		route.TextColor,
+		route.ContinuousPickup,
+		route.ContinuousDropOff,
+		sql.NullInt64{
+			Int64: int64(route.SortOrder),
+			Valid: route.HasSortOrder,
+		},
+		route.NetworkID,
+		encodeExtras(route.Extras),
 	)
 	if err != nil {
 		return fmt.Errorf("inserting route: %w", err)
@@ -507,14 +1014,20 @@ func (f *SQLiteFeedWriter) BeginTrips() error {
 
 func (f *SQLiteFeedWriter) WriteTrip(trip model.Trip) error {
 	_, err := f.db.Exec(`
-INSERT INTO trips (id, route_id, service_id, headsign, short_name, direction_id)
-VALUES (?, ?, ?, ?, ?, ?)`,
+INSERT INTO trips (id, route_id, service_id, headsign, short_name, direction_id, shape_id, wheelchair_accessible, block_id, bikes_allowed, cars_allowed, extras)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		trip.ID,
 		trip.RouteID,
 		trip.ServiceID,
 		trip.Headsign,
 		trip.ShortName,
 		trip.DirectionID,
+		trip.ShapeID,
+		trip.WheelchairAccessible,
+		trip.BlockID,
+		trip.BikesAllowed,
+		trip.CarsAllowed,
+		encodeExtras(trip.Extras),
 	)
 	if err != nil {
 		return fmt.Errorf("inserting trip: %w", err)
@@ -526,6 +1039,61 @@ func (f *SQLiteFeedWriter) EndTrips() error {
 	return nil
 }
 
+func (f *SQLiteFeedWriter) BeginShapes() error {
+	var err error
+	f.shapeInsertTx, err = f.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning shape insert transaction: %w", err)
+	}
+
+	f.shapeInsertQuery, err = f.shapeInsertTx.Prepare(`
+INSERT INTO shapes (shape_id, lat, lon, sequence, dist_traveled)
+VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		f.shapeInsertTx.Rollback()
+		f.shapeInsertTx = nil
+		return fmt.Errorf("preparing shape insert: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteShapePoint(shapeID string, point model.ShapePoint) error {
+	dist := sql.NullFloat64{
+		Float64: point.DistTraveled,
+		Valid:   point.HasDistTraveled,
+	}
+
+	_, err := f.shapeInsertQuery.Exec(
+		shapeID,
+		point.Lat,
+		point.Lon,
+		point.Sequence,
+		dist,
+	)
+	if err != nil {
+		f.shapeInsertQuery.Close()
+		f.shapeInsertTx.Rollback()
+		f.shapeInsertTx = nil
+		f.shapeInsertQuery = nil
+		return fmt.Errorf("inserting shape point: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) EndShapes() error {
+	f.shapeInsertQuery.Close()
+	err := f.shapeInsertTx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing shape insert transaction: %w", err)
+	}
+	f.shapeInsertTx = nil
+	f.shapeInsertQuery = nil
+
+	return nil
+}
+
 func (f *SQLiteFeedWriter) BeginStopTimes() error {
 	// transaction with prepared statement.
 	var err error
@@ -535,8 +1103,8 @@ func (f *SQLiteFeedWriter) BeginStopTimes() error {
 	}
 
 	f.stopTimeInsertQuery, err = f.stopTimeInsertTx.Prepare(`
-INSERT INTO stop_times (trip_id, stop_id, stop_sequence, arrival_time, departure_time, stop_id, headsign)
-VALUES (?, ?, ?, ?, ?, ? ,?)`)
+INSERT INTO stop_times (trip_id, stop_id, stop_sequence, arrival_time, departure_time, stop_id, headsign, pickup_type, drop_off_type, approximate, shape_dist_traveled, continuous_pickup, continuous_drop_off)
+VALUES (?, ?, ?, ?, ?, ? ,?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		f.stopTimeInsertTx.Rollback()
 		f.stopTimeInsertTx = nil
@@ -555,6 +1123,21 @@ func (f *SQLiteFeedWriter) WriteStopTime(stopTime model.StopTime) error {
 		stopTime.Departure,
 		stopTime.StopID,
 		stopTime.Headsign,
+		stopTime.PickupType,
+		stopTime.DropOffType,
+		stopTime.Approximate,
+		sql.NullFloat64{
+			Float64: stopTime.ShapeDistTraveled,
+			Valid:   stopTime.HasShapeDistTraveled,
+		},
+		sql.NullInt64{
+			Int64: int64(stopTime.ContinuousPickup),
+			Valid: stopTime.HasContinuousPickup,
+		},
+		sql.NullInt64{
+			Int64: int64(stopTime.ContinuousDropOff),
+			Valid: stopTime.HasContinuousDropOff,
+		},
 	)
 	if err != nil {
 		f.stopTimeInsertQuery.Close()
@@ -580,81 +1163,444 @@ func (f *SQLiteFeedWriter) EndStopTimes() error {
 	return nil
 }
 
-func (f *SQLiteFeedWriter) WriteCalendar(cal model.Calendar) error {
-	mon, tue, wed, thu, fri, sat, sun := 0, 0, 0, 0, 0, 0, 0
-	if cal.Weekday&(1<<time.Monday) != 0 {
-		mon = 1
-	}
-	if cal.Weekday&(1<<time.Tuesday) != 0 {
-		tue = 1
-	}
-	if cal.Weekday&(1<<time.Wednesday) != 0 {
-		wed = 1
-	}
-	if cal.Weekday&(1<<time.Thursday) != 0 {
-		thu = 1
-	}
-	if cal.Weekday&(1<<time.Friday) != 0 {
-		fri = 1
-	}
-	if cal.Weekday&(1<<time.Saturday) != 0 {
-		sat = 1
-	}
-	if cal.Weekday&(1<<time.Sunday) != 0 {
-		sun = 1
+func (f *SQLiteFeedWriter) WriteFrequency(freq model.Frequency) error {
+	_, err := f.db.Exec(`
+INSERT INTO frequencies (trip_id, start_time, end_time, headway_secs, exact_times)
+VALUES (?, ?, ?, ?, ?)`,
+		freq.TripID,
+		freq.Start,
+		freq.End,
+		freq.HeadwaySecs,
+		freq.ExactTimes,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting frequency: %w", err)
 	}
 
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteTransfer(transfer model.Transfer) error {
 	_, err := f.db.Exec(`
-INSERT INTO calendar (service_id, start_date, end_date, monday, tuesday, wednesday, thursday, friday, saturday, sunday)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		cal.ServiceID,
-		cal.StartDate,
-		cal.EndDate,
-		mon, tue, wed, thu, fri, sat, sun,
+INSERT INTO transfers (from_stop_id, to_stop_id, from_route_id, to_route_id, from_trip_id, to_trip_id, transfer_type, min_transfer_time)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		transfer.FromStopID,
+		transfer.ToStopID,
+		transfer.FromRouteID,
+		transfer.ToRouteID,
+		transfer.FromTripID,
+		transfer.ToTripID,
+		transfer.Type,
+		transfer.MinTransferTime,
 	)
 	if err != nil {
-		return fmt.Errorf("inserting calendar: %w", err)
+		return fmt.Errorf("inserting transfer: %w", err)
 	}
 
 	return nil
 }
 
-func (f *SQLiteFeedWriter) WriteCalendarDate(cd model.CalendarDate) error {
+func (f *SQLiteFeedWriter) WriteFareAttribute(fare model.FareAttribute) error {
 	_, err := f.db.Exec(`
-INSERT INTO calendar_dates (service_id, date, exception_type)
-VALUES (?, ?, ?)`,
-		cd.ServiceID,
-		cd.Date,
-		cd.ExceptionType,
+INSERT INTO fare_attributes (id, price, currency_type, payment_method, transfers, agency_id, transfer_duration)
+VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		fare.ID,
+		fare.Price,
+		fare.CurrencyType,
+		fare.PaymentMethod,
+		fare.Transfers,
+		fare.AgencyID,
+		fare.TransferDuration,
 	)
 	if err != nil {
-		return fmt.Errorf("inserting calendar date: %w", err)
+		return fmt.Errorf("inserting fare attribute: %w", err)
 	}
 
 	return nil
 }
 
-func (f *SQLiteFeedWriter) Close() error {
-	_, err := f.db.Exec(`ANALYZE;`)
+func (f *SQLiteFeedWriter) WriteFareRule(rule model.FareRule) error {
+	_, err := f.db.Exec(`
+INSERT INTO fare_rules (fare_id, route_id, origin_id, destination_id, contains_id)
+VALUES (?, ?, ?, ?, ?)`,
+		rule.FareID,
+		rule.RouteID,
+		rule.OriginID,
+		rule.DestinationID,
+		rule.ContainsID,
+	)
 	if err != nil {
-		f.db.Close()
-		return fmt.Errorf("analyzing database: %s", err)
+		return fmt.Errorf("inserting fare rule: %w", err)
 	}
 
 	return nil
 }
 
-func (f *SQLiteFeedReader) ActiveServices(date string) ([]string, error) {
-	parsedDate, err := time.Parse("20060102", date)
+func (f *SQLiteFeedWriter) WriteNetwork(network model.Network) error {
+	_, err := f.db.Exec(`
+INSERT INTO networks (id, name)
+VALUES (?, ?)`,
+		network.ID,
+		network.Name,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date: %s", date)
+		return fmt.Errorf("inserting network: %w", err)
 	}
 
-	var weekday string
-	switch parsedDate.Weekday() {
-	case time.Monday:
-		weekday = "monday"
-	case time.Tuesday:
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteRouteNetwork(routeNetwork model.RouteNetwork) error {
+	_, err := f.db.Exec(`
+INSERT INTO route_networks (network_id, route_id)
+VALUES (?, ?)`,
+		routeNetwork.NetworkID,
+		routeNetwork.RouteID,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting route network: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteArea(area model.Area) error {
+	_, err := f.db.Exec(`
+INSERT INTO areas (id, name)
+VALUES (?, ?)`,
+		area.ID,
+		area.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting area: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteStopArea(stopArea model.StopArea) error {
+	_, err := f.db.Exec(`
+INSERT INTO stop_areas (area_id, stop_id)
+VALUES (?, ?)`,
+		stopArea.AreaID,
+		stopArea.StopID,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting stop area: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteFareMedia(media model.FareMedia) error {
+	_, err := f.db.Exec(`
+INSERT INTO fare_media (id, name, type)
+VALUES (?, ?, ?)`,
+		media.ID,
+		media.Name,
+		media.Type,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting fare media: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteFareProduct(product model.FareProduct) error {
+	_, err := f.db.Exec(`
+INSERT INTO fare_products (id, name, fare_media_id, amount, currency)
+VALUES (?, ?, ?, ?, ?)`,
+		product.ID,
+		product.Name,
+		product.FareMediaID,
+		product.Amount,
+		product.Currency,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting fare product: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteFareLegRule(rule model.FareLegRule) error {
+	_, err := f.db.Exec(`
+INSERT INTO fare_leg_rules (leg_group_id, network_id, from_area_id, to_area_id, fare_product_id, rule_priority)
+VALUES (?, ?, ?, ?, ?, ?)`,
+		rule.LegGroupID,
+		rule.NetworkID,
+		rule.FromAreaID,
+		rule.ToAreaID,
+		rule.FareProductID,
+		sql.NullInt64{
+			Int64: int64(rule.RulePriority),
+			Valid: rule.HasRulePriority,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("inserting fare leg rule: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteFareTransferRule(rule model.FareTransferRule) error {
+	_, err := f.db.Exec(`
+INSERT INTO fare_transfer_rules (from_leg_group_id, to_leg_group_id, transfer_count, duration_limit, duration_limit_type, fare_transfer_type, fare_product_id)
+VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		rule.FromLegGroupID,
+		rule.ToLegGroupID,
+		rule.TransferCount,
+		rule.DurationLimit,
+		rule.DurationLimitType,
+		rule.FareTransferType,
+		rule.FareProductID,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting fare transfer rule: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteLevel(level model.Level) error {
+	_, err := f.db.Exec(`
+INSERT INTO levels (id, level_index, name)
+VALUES (?, ?, ?)`,
+		level.ID,
+		level.Index,
+		level.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting level: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WritePathway(pathway model.Pathway) error {
+	_, err := f.db.Exec(`
+INSERT INTO pathways (id, from_stop_id, to_stop_id, mode, is_bidirectional, length, traversal_time, stair_count, max_slope, min_width, signposted_as, reversed_signposted_as)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		pathway.ID,
+		pathway.FromStopID,
+		pathway.ToStopID,
+		pathway.Mode,
+		pathway.IsBidirectional,
+		pathway.Length,
+		pathway.TraversalTime,
+		pathway.StairCount,
+		pathway.MaxSlope,
+		pathway.MinWidth,
+		pathway.SignpostedAs,
+		pathway.ReversedSignpostedAs,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting pathway: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteTranslation(translation model.Translation) error {
+	_, err := f.db.Exec(`
+INSERT INTO translations (table_name, field_name, language, translation, record_id, record_sub_id, field_value)
+VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		translation.TableName,
+		translation.FieldName,
+		translation.Language,
+		translation.Translation,
+		translation.RecordID,
+		translation.RecordSubID,
+		translation.FieldValue,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting translation: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteLocation(location model.Location) error {
+	geometry, err := json.Marshal(location.Polygons)
+	if err != nil {
+		return fmt.Errorf("marshaling location geometry: %w", err)
+	}
+
+	_, err = f.db.Exec(`
+INSERT INTO locations (id, name, description, geometry)
+VALUES (?, ?, ?, ?)`,
+		location.ID,
+		location.Name,
+		location.Desc,
+		string(geometry),
+	)
+	if err != nil {
+		return fmt.Errorf("inserting location: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteLocationGroup(group model.LocationGroup) error {
+	_, err := f.db.Exec(`
+INSERT INTO location_groups (id, name)
+VALUES (?, ?)`,
+		group.ID,
+		group.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting location group: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteLocationGroupStop(lgs model.LocationGroupStop) error {
+	_, err := f.db.Exec(`
+INSERT INTO location_group_stops (location_group_id, stop_id)
+VALUES (?, ?)`,
+		lgs.LocationGroupID,
+		lgs.StopID,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting location group stop: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteBookingRule(rule model.BookingRule) error {
+	_, err := f.db.Exec(`
+INSERT INTO booking_rules (id, booking_type, prior_notice_duration_min, prior_notice_duration_max, prior_notice_last_day, prior_notice_last_time, prior_notice_start_day, prior_notice_start_time, prior_notice_service_id, message, pickup_message, drop_off_message, phone_number, info_url, booking_url)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID,
+		rule.Type,
+		rule.PriorNoticeDurationMin,
+		rule.PriorNoticeDurationMax,
+		rule.PriorNoticeLastDay,
+		rule.PriorNoticeLastTime,
+		rule.PriorNoticeStartDay,
+		rule.PriorNoticeStartTime,
+		rule.PriorNoticeServiceID,
+		rule.Message,
+		rule.PickupMessage,
+		rule.DropOffMessage,
+		rule.PhoneNumber,
+		rule.InfoURL,
+		rule.BookingURL,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting booking rule: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteFlexStopTime(stopTime model.FlexStopTime) error {
+	// Part of the stop_times transaction, so must go through it.
+	_, err := f.stopTimeInsertTx.Exec(`
+INSERT INTO flex_stop_times (trip_id, stop_sequence, stop_id, location_group_id, location_id, start_window, end_window, pickup_type, drop_off_type, pickup_booking_rule_id, drop_off_booking_rule_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		stopTime.TripID,
+		stopTime.StopSequence,
+		stopTime.StopID,
+		stopTime.LocationGroupID,
+		stopTime.LocationID,
+		stopTime.StartWindow,
+		stopTime.EndWindow,
+		stopTime.PickupType,
+		stopTime.DropOffType,
+		stopTime.PickupBookingRuleID,
+		stopTime.DropOffBookingRuleID,
+	)
+	if err != nil {
+		f.stopTimeInsertQuery.Close()
+		f.stopTimeInsertTx.Rollback()
+		f.stopTimeInsertTx = nil
+		f.stopTimeInsertQuery = nil
+		return fmt.Errorf("inserting flex stop_time: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteCalendar(cal model.Calendar) error {
+	mon, tue, wed, thu, fri, sat, sun := 0, 0, 0, 0, 0, 0, 0
+	if cal.Weekday&(1<<time.Monday) != 0 {
+		mon = 1
+	}
+	if cal.Weekday&(1<<time.Tuesday) != 0 {
+		tue = 1
+	}
+	if cal.Weekday&(1<<time.Wednesday) != 0 {
+		wed = 1
+	}
+	if cal.Weekday&(1<<time.Thursday) != 0 {
+		thu = 1
+	}
+	if cal.Weekday&(1<<time.Friday) != 0 {
+		fri = 1
+	}
+	if cal.Weekday&(1<<time.Saturday) != 0 {
+		sat = 1
+	}
+	if cal.Weekday&(1<<time.Sunday) != 0 {
+		sun = 1
+	}
+
+	_, err := f.db.Exec(`
+INSERT INTO calendar (service_id, start_date, end_date, monday, tuesday, wednesday, thursday, friday, saturday, sunday)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		cal.ServiceID,
+		cal.StartDate,
+		cal.EndDate,
+		mon, tue, wed, thu, fri, sat, sun,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting calendar: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) WriteCalendarDate(cd model.CalendarDate) error {
+	_, err := f.db.Exec(`
+INSERT INTO calendar_dates (service_id, date, exception_type)
+VALUES (?, ?, ?)`,
+		cd.ServiceID,
+		cd.Date,
+		cd.ExceptionType,
+	)
+	if err != nil {
+		return fmt.Errorf("inserting calendar date: %w", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedWriter) Close() error {
+	_, err := f.db.Exec(`ANALYZE;`)
+	if err != nil {
+		f.db.Close()
+		return fmt.Errorf("analyzing database: %s", err)
+	}
+
+	return nil
+}
+
+func (f *SQLiteFeedReader) ActiveServices(date string) ([]string, error) {
+	parsedDate, err := time.Parse("20060102", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date: %s", date)
+	}
+
+	var weekday string
+	switch parsedDate.Weekday() {
+	case time.Monday:
+		weekday = "monday"
+	case time.Tuesday:
 		weekday = "tuesday"
 	case time.Wednesday:
 		weekday = "wednesday"
@@ -722,7 +1668,12 @@ SELECT
     stops.url,
     stops.location_type,
     stops.parent_station,
-    stops.platform_code
+    stops.platform_code,
+    stops.zone_id,
+    stops.level_id,
+    stops.timezone,
+    stops.wheelchair_boarding,
+    stops.extras
 FROM
     stops
 WHERE
@@ -734,6 +1685,7 @@ WHERE
 	stops := []model.Stop{}
 	for row.Next() {
 		stop := model.Stop{}
+		extras := ""
 		err = row.Scan(
 			&stop.ID,
 			&stop.Code,
@@ -745,10 +1697,19 @@ WHERE
 			&stop.LocationType,
 			&stop.ParentStation,
 			&stop.PlatformCode,
+			&stop.ZoneID,
+			&stop.LevelID,
+			&stop.Timezone,
+			&stop.WheelchairBoarding,
+			&extras,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning stop: %w", err)
 		}
+		stop.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
 
 		stops = append(stops, stop)
 	}
@@ -757,14 +1718,11 @@ WHERE
 }
 
 func (f *SQLiteFeedReader) getStopsByRouteType(routeTypes []model.RouteType) ([]model.Stop, error) {
+	routeTypeCond, routeTypeValues := routeTypeCondition(routeTypes, func(int) string { return "?" })
 	queryValues := []interface{}{}
-	for _, rt := range routeTypes {
+	for _, rt := range routeTypeValues {
 		queryValues = append(queryValues, rt)
 	}
-	routeTypePlaceholders := []string{}
-	for range routeTypes {
-		routeTypePlaceholders = append(routeTypePlaceholders, "?")
-	}
 
 	rows, err := f.db.Query(`
 SELECT
@@ -778,6 +1736,11 @@ SELECT
     stops.location_type,
     stops.parent_station,
     stops.platform_code,
+    stops.zone_id,
+    stops.level_id,
+    stops.timezone,
+    stops.wheelchair_boarding,
+    stops.extras,
     parent.id,
     parent.code,
     parent.name,
@@ -786,7 +1749,12 @@ SELECT
     parent.lon,
     parent.url,
     parent.location_type,
-    parent.platform_code
+    parent.platform_code,
+    parent.zone_id,
+    parent.level_id,
+    parent.timezone,
+    parent.wheelchair_boarding,
+    parent.extras
 FROM stop_times
 INNER JOIN trips ON stop_times.trip_id = trips.id
 INNER JOIN routes ON trips.route_id = routes.id
@@ -794,7 +1762,7 @@ INNER JOIN stops ON stop_times.stop_id = stops.id
 LEFT OUTER JOIN stops AS parent ON stops.parent_station = parent.id
 WHERE
     stops.location_type = 0 AND
-    routes.type IN (`+strings.Join(routeTypePlaceholders, ", ")+`)
+    `+routeTypeCond+`
 `, queryValues...)
 	if err != nil {
 		return nil, fmt.Errorf("querying for stops by route type: %w", err)
@@ -813,6 +1781,12 @@ WHERE
 		parentURL := sql.NullString{}
 		parentLocationType := sql.NullInt64{}
 		parentPlatformCode := sql.NullString{}
+		parentZoneID := sql.NullString{}
+		parentLevelID := sql.NullString{}
+		parentWheelchairBoarding := sql.NullInt64{}
+		parentTimezone := sql.NullString{}
+		parentExtras := sql.NullString{}
+		extras := ""
 		err := rows.Scan(
 			&s.ID,
 			&s.Code,
@@ -824,6 +1798,11 @@ WHERE
 			&s.LocationType,
 			&s.ParentStation,
 			&s.PlatformCode,
+			&s.ZoneID,
+			&s.LevelID,
+			&s.Timezone,
+			&s.WheelchairBoarding,
+			&extras,
 			&parentID,
 			&parentCode,
 			&parentName,
@@ -833,298 +1812,1353 @@ WHERE
 			&parentURL,
 			&parentLocationType,
 			&parentPlatformCode,
+			&parentZoneID,
+			&parentLevelID,
+			&parentTimezone,
+			&parentWheelchairBoarding,
+			&parentExtras,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning stop: %w", err)
+		}
+
+		if parentID.Valid {
+			parent := model.Stop{
+				ID:                 parentID.String,
+				Code:               parentCode.String,
+				Name:               parentName.String,
+				Desc:               parentDesc.String,
+				Lat:                parentLat.Float64,
+				Lon:                parentLon.Float64,
+				URL:                parentURL.String,
+				LocationType:       model.LocationType(parentLocationType.Int64),
+				PlatformCode:       parentPlatformCode.String,
+				ZoneID:             parentZoneID.String,
+				LevelID:            parentLevelID.String,
+				Timezone:           parentTimezone.String,
+				WheelchairBoarding: model.WheelchairAccessibility(parentWheelchairBoarding.Int64),
+			}
+			parent.Extras, err = decodeExtras(parentExtras.String)
+			if err != nil {
+				return nil, err
+			}
+			allStops[parentID.String] = parent
+		} else {
+			s.Extras, err = decodeExtras(extras)
+			if err != nil {
+				return nil, err
+			}
+			allStops[s.ID] = s
+		}
+	}
+
+	stops := []model.Stop{}
+	for _, s := range allStops {
+		stops = append(stops, s)
+	}
+
+	return stops, nil
+}
+
+func (f *SQLiteFeedReader) NearbyStops(lat float64, lng float64, limit int, routeTypes []model.RouteType) ([]model.Stop, error) {
+	var stops []model.Stop
+	var err error
+
+	if len(routeTypes) == 0 {
+		stops, err = f.getStops()
+		if err != nil {
+			return nil, fmt.Errorf("getting all stops: %w", err)
+		}
+	} else {
+		// NOTE: With this query, only stops that have an
+		// actual trip of the correct route type passing
+		// through will be included in the result.
+		stops, err = f.getStopsByRouteType(routeTypes)
+		if err != nil {
+			return nil, fmt.Errorf("getting stops by route type: %w", err)
+		}
+	}
+
+	sort.Slice(stops, func(i, j int) bool {
+		di := HaversineDistance(lat, lng, stops[i].Lat, stops[i].Lon)
+		dj := HaversineDistance(lat, lng, stops[j].Lat, stops[j].Lon)
+		return di < dj
+	})
+
+	if limit > 0 && len(stops) > limit {
+		stops = stops[:limit]
+	}
+
+	return stops, nil
+}
+
+func (f *SQLiteFeedReader) Agencies() ([]model.Agency, error) {
+	rows, err := f.db.Query(`
+SELECT id, name, url, timezone, lang, phone, fare_url, email, extras
+FROM agency`)
+	if err != nil {
+		return nil, fmt.Errorf("querying agencies: %w", err)
+	}
+	defer rows.Close()
+
+	agencies := []model.Agency{}
+	for rows.Next() {
+		a := model.Agency{}
+		extras := ""
+		err := rows.Scan(&a.ID, &a.Name, &a.URL, &a.Timezone, &a.Lang, &a.Phone, &a.FareURL, &a.Email, &extras)
+		if err != nil {
+			return nil, fmt.Errorf("scanning agency: %w", err)
+		}
+		a.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
+		agencies = append(agencies, a)
+	}
+
+	return agencies, nil
+}
+
+func (f *SQLiteFeedReader) FeedInfo() ([]model.FeedInfo, error) {
+	rows, err := f.db.Query(`
+SELECT publisher_name, publisher_url, lang, default_lang, start_date, end_date, version, contact_email, contact_url
+FROM feed_info`)
+	if err != nil {
+		return nil, fmt.Errorf("querying feed info: %w", err)
+	}
+	defer rows.Close()
+
+	feedInfo := []model.FeedInfo{}
+	for rows.Next() {
+		fi := model.FeedInfo{}
+		err := rows.Scan(
+			&fi.PublisherName,
+			&fi.PublisherURL,
+			&fi.Lang,
+			&fi.DefaultLang,
+			&fi.StartDate,
+			&fi.EndDate,
+			&fi.Version,
+			&fi.ContactEmail,
+			&fi.ContactURL,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning feed info: %w", err)
+		}
+		feedInfo = append(feedInfo, fi)
+	}
+
+	return feedInfo, nil
+}
+
+func (f *SQLiteFeedReader) Stops() ([]model.Stop, error) {
+	rows, err := f.db.Query(`
+SELECT id, code, name, desc, lat, lon, url, location_type, parent_station, platform_code, zone_id, level_id, timezone, wheelchair_boarding, extras
+FROM stops`)
+	if err != nil {
+		return nil, fmt.Errorf("querying stops: %w", err)
+	}
+	defer rows.Close()
+
+	stops := []model.Stop{}
+	for rows.Next() {
+		s := model.Stop{}
+		extras := ""
+		err := rows.Scan(
+			&s.ID,
+			&s.Code,
+			&s.Name,
+			&s.Desc,
+			&s.Lat,
+			&s.Lon,
+			&s.URL,
+			&s.LocationType,
+			&s.ParentStation,
+			&s.PlatformCode,
+			&s.ZoneID,
+			&s.LevelID,
+			&s.Timezone,
+			&s.WheelchairBoarding,
+			&extras,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning stop: %w", err)
+		}
+		s.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
+		stops = append(stops, s)
+	}
+
+	return stops, nil
+}
+
+func (f *SQLiteFeedReader) Stop(stopID string) (*model.Stop, error) {
+	row := f.db.QueryRow(`
+SELECT id, code, name, desc, lat, lon, url, location_type, parent_station, platform_code, zone_id, level_id, timezone, wheelchair_boarding, extras
+FROM stops
+WHERE id = ?`, stopID)
+
+	s := &model.Stop{}
+	extras := ""
+	err := row.Scan(
+		&s.ID,
+		&s.Code,
+		&s.Name,
+		&s.Desc,
+		&s.Lat,
+		&s.Lon,
+		&s.URL,
+		&s.LocationType,
+		&s.ParentStation,
+		&s.PlatformCode,
+		&s.ZoneID,
+		&s.LevelID,
+		&s.Timezone,
+		&s.WheelchairBoarding,
+		&extras,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning stop: %w", err)
+	}
+	s.Extras, err = decodeExtras(extras)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (f *SQLiteFeedReader) Routes() ([]model.Route, error) {
+	return f.routes(`
+SELECT id, agency_id, short_name, long_name, desc, type, url, color, text_color, continuous_pickup, continuous_drop_off, sort_order, network_id, extras
+FROM routes`)
+}
+
+func (f *SQLiteFeedReader) StopRoutes(stopID string) ([]model.Route, error) {
+	return f.routes(`
+SELECT id, agency_id, short_name, long_name, desc, type, url, color, text_color, continuous_pickup, continuous_drop_off, sort_order, network_id, extras
+FROM routes
+WHERE id IN (
+    SELECT DISTINCT trips.route_id
+    FROM stop_times
+    INNER JOIN trips ON trips.id = stop_times.trip_id
+    WHERE stop_times.stop_id IN (
+        SELECT id FROM stops WHERE id = ? OR parent_station = ?
+    )
+)
+ORDER BY id`, stopID, stopID)
+}
+
+func (f *SQLiteFeedReader) routes(query string, args ...interface{}) ([]model.Route, error) {
+	rows, err := f.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying routes: %w", err)
+	}
+	defer rows.Close()
+
+	routes := []model.Route{}
+	for rows.Next() {
+		r := model.Route{}
+		sortOrder := sql.NullInt64{}
+		extras := ""
+		err := rows.Scan(
+			&r.ID,
+			&r.AgencyID,
+			&r.ShortName,
+			&r.LongName,
+			&r.Desc,
+			&r.Type,
+			&r.URL,
+			&r.Color,
+			&r.TextColor,
+			&r.ContinuousPickup,
+			&r.ContinuousDropOff,
+			&sortOrder,
+			&r.NetworkID,
+			&extras,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning route: %w", err)
+		}
+		r.SortOrder = int(sortOrder.Int64)
+		r.HasSortOrder = sortOrder.Valid
+		r.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, r)
+	}
+
+	return routes, nil
+}
+
+func (f *SQLiteFeedReader) Trips() ([]model.Trip, error) {
+	rows, err := f.db.Query(`
+SELECT id, route_id, service_id, headsign, short_name, direction_id, shape_id, wheelchair_accessible, block_id, bikes_allowed, cars_allowed, extras
+FROM trips`)
+	if err != nil {
+		return nil, fmt.Errorf("querying trips: %w", err)
+	}
+	defer rows.Close()
+
+	trips := []model.Trip{}
+	for rows.Next() {
+		t := model.Trip{}
+		extras := ""
+		err := rows.Scan(
+			&t.ID,
+			&t.RouteID,
+			&t.ServiceID,
+			&t.Headsign,
+			&t.ShortName,
+			&t.DirectionID,
+			&t.ShapeID,
+			&t.WheelchairAccessible,
+			&t.BlockID,
+			&t.BikesAllowed,
+			&t.CarsAllowed,
+			&extras,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning trip: %w", err)
+		}
+		t.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, t)
+	}
+
+	return trips, nil
+}
+
+func (f *SQLiteFeedReader) StopTimes() ([]model.StopTime, error) {
+	rows, err := f.db.Query(`
+SELECT trip_id, stop_id, headsign, stop_sequence, arrival_time, departure_time, pickup_type, drop_off_type, approximate, shape_dist_traveled, continuous_pickup, continuous_drop_off
+FROM stop_times
+ORDER BY trip_id, stop_sequence`)
+	if err != nil {
+		return nil, fmt.Errorf("querying stop times: %w", err)
+	}
+	defer rows.Close()
+
+	stopTimes := []model.StopTime{}
+	for rows.Next() {
+		st := model.StopTime{}
+		shapeDist := sql.NullFloat64{}
+		continuousPickup := sql.NullInt64{}
+		continuousDropOff := sql.NullInt64{}
+		err := rows.Scan(
+			&st.TripID,
+			&st.StopID,
+			&st.Headsign,
+			&st.StopSequence,
+			&st.Arrival,
+			&st.Departure,
+			&st.PickupType,
+			&st.DropOffType,
+			&st.Approximate,
+			&shapeDist,
+			&continuousPickup,
+			&continuousDropOff,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning stop time: %w", err)
+		}
+		st.ShapeDistTraveled = shapeDist.Float64
+		st.HasShapeDistTraveled = shapeDist.Valid
+		st.ContinuousPickup = model.ContinuousStopping(continuousPickup.Int64)
+		st.HasContinuousPickup = continuousPickup.Valid
+		st.ContinuousDropOff = model.ContinuousStopping(continuousDropOff.Int64)
+		st.HasContinuousDropOff = continuousDropOff.Valid
+		stopTimes = append(stopTimes, st)
+	}
+
+	return stopTimes, nil
+}
+
+func (f *SQLiteFeedReader) Calendars() ([]model.Calendar, error) {
+	rows, err := f.db.Query(`
+SELECT service_id, start_date, end_date, monday, tuesday, wednesday, thursday, friday, saturday, sunday
+FROM calendar`)
+	if err != nil {
+		return nil, fmt.Errorf("querying calendar: %w", err)
+	}
+	defer rows.Close()
+
+	calendars := []model.Calendar{}
+	for rows.Next() {
+		var serviceID, startDate, endDate string
+		var monday, tuesday, wednesday, thursday, friday, saturday, sunday bool
+		err := rows.Scan(
+			&serviceID,
+			&startDate,
+			&endDate,
+			&monday,
+			&tuesday,
+			&wednesday,
+			&thursday,
+			&friday,
+			&saturday,
+			&sunday,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning calendar: %w", err)
+		}
+		weekday := int8(0)
+		if monday {
+			weekday |= 1 << time.Monday
+		}
+		if tuesday {
+			weekday |= 1 << time.Tuesday
+		}
+		if wednesday {
+			weekday |= 1 << time.Wednesday
+		}
+		if thursday {
+			weekday |= 1 << time.Thursday
+		}
+		if friday {
+			weekday |= 1 << time.Friday
+		}
+		if saturday {
+			weekday |= 1 << time.Saturday
+		}
+		if sunday {
+			weekday |= 1 << time.Sunday
+		}
+		calendars = append(calendars, model.Calendar{
+			ServiceID: serviceID,
+			StartDate: startDate,
+			EndDate:   endDate,
+			Weekday:   weekday,
+		})
+	}
+
+	return calendars, nil
+}
+
+func (f *SQLiteFeedReader) CalendarDates() ([]model.CalendarDate, error) {
+	rows, err := f.db.Query(`
+SELECT service_id, date, exception_type
+FROM calendar_dates`)
+	if err != nil {
+		return nil, fmt.Errorf("querying calendar dates: %w", err)
+	}
+	defer rows.Close()
+
+	calendarDates := []model.CalendarDate{}
+	for rows.Next() {
+		cd := model.CalendarDate{}
+		err := rows.Scan(
+			&cd.ServiceID,
+			&cd.Date,
+			&cd.ExceptionType,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning calendar date: %w", err)
+		}
+		calendarDates = append(calendarDates, cd)
+	}
+
+	return calendarDates, nil
+}
+
+func (f *SQLiteFeedReader) Shape(shapeID string) (*model.Shape, error) {
+	rows, err := f.db.Query(`
+SELECT lat, lon, sequence, dist_traveled
+FROM shapes
+WHERE shape_id = ?
+ORDER BY sequence ASC`, shapeID)
+	if err != nil {
+		return nil, fmt.Errorf("querying shape: %w", err)
+	}
+	defer rows.Close()
+
+	points := []model.ShapePoint{}
+	for rows.Next() {
+		p := model.ShapePoint{}
+		dist := sql.NullFloat64{}
+		err := rows.Scan(&p.Lat, &p.Lon, &p.Sequence, &dist)
+		if err != nil {
+			return nil, fmt.Errorf("scanning shape point: %w", err)
+		}
+		p.DistTraveled = dist.Float64
+		p.HasDistTraveled = dist.Valid
+		points = append(points, p)
+	}
+
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	return &model.Shape{
+		ID:     shapeID,
+		Points: points,
+	}, nil
+}
+
+func (f *SQLiteFeedReader) RouteShapeIDs(routeID string, directionID int) ([]string, error) {
+	query := `
+SELECT shape_id, COUNT(*) AS n
+FROM trips
+WHERE route_id = ? AND shape_id != ''`
+	params := []interface{}{routeID}
+	if directionID > -1 {
+		query += " AND direction_id = ?"
+		params = append(params, directionID)
+	}
+	query += " GROUP BY shape_id ORDER BY n DESC, shape_id ASC"
+
+	rows, err := f.db.Query(query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("querying route shapes: %w", err)
+	}
+	defer rows.Close()
+
+	shapeIDs := []string{}
+	for rows.Next() {
+		var shapeID string
+		var n int
+		err := rows.Scan(&shapeID, &n)
+		if err != nil {
+			return nil, fmt.Errorf("scanning route shape: %w", err)
+		}
+		shapeIDs = append(shapeIDs, shapeID)
+	}
+
+	return shapeIDs, nil
+}
+
+func (f *SQLiteFeedReader) NextTripInBlock(tripID string, serviceIDs []string) (*model.Trip, error) {
+	if len(serviceIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders := []string{}
+	params := []interface{}{tripID}
+	for _, serviceID := range serviceIDs {
+		placeholders = append(placeholders, "?")
+		params = append(params, serviceID)
+	}
+	params = append(params, tripID)
+
+	row := f.db.QueryRow(`
+SELECT trips.id, trips.route_id, trips.service_id, trips.headsign, trips.short_name, trips.direction_id, trips.shape_id, trips.wheelchair_accessible, trips.block_id, trips.bikes_allowed, trips.cars_allowed, trips.extras, MIN(stop_times.departure_time) AS start
+FROM trips
+INNER JOIN trips AS cur ON cur.block_id = trips.block_id
+INNER JOIN stop_times ON stop_times.trip_id = trips.id
+WHERE cur.id = ? AND
+      cur.block_id != '' AND
+      trips.id != cur.id AND
+      trips.service_id IN (`+strings.Join(placeholders, ", ")+`)
+GROUP BY trips.id
+HAVING start > (SELECT MIN(departure_time) FROM stop_times WHERE trip_id = ?)
+ORDER BY start ASC, trips.id ASC
+LIMIT 1`, params...)
+
+	t := model.Trip{}
+	var extras string
+	var start string
+	err := row.Scan(
+		&t.ID,
+		&t.RouteID,
+		&t.ServiceID,
+		&t.Headsign,
+		&t.ShortName,
+		&t.DirectionID,
+		&t.ShapeID,
+		&t.WheelchairAccessible,
+		&t.BlockID,
+		&t.BikesAllowed,
+		&t.CarsAllowed,
+		&extras,
+		&start,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying next trip in block: %w", err)
+	}
+	t.Extras, err = decodeExtras(extras)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (f *SQLiteFeedReader) Frequencies() ([]model.Frequency, error) {
+	rows, err := f.db.Query(`
+SELECT trip_id, start_time, end_time, headway_secs, exact_times
+FROM frequencies`)
+	if err != nil {
+		return nil, fmt.Errorf("querying frequencies: %w", err)
+	}
+	defer rows.Close()
+
+	frequencies := []model.Frequency{}
+	for rows.Next() {
+		freq := model.Frequency{}
+		err := rows.Scan(
+			&freq.TripID,
+			&freq.Start,
+			&freq.End,
+			&freq.HeadwaySecs,
+			&freq.ExactTimes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning frequency: %w", err)
+		}
+		frequencies = append(frequencies, freq)
+	}
+
+	return frequencies, nil
+}
+
+func (f *SQLiteFeedReader) Transfers(filter TransferFilter) ([]model.Transfer, error) {
+	whereClauses := []string{}
+	whereParams := []interface{}{}
+
+	if filter.FromStopID != "" {
+		whereClauses = append(whereClauses, `(
+    from_stop_id = ? OR
+    from_stop_id IN (SELECT parent_station FROM stops WHERE id = ? AND parent_station != '')
+)`)
+		whereParams = append(whereParams, filter.FromStopID, filter.FromStopID)
+	}
+	if filter.FromTripID != "" {
+		whereClauses = append(whereClauses, "from_trip_id = ?")
+		whereParams = append(whereParams, filter.FromTripID)
+	}
+
+	where := ""
+	if len(whereClauses) > 0 {
+		where = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	rows, err := f.db.Query(fmt.Sprintf(`
+SELECT from_stop_id, to_stop_id, from_route_id, to_route_id, from_trip_id, to_trip_id, transfer_type, min_transfer_time
+FROM transfers
+%s
+ORDER BY from_stop_id, to_stop_id, from_route_id, to_route_id, from_trip_id, to_trip_id`, where), whereParams...)
+	if err != nil {
+		return nil, fmt.Errorf("querying transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []model.Transfer{}
+	for rows.Next() {
+		transfer := model.Transfer{}
+		err := rows.Scan(
+			&transfer.FromStopID,
+			&transfer.ToStopID,
+			&transfer.FromRouteID,
+			&transfer.ToRouteID,
+			&transfer.FromTripID,
+			&transfer.ToTripID,
+			&transfer.Type,
+			&transfer.MinTransferTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning transfer: %w", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+func (f *SQLiteFeedReader) FareAttributes() ([]model.FareAttribute, error) {
+	rows, err := f.db.Query(`
+SELECT id, price, currency_type, payment_method, transfers, agency_id, transfer_duration
+FROM fare_attributes
+ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare attributes: %w", err)
+	}
+	defer rows.Close()
+
+	fares := []model.FareAttribute{}
+	for rows.Next() {
+		fare := model.FareAttribute{}
+		err := rows.Scan(
+			&fare.ID,
+			&fare.Price,
+			&fare.CurrencyType,
+			&fare.PaymentMethod,
+			&fare.Transfers,
+			&fare.AgencyID,
+			&fare.TransferDuration,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fare attribute: %w", err)
+		}
+		fares = append(fares, fare)
+	}
+
+	return fares, nil
+}
+
+func (f *SQLiteFeedReader) FareRules() ([]model.FareRule, error) {
+	rows, err := f.db.Query(`
+SELECT fare_id, route_id, origin_id, destination_id, contains_id
+FROM fare_rules
+ORDER BY fare_id, route_id, origin_id, destination_id, contains_id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []model.FareRule{}
+	for rows.Next() {
+		rule := model.FareRule{}
+		err := rows.Scan(
+			&rule.FareID,
+			&rule.RouteID,
+			&rule.OriginID,
+			&rule.DestinationID,
+			&rule.ContainsID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fare rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (f *SQLiteFeedReader) Networks() ([]model.Network, error) {
+	rows, err := f.db.Query(`
+SELECT id, name
+FROM networks
+ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying networks: %w", err)
+	}
+	defer rows.Close()
+
+	networks := []model.Network{}
+	for rows.Next() {
+		network := model.Network{}
+		err := rows.Scan(
+			&network.ID,
+			&network.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning network: %w", err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+func (f *SQLiteFeedReader) RouteNetworks() ([]model.RouteNetwork, error) {
+	rows, err := f.db.Query(`
+SELECT network_id, route_id
+FROM route_networks
+ORDER BY network_id, route_id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying route networks: %w", err)
+	}
+	defer rows.Close()
+
+	routeNetworks := []model.RouteNetwork{}
+	for rows.Next() {
+		routeNetwork := model.RouteNetwork{}
+		err := rows.Scan(
+			&routeNetwork.NetworkID,
+			&routeNetwork.RouteID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning route network: %w", err)
+		}
+		routeNetworks = append(routeNetworks, routeNetwork)
+	}
+
+	return routeNetworks, nil
+}
+
+func (f *SQLiteFeedReader) Areas() ([]model.Area, error) {
+	rows, err := f.db.Query(`
+SELECT id, name
+FROM areas
+ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying areas: %w", err)
+	}
+	defer rows.Close()
+
+	areas := []model.Area{}
+	for rows.Next() {
+		area := model.Area{}
+		err := rows.Scan(
+			&area.ID,
+			&area.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning area: %w", err)
+		}
+		areas = append(areas, area)
+	}
+
+	return areas, nil
+}
+
+func (f *SQLiteFeedReader) StopAreas() ([]model.StopArea, error) {
+	rows, err := f.db.Query(`
+SELECT area_id, stop_id
+FROM stop_areas
+ORDER BY area_id, stop_id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying stop areas: %w", err)
+	}
+	defer rows.Close()
+
+	stopAreas := []model.StopArea{}
+	for rows.Next() {
+		stopArea := model.StopArea{}
+		err := rows.Scan(
+			&stopArea.AreaID,
+			&stopArea.StopID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning stop area: %w", err)
+		}
+		stopAreas = append(stopAreas, stopArea)
+	}
+
+	return stopAreas, nil
+}
+
+func (f *SQLiteFeedReader) FareMedia() ([]model.FareMedia, error) {
+	rows, err := f.db.Query(`
+SELECT id, name, type
+FROM fare_media
+ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare media: %w", err)
+	}
+	defer rows.Close()
+
+	fareMedia := []model.FareMedia{}
+	for rows.Next() {
+		media := model.FareMedia{}
+		err := rows.Scan(
+			&media.ID,
+			&media.Name,
+			&media.Type,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fare media: %w", err)
+		}
+		fareMedia = append(fareMedia, media)
+	}
+
+	return fareMedia, nil
+}
+
+func (f *SQLiteFeedReader) FareProducts() ([]model.FareProduct, error) {
+	rows, err := f.db.Query(`
+SELECT id, name, fare_media_id, amount, currency
+FROM fare_products
+ORDER BY id, fare_media_id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []model.FareProduct{}
+	for rows.Next() {
+		product := model.FareProduct{}
+		err := rows.Scan(
+			&product.ID,
+			&product.Name,
+			&product.FareMediaID,
+			&product.Amount,
+			&product.Currency,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning stop: %w", err)
+			return nil, fmt.Errorf("scanning fare product: %w", err)
 		}
+		products = append(products, product)
+	}
 
-		if parentID.Valid {
-			allStops[parentID.String] = model.Stop{
-				ID:           parentID.String,
-				Code:         parentCode.String,
-				Name:         parentName.String,
-				Desc:         parentDesc.String,
-				Lat:          parentLat.Float64,
-				Lon:          parentLon.Float64,
-				URL:          parentURL.String,
-				LocationType: model.LocationType(parentLocationType.Int64),
-				PlatformCode: parentPlatformCode.String,
-			}
-		} else {
-			allStops[s.ID] = s
-		}
+	return products, nil
+}
+
+func (f *SQLiteFeedReader) FareLegRules() ([]model.FareLegRule, error) {
+	rows, err := f.db.Query(`
+SELECT leg_group_id, network_id, from_area_id, to_area_id, fare_product_id, rule_priority
+FROM fare_leg_rules
+ORDER BY leg_group_id, network_id, from_area_id, to_area_id, fare_product_id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare leg rules: %w", err)
 	}
+	defer rows.Close()
 
-	stops := []model.Stop{}
-	for _, s := range allStops {
-		stops = append(stops, s)
+	rules := []model.FareLegRule{}
+	for rows.Next() {
+		rule := model.FareLegRule{}
+		rulePriority := sql.NullInt64{}
+		err := rows.Scan(
+			&rule.LegGroupID,
+			&rule.NetworkID,
+			&rule.FromAreaID,
+			&rule.ToAreaID,
+			&rule.FareProductID,
+			&rulePriority,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning fare leg rule: %w", err)
+		}
+		rule.RulePriority = int(rulePriority.Int64)
+		rule.HasRulePriority = rulePriority.Valid
+		rules = append(rules, rule)
 	}
 
-	return stops, nil
+	return rules, nil
 }
 
-func (f *SQLiteFeedReader) NearbyStops(lat float64, lng float64, limit int, routeTypes []model.RouteType) ([]model.Stop, error) {
-	var stops []model.Stop
-	var err error
+func (f *SQLiteFeedReader) FareTransferRules() ([]model.FareTransferRule, error) {
+	rows, err := f.db.Query(`
+SELECT from_leg_group_id, to_leg_group_id, transfer_count, duration_limit, duration_limit_type, fare_transfer_type, fare_product_id
+FROM fare_transfer_rules
+ORDER BY from_leg_group_id, to_leg_group_id, fare_product_id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying fare transfer rules: %w", err)
+	}
+	defer rows.Close()
 
-	if len(routeTypes) == 0 {
-		stops, err = f.getStops()
-		if err != nil {
-			return nil, fmt.Errorf("getting all stops: %w", err)
-		}
-	} else {
-		// NOTE: With this query, only stops that have an
-		// actual trip of the correct route type passing
-		// through will be included in the result.
-		stops, err = f.getStopsByRouteType(routeTypes)
+	rules := []model.FareTransferRule{}
+	for rows.Next() {
+		rule := model.FareTransferRule{}
+		err := rows.Scan(
+			&rule.FromLegGroupID,
+			&rule.ToLegGroupID,
+			&rule.TransferCount,
+			&rule.DurationLimit,
+			&rule.DurationLimitType,
+			&rule.FareTransferType,
+			&rule.FareProductID,
+		)
 		if err != nil {
-			return nil, fmt.Errorf("getting stops by route type: %w", err)
+			return nil, fmt.Errorf("scanning fare transfer rule: %w", err)
 		}
+		rules = append(rules, rule)
 	}
 
-	sort.Slice(stops, func(i, j int) bool {
-		di := HaversineDistance(lat, lng, stops[i].Lat, stops[i].Lon)
-		dj := HaversineDistance(lat, lng, stops[j].Lat, stops[j].Lon)
-		return di < dj
-	})
+	return rules, nil
+}
 
-	if limit > 0 && len(stops) > limit {
-		stops = stops[:limit]
+func (f *SQLiteFeedReader) Levels() ([]model.Level, error) {
+	rows, err := f.db.Query(`
+SELECT id, level_index, name
+FROM levels
+ORDER BY level_index, id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying levels: %w", err)
 	}
+	defer rows.Close()
 
-	return stops, nil
+	levels := []model.Level{}
+	for rows.Next() {
+		level := model.Level{}
+		err := rows.Scan(
+			&level.ID,
+			&level.Index,
+			&level.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning level: %w", err)
+		}
+		levels = append(levels, level)
+	}
+
+	return levels, nil
 }
 
-func (f *SQLiteFeedReader) Agencies() ([]model.Agency, error) {
+func (f *SQLiteFeedReader) Pathways() ([]model.Pathway, error) {
 	rows, err := f.db.Query(`
-SELECT id, name, url, timezone
-FROM agency`)
+SELECT id, from_stop_id, to_stop_id, mode, is_bidirectional, length, traversal_time, stair_count, max_slope, min_width, signposted_as, reversed_signposted_as
+FROM pathways
+ORDER BY id`)
 	if err != nil {
-		return nil, fmt.Errorf("querying agencies: %w", err)
+		return nil, fmt.Errorf("querying pathways: %w", err)
 	}
 	defer rows.Close()
 
-	agencies := []model.Agency{}
+	pathways := []model.Pathway{}
 	for rows.Next() {
-		a := model.Agency{}
-		err := rows.Scan(&a.ID, &a.Name, &a.URL, &a.Timezone)
+		pathway := model.Pathway{}
+		err := rows.Scan(
+			&pathway.ID,
+			&pathway.FromStopID,
+			&pathway.ToStopID,
+			&pathway.Mode,
+			&pathway.IsBidirectional,
+			&pathway.Length,
+			&pathway.TraversalTime,
+			&pathway.StairCount,
+			&pathway.MaxSlope,
+			&pathway.MinWidth,
+			&pathway.SignpostedAs,
+			&pathway.ReversedSignpostedAs,
+		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning agency: %w", err)
+			return nil, fmt.Errorf("scanning pathway: %w", err)
 		}
-		agencies = append(agencies, a)
+		pathways = append(pathways, pathway)
 	}
 
-	return agencies, nil
+	return pathways, nil
 }
 
-func (f *SQLiteFeedReader) Stops() ([]model.Stop, error) {
-	rows, err := f.db.Query(`
-SELECT id, code, name, desc, lat, lon, url, location_type, parent_station, platform_code
-FROM stops`)
+func (f *SQLiteFeedReader) Translations(filter TranslationFilter) ([]model.Translation, error) {
+	whereClauses := []string{}
+	whereParams := []interface{}{}
+
+	if filter.TableName != "" {
+		whereClauses = append(whereClauses, "table_name = ?")
+		whereParams = append(whereParams, filter.TableName)
+	}
+	if filter.FieldName != "" {
+		whereClauses = append(whereClauses, "field_name = ?")
+		whereParams = append(whereParams, filter.FieldName)
+	}
+	if len(filter.Languages) > 0 {
+		whereClauses = append(whereClauses, fmt.Sprintf(
+			"language IN (%s)",
+			strings.Repeat("?, ", len(filter.Languages)-1)+"?",
+		))
+		for _, lang := range filter.Languages {
+			whereParams = append(whereParams, lang)
+		}
+	}
+
+	where := ""
+	if len(whereClauses) > 0 {
+		where = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	rows, err := f.db.Query(fmt.Sprintf(`
+SELECT table_name, field_name, language, translation, record_id, record_sub_id, field_value
+FROM translations
+%s
+ORDER BY table_name, field_name, language, record_id, record_sub_id, field_value`, where), whereParams...)
 	if err != nil {
-		return nil, fmt.Errorf("querying stops: %w", err)
+		return nil, fmt.Errorf("querying translations: %w", err)
 	}
 	defer rows.Close()
 
-	stops := []model.Stop{}
+	translations := []model.Translation{}
 	for rows.Next() {
-		s := model.Stop{}
+		translation := model.Translation{}
 		err := rows.Scan(
-			&s.ID,
-			&s.Code,
-			&s.Name,
-			&s.Desc,
-			&s.Lat,
-			&s.Lon,
-			&s.URL,
-			&s.LocationType,
-			&s.ParentStation,
-			&s.PlatformCode,
+			&translation.TableName,
+			&translation.FieldName,
+			&translation.Language,
+			&translation.Translation,
+			&translation.RecordID,
+			&translation.RecordSubID,
+			&translation.FieldValue,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning stop: %w", err)
+			return nil, fmt.Errorf("scanning translation: %w", err)
 		}
-		stops = append(stops, s)
+		translations = append(translations, translation)
 	}
 
-	return stops, nil
+	return translations, nil
 }
 
-func (f *SQLiteFeedReader) Routes() ([]model.Route, error) {
+func (f *SQLiteFeedReader) Locations() ([]model.Location, error) {
 	rows, err := f.db.Query(`
-SELECT id, agency_id, short_name, long_name, desc, type, url, color, text_color
-FROM routes`)
+SELECT id, name, description, geometry
+FROM locations
+ORDER BY id`)
 	if err != nil {
-		return nil, fmt.Errorf("querying routes: %w", err)
+		return nil, fmt.Errorf("querying location: %w", err)
 	}
 	defer rows.Close()
 
-	routes := []model.Route{}
+	locations := []model.Location{}
 	for rows.Next() {
-		r := model.Route{}
+		location := model.Location{}
+		var geometry string
 		err := rows.Scan(
-			&r.ID,
-			&r.AgencyID,
-			&r.ShortName,
-			&r.LongName,
-			&r.Desc,
-			&r.Type,
-			&r.URL,
-			&r.Color,
-			&r.TextColor,
+			&location.ID,
+			&location.Name,
+			&location.Desc,
+			&geometry,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning route: %w", err)
+			return nil, fmt.Errorf("scanning location: %w", err)
 		}
-		routes = append(routes, r)
+		err = json.Unmarshal([]byte(geometry), &location.Polygons)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshaling location geometry: %w", err)
+		}
+		locations = append(locations, location)
 	}
 
-	return routes, nil
+	return locations, nil
 }
 
-func (f *SQLiteFeedReader) Trips() ([]model.Trip, error) {
+func (f *SQLiteFeedReader) LocationGroups() ([]model.LocationGroup, error) {
 	rows, err := f.db.Query(`
-SELECT id, route_id, service_id, headsign, short_name, direction_id
-FROM trips`)
+SELECT id, name
+FROM location_groups
+ORDER BY id`)
 	if err != nil {
-		return nil, fmt.Errorf("querying trips: %w", err)
+		return nil, fmt.Errorf("querying location group: %w", err)
 	}
 	defer rows.Close()
 
-	trips := []model.Trip{}
+	groups := []model.LocationGroup{}
 	for rows.Next() {
-		t := model.Trip{}
+		group := model.LocationGroup{}
 		err := rows.Scan(
-			&t.ID,
-			&t.RouteID,
-			&t.ServiceID,
-			&t.Headsign,
-			&t.ShortName,
-			&t.DirectionID,
+			&group.ID,
+			&group.Name,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning trip: %w", err)
+			return nil, fmt.Errorf("scanning location group: %w", err)
 		}
-		trips = append(trips, t)
+		groups = append(groups, group)
 	}
 
-	return trips, nil
+	return groups, nil
 }
 
-func (f *SQLiteFeedReader) StopTimes() ([]model.StopTime, error) {
+func (f *SQLiteFeedReader) LocationGroupStops() ([]model.LocationGroupStop, error) {
 	rows, err := f.db.Query(`
-SELECT trip_id, stop_id, headsign, stop_sequence, arrival_time, departure_time
-FROM stop_times`)
+SELECT location_group_id, stop_id
+FROM location_group_stops
+ORDER BY location_group_id, stop_id`)
 	if err != nil {
-		return nil, fmt.Errorf("querying stop times: %w", err)
+		return nil, fmt.Errorf("querying location group stop: %w", err)
 	}
 	defer rows.Close()
 
-	stopTimes := []model.StopTime{}
+	lgss := []model.LocationGroupStop{}
 	for rows.Next() {
-		st := model.StopTime{}
+		lgs := model.LocationGroupStop{}
 		err := rows.Scan(
-			&st.TripID,
-			&st.StopID,
-			&st.Headsign,
-			&st.StopSequence,
-			&st.Arrival,
-			&st.Departure,
+			&lgs.LocationGroupID,
+			&lgs.StopID,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning stop time: %w", err)
+			return nil, fmt.Errorf("scanning location group stop: %w", err)
 		}
-		stopTimes = append(stopTimes, st)
+		lgss = append(lgss, lgs)
 	}
 
-	return stopTimes, nil
+	return lgss, nil
 }
 
-func (f *SQLiteFeedReader) Calendars() ([]model.Calendar, error) {
+func (f *SQLiteFeedReader) BookingRules() ([]model.BookingRule, error) {
 	rows, err := f.db.Query(`
-SELECT service_id, start_date, end_date, monday, tuesday, wednesday, thursday, friday, saturday, sunday
-FROM calendar`)
+SELECT id, booking_type, prior_notice_duration_min, prior_notice_duration_max, prior_notice_last_day, prior_notice_last_time, prior_notice_start_day, prior_notice_start_time, prior_notice_service_id, message, pickup_message, drop_off_message, phone_number, info_url, booking_url
+FROM booking_rules
+ORDER BY id`)
 	if err != nil {
-		return nil, fmt.Errorf("querying calendar: %w", err)
+		return nil, fmt.Errorf("querying booking rule: %w", err)
 	}
 	defer rows.Close()
 
-	calendars := []model.Calendar{}
+	rules := []model.BookingRule{}
 	for rows.Next() {
-		var serviceID, startDate, endDate string
-		var monday, tuesday, wednesday, thursday, friday, saturday, sunday bool
+		rule := model.BookingRule{}
 		err := rows.Scan(
-			&serviceID,
-			&startDate,
-			&endDate,
-			&monday,
-			&tuesday,
-			&wednesday,
-			&thursday,
-			&friday,
-			&saturday,
-			&sunday,
+			&rule.ID,
+			&rule.Type,
+			&rule.PriorNoticeDurationMin,
+			&rule.PriorNoticeDurationMax,
+			&rule.PriorNoticeLastDay,
+			&rule.PriorNoticeLastTime,
+			&rule.PriorNoticeStartDay,
+			&rule.PriorNoticeStartTime,
+			&rule.PriorNoticeServiceID,
+			&rule.Message,
+			&rule.PickupMessage,
+			&rule.DropOffMessage,
+			&rule.PhoneNumber,
+			&rule.InfoURL,
+			&rule.BookingURL,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning calendar: %w", err)
-		}
-		weekday := int8(0)
-		if monday {
-			weekday |= 1 << time.Monday
-		}
-		if tuesday {
-			weekday |= 1 << time.Tuesday
-		}
-		if wednesday {
-			weekday |= 1 << time.Wednesday
-		}
-		if thursday {
-			weekday |= 1 << time.Thursday
+			return nil, fmt.Errorf("scanning booking rule: %w", err)
 		}
-		if friday {
-			weekday |= 1 << time.Friday
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func (f *SQLiteFeedReader) FlexStopTimes(filter FlexStopTimeFilter) ([]*FlexStopTimeEvent, error) {
+	baseQuery := `
+SELECT
+    flex_stop_times.trip_id,
+    flex_stop_times.stop_sequence,
+    flex_stop_times.stop_id,
+    flex_stop_times.location_group_id,
+    flex_stop_times.location_id,
+    flex_stop_times.start_window,
+    flex_stop_times.end_window,
+    flex_stop_times.pickup_type,
+    flex_stop_times.drop_off_type,
+    flex_stop_times.pickup_booking_rule_id,
+    flex_stop_times.drop_off_booking_rule_id,
+    trips.id,
+    trips.route_id,
+    trips.service_id,
+    trips.headsign,
+    trips.short_name,
+    trips.direction_id,
+    trips.shape_id,
+    trips.wheelchair_accessible,
+    trips.block_id,
+    trips.bikes_allowed,
+    trips.cars_allowed,
+    trips.extras,
+    routes.id,
+    routes.agency_id,
+    routes.short_name,
+    routes.long_name,
+    routes.desc,
+    routes.type,
+    routes.url,
+    routes.color,
+    routes.text_color,
+    routes.continuous_pickup,
+    routes.continuous_drop_off,
+    routes.sort_order,
+    routes.network_id,
+    routes.extras
+FROM flex_stop_times
+INNER JOIN trips ON flex_stop_times.trip_id = trips.id
+INNER JOIN routes ON trips.route_id = routes.id
+`
+
+	// Apply filters to query
+	fParams, fVals := []string{}, []interface{}{}
+
+	// Locations, location groups and stops are alternatives
+	locParams := []string{}
+	for _, ids := range []struct {
+		column string
+		values []string
+	}{
+		{"flex_stop_times.location_id", filter.LocationIDs},
+		{"flex_stop_times.location_group_id", filter.LocationGroupIDs},
+		{"flex_stop_times.stop_id", filter.StopIDs},
+	} {
+		if len(ids.values) == 0 {
+			continue
 		}
-		if saturday {
-			weekday |= 1 << time.Saturday
+		placeholders := []string{}
+		for _, id := range ids.values {
+			placeholders = append(placeholders, "?")
+			fVals = append(fVals, id)
 		}
-		if sunday {
-			weekday |= 1 << time.Sunday
+		locParams = append(locParams, ids.column+" IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if len(locParams) > 0 {
+		fParams = append(fParams, "("+strings.Join(locParams, " OR ")+")")
+	}
+
+	if len(filter.ServiceIDs) > 0 {
+		placeholders := []string{}
+		for _, id := range filter.ServiceIDs {
+			placeholders = append(placeholders, "?")
+			fVals = append(fVals, id)
 		}
-		calendars = append(calendars, model.Calendar{
-			ServiceID: serviceID,
-			StartDate: startDate,
-			EndDate:   endDate,
-			Weekday:   weekday,
-		})
+		fParams = append(fParams, "trips.service_id IN ("+strings.Join(placeholders, ", ")+")")
 	}
 
-	return calendars, nil
-}
+	query := baseQuery
+	if len(fParams) > 0 {
+		query += " WHERE " + strings.Join(fParams, " AND ")
+	}
+	query += " ORDER BY flex_stop_times.trip_id, flex_stop_times.stop_sequence"
 
-func (f *SQLiteFeedReader) CalendarDates() ([]model.CalendarDate, error) {
-	rows, err := f.db.Query(`
-SELECT service_id, date, exception_type
-FROM calendar_dates`)
+	rows, err := f.db.Query(query, fVals...)
 	if err != nil {
-		return nil, fmt.Errorf("querying calendar dates: %w", err)
+		return nil, fmt.Errorf("querying flex stop_times: %w", err)
 	}
 	defer rows.Close()
 
-	calendarDates := []model.CalendarDate{}
+	events := []*FlexStopTimeEvent{}
 	for rows.Next() {
-		cd := model.CalendarDate{}
+		event := &FlexStopTimeEvent{}
+		sortOrder := sql.NullInt64{}
+		tripExtras, routeExtras := "", ""
 		err := rows.Scan(
-			&cd.ServiceID,
-			&cd.Date,
-			&cd.ExceptionType,
+			&event.StopTime.TripID,
+			&event.StopTime.StopSequence,
+			&event.StopTime.StopID,
+			&event.StopTime.LocationGroupID,
+			&event.StopTime.LocationID,
+			&event.StopTime.StartWindow,
+			&event.StopTime.EndWindow,
+			&event.StopTime.PickupType,
+			&event.StopTime.DropOffType,
+			&event.StopTime.PickupBookingRuleID,
+			&event.StopTime.DropOffBookingRuleID,
+			&event.Trip.ID,
+			&event.Trip.RouteID,
+			&event.Trip.ServiceID,
+			&event.Trip.Headsign,
+			&event.Trip.ShortName,
+			&event.Trip.DirectionID,
+			&event.Trip.ShapeID,
+			&event.Trip.WheelchairAccessible,
+			&event.Trip.BlockID,
+			&event.Trip.BikesAllowed,
+			&event.Trip.CarsAllowed,
+			&tripExtras,
+			&event.Route.ID,
+			&event.Route.AgencyID,
+			&event.Route.ShortName,
+			&event.Route.LongName,
+			&event.Route.Desc,
+			&event.Route.Type,
+			&event.Route.URL,
+			&event.Route.Color,
+			&event.Route.TextColor,
+			&event.Route.ContinuousPickup,
+			&event.Route.ContinuousDropOff,
+			&sortOrder,
+			&event.Route.NetworkID,
+			&routeExtras,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scanning calendar date: %w", err)
+			return nil, fmt.Errorf("scanning flex stop_time: %w", err)
 		}
-		calendarDates = append(calendarDates, cd)
+		event.Route.SortOrder = int(sortOrder.Int64)
+		event.Route.HasSortOrder = sortOrder.Valid
+		event.Trip.Extras, err = decodeExtras(tripExtras)
+		if err != nil {
+			return nil, err
+		}
+		event.Route.Extras, err = decodeExtras(routeExtras)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
 	}
 
-	return calendarDates, nil
+	return events, nil
 }
 
 func (f *SQLiteFeedReader) MinMaxStopSeq() (map[string][2]uint32, error) {
@@ -1167,18 +3201,35 @@ SELECT
     stops.location_type,
     stops.parent_station,
     stops.platform_code,
+    stops.zone_id,
+    stops.level_id,
+    stops.timezone,
+    stops.wheelchair_boarding,
+    stops.extras,
     stop_times.trip_id,
     stop_times.stop_id,
     stop_times.stop_sequence,
     stop_times.arrival_time,
     stop_times.departure_time,
     stop_times.headsign,
+    stop_times.pickup_type,
+    stop_times.drop_off_type,
+    stop_times.approximate,
+    stop_times.shape_dist_traveled,
+    stop_times.continuous_pickup,
+    stop_times.continuous_drop_off,
     trips.id,
     trips.route_id,
     trips.service_id,
     trips.headsign,
     trips.short_name,
     trips.direction_id,
+    trips.shape_id,
+    trips.wheelchair_accessible,
+    trips.block_id,
+    trips.bikes_allowed,
+    trips.cars_allowed,
+    trips.extras,
     routes.id,
     routes.agency_id,
     routes.short_name,
@@ -1187,7 +3238,12 @@ SELECT
     routes.type,
     routes.url,
     routes.color,
-    routes.text_color
+    routes.text_color,
+    routes.continuous_pickup,
+    routes.continuous_drop_off,
+    routes.sort_order,
+    routes.network_id,
+    routes.extras
 FROM stop_times
 INNER JOIN stops ON stop_times.stop_id = stops.id
 INNER JOIN trips ON stop_times.trip_id = trips.id
@@ -1251,12 +3307,11 @@ INNER JOIN routes ON trips.route_id = routes.id
 	}
 
 	if len(filter.RouteTypes) > 0 {
-		placeholders := []string{}
-		for _, rt := range filter.RouteTypes {
-			placeholders = append(placeholders, "?")
+		cond, values := routeTypeCondition(filter.RouteTypes, func(int) string { return "?" })
+		for _, rt := range values {
 			fVals = append(fVals, fmt.Sprintf("%d", rt))
 		}
-		fParams = append(fParams, "routes.type IN ("+strings.Join(placeholders, ", ")+")")
+		fParams = append(fParams, cond)
 	}
 
 	// Run query
@@ -1284,6 +3339,11 @@ INNER JOIN routes ON trips.route_id = routes.id
 		stopTime := model.StopTime{}
 		trip := model.Trip{}
 		route := model.Route{}
+		shapeDist := sql.NullFloat64{}
+		continuousPickup := sql.NullInt64{}
+		continuousDropOff := sql.NullInt64{}
+		sortOrder := sql.NullInt64{}
+		stopExtras, tripExtras, routeExtras := "", "", ""
 
 		err = rows.Scan(
 			&stop.ID,
@@ -1296,18 +3356,35 @@ INNER JOIN routes ON trips.route_id = routes.id
 			&stop.LocationType,
 			&stop.ParentStation,
 			&stop.PlatformCode,
+			&stop.ZoneID,
+			&stop.LevelID,
+			&stop.Timezone,
+			&stop.WheelchairBoarding,
+			&stopExtras,
 			&stopTime.TripID,
 			&stopTime.StopID,
 			&stopTime.StopSequence,
 			&stopTime.Arrival,
 			&stopTime.Departure,
 			&stopTime.Headsign,
+			&stopTime.PickupType,
+			&stopTime.DropOffType,
+			&stopTime.Approximate,
+			&shapeDist,
+			&continuousPickup,
+			&continuousDropOff,
 			&trip.ID,
 			&trip.RouteID,
 			&trip.ServiceID,
 			&trip.Headsign,
 			&trip.ShortName,
 			&trip.DirectionID,
+			&trip.ShapeID,
+			&trip.WheelchairAccessible,
+			&trip.BlockID,
+			&trip.BikesAllowed,
+			&trip.CarsAllowed,
+			&tripExtras,
 			&route.ID,
 			&route.AgencyID,
 			&route.ShortName,
@@ -1317,10 +3394,35 @@ INNER JOIN routes ON trips.route_id = routes.id
 			&route.URL,
 			&route.Color,
 			&route.TextColor,
+			&route.ContinuousPickup,
+			&route.ContinuousDropOff,
+			&sortOrder,
+			&route.NetworkID,
+			&routeExtras,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning stop time event: %w", err)
 		}
+		stopTime.ShapeDistTraveled = shapeDist.Float64
+		stopTime.HasShapeDistTraveled = shapeDist.Valid
+		stopTime.ContinuousPickup = model.ContinuousStopping(continuousPickup.Int64)
+		stopTime.HasContinuousPickup = continuousPickup.Valid
+		stopTime.ContinuousDropOff = model.ContinuousStopping(continuousDropOff.Int64)
+		stopTime.HasContinuousDropOff = continuousDropOff.Valid
+		route.SortOrder = int(sortOrder.Int64)
+		route.HasSortOrder = sortOrder.Valid
+		stop.Extras, err = decodeExtras(stopExtras)
+		if err != nil {
+			return nil, err
+		}
+		trip.Extras, err = decodeExtras(tripExtras)
+		if err != nil {
+			return nil, err
+		}
+		route.Extras, err = decodeExtras(routeExtras)
+		if err != nil {
+			return nil, err
+		}
 
 		events = append(events, &StopTimeEvent{
 			Stop:     stop,
@@ -1358,7 +3460,7 @@ INNER JOIN routes ON trips.route_id = routes.id
 	}
 
 	rows, err = f.db.Query(`
-SELECT id, code, name, desc, lat, lon, url, location_type, platform_code
+SELECT id, code, name, desc, lat, lon, url, location_type, platform_code, zone_id, level_id, timezone, wheelchair_boarding, extras
 FROM stops
 WHERE id IN (`+strings.Join(placeholders, ", ")+`)
 `, parentIDs...)
@@ -1369,6 +3471,7 @@ WHERE id IN (`+strings.Join(placeholders, ", ")+`)
 
 	for rows.Next() {
 		stop := model.Stop{}
+		extras := ""
 		err = rows.Scan(
 			&stop.ID,
 			&stop.Code,
@@ -1379,10 +3482,19 @@ WHERE id IN (`+strings.Join(placeholders, ", ")+`)
 			&stop.URL,
 			&stop.LocationType,
 			&stop.PlatformCode,
+			&stop.ZoneID,
+			&stop.LevelID,
+			&stop.Timezone,
+			&stop.WheelchairBoarding,
+			&extras,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning parent station: %w", err)
 		}
+		stop.Extras, err = decodeExtras(extras)
+		if err != nil {
+			return nil, err
+		}
 
 		parents[stop.ID] = stop
 	}
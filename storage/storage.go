@@ -20,9 +20,10 @@ type Storage interface {
 	ListFeedRequests(url string) ([]FeedRequest, error)
 
 	// Writes a FeedRequest record. If a record with the same URL
-	// exists, it is updated. All consumers included in the
-	// request will be created/updated. Missing consumers will
-	// _not_ be removed.
+	// exists, its RefreshedAt, Lenient, Encoding, Extras and
+	// LastFailure are updated, unless RefreshedAt is zero. All consumers
+	// included in the request will be created/updated. Missing
+	// consumers will _not_ be removed.
 	WriteFeedRequest(req FeedRequest) error
 
 	// Gets a reader for the feed with the given hash.
@@ -47,6 +48,37 @@ type FeedRequest struct {
 	URL         string
 	RefreshedAt time.Time
 	Consumers   []FeedConsumer
+
+	// If set, the feed is parsed leniently, dropping bad rows
+	// rather than rejecting the feed.
+	Lenient bool
+
+	// Character encoding of the feed's files, e.g. "windows-1252",
+	// as understood by parse.LookupEncoding(). If blank, the
+	// encoding is detected per file.
+	Encoding string
+
+	// If set, values in columns not defined by the spec are
+	// captured in the Extras of agencies, stops, routes and
+	// trips.
+	Extras bool
+
+	// The most recent failure to parse the feed, or nil if the
+	// last refresh succeeded.
+	LastFailure *FeedFailure
+}
+
+// A failure to parse a static feed. Code, File, Row, Column and Value
+// are taken from parse.ParseError, and are blank if the failure
+// wasn't one.
+type FeedFailure struct {
+	FailedAt time.Time `json:"failed_at"`
+	Code     string    `json:"code"`
+	File     string    `json:"file,omitempty"`
+	Row      int       `json:"row,omitempty"`
+	Column   string    `json:"column,omitempty"`
+	Value    string    `json:"value,omitempty"`
+	Message  string    `json:"message"`
 }
 
 type FeedConsumer struct {
@@ -67,36 +99,134 @@ type FeedMetadata struct {
 	CalendarEndDate   string
 	MaxArrival        string
 	MaxDeparture      string
+
+	// From feed_info.txt, if present in the feed. Dates are
+	// given as YYYYMMDD, and are blank if not provided.
+	FeedStartDate     string
+	FeedEndDate       string
+	FeedVersion       string
+	FeedPublisherName string
+	FeedPublisherURL  string
+	FeedLang          string
+
+	// Problems found when the feed was parsed, each of which
+	// caused rows to be dropped. Bad rows are dropped from
+	// translations.txt, and when parsing leniently. Only the first
+	// parse.MaxDroppedDetails are described in Dropped.
+	DroppedCount int
+	Dropped      []string
 }
 
 // Writes GTFS records for a single feed.
 //
 // As stop_times.txt tends to be very large, BeginStopTimes() and
 // EndStopTimes() are called before and after all calls to
-// WriteStopTime(), allowing transactions/batching/whathaveyou.
+// WriteStopTime(), allowing transactions/batching/whathaveyou. The
+// same goes for trips and shapes. GTFS-Flex stop_times are also
+// written between BeginStopTimes() and EndStopTimes().
 type FeedWriter interface {
 	WriteAgency(agency model.Agency) error
+	WriteFeedInfo(info model.FeedInfo) error
 	WriteStop(stop model.Stop) error
 	WriteRoute(route model.Route) error
 	WriteTrip(trip model.Trip) error
 	BeginTrips() error
 	EndTrips() error
+	WriteShapePoint(shapeID string, point model.ShapePoint) error
+	BeginShapes() error
+	EndShapes() error
 	WriteCalendar(cal model.Calendar) error
 	WriteCalendarDate(caldate model.CalendarDate) error
 	WriteStopTime(stopTime model.StopTime) error
 	BeginStopTimes() error
 	EndStopTimes() error
+	WriteFrequency(freq model.Frequency) error
+	WriteTransfer(transfer model.Transfer) error
+	WriteFareAttribute(fare model.FareAttribute) error
+	WriteFareRule(rule model.FareRule) error
+	WriteNetwork(network model.Network) error
+	WriteRouteNetwork(routeNetwork model.RouteNetwork) error
+	WriteArea(area model.Area) error
+	WriteStopArea(stopArea model.StopArea) error
+	WriteFareMedia(media model.FareMedia) error
+	WriteFareProduct(product model.FareProduct) error
+	WriteFareLegRule(rule model.FareLegRule) error
+	WriteFareTransferRule(rule model.FareTransferRule) error
+	WriteLevel(level model.Level) error
+	WritePathway(pathway model.Pathway) error
+	WriteTranslation(translation model.Translation) error
+	WriteLocation(location model.Location) error
+	WriteLocationGroup(group model.LocationGroup) error
+	WriteLocationGroupStop(lgs model.LocationGroupStop) error
+	WriteBookingRule(rule model.BookingRule) error
+	WriteFlexStopTime(stopTime model.FlexStopTime) error
 	Close() error
 }
 
 type FeedReader interface {
 	Agencies() ([]model.Agency, error)
+	FeedInfo() ([]model.FeedInfo, error)
 	Stops() ([]model.Stop, error)
 	Routes() ([]model.Route, error)
 	Trips() ([]model.Trip, error)
 	StopTimes() ([]model.StopTime, error)
 	Calendars() ([]model.Calendar, error)
 	CalendarDates() ([]model.CalendarDate, error)
+	Frequencies() ([]model.Frequency, error)
+
+	FareAttributes() ([]model.FareAttribute, error)
+	FareRules() ([]model.FareRule, error)
+
+	// Fares v2
+	Networks() ([]model.Network, error)
+	RouteNetworks() ([]model.RouteNetwork, error)
+	Areas() ([]model.Area, error)
+	StopAreas() ([]model.StopArea, error)
+	FareMedia() ([]model.FareMedia, error)
+	FareProducts() ([]model.FareProduct, error)
+	FareLegRules() ([]model.FareLegRule, error)
+	FareTransferRules() ([]model.FareTransferRule, error)
+
+	Levels() ([]model.Level, error)
+	Pathways() ([]model.Pathway, error)
+
+	// GTFS-Flex
+	Locations() ([]model.Location, error)
+	LocationGroups() ([]model.LocationGroup, error)
+	LocationGroupStops() ([]model.LocationGroupStop, error)
+	BookingRules() ([]model.BookingRule, error)
+
+	// List of GTFS-Flex stop_times and associated trips matching
+	// the provided filter.
+	FlexStopTimes(filter FlexStopTimeFilter) ([]*FlexStopTimeEvent, error)
+
+	// List of translations matching the provided filter.
+	Translations(filter TranslationFilter) ([]model.Translation, error)
+
+	// Retrieves a stop. Returns nil if the stop doesn't exist.
+	Stop(stopID string) (*model.Stop, error)
+
+	// List of transfers matching the provided filter.
+	Transfers(filter TransferFilter) ([]model.Transfer, error)
+
+	// Retrieves a shape, with points ordered by sequence. Returns
+	// nil if the shape doesn't exist.
+	Shape(shapeID string) (*model.Shape, error)
+
+	// Distinct shape IDs used by trips on a route, ordered by
+	// number of trips using the shape (descending). Pass -1 as
+	// directionID to include all directions.
+	RouteShapeIDs(routeID string, directionID int) ([]string, error)
+
+	// The trip following tripID in its block, among trips of the
+	// given services. Trips are ordered by their first departure
+	// time. Returns nil if tripID has no block_id or is the last
+	// trip in its block.
+	NextTripInBlock(tripID string, serviceIDs []string) (*model.Trip, error)
+
+	// Routes with trips stopping at a stop, or at any stop within
+	// it if it's a station. Ordered by route_id.
+	StopRoutes(stopID string) ([]model.Route, error)
 
 	// Services IDs for all services active on the given
 	// date. Date is given as YYYYMMDD.
@@ -131,6 +261,46 @@ type FeedReader interface {
 	NearbyStops(lat float64, lng float64, limit int, routeTypes []model.RouteType) ([]model.Stop, error)
 }
 
+// Filter for Transfers()
+type TransferFilter struct {
+	// Limit results to transfers from the given stop ID. This
+	// includes transfers from the stop's parent station, if any.
+	FromStopID string
+
+	// Limit results to transfers from the given trip ID.
+	FromTripID string
+}
+
+// Filter for Translations()
+type TranslationFilter struct {
+	// Limit results to translations of a field in a table,
+	// e.g. stop_name in stops.
+	TableName string
+	FieldName string
+
+	// Limit results to a set of languages.
+	Languages []string
+}
+
+// Filter for FlexStopTimes()
+type FlexStopTimeFilter struct {
+	// Limit results to stop_times at any of the given locations,
+	// location groups or stops.
+	LocationIDs      []string
+	LocationGroupIDs []string
+	StopIDs          []string
+
+	// Limit results to a set of services.
+	ServiceIDs []string
+}
+
+// A GTFS-Flex stop_time, with its trip and route.
+type FlexStopTimeEvent struct {
+	StopTime model.FlexStopTime
+	Trip     model.Trip
+	Route    model.Route
+}
+
 // Filter for StopTimeEvents()
 type StopTimeEventFilter struct {
 	// Limit results to events for the given stop ID. This can
@@ -139,7 +309,9 @@ type StopTimeEventFilter struct {
 	StopID string
 
 	// Limit results to a set of services, a specific route,
-	// a set of route types and/or a set of trips.
+	// a set of route types and/or a set of trips. Basic route
+	// types also match the extended route types falling under
+	// them, e.g. RouteTypeBus matches 700 and 701.
 	ServiceIDs []string
 	RouteID    string
 	RouteTypes []model.RouteType
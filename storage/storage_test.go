@@ -2,6 +2,7 @@ package storage_test
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -32,6 +33,23 @@ func readerFromFiles(t *testing.T, sb StorageBuilder, files map[string][]string)
 	routes := map[string]bool{}
 	trips := map[string]bool{}
 	stops := map[string]bool{}
+	shapes := map[string]bool{}
+	zones := map[string]bool{}
+	levels := map[string]bool{}
+	fares := map[string]bool{}
+	locations := map[string]bool{}
+	locationGroups := map[string]bool{}
+	bookingRules := map[string]bool{}
+
+	if files["shapes.txt"] != nil {
+		require.NoError(t, writer.BeginShapes())
+		shapes, err = parse.ParseShapes(
+			writer,
+			bytes.NewBufferString(strings.Join(files["shapes.txt"], "\n")),
+		)
+		require.NoError(t, err)
+		require.NoError(t, writer.EndShapes())
+	}
 
 	if files["calendar.txt"] != nil {
 		services, _, _, err = parse.ParseCalendar(
@@ -65,14 +83,57 @@ func readerFromFiles(t *testing.T, sb StorageBuilder, files map[string][]string)
 			bytes.NewBufferString(strings.Join(files["trips.txt"], "\n")),
 			routes,
 			services,
+			shapes,
 		)
 		require.NoError(t, err)
 		require.NoError(t, writer.EndTrips())
 	}
+	if files["levels.txt"] != nil {
+		levels, err = parse.ParseLevels(
+			writer,
+			bytes.NewBufferString(strings.Join(files["levels.txt"], "\n")),
+		)
+		require.NoError(t, err)
+	}
 	if files["stops.txt"] != nil {
-		stops, err = parse.ParseStops(
+		stops, zones, err = parse.ParseStops(
 			writer,
 			bytes.NewBufferString(strings.Join(files["stops.txt"], "\n")),
+			levels,
+		)
+		require.NoError(t, err)
+	}
+	if files["locations.geojson"] != nil {
+		locations, err = parse.ParseLocations(
+			writer,
+			bytes.NewBufferString(strings.Join(files["locations.geojson"], "\n")),
+			stops,
+		)
+		require.NoError(t, err)
+	}
+	if files["location_groups.txt"] != nil {
+		locationGroups, err = parse.ParseLocationGroups(
+			writer,
+			bytes.NewBufferString(strings.Join(files["location_groups.txt"], "\n")),
+			stops,
+			locations,
+		)
+		require.NoError(t, err)
+	}
+	if files["location_group_stops.txt"] != nil {
+		err = parse.ParseLocationGroupStops(
+			writer,
+			bytes.NewBufferString(strings.Join(files["location_group_stops.txt"], "\n")),
+			locationGroups,
+			stops,
+		)
+		require.NoError(t, err)
+	}
+	if files["booking_rules.txt"] != nil {
+		bookingRules, err = parse.ParseBookingRules(
+			writer,
+			bytes.NewBufferString(strings.Join(files["booking_rules.txt"], "\n")),
+			services,
 		)
 		require.NoError(t, err)
 	}
@@ -83,10 +144,60 @@ func readerFromFiles(t *testing.T, sb StorageBuilder, files map[string][]string)
 			bytes.NewBufferString(strings.Join(files["stop_times.txt"], "\n")),
 			trips,
 			stops,
+			locations,
+			locationGroups,
+			bookingRules,
 		)
 		require.NoError(t, err)
 		require.NoError(t, writer.EndStopTimes())
 	}
+	if files["fare_attributes.txt"] != nil {
+		fares, err = parse.ParseFareAttributes(
+			writer,
+			bytes.NewBufferString(strings.Join(files["fare_attributes.txt"], "\n")),
+			map[string]bool{"": true},
+		)
+		require.NoError(t, err)
+	}
+	if files["fare_rules.txt"] != nil {
+		err := parse.ParseFareRules(
+			writer,
+			bytes.NewBufferString(strings.Join(files["fare_rules.txt"], "\n")),
+			fares,
+			routes,
+			zones,
+		)
+		require.NoError(t, err)
+	}
+	if files["pathways.txt"] != nil {
+		err := parse.ParsePathways(
+			writer,
+			bytes.NewBufferString(strings.Join(files["pathways.txt"], "\n")),
+			stops,
+		)
+		require.NoError(t, err)
+	}
+	if files["transfers.txt"] != nil {
+		err := parse.ParseTransfers(
+			writer,
+			bytes.NewBufferString(strings.Join(files["transfers.txt"], "\n")),
+			stops,
+			routes,
+			trips,
+		)
+		require.NoError(t, err)
+	}
+	if files["translations.txt"] != nil {
+		err := parse.ParseTranslations(
+			writer,
+			bytes.NewBufferString(strings.Join(files["translations.txt"], "\n")),
+			map[string]bool{},
+			stops,
+			routes,
+			trips,
+		)
+		require.NoError(t, err)
+	}
 
 	require.NoError(t, writer.Close())
 
@@ -122,6 +233,58 @@ func testInitiallyEmpty(t *testing.T, sb StorageBuilder) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, len(agencies))
 
+	feedInfo, err := reader.FeedInfo()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(feedInfo))
+
+	frequencies, err := reader.Frequencies()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(frequencies))
+
+	transfers, err := reader.Transfers(storage.TransferFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(transfers))
+
+	fares, err := reader.FareAttributes()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(fares))
+
+	fareRules, err := reader.FareRules()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(fareRules))
+
+	stop, err := reader.Stop("nope")
+	require.NoError(t, err)
+	assert.Nil(t, stop)
+
+	fareProducts, err := reader.FareProducts()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(fareProducts))
+
+	fareLegRules, err := reader.FareLegRules()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(fareLegRules))
+
+	stopAreas, err := reader.StopAreas()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(stopAreas))
+
+	routeNetworks, err := reader.RouteNetworks()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(routeNetworks))
+
+	levels, err := reader.Levels()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(levels))
+
+	pathways, err := reader.Pathways()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(pathways))
+
+	translations, err := reader.Translations(storage.TranslationFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(translations))
+
 	stops, err := reader.Stops()
 	require.NoError(t, err)
 	assert.Equal(t, 0, len(stops))
@@ -167,6 +330,10 @@ func testBasicReadingAndWriting(t *testing.T, sb StorageBuilder) {
 		Name:     "Agency 2",
 		URL:      "http://example.com/agency_2",
 		Timezone: "America/New_York",
+		Lang:     "en",
+		Phone:    "555-0100",
+		FareURL:  "http://example.com/agency_2/fares",
+		Email:    "agency_2@example.com",
 	})
 	require.NoError(t, err)
 
@@ -320,6 +487,10 @@ func testBasicReadingAndWriting(t *testing.T, sb StorageBuilder) {
 			Name:     "Agency 2",
 			URL:      "http://example.com/agency_2",
 			Timezone: "America/New_York",
+			Lang:     "en",
+			Phone:    "555-0100",
+			FareURL:  "http://example.com/agency_2/fares",
+			Email:    "agency_2@example.com",
 		},
 	}, agencies)
 
@@ -1155,17 +1326,17 @@ func testStopTimeEvent_AllTheFields(t *testing.T, sb StorageBuilder) {
 			"139,code139,Station 139,Station no 139,47.13,19.94,http://stops/139,1,,pcode139",
 		},
 		"routes.txt": {
-			"route_id,route_short_name,route_long_name,route_desc,route_type,route_url,route_color,route_text_color",
-			"r,R,The R,Route R,3,http://routes/r,FF0000,0000FF",
+			"route_id,route_short_name,route_long_name,route_desc,route_type,route_url,route_color,route_text_color,continuous_pickup,continuous_drop_off",
+			"r,R,The R,Route R,3,http://routes/r,FF0000,0000FF,0,3",
 		},
 		"trips.txt": {
 			"trip_id,route_id,service_id,direction_id,trip_headsign,trip_short_name",
 			"1,r,weekday,1,Headsign 1,trip1",
 		},
 		"stop_times.txt": {
-			"trip_id,stop_id,stop_sequence,arrival_time,departure_time,stop_headsign",
-			"1,137,1,12:34:56,23:45:31,stop headsign 1",
-			"1,138,2,12:34:57,23:45:32,stop headsign 2",
+			"trip_id,stop_id,stop_sequence,arrival_time,departure_time,stop_headsign,pickup_type,drop_off_type,timepoint,shape_dist_traveled,continuous_pickup,continuous_drop_off",
+			"1,137,1,12:34:56,23:45:31,stop headsign 1,2,1,1,,2,",
+			"1,138,2,12:34:57,23:45:32,stop headsign 2,1,3,0,1.5,,1",
 		},
 	})
 
@@ -1182,6 +1353,11 @@ func testStopTimeEvent_AllTheFields(t *testing.T, sb StorageBuilder) {
 		Arrival:      "123456",
 		Departure:    "234531",
 		Headsign:     "stop headsign 1",
+		PickupType:   model.PickupDropOffPhoneAgency,
+		DropOffType:  model.PickupDropOffNone,
+
+		ContinuousPickup:    model.ContinuousStoppingPhoneAgency,
+		HasContinuousPickup: true,
 	}, events[0].StopTime)
 	assert.Equal(t, model.Trip{
 		ID:          "1",
@@ -1200,6 +1376,9 @@ func testStopTimeEvent_AllTheFields(t *testing.T, sb StorageBuilder) {
 		URL:       "http://routes/r",
 		Color:     "FF0000",
 		TextColor: "0000FF",
+
+		ContinuousPickup:  model.ContinuousStoppingRegular,
+		ContinuousDropOff: model.ContinuousStoppingCoordinateWithDriver,
 	}, events[0].Route)
 	assert.Equal(t, model.Stop{
 		ID:            "137",
@@ -1221,6 +1400,15 @@ func testStopTimeEvent_AllTheFields(t *testing.T, sb StorageBuilder) {
 		Arrival:      "123457",
 		Departure:    "234532",
 		Headsign:     "stop headsign 2",
+		PickupType:   model.PickupDropOffNone,
+		DropOffType:  model.PickupDropOffCoordinateWithDriver,
+
+		ShapeDistTraveled:    1.5,
+		HasShapeDistTraveled: true,
+		Approximate:          true,
+
+		ContinuousDropOff:    model.ContinuousStoppingNone,
+		HasContinuousDropOff: true,
 	}, events[1].StopTime)
 	assert.Equal(t, events[0].Trip, events[1].Trip)
 	assert.Equal(t, events[0].Route, events[1].Route)
@@ -1249,6 +1437,13 @@ func testStopTimeEvent_AllTheFields(t *testing.T, sb StorageBuilder) {
 		PlatformCode:  "pcode139",
 	}, events[1].ParentStation)
 
+	stopTimes, err := reader.StopTimes()
+	require.NoError(t, err)
+	assert.Equal(t, []model.StopTime{events[0].StopTime, events[1].StopTime}, stopTimes)
+
+	routes, err := reader.Routes()
+	require.NoError(t, err)
+	assert.Equal(t, []model.Route{events[0].Route}, routes)
 }
 
 func testStopTimeEvent_ParentStations(t *testing.T, sb StorageBuilder) {
@@ -1525,6 +1720,90 @@ func testRouteDirections(t *testing.T, sb StorageBuilder) {
 	assert.Equal(t, []model.RouteDirection{}, rds)
 }
 
+func testShapes(t *testing.T, sb StorageBuilder) {
+	reader := readerFromFiles(t, sb, map[string][]string{
+		"calendar.txt": {"service_id,start_date,end_date", "nodays,20200101,20201231"},
+		"routes.txt":   {"route_id,route_short_name,route_type", "R,R,3", "S,S,3"},
+		"shapes.txt": {
+			"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence,shape_dist_traveled",
+			"long,1,1,1,0",
+			"long,2,2,2,1.5",
+			"long,3,3,3,3",
+			"short,2,2,1,",
+			"short,1,1,0,",
+			"back,3,3,1,",
+			"back,1,1,2,",
+		},
+		"trips.txt": {
+			"service_id,trip_id,route_id,direction_id,shape_id",
+			"nodays,t1,R,0,long",
+			"nodays,t2,R,0,short",
+			"nodays,t3,R,0,long",
+			"nodays,t4,R,1,back",
+			"nodays,t5,R,1,",
+			"nodays,t6,S,0,",
+		},
+	})
+
+	shape, err := reader.Shape("long")
+	require.NoError(t, err)
+	assert.Equal(t, &model.Shape{
+		ID: "long",
+		Points: []model.ShapePoint{
+			{Lat: 1, Lon: 1, Sequence: 1, DistTraveled: 0, HasDistTraveled: true},
+			{Lat: 2, Lon: 2, Sequence: 2, DistTraveled: 1.5, HasDistTraveled: true},
+			{Lat: 3, Lon: 3, Sequence: 3, DistTraveled: 3, HasDistTraveled: true},
+		},
+	}, shape)
+
+	shape, err = reader.Shape("short")
+	require.NoError(t, err)
+	assert.Equal(t, &model.Shape{
+		ID: "short",
+		Points: []model.ShapePoint{
+			{Lat: 1, Lon: 1, Sequence: 0},
+			{Lat: 2, Lon: 2, Sequence: 1},
+		},
+	}, shape)
+
+	shape, err = reader.Shape("nonexistent")
+	require.NoError(t, err)
+	assert.Nil(t, shape)
+
+	// Shape IDs per route and direction, most used first
+	shapeIDs, err := reader.RouteShapeIDs("R", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"long", "short"}, shapeIDs)
+
+	shapeIDs, err = reader.RouteShapeIDs("R", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"back"}, shapeIDs)
+
+	shapeIDs, err = reader.RouteShapeIDs("R", -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"long", "back", "short"}, shapeIDs)
+
+	shapeIDs, err = reader.RouteShapeIDs("S", -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, shapeIDs)
+
+	// Trips reference their shapes
+	trips, err := reader.Trips()
+	require.NoError(t, err)
+	shapeByTrip := map[string]string{}
+	for _, trip := range trips {
+		shapeByTrip[trip.ID] = trip.ShapeID
+	}
+	assert.Equal(t, map[string]string{
+		"t1": "long",
+		"t2": "short",
+		"t3": "long",
+		"t4": "back",
+		"t5": "",
+		"t6": "",
+	}, shapeByTrip)
+}
+
 func testNearbyStops(t *testing.T, sb StorageBuilder) {
 	reader := readerFromFiles(t, sb, map[string][]string{
 		"stops.txt": {
@@ -1701,6 +1980,14 @@ func testFeedMetadataReadWrite(t *testing.T, sb StorageBuilder) {
 		CalendarEndDate:   "20191130",
 		MaxArrival:        "123457",
 		MaxDeparture:      "754321",
+		FeedStartDate:     "20190203",
+		FeedEndDate:       "20191129",
+		FeedVersion:       "v2",
+		FeedPublisherName: "Publisher",
+		FeedPublisherURL:  "https://publisher",
+		FeedLang:          "en",
+		DroppedCount:      3,
+		Dropped:           []string{"trips.txt: bad", "stop_times.txt: worse"},
 	})
 	assert.NoError(t, err)
 
@@ -1715,6 +2002,14 @@ func testFeedMetadataReadWrite(t *testing.T, sb StorageBuilder) {
 	assert.Equal(t, "20191130", feeds[0].CalendarEndDate)
 	assert.Equal(t, "123457", feeds[0].MaxArrival)
 	assert.Equal(t, "754321", feeds[0].MaxDeparture)
+	assert.Equal(t, "20190203", feeds[0].FeedStartDate)
+	assert.Equal(t, "20191129", feeds[0].FeedEndDate)
+	assert.Equal(t, "v2", feeds[0].FeedVersion)
+	assert.Equal(t, "Publisher", feeds[0].FeedPublisherName)
+	assert.Equal(t, "https://publisher", feeds[0].FeedPublisherURL)
+	assert.Equal(t, "en", feeds[0].FeedLang)
+	assert.Equal(t, 3, feeds[0].DroppedCount)
+	assert.Equal(t, []string{"trips.txt: bad", "stop_times.txt: worse"}, feeds[0].Dropped)
 	assert.Equal(t, "feed1", feeds[1].Hash)
 	assert.Equal(t, "https://gtfs/feed1", feeds[1].URL)
 	assert.True(t, time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC).Equal(feeds[1].RetrievedAt))
@@ -1722,6 +2017,10 @@ func testFeedMetadataReadWrite(t *testing.T, sb StorageBuilder) {
 	assert.Equal(t, "20191131", feeds[1].CalendarEndDate)
 	assert.Equal(t, "123456", feeds[1].MaxArrival)
 	assert.Equal(t, "654321", feeds[1].MaxDeparture)
+	assert.Equal(t, "", feeds[1].FeedStartDate)
+	assert.Equal(t, "", feeds[1].FeedVersion)
+	assert.Equal(t, 0, feeds[1].DroppedCount)
+	assert.Equal(t, 0, len(feeds[1].Dropped))
 
 	// Overwrite one of the feeds
 	err = s.WriteFeedMetadata(&storage.FeedMetadata{
@@ -2128,6 +2427,72 @@ func testFeedRequest(t *testing.T, sb StorageBuilder) {
 	assert.Equal(t, "luigi-headers", requests[0].Consumers[0].Headers)
 	assert.Equal(t, time.Date(2019, 1, 3, 0, 0, 0, 0, time.UTC), requests[0].Consumers[0].CreatedAt)
 	assert.Equal(t, time.Date(2019, 1, 4, 0, 0, 0, 0, time.UTC), requests[0].Consumers[0].UpdatedAt)
+	assert.False(t, requests[0].Lenient)
+	assert.Equal(t, "", requests[0].Encoding)
+	assert.False(t, requests[0].Extras)
+
+	// Lenient, Encoding and Extras are only updated along with
+	// refreshed_at
+	assert.NoError(t, s.WriteFeedRequest(storage.FeedRequest{
+		URL:      "https://microsoft.com",
+		Lenient:  true,
+		Encoding: "windows-1252",
+		Extras:   true,
+	}))
+	requests, err = s.ListFeedRequests("https://microsoft.com")
+	assert.NoError(t, err)
+	assert.False(t, requests[0].Lenient)
+	assert.Equal(t, "", requests[0].Encoding)
+	assert.False(t, requests[0].Extras)
+	assert.NoError(t, s.WriteFeedRequest(storage.FeedRequest{
+		URL:         "https://microsoft.com",
+		RefreshedAt: time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC),
+		Lenient:     true,
+		Encoding:    "windows-1252",
+		Extras:      true,
+	}))
+	requests, err = s.ListFeedRequests("https://microsoft.com")
+	assert.NoError(t, err)
+	assert.True(t, requests[0].Lenient)
+	assert.Equal(t, "windows-1252", requests[0].Encoding)
+	assert.True(t, requests[0].Extras)
+	assert.Equal(t, 1, len(requests[0].Consumers))
+	assert.Nil(t, requests[0].LastFailure)
+
+	// LastFailure is also only updated along with refreshed_at
+	failure := &storage.FeedFailure{
+		FailedAt: time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC),
+		Code:     "unknown_reference",
+		File:     "trips.txt",
+		Row:      3,
+		Column:   "route_id",
+		Value:    "r",
+		Message:  "trips.txt: unknown route_id 'r' (row 3)",
+	}
+	assert.NoError(t, s.WriteFeedRequest(storage.FeedRequest{
+		URL:         "https://microsoft.com",
+		LastFailure: failure,
+	}))
+	requests, err = s.ListFeedRequests("https://microsoft.com")
+	assert.NoError(t, err)
+	assert.Nil(t, requests[0].LastFailure)
+	assert.NoError(t, s.WriteFeedRequest(storage.FeedRequest{
+		URL:         "https://microsoft.com",
+		RefreshedAt: time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC),
+		LastFailure: failure,
+	}))
+	requests, err = s.ListFeedRequests("https://microsoft.com")
+	assert.NoError(t, err)
+	assert.Equal(t, failure, requests[0].LastFailure)
+
+	// And cleared when refreshed without one
+	assert.NoError(t, s.WriteFeedRequest(storage.FeedRequest{
+		URL:         "https://microsoft.com",
+		RefreshedAt: time.Date(2020, 1, 7, 0, 0, 0, 0, time.UTC),
+	}))
+	requests, err = s.ListFeedRequests("https://microsoft.com")
+	assert.NoError(t, err)
+	assert.Nil(t, requests[0].LastFailure)
 }
 
 // Verifies that (all) storage queries are partitioned by feed hash
@@ -2572,6 +2937,766 @@ func testMultipleFeedsInStorage(t *testing.T, sb StorageBuilder) {
 	}, events2[0])
 }
 
+func testTransfers(t *testing.T, sb StorageBuilder) {
+	reader := readerFromFiles(t, sb, map[string][]string{
+		"calendar.txt": {"service_id,start_date,end_date", "nodays,20200101,20201231"},
+		"routes.txt":   {"route_id,route_short_name,route_type", "R,R,3", "S,S,3"},
+		"trips.txt": {
+			"service_id,trip_id,route_id",
+			"nodays,t1,R",
+			"nodays,t2,R",
+			"nodays,t3,S",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station",
+			"station,Station,1,1,1,",
+			"p1,P1,1,1,0,station",
+			"p2,P2,1,1,0,station",
+			"other,Other,2,2,0,",
+		},
+		"transfers.txt": {
+			"from_stop_id,to_stop_id,from_route_id,to_route_id,from_trip_id,to_trip_id,transfer_type,min_transfer_time",
+			"station,other,,,,,2,300",
+			"p1,p2,R,S,,,1,",
+			"p2,p1,,,,,3,",
+			",,,,t1,t2,4,",
+			"p1,p1,,,t1,t3,5,",
+		},
+	})
+
+	all, err := reader.Transfers(storage.TransferFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, len(all))
+
+	// Transfers from the parent station apply to its stops
+	transfers, err := reader.Transfers(storage.TransferFilter{FromStopID: "p1"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Transfer{
+		{FromStopID: "p1", ToStopID: "p1", FromTripID: "t1", ToTripID: "t3", Type: model.TransferTypeInSeatNotAllowed},
+		{FromStopID: "p1", ToStopID: "p2", FromRouteID: "R", ToRouteID: "S", Type: model.TransferTypeTimed},
+		{FromStopID: "station", ToStopID: "other", Type: model.TransferTypeMinTime, MinTransferTime: 300},
+	}, transfers)
+
+	transfers, err = reader.Transfers(storage.TransferFilter{FromStopID: "station"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Transfer{
+		{FromStopID: "station", ToStopID: "other", Type: model.TransferTypeMinTime, MinTransferTime: 300},
+	}, transfers)
+
+	transfers, err = reader.Transfers(storage.TransferFilter{FromStopID: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Transfer{}, transfers)
+
+	// By trip, including in-seat transfers without stops
+	transfers, err = reader.Transfers(storage.TransferFilter{FromTripID: "t1"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Transfer{
+		{FromTripID: "t1", ToTripID: "t2", Type: model.TransferTypeInSeat},
+		{FromStopID: "p1", ToStopID: "p1", FromTripID: "t1", ToTripID: "t3", Type: model.TransferTypeInSeatNotAllowed},
+	}, transfers)
+
+	// Both
+	transfers, err = reader.Transfers(storage.TransferFilter{FromStopID: "p2", FromTripID: "t1"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Transfer{}, transfers)
+}
+
+func testPathways(t *testing.T, sb StorageBuilder) {
+	reader := readerFromFiles(t, sb, map[string][]string{
+		"calendar.txt": {"service_id,start_date,end_date", "nodays,20200101,20201231"},
+		"levels.txt": {
+			"level_id,level_index,level_name",
+			"street,0,Street",
+			"platform,-1,Platform",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station,level_id",
+			"station,Station,1,1,1,,",
+			"entrance,Entrance,1,1,2,station,street",
+			"p1,P1,1,1,0,station,platform",
+		},
+		"pathways.txt": {
+			"pathway_id,from_stop_id,to_stop_id,pathway_mode,is_bidirectional,traversal_time,signposted_as",
+			"down,entrance,p1,2,1,45,Trains",
+			"up,p1,entrance,4,0,30,Exit",
+		},
+	})
+
+	levels, err := reader.Levels()
+	require.NoError(t, err)
+	assert.Equal(t, []model.Level{
+		{ID: "platform", Index: -1, Name: "Platform"},
+		{ID: "street", Index: 0, Name: "Street"},
+	}, levels)
+
+	pathways, err := reader.Pathways()
+	require.NoError(t, err)
+	assert.Equal(t, []model.Pathway{
+		{
+			ID:              "down",
+			FromStopID:      "entrance",
+			ToStopID:        "p1",
+			Mode:            model.PathwayModeStairs,
+			IsBidirectional: true,
+			TraversalTime:   45,
+			SignpostedAs:    "Trains",
+		},
+		{
+			ID:            "up",
+			FromStopID:    "p1",
+			ToStopID:      "entrance",
+			Mode:          model.PathwayModeEscalator,
+			TraversalTime: 30,
+			SignpostedAs:  "Exit",
+		},
+	}, pathways)
+
+	stop, err := reader.Stop("p1")
+	require.NoError(t, err)
+	assert.Equal(t, "platform", stop.LevelID)
+}
+
+func testTranslations(t *testing.T, sb StorageBuilder) {
+	reader := readerFromFiles(t, sb, map[string][]string{
+		"calendar.txt": {"service_id,start_date,end_date", "nodays,20200101,20201231"},
+		"routes.txt":   {"route_id,route_short_name,route_type", "R,R,3"},
+		"trips.txt":    {"service_id,trip_id,route_id,trip_headsign", "nodays,t1,R,North"},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"s1,Main Street,1,1",
+			"s2,Station,2,2",
+		},
+		"translations.txt": {
+			"table_name,field_name,language,translation,record_id,record_sub_id,field_value",
+			"stops,stop_name,fr,Rue Principale,s1,,",
+			"stops,stop_name,de,Hauptstraße,s1,,",
+			"stops,stop_name,fr,Gare,,,Station",
+			"trips,trip_headsign,fr,Nord,t1,,",
+			"stop_times,stop_headsign,fr,Nord,t1,01,",
+		},
+	})
+
+	all, err := reader.Translations(storage.TranslationFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, len(all))
+
+	translations, err := reader.Translations(storage.TranslationFilter{
+		TableName: "stops",
+		FieldName: "stop_name",
+		Languages: []string{"fr", "es"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Translation{
+		{TableName: "stops", FieldName: "stop_name", Language: "fr", Translation: "Gare", FieldValue: "Station"},
+		{TableName: "stops", FieldName: "stop_name", Language: "fr", Translation: "Rue Principale", RecordID: "s1"},
+	}, translations)
+
+	// record_sub_id is normalized
+	translations, err = reader.Translations(storage.TranslationFilter{TableName: "stop_times"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Translation{
+		{TableName: "stop_times", FieldName: "stop_headsign", Language: "fr", Translation: "Nord", RecordID: "t1", RecordSubID: "1"},
+	}, translations)
+
+	translations, err = reader.Translations(storage.TranslationFilter{Languages: []string{"es"}})
+	require.NoError(t, err)
+	assert.Equal(t, []model.Translation{}, translations)
+}
+
+func testWheelchairAccessibility(t *testing.T, sb StorageBuilder) {
+	reader := readerFromFiles(t, sb, map[string][]string{
+		"calendar.txt": {"service_id,start_date,end_date", "nodays,20200101,20201231"},
+		"routes.txt":   {"route_id,route_short_name,route_type", "R,R,3"},
+		"trips.txt": {
+			"service_id,trip_id,route_id,wheelchair_accessible",
+			"nodays,t1,R,1",
+			"nodays,t2,R,2",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station,wheelchair_boarding",
+			"station,Station,1,1,1,,1",
+			"p1,P1,1,1,0,station,",
+			"p2,P2,1,1,0,station,2",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+			"t1,p1,1,10:00:00,10:00:00",
+			"t1,p2,2,10:10:00,10:10:00",
+			"t2,p2,1,11:00:00,11:00:00",
+		},
+	})
+
+	stop, err := reader.Stop("station")
+	require.NoError(t, err)
+	assert.Equal(t, model.WheelchairAccessible, stop.WheelchairBoarding)
+	stop, err = reader.Stop("p2")
+	require.NoError(t, err)
+	assert.Equal(t, model.WheelchairInaccessible, stop.WheelchairBoarding)
+
+	trips, err := reader.Trips()
+	require.NoError(t, err)
+	sort.Slice(trips, func(i, j int) bool { return trips[i].ID < trips[j].ID })
+	assert.Equal(t, model.WheelchairAccessible, trips[0].WheelchairAccessible)
+	assert.Equal(t, model.WheelchairInaccessible, trips[1].WheelchairAccessible)
+
+	// Events carry the stop's, the parent station's and the
+	// trip's accessibility
+	events, err := reader.StopTimeEvents(storage.StopTimeEventFilter{
+		StopID:      "p1",
+		DirectionID: -1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, model.WheelchairNoInfo, events[0].Stop.WheelchairBoarding)
+	assert.Equal(t, model.WheelchairAccessible, events[0].ParentStation.WheelchairBoarding)
+	assert.Equal(t, model.WheelchairAccessible, events[0].Trip.WheelchairAccessible)
+}
+
+func testNextTripInBlock(t *testing.T, sb StorageBuilder) {
+	reader := readerFromFiles(t, sb, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date",
+			"weekday,20200101,20201231",
+			"weekend,20200101,20201231",
+		},
+		"routes.txt": {"route_id,route_short_name,route_type", "R1,R1,3", "R2,R2,3"},
+		"trips.txt": {
+			"service_id,trip_id,route_id,block_id,trip_headsign",
+			"weekday,t1,R1,b1,One",
+			"weekday,t2,R2,b1,Two",
+			"weekday,t3,R1,b1,Three",
+			"weekend,t4,R2,b1,Four",
+			"weekday,t5,R1,,Five",
+			"weekday,t6,R1,b2,Six",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"s1,S1,1,1",
+			"s2,S2,2,2",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+			"t1,s1,1,10:00:00,10:00:00",
+			"t1,s2,2,10:30:00,10:30:00",
+			"t3,s2,1,12:00:00,12:00:00",
+			"t3,s1,2,12:30:00,12:30:00",
+			"t2,s2,1,10:40:00,10:40:00",
+			"t2,s1,2,11:10:00,11:10:00",
+			"t4,s2,1,10:35:00,10:35:00",
+			"t4,s1,2,11:05:00,11:05:00",
+			"t5,s1,1,09:00:00,09:00:00",
+			"t5,s2,2,09:30:00,09:30:00",
+			"t6,s1,1,09:00:00,09:00:00",
+			"t6,s2,2,09:30:00,09:30:00",
+		},
+	})
+
+	next, err := reader.NextTripInBlock("t1", []string{"weekday"})
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, model.Trip{
+		ID:        "t2",
+		RouteID:   "R2",
+		ServiceID: "weekday",
+		Headsign:  "Two",
+		BlockID:   "b1",
+	}, *next)
+
+	next, err = reader.NextTripInBlock("t2", []string{"weekday"})
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "t3", next.ID)
+
+	// Only trips of the given services are considered
+	next, err = reader.NextTripInBlock("t1", []string{"weekday", "weekend"})
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "t4", next.ID)
+
+	// Last trip in block
+	next, err = reader.NextTripInBlock("t3", []string{"weekday"})
+	require.NoError(t, err)
+	assert.Nil(t, next)
+
+	// Trips without a block, or alone in their block
+	next, err = reader.NextTripInBlock("t5", []string{"weekday"})
+	require.NoError(t, err)
+	assert.Nil(t, next)
+	next, err = reader.NextTripInBlock("t6", []string{"weekday"})
+	require.NoError(t, err)
+	assert.Nil(t, next)
+
+	// Unknown trip
+	next, err = reader.NextTripInBlock("x", []string{"weekday"})
+	require.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func testStopRoutes(t *testing.T, sb StorageBuilder) {
+	reader := readerFromFiles(t, sb, map[string][]string{
+		"calendar.txt": {"service_id,start_date,end_date", "s,20200101,20201231"},
+		"routes.txt": {
+			"route_id,route_short_name,route_type,route_sort_order",
+			"r1,R1,3,2",
+			"r2,R2,3,",
+			"r3,R3,3,1",
+		},
+		"trips.txt": {
+			"service_id,trip_id,route_id",
+			"s,t1,r1",
+			"s,t2,r2",
+			"s,t3,r3",
+			"s,t4,r1",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon,location_type,parent_station",
+			"station,Station,1,1,1,",
+			"p1,P1,1,1,0,station",
+			"p2,P2,1,1,0,station",
+			"x,X,2,2,0,",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+			"t1,p1,1,10:00:00,10:00:00",
+			"t1,x,2,10:10:00,10:10:00",
+			"t2,p2,1,11:00:00,11:00:00",
+			"t2,x,2,11:10:00,11:10:00",
+			"t3,x,1,12:00:00,12:00:00",
+			"t4,p1,1,13:00:00,13:00:00",
+		},
+	})
+
+	routeIDs := func(routes []model.Route) []string {
+		ids := []string{}
+		for _, r := range routes {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	routes, err := reader.StopRoutes("p1")
+	require.NoError(t, err)
+	assert.Equal(t, []model.Route{{
+		ID:           "r1",
+		ShortName:    "R1",
+		Type:         model.RouteTypeBus,
+		Color:        "FFFFFF",
+		TextColor:    "000000",
+		SortOrder:    2,
+		HasSortOrder: true,
+	}}, routes)
+
+	routes, err = reader.StopRoutes("station")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2"}, routeIDs(routes))
+
+	routes, err = reader.StopRoutes("x")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2", "r3"}, routeIDs(routes))
+	assert.False(t, routes[1].HasSortOrder)
+
+	routes, err = reader.StopRoutes("unknown")
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(routes))
+}
+
+func testFlexStopTimes(t *testing.T, sb StorageBuilder) {
+	reader := readerFromFiles(t, sb, map[string][]string{
+		"calendar.txt": {"service_id,start_date,end_date", "s1,20200101,20201231", "s2,20200101,20201231"},
+		"routes.txt":   {"route_id,route_short_name,route_type", "r,R,3"},
+		"trips.txt": {
+			"service_id,trip_id,route_id",
+			"s1,t1,r",
+			"s2,t2,r",
+			"s2,t3,r",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"a,A,1,1",
+			"b,B,2,2",
+		},
+		"locations.geojson": {`{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "id": "zone", "properties": {"stop_name": "Zone"},
+			 "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}}]}`},
+		"location_groups.txt": {
+			"location_group_id,location_group_name",
+			"group,Group",
+		},
+		"location_group_stops.txt": {
+			"location_group_id,stop_id",
+			"group,a",
+			"group,b",
+		},
+		"booking_rules.txt": {
+			"booking_rule_id,booking_type,prior_notice_duration_min,phone_number",
+			"call,1,60,555-1234",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,location_id,location_group_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window,pickup_type,drop_off_type,pickup_booking_rule_id",
+			"t1,,zone,,1,08:00:00,12:00:00,2,2,call",
+			"t1,,zone,,2,08:00:00,12:00:00,1,2,",
+			"t2,,,group,1,13:00:00,17:00:00,2,2,call",
+			"t2,,,group,2,13:00:00,17:00:00,1,2,",
+			"t3,b,,,1,18:00:00,20:00:00,2,1,call",
+		},
+	})
+
+	locations, err := reader.Locations()
+	require.NoError(t, err)
+	assert.Equal(t, []model.Location{{
+		ID:       "zone",
+		Name:     "Zone",
+		Polygons: [][][][2]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}},
+	}}, locations)
+
+	groups, err := reader.LocationGroups()
+	require.NoError(t, err)
+	assert.Equal(t, []model.LocationGroup{{ID: "group", Name: "Group"}}, groups)
+
+	groupStops, err := reader.LocationGroupStops()
+	require.NoError(t, err)
+	assert.Equal(t, []model.LocationGroupStop{
+		{LocationGroupID: "group", StopID: "a"},
+		{LocationGroupID: "group", StopID: "b"},
+	}, groupStops)
+
+	rules, err := reader.BookingRules()
+	require.NoError(t, err)
+	assert.Equal(t, []model.BookingRule{{
+		ID:                     "call",
+		Type:                   model.BookingTypeSameDay,
+		PriorNoticeDurationMin: 60,
+		PhoneNumber:            "555-1234",
+	}}, rules)
+
+	eventKeys := func(events []*storage.FlexStopTimeEvent) []string {
+		keys := []string{}
+		for _, e := range events {
+			keys = append(keys, fmt.Sprintf("%s/%d", e.Trip.ID, e.StopTime.StopSequence))
+		}
+		return keys
+	}
+
+	// No filter
+	events, err := reader.FlexStopTimes(storage.FlexStopTimeFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1/1", "t1/2", "t2/1", "t2/2", "t3/1"}, eventKeys(events))
+	assert.Equal(t, model.FlexStopTime{
+		TripID:              "t1",
+		StopSequence:        1,
+		LocationID:          "zone",
+		StartWindow:         "080000",
+		EndWindow:           "120000",
+		PickupType:          model.PickupDropOffPhoneAgency,
+		DropOffType:         model.PickupDropOffPhoneAgency,
+		PickupBookingRuleID: "call",
+	}, events[0].StopTime)
+	assert.Equal(t, "r", events[0].Route.ID)
+	assert.Equal(t, "s1", events[0].Trip.ServiceID)
+
+	// By location, location group or stop
+	events, err = reader.FlexStopTimes(storage.FlexStopTimeFilter{LocationIDs: []string{"zone"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1/1", "t1/2"}, eventKeys(events))
+	events, err = reader.FlexStopTimes(storage.FlexStopTimeFilter{LocationGroupIDs: []string{"group"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t2/1", "t2/2"}, eventKeys(events))
+	events, err = reader.FlexStopTimes(storage.FlexStopTimeFilter{StopIDs: []string{"b"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t3/1"}, eventKeys(events))
+	assert.Equal(t, "b", events[0].StopTime.StopID)
+	events, err = reader.FlexStopTimes(storage.FlexStopTimeFilter{
+		LocationIDs:      []string{"zone"},
+		LocationGroupIDs: []string{"group"},
+		StopIDs:          []string{"b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t1/1", "t1/2", "t2/1", "t2/2", "t3/1"}, eventKeys(events))
+
+	// By service
+	events, err = reader.FlexStopTimes(storage.FlexStopTimeFilter{
+		LocationIDs:      []string{"zone"},
+		LocationGroupIDs: []string{"group"},
+		ServiceIDs:       []string{"s2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"t2/1", "t2/2"}, eventKeys(events))
+
+	// Flex stop times aren't regular stop times
+	stopTimes, err := reader.StopTimes()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(stopTimes))
+}
+
+func testExtras(t *testing.T, sb StorageBuilder) {
+	s, err := sb()
+	require.NoError(t, err)
+	writer, err := s.GetWriter("unit-test")
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteAgency(model.Agency{
+		ID:       "agency",
+		Name:     "Agency",
+		URL:      "http://example.com/agency",
+		Timezone: "America/New_York",
+		Extras:   map[string]string{"ticket_url": "http://example.com/tickets"},
+	}))
+	require.NoError(t, writer.WriteStop(model.Stop{
+		ID:           "station",
+		Name:         "Station",
+		Lat:          1,
+		Lon:          1,
+		LocationType: model.LocationTypeStation,
+		Extras:       map[string]string{"wifi": "1"},
+	}))
+	require.NoError(t, writer.WriteStop(model.Stop{
+		ID:            "platform",
+		Name:          "Platform",
+		Lat:           1,
+		Lon:           1,
+		ParentStation: "station",
+		Extras:        map[string]string{"platform_side": "left", "wifi": "0"},
+	}))
+	require.NoError(t, writer.WriteStop(model.Stop{
+		ID:   "plain",
+		Name: "Plain",
+		Lat:  2,
+		Lon:  2,
+	}))
+	require.NoError(t, writer.WriteRoute(model.Route{
+		ID:        "route",
+		AgencyID:  "agency",
+		ShortName: "R",
+		Type:      model.RouteTypeBus,
+		Extras:    map[string]string{"route_branding": "Express"},
+	}))
+	require.NoError(t, writer.BeginTrips())
+	require.NoError(t, writer.WriteTrip(model.Trip{
+		ID:        "t1",
+		RouteID:   "route",
+		ServiceID: "weekday",
+		BlockID:   "b",
+	}))
+	require.NoError(t, writer.WriteTrip(model.Trip{
+		ID:        "t2",
+		RouteID:   "route",
+		ServiceID: "weekday",
+		BlockID:   "b",
+		Extras:    map[string]string{"vehicle_type": "articulated"},
+	}))
+	require.NoError(t, writer.EndTrips())
+	require.NoError(t, writer.BeginStopTimes())
+	for _, st := range []model.StopTime{
+		{TripID: "t1", StopID: "platform", StopSequence: 1, Arrival: "120000", Departure: "120000"},
+		{TripID: "t1", StopID: "plain", StopSequence: 2, Arrival: "121000", Departure: "121000"},
+		{TripID: "t2", StopID: "plain", StopSequence: 1, Arrival: "130000", Departure: "130000"},
+		{TripID: "t2", StopID: "platform", StopSequence: 2, Arrival: "131000", Departure: "131000"},
+	} {
+		require.NoError(t, writer.WriteStopTime(st))
+	}
+	require.NoError(t, writer.EndStopTimes())
+	require.NoError(t, writer.WriteCalendar(model.Calendar{
+		ServiceID: "weekday",
+		StartDate: "20200101",
+		EndDate:   "20201231",
+		Weekday:   0x7f,
+	}))
+	require.NoError(t, writer.Close())
+
+	reader, err := s.GetReader("unit-test")
+	require.NoError(t, err)
+
+	agencies, err := reader.Agencies()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(agencies))
+	assert.Equal(t, map[string]string{"ticket_url": "http://example.com/tickets"}, agencies[0].Extras)
+
+	// Records without extras have none
+	stops, err := reader.Stops()
+	require.NoError(t, err)
+	extras := map[string]map[string]string{}
+	for _, stop := range stops {
+		extras[stop.ID] = stop.Extras
+	}
+	assert.Equal(t, map[string]map[string]string{
+		"station":  {"wifi": "1"},
+		"platform": {"platform_side": "left", "wifi": "0"},
+		"plain":    nil,
+	}, extras)
+
+	stop, err := reader.Stop("platform")
+	require.NoError(t, err)
+	require.NotNil(t, stop)
+	assert.Equal(t, map[string]string{"platform_side": "left", "wifi": "0"}, stop.Extras)
+
+	nearby, err := reader.NearbyStops(1, 1, 1, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(nearby))
+	assert.Equal(t, map[string]string{"wifi": "1"}, nearby[0].Extras)
+
+	routes, err := reader.Routes()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(routes))
+	assert.Equal(t, map[string]string{"route_branding": "Express"}, routes[0].Extras)
+
+	routes, err = reader.StopRoutes("plain")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(routes))
+	assert.Equal(t, map[string]string{"route_branding": "Express"}, routes[0].Extras)
+
+	trips, err := reader.Trips()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(trips))
+	sort.Slice(trips, func(i, j int) bool { return trips[i].ID < trips[j].ID })
+	assert.Nil(t, trips[0].Extras)
+	assert.Equal(t, map[string]string{"vehicle_type": "articulated"}, trips[1].Extras)
+
+	next, err := reader.NextTripInBlock("t1", []string{"weekday"})
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "t2", next.ID)
+	assert.Equal(t, map[string]string{"vehicle_type": "articulated"}, next.Extras)
+
+	events, err := reader.StopTimeEvents(storage.StopTimeEventFilter{
+		ServiceIDs:   []string{"weekday"},
+		StopID:       "platform",
+		ArrivalStart: "125000",
+		ArrivalEnd:   "132000",
+		DirectionID:  -1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, map[string]string{"platform_side": "left", "wifi": "0"}, events[0].Stop.Extras)
+	assert.Equal(t, map[string]string{"wifi": "1"}, events[0].ParentStation.Extras)
+	assert.Equal(t, map[string]string{"vehicle_type": "articulated"}, events[0].Trip.Extras)
+	assert.Equal(t, map[string]string{"route_branding": "Express"}, events[0].Route.Extras)
+}
+
+func testExtendedRouteTypes(t *testing.T, sb StorageBuilder) {
+	reader := readerFromFiles(t, sb, map[string][]string{
+		"calendar.txt": {
+			"service_id,start_date,end_date",
+			"weekday,20200101,20201231",
+		},
+		"routes.txt": {
+			"route_id,route_short_name,route_type",
+			"bus,Bus,3",
+			"express,Express,702",
+			"suburban,Suburban,109",
+			"regional,Regional,106",
+			"s_bahn,S-Bahn,300",
+			"minibus,Minibus,750",
+			"plane,Plane,1100",
+			"odd,Odd,8",
+		},
+		"trips.txt": {
+			"service_id,trip_id,route_id",
+			"weekday,t_bus,bus",
+			"weekday,t_express,express",
+			"weekday,t_suburban,suburban",
+			"weekday,t_regional,regional",
+			"weekday,t_s_bahn,s_bahn",
+			"weekday,t_minibus,minibus",
+			"weekday,t_plane,plane",
+			"weekday,t_odd,odd",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"street,Street,1,1",
+			"station,Station,2,2",
+			"airport,Airport,3,3",
+		},
+		"stop_times.txt": {
+			"trip_id,stop_id,stop_sequence,arrival_time,departure_time",
+			"t_bus,street,1,10:00:00,10:00:00",
+			"t_express,street,1,10:10:00,10:10:00",
+			"t_suburban,station,1,10:20:00,10:20:00",
+			"t_regional,station,1,10:30:00,10:30:00",
+			"t_s_bahn,station,1,10:35:00,10:35:00",
+			"t_minibus,street,1,10:15:00,10:15:00",
+			"t_plane,airport,1,10:40:00,10:40:00",
+			"t_odd,airport,1,10:50:00,10:50:00",
+		},
+	})
+
+	// Extended route types are stored as is
+	routes, err := reader.Routes()
+	require.NoError(t, err)
+	types := map[string]model.RouteType{}
+	for _, r := range routes {
+		types[r.ID] = r.Type
+	}
+	assert.Equal(t, map[string]model.RouteType{
+		"bus":      model.RouteTypeBus,
+		"express":  702,
+		"suburban": 109,
+		"regional": 106,
+		"s_bahn":   300,
+		"minibus":  750,
+		"plane":    model.RouteTypeAirService,
+		"odd":      8,
+	}, types)
+
+	tripsOfTypes := func(routeTypes ...model.RouteType) []string {
+		events, err := reader.StopTimeEvents(storage.StopTimeEventFilter{
+			ServiceIDs:  []string{"weekday"},
+			RouteTypes:  routeTypes,
+			DirectionID: -1,
+		})
+		require.NoError(t, err)
+		trips := []string{}
+		for _, e := range events {
+			trips = append(trips, e.Trip.ID)
+		}
+		sort.Strings(trips)
+		return trips
+	}
+
+	// Basic route types match extended types in their category
+	assert.Equal(t, []string{"t_bus", "t_express", "t_minibus"}, tripsOfTypes(model.RouteTypeBus))
+	assert.Equal(t, []string{"t_regional", "t_s_bahn", "t_suburban"}, tripsOfTypes(model.RouteTypeRail))
+	assert.Equal(t, []string{}, tripsOfTypes(model.RouteTypeTram))
+
+	// Extended route types only match themselves
+	assert.Equal(t, []string{"t_suburban"}, tripsOfTypes(109))
+	assert.Equal(t, []string{"t_plane"}, tripsOfTypes(model.RouteTypeAirService))
+	assert.Equal(t, []string{"t_express", "t_suburban"}, tripsOfTypes(702, 109))
+
+	// As do unknown route types
+	assert.Equal(t, []string{"t_odd"}, tripsOfTypes(8))
+
+	stops, err := reader.NearbyStops(0, 0, 0, []model.RouteType{model.RouteTypeRail})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stops))
+	assert.Equal(t, "station", stops[0].ID)
+
+	stops, err = reader.NearbyStops(0, 0, 0, []model.RouteType{model.RouteTypeBus, model.RouteTypeAirService})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(stops))
+	assert.Equal(t, "street", stops[0].ID)
+	assert.Equal(t, "airport", stops[1].ID)
+
+	// Mapping between basic and extended route types
+	basic, ok := model.RouteType(109).Basic()
+	assert.True(t, ok)
+	assert.Equal(t, model.RouteType(model.RouteTypeRail), basic)
+	basic, ok = model.RouteType(model.RouteTypeFerry).Basic()
+	assert.True(t, ok)
+	assert.Equal(t, model.RouteType(model.RouteTypeFerry), basic)
+	_, ok = model.RouteTypeTaxiService.Basic()
+	assert.False(t, ok)
+	basic, ok = model.RouteType(1021).Basic()
+	assert.True(t, ok)
+	assert.Equal(t, model.RouteType(model.RouteTypeFerry), basic)
+	_, ok = model.RouteType(42).Basic()
+	assert.False(t, ok)
+	assert.Equal(t, []model.RouteTypeRange{
+		{First: 1000, Last: 1099},
+		{First: 1200, Last: 1299},
+	}, model.RouteType(model.RouteTypeFerry).Extended())
+	assert.Equal(t, []model.RouteTypeRange{}, model.RouteTypeBusService.Extended())
+}
+
 func TestStorage(t *testing.T) {
 	for _, test := range []struct {
 		Name string
@@ -2593,6 +3718,16 @@ func TestStorage(t *testing.T) {
 		{"StopTimeEvent_AllTheFields", testStopTimeEvent_AllTheFields},
 		{"StopTimeEvent_ParentStations", testStopTimeEvent_ParentStations},
 		{"RouteDirections", testRouteDirections},
+		{"Shapes", testShapes},
+		{"Transfers", testTransfers},
+		{"Pathways", testPathways},
+		{"Translations", testTranslations},
+		{"Extras", testExtras},
+		{"ExtendedRouteTypes", testExtendedRouteTypes},
+		{"WheelchairAccessibility", testWheelchairAccessibility},
+		{"NextTripInBlock", testNextTripInBlock},
+		{"StopRoutes", testStopRoutes},
+		{"FlexStopTimes", testFlexStopTimes},
 		{"NearbyStops", testNearbyStops},
 		{"NearbyStopsWithParentStations", testNearbyStopsWithParentStations},
 		{"NearbyStopsWithRouteTypeFiltering", testNearbyStopsWithRouteTypeFiltering},
@@ -2624,3 +3759,325 @@ func TestStorage(t *testing.T) {
 		}
 	}
 }
+
+// Tables created by earlier versions, lacking columns added since,
+// are brought up to date.
+func TestPSQLStorageExistingTables(t *testing.T) {
+	if testutil.PostgresConnStr == "" {
+		t.Skip("GTFS_TEST_POSTGRES not set")
+	}
+
+	db, err := sql.Open("postgres", testutil.PostgresConnStr)
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`
+DROP TABLE IF EXISTS feed;
+DROP TABLE IF EXISTS feed_request;
+DROP TABLE IF EXISTS feed_consumer;
+DROP TABLE IF EXISTS agency;
+DROP TABLE IF EXISTS stops;
+DROP TABLE IF EXISTS routes;
+DROP TABLE IF EXISTS trips;
+DROP TABLE IF EXISTS stop_times;
+
+CREATE TABLE feed (
+    hash TEXT,
+    url TEXT NOT NULL,
+    retrieved_at TIMESTAMPTZ NOT NULL,
+    calendar_start TEXT NOT NULL,
+    calendar_end TEXT NOT NULL,
+    timezone TEXT NOT NULL,
+    max_arrival TEXT NOT NULL,
+    max_departure TEXT NOT NULL,
+    PRIMARY KEY (hash, url)
+);
+CREATE TABLE feed_request (
+    url TEXT NOT NULL,
+    refreshed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (url)
+);
+CREATE TABLE feed_consumer (
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    headers TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (name, url)
+);
+CREATE TABLE agency (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    timezone TEXT NOT NULL,
+    PRIMARY KEY(hash, id)
+);
+CREATE TABLE stops (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    code TEXT,
+    name TEXT NOT NULL,
+    description TEXT,
+    lat DOUBLE PRECISION NOT NULL,
+    lon DOUBLE PRECISION NOT NULL,
+    url TEXT,
+    location_type INTEGER NOT NULL,
+    parent_station TEXT,
+    platform_code TEXT,
+    PRIMARY KEY(hash, id)
+);
+CREATE TABLE routes (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    agency_id TEXT,
+    short_name TEXT,
+    long_name TEXT NOT NULL,
+    description TEXT,
+    type INTEGER NOT NULL,
+    url TEXT,
+    color TEXT,
+    text_color TEXT,
+    PRIMARY KEY(hash, id)
+);
+CREATE TABLE trips (
+    hash TEXT NOT NULL,
+    id TEXT NOT NULL,
+    route_id TEXT NOT NULL,
+    service_id TEXT NOT NULL,
+    headsign TEXT,
+    short_name TEXT,
+    direction_id INTEGER,
+    PRIMARY KEY(hash, id)
+);
+CREATE TABLE stop_times (
+    hash TEXT NOT NULL,
+    trip_id TEXT NOT NULL,
+    stop_id TEXT NOT NULL,
+    stop_sequence INTEGER NOT NULL,
+    arrival_time TEXT NOT NULL,
+    departure_time TEXT NOT NULL,
+    headsign TEXT,
+    PRIMARY KEY(hash, trip_id, stop_id, stop_sequence)
+);
+
+INSERT INTO feed_request (url, refreshed_at) VALUES ('http://old', '2020-01-02T00:00:00Z');
+INSERT INTO agency (hash, id, name, url, timezone) VALUES ('old', 'a', 'A', 'http://a', 'UTC');
+INSERT INTO stops (hash, id, name, lat, lon, location_type) VALUES ('old', 's', 'S', 1, 2, 0);
+INSERT INTO trips (hash, id, route_id, service_id, direction_id) VALUES ('old', 't', 'r', 'weekday', 0);
+`)
+	require.NoError(t, err)
+
+	s, err := storage.NewPSQLStorage(testutil.PostgresConnStr, false)
+	require.NoError(t, err)
+
+	// Existing records read with defaults for new columns
+	requests, err := s.ListFeedRequests("http://old")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(requests))
+	assert.False(t, requests[0].Lenient)
+	assert.Equal(t, "", requests[0].Encoding)
+	assert.Nil(t, requests[0].LastFailure)
+
+	reader, err := s.GetReader("old")
+	require.NoError(t, err)
+	stops, err := reader.Stops()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stops))
+	assert.Equal(t, "S", stops[0].Name)
+	assert.Equal(t, "", stops[0].Code)
+	assert.Equal(t, "", stops[0].ZoneID)
+	assert.Equal(t, model.WheelchairNoInfo, stops[0].WheelchairBoarding)
+	assert.Nil(t, stops[0].Extras)
+	agencies, err := reader.Agencies()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(agencies))
+	assert.Equal(t, "", agencies[0].Lang)
+	trips, err := reader.Trips()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trips))
+	assert.Equal(t, "", trips[0].Headsign)
+	assert.Equal(t, "", trips[0].BlockID)
+
+	// New records are written in full
+	writer, err := s.GetWriter("new")
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteStop(model.Stop{
+		ID:                 "s",
+		Name:               "S",
+		Lat:                1,
+		Lon:                2,
+		Timezone:           "Europe/Berlin",
+		WheelchairBoarding: model.WheelchairAccessible,
+	}))
+	require.NoError(t, writer.WriteRoute(model.Route{
+		ID:        "r",
+		ShortName: "R",
+		Type:      model.RouteTypeBus,
+	}))
+	require.NoError(t, writer.BeginTrips())
+	require.NoError(t, writer.WriteTrip(model.Trip{
+		ID:        "t",
+		RouteID:   "r",
+		ServiceID: "weekday",
+		BlockID:   "b",
+	}))
+	require.NoError(t, writer.EndTrips())
+	require.NoError(t, writer.Close())
+
+	reader, err = s.GetReader("new")
+	require.NoError(t, err)
+	stops, err = reader.Stops()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stops))
+	assert.Equal(t, "Europe/Berlin", stops[0].Timezone)
+	assert.Equal(t, model.WheelchairAccessible, stops[0].WheelchairBoarding)
+	trips, err = reader.Trips()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trips))
+	assert.Equal(t, "b", trips[0].BlockID)
+}
+
+func TestSQLiteStorageExistingDatabase(t *testing.T) {
+	dir := t.TempDir()
+
+	// Databases as written before columns and tables were added
+	db, err := sql.Open("sqlite3", dir+"/gtfs.db")
+	require.NoError(t, err)
+	_, err = db.Exec(`
+CREATE TABLE feed (
+    hash TEXT,
+    url TEXT NOT NULL,
+    retrieved_at TIMESTAMP NOT NULL,
+    calendar_start TEXT NOT NULL,
+    calendar_end TEXT NOT NULL,
+    timezone TEXT NOT NULL,
+    max_arrival TEXT NOT NULL,
+    max_departure TEXT NOT NULL,
+PRIMARY KEY (hash, url)
+);
+CREATE TABLE feed_request (
+    url TEXT NOT NULL,
+    refreshed_at TIMESTAMP NOT NULL,
+PRIMARY KEY (url)
+);
+INSERT INTO feed VALUES ('old', 'http://old', '2020-01-02 00:00:00', '20200101', '20201231', 'UTC', '120000', '120000');
+INSERT INTO feed_request VALUES ('http://old', '2020-01-02 00:00:00');
+`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = sql.Open("sqlite3", dir+"/old.db")
+	require.NoError(t, err)
+	_, err = db.Exec(`
+CREATE TABLE agency (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    timezone TEXT NOT NULL
+);
+CREATE TABLE stops (
+    id TEXT PRIMARY KEY,
+    code TEXT,
+    name TEXT NOT NULL,
+    desc TEXT,
+    lat REAL NOT NULL,
+    lon REAL NOT NULL,
+    url TEXT,
+    location_type INTEGER NOT NULL,
+    parent_station TEXT,
+    platform_code TEXT
+);
+CREATE TABLE routes (
+    id TEXT PRIMARY KEY,
+    agency_id TEXT,
+    short_name TEXT,
+    long_name TEXT NOT NULL,
+    desc TEXT,
+    type INTEGER NOT NULL,
+    url TEXT,
+    color TEXT,
+    text_color TEXT
+);
+CREATE TABLE trips (
+    id TEXT PRIMARY KEY,
+    route_id TEXT NOT NULL,
+    service_id TEXT NOT NULL,
+    headsign TEXT,
+    short_name TEXT,
+    direction_id INTEGER
+);
+CREATE TABLE stop_times (
+    trip_id TEXT NOT NULL,
+    stop_id TEXT NOT NULL,
+    stop_sequence INTEGER NOT NULL,
+    arrival_time TEXT NOT NULL,
+    departure_time TEXT NOT NULL,
+    headsign TEXT
+);
+INSERT INTO agency VALUES ('a', 'A', 'http://a', 'UTC');
+INSERT INTO stops VALUES ('s', '', 'S', '', 1, 2, '', 0, '', '');
+INSERT INTO routes VALUES ('r', 'a', 'R', '', '', 3, '', '', '');
+INSERT INTO trips VALUES ('t', 'r', 'weekday', '', '', 0);
+INSERT INTO stop_times VALUES ('t', 's', 1, '120000', '120000', '');
+`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	s, err := storage.NewSQLiteStorage(storage.SQLiteConfig{OnDisk: true, Directory: dir})
+	require.NoError(t, err)
+
+	// Existing records read with defaults for new columns
+	feeds, err := s.ListFeeds(storage.ListFeedsFilter{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(feeds))
+	assert.Equal(t, "old", feeds[0].Hash)
+	assert.Equal(t, 0, feeds[0].DroppedCount)
+	requests, err := s.ListFeedRequests("http://old")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(requests))
+	assert.False(t, requests[0].Lenient)
+	assert.Nil(t, requests[0].LastFailure)
+
+	reader, err := s.GetReader("old")
+	require.NoError(t, err)
+	agencies, err := reader.Agencies()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(agencies))
+	assert.Equal(t, "", agencies[0].Lang)
+	stops, err := reader.Stops()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stops))
+	assert.Equal(t, "", stops[0].Timezone)
+	assert.Nil(t, stops[0].Extras)
+	routes, err := reader.Routes()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(routes))
+	assert.False(t, routes[0].HasSortOrder)
+	trips, err := reader.Trips()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(trips))
+	assert.Equal(t, "", trips[0].BlockID)
+	stopTimes, err := reader.StopTimes()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stopTimes))
+	assert.False(t, stopTimes[0].HasShapeDistTraveled)
+
+	// Tables added since are empty
+	calendars, err := reader.Calendars()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(calendars))
+	rules, err := reader.FareLegRules()
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(rules))
+
+	// New requests are written in full
+	require.NoError(t, s.WriteFeedRequest(storage.FeedRequest{
+		URL:     "http://new",
+		Lenient: true,
+	}))
+	requests, err = s.ListFeedRequests("http://new")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(requests))
+	assert.True(t, requests[0].Lenient)
+}
@@ -1,7 +1,12 @@
 package storage
 
 import (
+	"encoding/json"
+	"fmt"
 	"math"
+	"strings"
+
+	"tidbyt.dev/gtfs/model"
 )
 
 func HaversineDistance(aLat, aLon, bLat, bLon float64) float64 {
@@ -19,3 +24,49 @@ func HaversineDistance(aLat, aLon, bLat, bLon float64) float64 {
 
 	return c * earthRadiusKm
 }
+
+// SQL condition matching routes of the given types, and the values
+// for its placeholders. Basic route types also match the extended
+// route types falling under them. Placeholders are formatted by
+// placeholder, given the index of each value.
+func routeTypeCondition(types []model.RouteType, placeholder func(i int) string) (string, []model.RouteType) {
+	values := []model.RouteType{}
+	param := func(t model.RouteType) string {
+		values = append(values, t)
+		return placeholder(len(values) - 1)
+	}
+
+	in := []string{}
+	for _, t := range types {
+		in = append(in, param(t))
+	}
+	conditions := []string{"routes.type IN (" + strings.Join(in, ", ") + ")"}
+	for _, t := range types {
+		for _, r := range t.Extended() {
+			conditions = append(conditions, "routes.type BETWEEN "+param(r.First)+" AND "+param(r.Last))
+		}
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", values
+}
+
+// Extras are stored as JSON, or as a blank string if there are none.
+func encodeExtras(extras map[string]string) string {
+	if len(extras) == 0 {
+		return ""
+	}
+	buf, _ := json.Marshal(extras)
+	return string(buf)
+}
+
+func decodeExtras(encoded string) (map[string]string, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	extras := map[string]string{}
+	err := json.Unmarshal([]byte(encoded), &extras)
+	if err != nil {
+		return nil, fmt.Errorf("decoding extras: %w", err)
+	}
+	return extras, nil
+}
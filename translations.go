@@ -0,0 +1,182 @@
+package gtfs
+
+import (
+	"fmt"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+// Translations of a single field (e.g. stop_name in stops), for a
+// set of languages.
+type translator struct {
+	// language -> record ID and sub ID -> translation
+	byRecord map[string]map[[2]string]string
+
+	// language -> original field value -> translation
+	byValue map[string]map[string]string
+}
+
+// Loads translations of a field in the given languages. No
+// translations are loaded if langs is empty.
+func (s Static) translator(tableName string, fieldName string, langs []string) (*translator, error) {
+	t := &translator{
+		byRecord: map[string]map[[2]string]string{},
+		byValue:  map[string]map[string]string{},
+	}
+	if len(langs) == 0 {
+		return t, nil
+	}
+
+	translations, err := s.Reader.Translations(storage.TranslationFilter{
+		TableName: tableName,
+		FieldName: fieldName,
+		Languages: langs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting translations: %w", err)
+	}
+
+	for _, tr := range translations {
+		if tr.RecordID != "" {
+			if t.byRecord[tr.Language] == nil {
+				t.byRecord[tr.Language] = map[[2]string]string{}
+			}
+			t.byRecord[tr.Language][[2]string{tr.RecordID, tr.RecordSubID}] = tr.Translation
+		} else {
+			if t.byValue[tr.Language] == nil {
+				t.byValue[tr.Language] = map[string]string{}
+			}
+			t.byValue[tr.Language][tr.FieldValue] = tr.Translation
+		}
+	}
+
+	return t, nil
+}
+
+// Returns the translation of a record's field value into lang, if
+// available. Translations of the specific record take precedence
+// over those keyed by field value.
+func (t *translator) lookup(lang string, recordID string, recordSubID string, value string) (string, bool) {
+	if value == "" {
+		return "", false
+	}
+	if tr, found := t.byRecord[lang][[2]string{recordID, recordSubID}]; found {
+		return tr, true
+	}
+	if tr, found := t.byValue[lang][value]; found {
+		return tr, true
+	}
+	return "", false
+}
+
+// Returns the translation of a record's field value into the first
+// of langs for which one is available. Falls back to the original
+// value.
+func (t *translator) translate(langs []string, recordID string, recordSubID string, value string) string {
+	for _, lang := range langs {
+		if tr, found := t.lookup(lang, recordID, recordSubID, value); found {
+			return tr
+		}
+	}
+	return value
+}
+
+// Translations of headsigns, which can be set on both stop_times and
+// trips.
+type headsignTranslator struct {
+	stopTimes *translator
+	trips     *translator
+}
+
+func (s Static) headsignTranslator(langs []string) (*headsignTranslator, error) {
+	stopTimes, err := s.translator("stop_times", "stop_headsign", langs)
+	if err != nil {
+		return nil, err
+	}
+	trips, err := s.translator("trips", "trip_headsign", langs)
+	if err != nil {
+		return nil, err
+	}
+	return &headsignTranslator{stopTimes: stopTimes, trips: trips}, nil
+}
+
+// True if any translations are keyed by record rather than by
+// field value.
+func (h *headsignTranslator) hasRecords() bool {
+	return len(h.stopTimes.byRecord) > 0 || len(h.trips.byRecord) > 0
+}
+
+// Translates a headsign taken from a trip, rather than from any of
+// its stop_times.
+func (h *headsignTranslator) translateTrip(langs []string, tripID string, headsign string) string {
+	return h.trips.translate(langs, tripID, "", headsign)
+}
+
+// Translates the headsign displayed at a stop_time. The headsign
+// is the stop_time's own stop_headsign if set, and the trip's
+// trip_headsign otherwise. Only translations of the field it came
+// from are considered.
+func (h *headsignTranslator) translate(langs []string, tripID string, stopSequence uint32, stopHeadsign string, tripHeadsign string) string {
+	if stopHeadsign == "" {
+		return h.translateTrip(langs, tripID, tripHeadsign)
+	}
+	return h.stopTimes.translate(langs, tripID, fmt.Sprintf("%d", stopSequence), stopHeadsign)
+}
+
+// Translates a headsign without knowing which record it came from.
+// Only translations keyed by field value apply, from either
+// stop_times or trips.
+func (h *headsignTranslator) translateValue(langs []string, headsign string) string {
+	for _, lang := range langs {
+		if tr, found := h.stopTimes.lookup(lang, "", "", headsign); found {
+			return tr
+		}
+		if tr, found := h.trips.lookup(lang, "", "", headsign); found {
+			return tr
+		}
+	}
+	return headsign
+}
+
+// Translations of route names.
+type routeNameTranslator struct {
+	shortNames *translator
+	longNames  *translator
+}
+
+func (s Static) routeNameTranslator(langs []string) (*routeNameTranslator, error) {
+	shortNames, err := s.translator("routes", "route_short_name", langs)
+	if err != nil {
+		return nil, err
+	}
+	longNames, err := s.translator("routes", "route_long_name", langs)
+	if err != nil {
+		return nil, err
+	}
+	return &routeNameTranslator{shortNames: shortNames, longNames: longNames}, nil
+}
+
+// Returns the route with its short and long names translated into
+// the first of langs for which a translation is available.
+func (r *routeNameTranslator) translate(langs []string, route model.Route) model.Route {
+	route.ShortName = r.shortNames.translate(langs, route.ID, "", route.ShortName)
+	route.LongName = r.longNames.translate(langs, route.ID, "", route.LongName)
+	return route
+}
+
+// Translates the names of stops into the first of langs for which a
+// translation is available.
+func (s Static) translateStopNames(stops []model.Stop, langs []string) ([]model.Stop, error) {
+	if len(langs) > 0 {
+		names, err := s.translator("stops", "stop_name", langs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range stops {
+			stops[i].Name = names.translate(langs, stops[i].ID, "", stops[i].Name)
+		}
+	}
+
+	return stops, nil
+}
@@ -0,0 +1,597 @@
+package validate
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tidbyt.dev/gtfs/model"
+	"tidbyt.dev/gtfs/storage"
+)
+
+// Route short names longer than this are likely to be long names.
+const maxRouteShortNameLength = 12
+
+// Maximum plausible speed in km/h by route type, as used by the
+// canonical validator. Extended route types use the limit of the
+// basic route type they fall under, and others use defaultMaxSpeed.
+var maxSpeed = map[int]float64{
+	0:  100, // Tram
+	1:  150, // Subway
+	2:  500, // Rail
+	3:  150, // Bus
+	4:  80,  // Ferry
+	5:  30,  // Cable tram
+	6:  50,  // Aerial lift
+	7:  50,  // Funicular
+	11: 150, // Trolleybus
+	12: 150, // Monorail
+}
+
+const defaultMaxSpeed = 200
+
+func (v *validator) checkAgency() error {
+	v.agencies = map[string]bool{}
+
+	missingID := []int{}
+	err := v.each("agency.txt", []string{"agency_name", "agency_url", "agency_timezone"}, func(r *record) {
+		v.agencyCount++
+
+		id := r.get("agency_id")
+		if id == "" {
+			missingID = append(missingID, r.row)
+		} else if v.agencies[id] {
+			v.notice(SeverityError, CodeDuplicateKey, r.file, r.row, "agency_id", "repeated agency_id '%s'", id)
+		}
+		v.agencies[id] = true
+
+		v.require(r, "agency_name")
+		v.require(r, "agency_url")
+		if tz := v.require(r, "agency_timezone"); tz != "" {
+			if _, err := time.LoadLocation(tz); err != nil {
+				v.notice(SeverityError, CodeInvalidTimezone, r.file, r.row, "agency_timezone", "invalid timezone '%s'", tz)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// agency_id is required when there's more than one agency.
+	if v.agencyCount > 1 {
+		for _, row := range missingID {
+			v.notice(SeverityError, CodeMissingRequiredField, "agency.txt", row, "agency_id", "agency_id required when feed has multiple agencies")
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) checkStops() error {
+	v.stops = map[string]*stopInfo{}
+	parents := map[int]string{}
+
+	err := v.each("stops.txt", []string{"stop_id"}, func(r *record) {
+		id := v.require(r, "stop_id")
+		if id == "" {
+			return
+		}
+		if v.stops[id] != nil {
+			v.notice(SeverityError, CodeDuplicateKey, r.file, r.row, "stop_id", "repeated stop_id '%s'", id)
+			return
+		}
+		stop := &stopInfo{row: r.row}
+		v.stops[id] = stop
+
+		if lt := r.get("location_type"); lt != "" {
+			n, err := strconv.Atoi(lt)
+			if err != nil {
+				v.notice(SeverityError, CodeInvalidInteger, r.file, r.row, "location_type", "invalid integer '%s'", lt)
+			} else if n < 0 || n > 4 {
+				v.notice(SeverityError, CodeUnexpectedEnumValue, r.file, r.row, "location_type", "unexpected location_type %d", n)
+			} else {
+				stop.locationType = n
+			}
+		}
+
+		// Coordinates are required for stops, stations and
+		// entrances.
+		latStr, lonStr := r.get("stop_lat"), r.get("stop_lon")
+		if stop.locationType <= 2 {
+			latStr = v.require(r, "stop_lat")
+			lonStr = v.require(r, "stop_lon")
+		}
+		if latStr != "" && lonStr != "" {
+			lat, latOK := v.parseFloat(r, "stop_lat", latStr, -90, 90)
+			lon, lonOK := v.parseFloat(r, "stop_lon", lonStr, -180, 180)
+			if latOK && lonOK {
+				stop.lat, stop.lon, stop.hasCoords = lat, lon, true
+				if lat == 0 && lon == 0 {
+					v.notice(SeverityError, CodePointNearOrigin, r.file, r.row, "stop_lat", "stop is at 0,0")
+				}
+			}
+		}
+
+		if parent := r.get("parent_station"); parent != "" {
+			parents[r.row] = parent
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := make([]int, 0, len(parents))
+	for row := range parents {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	for _, row := range rows {
+		if v.stops[parents[row]] == nil {
+			v.notice(SeverityError, CodeForeignKeyViolation, "stops.txt", row, "parent_station", "unknown parent_station '%s'", parents[row])
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) checkRoutes() error {
+	v.routes = map[string]*routeInfo{}
+
+	return v.each("routes.txt", []string{"route_id", "route_type"}, func(r *record) {
+		id := v.require(r, "route_id")
+		if id == "" {
+			return
+		}
+		if v.routes[id] != nil {
+			v.notice(SeverityError, CodeDuplicateKey, r.file, r.row, "route_id", "repeated route_id '%s'", id)
+			return
+		}
+		route := &routeInfo{row: r.row, routeType: -1}
+		v.routes[id] = route
+
+		agencyID := r.get("agency_id")
+		if agencyID == "" && v.agencyCount > 1 {
+			v.notice(SeverityError, CodeMissingRequiredField, r.file, r.row, "agency_id", "agency_id required when feed has multiple agencies")
+		} else if agencyID != "" && !v.agencies[agencyID] {
+			v.notice(SeverityError, CodeForeignKeyViolation, r.file, r.row, "agency_id", "unknown agency_id '%s'", agencyID)
+		}
+
+		shortName, longName := r.get("route_short_name"), r.get("route_long_name")
+		if shortName == "" && longName == "" {
+			v.notice(SeverityError, CodeRouteBothShortAndLongNameMissing, r.file, r.row, "route_short_name", "route has neither short nor long name")
+		}
+		if len([]rune(shortName)) > maxRouteShortNameLength {
+			v.notice(SeverityWarning, CodeRouteShortNameTooLong, r.file, r.row, "route_short_name", "short name '%s' is longer than %d characters", shortName, maxRouteShortNameLength)
+		}
+
+		if rt := v.require(r, "route_type"); rt != "" {
+			n, err := strconv.Atoi(rt)
+			if err != nil {
+				v.notice(SeverityError, CodeInvalidInteger, r.file, r.row, "route_type", "invalid integer '%s'", rt)
+			} else {
+				// Unknown route types are accepted by the
+				// parser, but won't match route type filters
+				if !model.RouteType(n).Valid() {
+					v.notice(SeverityWarning, CodeUnexpectedEnumValue, r.file, r.row, "route_type", "unexpected route_type %d", n)
+				}
+				route.routeType = n
+			}
+		}
+	})
+}
+
+func (v *validator) checkCalendar() error {
+	v.services = map[string]bool{}
+	v.calendarEnds = map[string]calendarEnd{}
+
+	days := []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+	required := append([]string{"service_id", "start_date", "end_date"}, days...)
+
+	return v.each("calendar.txt", required, func(r *record) {
+		id := v.require(r, "service_id")
+		if id == "" {
+			return
+		}
+		if v.services[id] {
+			v.notice(SeverityError, CodeDuplicateKey, r.file, r.row, "service_id", "repeated service_id '%s'", id)
+			return
+		}
+		v.services[id] = true
+
+		for _, day := range days {
+			if value := v.require(r, day); value != "" && value != "0" && value != "1" {
+				v.notice(SeverityError, CodeUnexpectedEnumValue, r.file, r.row, day, "unexpected value '%s'", value)
+			}
+		}
+
+		start, startOK := v.parseDate(r, "start_date", v.require(r, "start_date"))
+		end, endOK := v.parseDate(r, "end_date", v.require(r, "end_date"))
+		if startOK && endOK {
+			if start > end {
+				v.notice(SeverityError, CodeStartAndEndRangeOutOfOrder, r.file, r.row, "end_date", "end_date %s is before start_date %s", end, start)
+			}
+			v.calendarEnds[id] = calendarEnd{row: r.row, date: end}
+		}
+	})
+}
+
+func (v *validator) checkCalendarDates() error {
+	v.lastAdded = map[string]string{}
+	seen := map[[2]string]bool{}
+
+	err := v.each("calendar_dates.txt", []string{"service_id", "date", "exception_type"}, func(r *record) {
+		id := v.require(r, "service_id")
+		if id == "" {
+			return
+		}
+		v.services[id] = true
+
+		date, ok := v.parseDate(r, "date", v.require(r, "date"))
+		if !ok {
+			return
+		}
+		if seen[[2]string{id, date}] {
+			v.notice(SeverityError, CodeDuplicateKey, r.file, r.row, "date", "repeated date %s for service_id '%s'", date, id)
+			return
+		}
+		seen[[2]string{id, date}] = true
+
+		switch et := v.require(r, "exception_type"); et {
+		case "":
+		case "1":
+			if date > v.lastAdded[id] {
+				v.lastAdded[id] = date
+			}
+		case "2":
+		default:
+			v.notice(SeverityError, CodeUnexpectedEnumValue, r.file, r.row, "exception_type", "unexpected exception_type '%s'", et)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Services in calendar.txt have expired if they've ended, and
+	// calendar_dates.txt adds no service in the future.
+	today := v.now.Format("20060102")
+	ids := make([]string, 0, len(v.calendarEnds))
+	for id := range v.calendarEnds {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return v.calendarEnds[ids[i]].row < v.calendarEnds[ids[j]].row
+	})
+	for _, id := range ids {
+		end := v.calendarEnds[id]
+		if end.date < today && v.lastAdded[id] < today {
+			v.notice(SeverityWarning, CodeExpiredCalendar, "calendar.txt", end.row, "end_date", "service_id '%s' ended %s", id, end.date)
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) checkShapes() error {
+	v.shapes = map[string]int{}
+
+	return v.each("shapes.txt", []string{"shape_id", "shape_pt_lat", "shape_pt_lon", "shape_pt_sequence"}, func(r *record) {
+		id := v.require(r, "shape_id")
+		if id == "" {
+			return
+		}
+		if _, found := v.shapes[id]; !found {
+			v.shapes[id] = r.row
+		}
+
+		v.parseFloat(r, "shape_pt_lat", v.require(r, "shape_pt_lat"), -90, 90)
+		v.parseFloat(r, "shape_pt_lon", v.require(r, "shape_pt_lon"), -180, 180)
+		v.parseUint(r, "shape_pt_sequence", v.require(r, "shape_pt_sequence"))
+	})
+}
+
+func (v *validator) checkTrips() error {
+	v.trips = map[string]*tripInfo{}
+	usedShapes := map[string]bool{}
+
+	err := v.each("trips.txt", []string{"route_id", "service_id", "trip_id"}, func(r *record) {
+		id := v.require(r, "trip_id")
+		if id == "" {
+			return
+		}
+		if v.trips[id] != nil {
+			v.notice(SeverityError, CodeDuplicateKey, r.file, r.row, "trip_id", "repeated trip_id '%s'", id)
+			return
+		}
+		trip := &tripInfo{row: r.row}
+		v.trips[id] = trip
+
+		if routeID := v.require(r, "route_id"); routeID != "" {
+			if route := v.routes[routeID]; route == nil {
+				v.notice(SeverityError, CodeForeignKeyViolation, r.file, r.row, "route_id", "unknown route_id '%s'", routeID)
+			} else {
+				route.used = true
+				trip.routeID = routeID
+			}
+		}
+
+		if serviceID := v.require(r, "service_id"); serviceID != "" && !v.services[serviceID] {
+			v.notice(SeverityError, CodeForeignKeyViolation, r.file, r.row, "service_id", "unknown service_id '%s'", serviceID)
+		}
+
+		if shapeID := r.get("shape_id"); shapeID != "" {
+			if _, found := v.shapes[shapeID]; !found {
+				v.notice(SeverityWarning, CodeForeignKeyViolation, r.file, r.row, "shape_id", "unknown shape_id '%s' is ignored", shapeID)
+			}
+			usedShapes[shapeID] = true
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(v.shapes))
+	for id := range v.shapes {
+		if !usedShapes[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return v.shapes[ids[i]] < v.shapes[ids[j]] })
+	for _, id := range ids {
+		v.notice(SeverityWarning, CodeUnusedShape, "shapes.txt", v.shapes[id], "shape_id", "shape_id '%s' is not used by any trip", id)
+	}
+
+	return nil
+}
+
+// A stop_times.txt row. Times are in seconds, -1 when not given.
+type stopTimeInfo struct {
+	row       int
+	seq       uint64
+	stopID    string
+	arrival   int
+	departure int
+}
+
+func (v *validator) checkStopTimes() error {
+	v.usedStops = map[string]bool{}
+	byTrip := map[string][]stopTimeInfo{}
+	flexRows := map[string]int{}
+
+	err := v.each("stop_times.txt", []string{"trip_id", "stop_sequence"}, func(r *record) {
+		tripID := v.require(r, "trip_id")
+		if tripID != "" && v.trips[tripID] == nil {
+			v.notice(SeverityError, CodeForeignKeyViolation, r.file, r.row, "trip_id", "unknown trip_id '%s'", tripID)
+			tripID = ""
+		}
+
+		seq, seqOK := v.parseUint(r, "stop_sequence", v.require(r, "stop_sequence"))
+
+		// GTFS-Flex rows serve locations rather than stops,
+		// and have windows instead of times.
+		if r.get("location_id") != "" || r.get("location_group_id") != "" {
+			flexRows[tripID]++
+			return
+		}
+
+		st := stopTimeInfo{row: r.row, seq: seq, arrival: -1, departure: -1}
+		if st.stopID = v.require(r, "stop_id"); st.stopID != "" {
+			if v.stops[st.stopID] == nil {
+				v.notice(SeverityError, CodeForeignKeyViolation, r.file, r.row, "stop_id", "unknown stop_id '%s'", st.stopID)
+				st.stopID = ""
+			} else {
+				v.usedStops[st.stopID] = true
+			}
+		}
+
+		// GTFS-Flex rows can also serve a single stop, with a
+		// window instead of times.
+		if r.get("start_pickup_drop_off_window") != "" || r.get("end_pickup_drop_off_window") != "" {
+			flexRows[tripID]++
+			return
+		}
+
+		st.arrival = v.parseTime(r, "arrival_time")
+		st.departure = v.parseTime(r, "departure_time")
+		if st.arrival == -1 {
+			st.arrival = st.departure
+		}
+		if st.departure == -1 {
+			st.departure = st.arrival
+		}
+		if st.departure < st.arrival {
+			v.notice(SeverityError, CodeStopTimeWithDepartureBeforeArrivalTime, r.file, r.row, "departure_time", "departure_time is before arrival_time")
+		}
+		if r.get("timepoint") == "1" && st.arrival == -1 {
+			v.notice(SeverityError, CodeStopTimeTimepointWithoutTimes, r.file, r.row, "arrival_time", "timepoint without arrival_time or departure_time")
+		}
+
+		if tripID != "" && seqOK {
+			byTrip[tripID] = append(byTrip[tripID], st)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	tripIDs := make([]string, 0, len(v.trips))
+	for id := range v.trips {
+		tripIDs = append(tripIDs, id)
+	}
+	sort.Slice(tripIDs, func(i, j int) bool { return v.trips[tripIDs[i]].row < v.trips[tripIDs[j]].row })
+
+	for _, tripID := range tripIDs {
+		trip := v.trips[tripID]
+		sts := byTrip[tripID]
+		if len(sts)+flexRows[tripID] < 2 {
+			v.notice(SeverityWarning, CodeUnusableTrip, "trips.txt", trip.row, "trip_id", "trip_id '%s' has fewer than two stop times", tripID)
+		}
+		if len(sts) == 0 {
+			continue
+		}
+
+		sort.SliceStable(sts, func(i, j int) bool { return sts[i].seq < sts[j].seq })
+
+		edges := []stopTimeInfo{sts[0]}
+		if len(sts) > 1 {
+			edges = append(edges, sts[len(sts)-1])
+		}
+		for _, edge := range edges {
+			if edge.arrival == -1 {
+				v.notice(SeverityError, CodeMissingTripEdge, "stop_times.txt", edge.row, "arrival_time", "first and last stop of trip_id '%s' must have times", tripID)
+			}
+		}
+
+		routeType := -1
+		if route := v.routes[trip.routeID]; route != nil {
+			routeType = route.routeType
+		}
+		if basic, ok := model.RouteType(routeType).Basic(); ok {
+			routeType = int(basic)
+		}
+		speedLimit, found := maxSpeed[routeType]
+		if !found {
+			speedLimit = defaultMaxSpeed
+		}
+
+		prevTimed := -1
+		for i, st := range sts {
+			if i > 0 && st.seq == sts[i-1].seq {
+				v.notice(SeverityError, CodeDuplicateKey, "stop_times.txt", st.row, "stop_sequence", "repeated stop_sequence %d for trip_id '%s'", st.seq, tripID)
+				continue
+			}
+			if st.arrival == -1 {
+				continue
+			}
+
+			if prevTimed >= 0 {
+				prev := sts[prevTimed]
+				if st.arrival < prev.departure {
+					v.notice(SeverityError, CodeStopTimeWithArrivalBeforePreviousDepartureTime, "stop_times.txt", st.row, "arrival_time", "arrival_time is before departure_time of previous stop")
+				} else if prevTimed == i-1 {
+					v.checkSpeed(prev, st, speedLimit)
+				}
+			}
+			prevTimed = i
+		}
+	}
+
+	return nil
+}
+
+// Flags travel between consecutive stops faster than the limit, in
+// km/h. Times are often rounded to the minute, so travel takes at
+// least a minute.
+func (v *validator) checkSpeed(from stopTimeInfo, to stopTimeInfo, limit float64) {
+	a, b := v.stops[from.stopID], v.stops[to.stopID]
+	if a == nil || b == nil || !a.hasCoords || !b.hasCoords {
+		return
+	}
+
+	seconds := to.arrival - from.departure
+	if seconds < 60 {
+		seconds = 60
+	}
+	distance := storage.HaversineDistance(a.lat, a.lon, b.lat, b.lon)
+	speed := distance / (float64(seconds) / 3600)
+	if speed > limit {
+		v.notice(SeverityWarning, CodeFastTravelBetweenConsecutiveStops, "stop_times.txt", to.row, "arrival_time",
+			"travel from stop_id '%s' to '%s' at %.0f km/h", from.stopID, to.stopID, speed)
+	}
+}
+
+func (v *validator) checkUnused() error {
+	// Stops in location groups are served by GTFS-Flex trips.
+	err := v.each("location_group_stops.txt", nil, func(r *record) {
+		v.usedStops[r.get("stop_id")] = true
+	})
+	if err != nil {
+		return err
+	}
+
+	stopIDs := []string{}
+	for id, stop := range v.stops {
+		if stop.locationType == 0 && !v.usedStops[id] {
+			stopIDs = append(stopIDs, id)
+		}
+	}
+	sort.Slice(stopIDs, func(i, j int) bool { return v.stops[stopIDs[i]].row < v.stops[stopIDs[j]].row })
+	for _, id := range stopIDs {
+		v.notice(SeverityWarning, CodeStopWithoutStopTime, "stops.txt", v.stops[id].row, "stop_id", "stop_id '%s' is not served by any trip", id)
+	}
+
+	routeIDs := []string{}
+	for id, route := range v.routes {
+		if !route.used {
+			routeIDs = append(routeIDs, id)
+		}
+	}
+	sort.Slice(routeIDs, func(i, j int) bool { return v.routes[routeIDs[i]].row < v.routes[routeIDs[j]].row })
+	for _, id := range routeIDs {
+		v.notice(SeverityWarning, CodeUnusedRoute, "routes.txt", v.routes[id].row, "route_id", "route_id '%s' has no trips", id)
+	}
+
+	return nil
+}
+
+func (v *validator) parseFloat(r *record, field string, value string, min float64, max float64) (float64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		v.notice(SeverityError, CodeInvalidFloat, r.file, r.row, field, "invalid float '%s'", value)
+		return 0, false
+	}
+	if f < min || f > max {
+		v.notice(SeverityError, CodeNumberOutOfRange, r.file, r.row, field, "%s not in [%g, %g]", value, min, max)
+		return 0, false
+	}
+	return f, true
+}
+
+func (v *validator) parseUint(r *record, field string, value string) (uint64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		v.notice(SeverityError, CodeInvalidInteger, r.file, r.row, field, "invalid non-negative integer '%s'", value)
+		return 0, false
+	}
+	return n, true
+}
+
+func (v *validator) parseDate(r *record, field string, value string) (string, bool) {
+	if value == "" {
+		return "", false
+	}
+	if _, err := time.Parse("20060102", value); err != nil {
+		v.notice(SeverityError, CodeInvalidDate, r.file, r.row, field, "invalid date '%s'", value)
+		return "", false
+	}
+	return value, true
+}
+
+// Parses an H:MM:SS time into seconds. Returns -1 if the field is
+// empty or invalid.
+func (v *validator) parseTime(r *record, field string) int {
+	value := r.get(field)
+	if value == "" {
+		return -1
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) == 3 {
+		h, herr := strconv.Atoi(parts[0])
+		m, merr := strconv.Atoi(parts[1])
+		s, serr := strconv.Atoi(parts[2])
+		if herr == nil && merr == nil && serr == nil &&
+			h >= 0 && m >= 0 && m < 60 && s >= 0 && s < 60 {
+			return h*3600 + m*60 + s
+		}
+	}
+
+	v.notice(SeverityError, CodeInvalidTime, r.file, r.row, field, "invalid time '%s'", value)
+	return -1
+}
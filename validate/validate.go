@@ -0,0 +1,389 @@
+// Package validate checks static GTFS feeds for problems.
+//
+// Unlike parse.ParseStatic, which gives up on the first fatal
+// problem, validation runs every check and reports all problems
+// found as Notices. Notice codes follow those of the canonical GTFS
+// validator (https://gtfs-validator.mobilitydata.org/rules.html)
+// where one exists. Only the core files are checked; see
+// Report.HasErrors.
+package validate
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"tidbyt.dev/gtfs/parse"
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "INFO"
+	SeverityWarning Severity = "WARNING"
+	SeverityError   Severity = "ERROR"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
+
+const (
+	CodeMissingRequiredFile                            = "missing_required_file"
+	CodeMissingRequiredColumn                          = "missing_required_column"
+	CodeMissingRequiredField                           = "missing_required_field"
+	CodeUnknownFile                                    = "unknown_file"
+	CodeCSVParsingFailed                               = "csv_parsing_failed"
+	CodeInvalidRowLength                               = "invalid_row_length"
+	CodeDuplicateKey                                   = "duplicate_key"
+	CodeForeignKeyViolation                            = "foreign_key_violation"
+	CodeInvalidInteger                                 = "invalid_integer"
+	CodeInvalidFloat                                   = "invalid_float"
+	CodeInvalidDate                                    = "invalid_date"
+	CodeInvalidTime                                    = "invalid_time"
+	CodeInvalidTimezone                                = "invalid_timezone"
+	CodeNumberOutOfRange                               = "number_out_of_range"
+	CodeUnexpectedEnumValue                            = "unexpected_enum_value"
+	CodePointNearOrigin                                = "point_near_origin"
+	CodeRouteBothShortAndLongNameMissing               = "route_both_short_and_long_name_missing"
+	CodeRouteShortNameTooLong                          = "route_short_name_too_long"
+	CodeStartAndEndRangeOutOfOrder                     = "start_and_end_range_out_of_order"
+	CodeExpiredCalendar                                = "expired_calendar"
+	CodeStopTimeWithDepartureBeforeArrivalTime         = "stop_time_with_departure_before_arrival_time"
+	CodeStopTimeWithArrivalBeforePreviousDepartureTime = "stop_time_with_arrival_before_previous_departure_time"
+	CodeStopTimeTimepointWithoutTimes                  = "stop_time_timepoint_without_times"
+	CodeMissingTripEdge                                = "missing_trip_edge"
+	CodeFastTravelBetweenConsecutiveStops              = "fast_travel_between_consecutive_stops"
+	CodeUnusableTrip                                   = "unusable_trip"
+	CodeStopWithoutStopTime                            = "stop_without_stop_time"
+	CodeUnusedRoute                                    = "unused_route"
+	CodeUnusedShape                                    = "unused_shape"
+)
+
+// A problem found in a feed. File, Row and Field are set when the
+// problem can be pinned to them. Row is the 1-based row number within
+// the file, not counting the header.
+type Notice struct {
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
+	File     string   `json:"file,omitempty"`
+	Row      int      `json:"row,omitempty"`
+	Field    string   `json:"field,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (n Notice) String() string {
+	location := n.File
+	if n.Row > 0 {
+		location = fmt.Sprintf("%s:%d", location, n.Row)
+	}
+	if n.Field != "" {
+		location = fmt.Sprintf("%s %s", location, n.Field)
+	}
+	if location == "" {
+		return fmt.Sprintf("%s %s: %s", n.Severity, n.Code, n.Message)
+	}
+	return fmt.Sprintf("%s %s (%s): %s", n.Severity, n.Code, location, n.Message)
+}
+
+// The result of validating a feed. Notices are ordered by severity,
+// most severe first, then by file and row.
+type Report struct {
+	Notices []Notice `json:"notices"`
+}
+
+// Number of notices with the given severity.
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, notice := range r.Notices {
+		if notice.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// True if any notice is an error. Feeds with errors are likely to be
+// rejected by parse.ParseStatic, but the converse doesn't hold: only
+// agency.txt, stops.txt, routes.txt, calendar.txt,
+// calendar_dates.txt, shapes.txt, trips.txt and stop_times.txt are
+// checked, so a feed without errors may still be rejected over
+// frequencies, transfers, translations, fares, pathways, levels or
+// GTFS-Flex files.
+func (r *Report) HasErrors() bool {
+	return r.Count(SeverityError) > 0
+}
+
+type Options struct {
+	// Date against which calendars are checked for expiry.
+	// Defaults to time.Now().
+	Now time.Time
+
+	// Character encoding of the feed's files, as for
+	// parse.ParseStatic. Detected if not set.
+	Encoding parse.Encoding
+}
+
+// Validates a static GTFS feed in a filesystem, e.g. an unzipped feed
+// directory opened with os.DirFS(), or a *zip.Reader. Problems with
+// the feed are reported as notices. An error is only returned if the
+// filesystem can't be read.
+func Validate(fsys fs.FS, options Options) (*Report, error) {
+	v := &validator{
+		fsys:     fsys,
+		files:    map[string]string{},
+		now:      options.Now,
+		encoding: options.Encoding,
+		report:   &Report{Notices: []Notice{}},
+	}
+	if v.now.IsZero() {
+		v.now = time.Now()
+	}
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Like the parser, we tolerate subdirectories.
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		if strings.Contains(path, "__MACOSX/") {
+			return nil
+		}
+
+		if !knownFiles[d.Name()] {
+			v.notice(SeverityInfo, CodeUnknownFile, d.Name(), 0, "", "file is not part of the GTFS specification")
+			return nil
+		}
+		v.files[d.Name()] = path
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing files: %w", err)
+	}
+
+	for _, required := range []string{"agency.txt", "stops.txt", "routes.txt", "trips.txt", "stop_times.txt"} {
+		if v.files[required] == "" {
+			v.notice(SeverityError, CodeMissingRequiredFile, required, 0, "", "missing required file")
+		}
+	}
+	if v.files["calendar.txt"] == "" && v.files["calendar_dates.txt"] == "" {
+		v.notice(SeverityError, CodeMissingRequiredFile, "calendar.txt", 0, "", "missing both calendar.txt and calendar_dates.txt")
+	}
+
+	for _, check := range []func() error{
+		v.checkAgency,
+		v.checkStops,
+		v.checkRoutes,
+		v.checkCalendar,
+		v.checkCalendarDates,
+		v.checkShapes,
+		v.checkTrips,
+		v.checkStopTimes,
+		v.checkUnused,
+	} {
+		if err := check(); err != nil {
+			return nil, err
+		}
+	}
+
+	notices := v.report.Notices
+	sort.SliceStable(notices, func(i, j int) bool {
+		if notices[i].Severity.rank() != notices[j].Severity.rank() {
+			return notices[i].Severity.rank() > notices[j].Severity.rank()
+		}
+		if notices[i].File != notices[j].File {
+			return notices[i].File < notices[j].File
+		}
+		return notices[i].Row < notices[j].Row
+	})
+
+	return v.report, nil
+}
+
+// Files defined by the GTFS specification, including GTFS-Fares v2
+// and GTFS-Flex.
+var knownFiles = map[string]bool{
+	"agency.txt":               true,
+	"stops.txt":                true,
+	"routes.txt":               true,
+	"trips.txt":                true,
+	"stop_times.txt":           true,
+	"calendar.txt":             true,
+	"calendar_dates.txt":       true,
+	"fare_attributes.txt":      true,
+	"fare_rules.txt":           true,
+	"timeframes.txt":           true,
+	"rider_categories.txt":     true,
+	"fare_media.txt":           true,
+	"fare_products.txt":        true,
+	"fare_leg_rules.txt":       true,
+	"fare_leg_join_rules.txt":  true,
+	"fare_transfer_rules.txt":  true,
+	"areas.txt":                true,
+	"stop_areas.txt":           true,
+	"networks.txt":             true,
+	"route_networks.txt":       true,
+	"shapes.txt":               true,
+	"frequencies.txt":          true,
+	"transfers.txt":            true,
+	"pathways.txt":             true,
+	"levels.txt":               true,
+	"location_groups.txt":      true,
+	"location_group_stops.txt": true,
+	"locations.geojson":        true,
+	"booking_rules.txt":        true,
+	"translations.txt":         true,
+	"feed_info.txt":            true,
+	"attributions.txt":         true,
+}
+
+type validator struct {
+	fsys     fs.FS
+	files    map[string]string
+	now      time.Time
+	encoding parse.Encoding
+	report   *Report
+
+	agencies     map[string]bool
+	agencyCount  int
+	stops        map[string]*stopInfo
+	routes       map[string]*routeInfo
+	services     map[string]bool
+	calendarEnds map[string]calendarEnd
+	lastAdded    map[string]string
+	shapes       map[string]int
+	trips        map[string]*tripInfo
+	usedStops    map[string]bool
+}
+
+type stopInfo struct {
+	row          int
+	locationType int
+	lat, lon     float64
+	hasCoords    bool
+}
+
+type routeInfo struct {
+	row       int
+	routeType int
+	used      bool
+}
+
+type calendarEnd struct {
+	row  int
+	date string
+}
+
+type tripInfo struct {
+	row     int
+	routeID string
+}
+
+func (v *validator) notice(severity Severity, code string, file string, row int, field string, format string, args ...interface{}) {
+	v.report.Notices = append(v.report.Notices, Notice{
+		Code:     code,
+		Severity: severity,
+		File:     file,
+		Row:      row,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// A row in a CSV file.
+type record struct {
+	file   string
+	row    int
+	header map[string]int
+	fields []string
+}
+
+// Value of a field, or "" if the column is missing.
+func (r *record) get(field string) string {
+	i, found := r.header[field]
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(r.fields[i])
+}
+
+// Value of a required field. Empty values are reported, unless the
+// column is missing entirely, which has already been reported.
+func (v *validator) require(r *record, field string) string {
+	value := r.get(field)
+	if _, found := r.header[field]; found && value == "" {
+		v.notice(SeverityError, CodeMissingRequiredField, r.file, r.row, field, "missing required field")
+	}
+	return value
+}
+
+// Calls fn for each row of a CSV file, if present. Missing required
+// columns and malformed rows are reported.
+func (v *validator) each(file string, required []string, fn func(r *record)) error {
+	path := v.files[file]
+	if path == "" {
+		return nil
+	}
+
+	f, err := v.fsys.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(parse.NewDecoder(f, v.encoding))
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	columns, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		v.notice(SeverityError, CodeCSVParsingFailed, file, 0, "", "file is empty")
+		return nil
+	}
+	if err != nil {
+		v.notice(SeverityError, CodeCSVParsingFailed, file, 0, "", "reading header: %s", err)
+		return nil
+	}
+
+	header := map[string]int{}
+	for i, column := range columns {
+		header[strings.TrimSpace(column)] = i
+	}
+	for _, column := range required {
+		if _, found := header[column]; !found {
+			v.notice(SeverityError, CodeMissingRequiredColumn, file, 0, column, "missing required column")
+		}
+	}
+
+	for row := 1; ; row++ {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			v.notice(SeverityError, CodeCSVParsingFailed, file, row, "", "%s", err)
+			break
+		}
+		if len(fields) != len(columns) {
+			v.notice(SeverityError, CodeInvalidRowLength, file, row, "", "found %d fields, header has %d", len(fields), len(columns))
+			continue
+		}
+		fn(&record{file: file, row: row, header: header, fields: fields})
+	}
+
+	return nil
+}
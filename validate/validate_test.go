@@ -0,0 +1,311 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/parse"
+)
+
+func validFeed() map[string][]string {
+	return map[string][]string{
+		"agency.txt": {
+			"agency_timezone,agency_name,agency_url",
+			"America/Los_Angeles,Fake Agency,http://agency/index.html",
+		},
+		"routes.txt": {
+			"route_id,route_short_name,route_type",
+			"r,R,3",
+		},
+		"calendar.txt": {
+			"service_id,monday,tuesday,wednesday,thursday,friday,saturday,sunday,start_date,end_date",
+			"mondays,1,0,0,0,0,0,0,20190101,20190301",
+		},
+		"trips.txt": {
+			"route_id,service_id,trip_id",
+			"r,mondays,t",
+		},
+		"stops.txt": {
+			"stop_id,stop_name,stop_lat,stop_lon",
+			"s1,S1,40.70,-74.00",
+			"s2,S2,40.71,-74.00",
+		},
+		"stop_times.txt": {
+			"trip_id,arrival_time,departure_time,stop_id,stop_sequence",
+			"t,12:00:00,12:00:00,s1,1",
+			"t,12:05:00,12:05:00,s2,2",
+		},
+	}
+}
+
+type expectedNotice struct {
+	Code  string
+	File  string
+	Row   int
+	Field string
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		modify   func(files map[string][]string)
+		expected []expectedNotice
+	}{
+		{
+			"valid",
+			func(files map[string][]string) {},
+			[]expectedNotice{},
+		},
+		{
+			"missing files",
+			func(files map[string][]string) {
+				delete(files, "stops.txt")
+				delete(files, "calendar.txt")
+				files["notes.txt"] = []string{"hello"}
+			},
+			[]expectedNotice{
+				{CodeMissingRequiredFile, "calendar.txt", 0, ""},
+				{CodeForeignKeyViolation, "stop_times.txt", 1, "stop_id"},
+				{CodeForeignKeyViolation, "stop_times.txt", 2, "stop_id"},
+				{CodeMissingRequiredFile, "stops.txt", 0, ""},
+				{CodeForeignKeyViolation, "trips.txt", 1, "service_id"},
+				{CodeUnknownFile, "notes.txt", 0, ""},
+			},
+		},
+		{
+			"missing column and field",
+			func(files map[string][]string) {
+				files["agency.txt"] = []string{
+					"agency_name,agency_url",
+					"Fake Agency,",
+				}
+			},
+			[]expectedNotice{
+				{CodeMissingRequiredColumn, "agency.txt", 0, "agency_timezone"},
+				{CodeMissingRequiredField, "agency.txt", 1, "agency_url"},
+			},
+		},
+		{
+			"malformed rows",
+			func(files map[string][]string) {
+				files["stops.txt"] = append(files["stops.txt"],
+					"s3,S3,40.72",
+					"s1,S1 again,40.70,-74.00",
+					"s4,S4,x,-74.00",
+					"s5,S5,0,0",
+					"s6,S6,95,-74.00",
+				)
+			},
+			[]expectedNotice{
+				{CodeInvalidRowLength, "stops.txt", 3, ""},
+				{CodeDuplicateKey, "stops.txt", 4, "stop_id"},
+				{CodeInvalidFloat, "stops.txt", 5, "stop_lat"},
+				{CodePointNearOrigin, "stops.txt", 6, "stop_lat"},
+				{CodeNumberOutOfRange, "stops.txt", 7, "stop_lat"},
+				{CodeStopWithoutStopTime, "stops.txt", 5, "stop_id"},
+				{CodeStopWithoutStopTime, "stops.txt", 6, "stop_id"},
+				{CodeStopWithoutStopTime, "stops.txt", 7, "stop_id"},
+			},
+		},
+		{
+			"routes",
+			func(files map[string][]string) {
+				files["routes.txt"] = append(files["routes.txt"],
+					"r2,,3",
+					"r3,Very Long Short Name,3",
+					"r4,R4,42",
+					"r5,R5,715",
+				)
+			},
+			[]expectedNotice{
+				{CodeRouteBothShortAndLongNameMissing, "routes.txt", 2, "route_short_name"},
+				{CodeUnusedRoute, "routes.txt", 2, "route_id"},
+				{CodeRouteShortNameTooLong, "routes.txt", 3, "route_short_name"},
+				{CodeUnusedRoute, "routes.txt", 3, "route_id"},
+				{CodeUnexpectedEnumValue, "routes.txt", 4, "route_type"},
+				{CodeUnusedRoute, "routes.txt", 4, "route_id"},
+				{CodeUnusedRoute, "routes.txt", 5, "route_id"},
+			},
+		},
+		{
+			"calendar",
+			func(files map[string][]string) {
+				files["calendar.txt"] = append(files["calendar.txt"],
+					"old,1,1,1,1,1,0,0,20180101,20180301",
+					"backwards,1,1,1,1,1,0,0,20190301,20190101",
+					"bad,1,1,1,1,1,0,0,2019-03-01,20190101",
+				)
+				files["calendar_dates.txt"] = []string{
+					"service_id,date,exception_type",
+					"old,20180101,3",
+				}
+			},
+			[]expectedNotice{
+				{CodeStartAndEndRangeOutOfOrder, "calendar.txt", 3, "end_date"},
+				{CodeInvalidDate, "calendar.txt", 4, "start_date"},
+				{CodeUnexpectedEnumValue, "calendar_dates.txt", 1, "exception_type"},
+				{CodeExpiredCalendar, "calendar.txt", 2, "end_date"},
+				{CodeExpiredCalendar, "calendar.txt", 3, "end_date"},
+			},
+		},
+		{
+			"expired calendar extended by calendar_dates",
+			func(files map[string][]string) {
+				files["calendar.txt"][1] = "mondays,1,0,0,0,0,0,0,20190101,20190201"
+				files["calendar_dates.txt"] = []string{
+					"service_id,date,exception_type",
+					"mondays,20190304,1",
+				}
+			},
+			[]expectedNotice{},
+		},
+		{
+			"stop times",
+			func(files map[string][]string) {
+				files["trips.txt"] = append(files["trips.txt"],
+					"r,mondays,t2",
+					"r,mondays,t3",
+					"r,mondays,t4",
+				)
+				files["stop_times.txt"] = append(files["stop_times.txt"],
+					// departure before arrival, then decreasing
+					"t2,12:00:00,11:59:00,s1,1",
+					"t2,11:58:00,11:58:00,s2,2",
+					// missing last time, and bad time
+					"t3,12:00:00,12:00:00,s1,1",
+					"t3,12:5:00x,,s2,2",
+					// single stop time
+					"t4,12:00:00,12:00:00,s1,1",
+					// unknown trip and stop
+					"t5,12:00:00,12:00:00,s1,1",
+					"t,12:10:00,12:10:00,s9,3",
+				)
+			},
+			[]expectedNotice{
+				{CodeStopTimeWithDepartureBeforeArrivalTime, "stop_times.txt", 3, "departure_time"},
+				{CodeStopTimeWithArrivalBeforePreviousDepartureTime, "stop_times.txt", 4, "arrival_time"},
+				{CodeInvalidTime, "stop_times.txt", 6, "arrival_time"},
+				{CodeMissingTripEdge, "stop_times.txt", 6, "arrival_time"},
+				{CodeForeignKeyViolation, "stop_times.txt", 8, "trip_id"},
+				{CodeForeignKeyViolation, "stop_times.txt", 9, "stop_id"},
+				{CodeUnusableTrip, "trips.txt", 4, "trip_id"},
+			},
+		},
+		{
+			"flex",
+			func(files map[string][]string) {
+				files["trips.txt"] = append(files["trips.txt"], "r,mondays,t2", "r,mondays,t3")
+				files["stop_times.txt"] = []string{
+					"trip_id,arrival_time,departure_time,stop_id,location_group_id,stop_sequence,start_pickup_drop_off_window,end_pickup_drop_off_window",
+					"t,12:00:00,12:00:00,s1,,1,,",
+					"t,12:05:00,12:05:00,s2,,2,,",
+					"t2,,,s1,,1,08:00:00,18:00:00",
+					"t2,,,s2,,2,08:00:00,18:00:00",
+					"t3,,,,g,1,08:00:00,18:00:00",
+					"t3,,,,g,2,08:00:00,18:00:00",
+				}
+			},
+			[]expectedNotice{},
+		},
+		{
+			"fast travel",
+			func(files map[string][]string) {
+				// ~111 km in 5 minutes, by bus
+				files["stops.txt"][2] = "s2,S2,41.70,-74.00"
+			},
+			[]expectedNotice{
+				{CodeFastTravelBetweenConsecutiveStops, "stop_times.txt", 2, "arrival_time"},
+			},
+		},
+		{
+			"shapes",
+			func(files map[string][]string) {
+				files["shapes.txt"] = []string{
+					"shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence",
+					"a,40.70,-74.00,1",
+					"a,40.71,-74.00,2",
+					"b,40.70,-74.00,1",
+				}
+				files["trips.txt"] = []string{
+					"route_id,service_id,trip_id,shape_id",
+					"r,mondays,t,a",
+					"r,nope,t2,c",
+				}
+			},
+			[]expectedNotice{
+				{CodeForeignKeyViolation, "trips.txt", 2, "service_id"},
+				{CodeUnusedShape, "shapes.txt", 3, "shape_id"},
+				{CodeForeignKeyViolation, "trips.txt", 2, "shape_id"},
+				{CodeUnusableTrip, "trips.txt", 2, "trip_id"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files := validFeed()
+			tc.modify(files)
+
+			fsys := fstest.MapFS{}
+			for name, lines := range files {
+				fsys["feed/"+name] = &fstest.MapFile{Data: []byte(strings.Join(lines, "\n"))}
+			}
+
+			report, err := Validate(fsys, Options{Now: time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)})
+			require.NoError(t, err)
+
+			notices := []expectedNotice{}
+			for _, n := range report.Notices {
+				notices = append(notices, expectedNotice{n.Code, n.File, n.Row, n.Field})
+			}
+			assert.Equal(t, tc.expected, notices)
+		})
+	}
+}
+
+func TestValidateReport(t *testing.T) {
+	files := validFeed()
+	files["routes.txt"] = append(files["routes.txt"], "r2,R2,3")
+	files["calendar.txt"][1] = "mondays,1,0,0,0,0,0,0,20190101,20190301x"
+
+	fsys := fstest.MapFS{}
+	for name, lines := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(strings.Join(lines, "\n"))}
+	}
+
+	report, err := Validate(fsys, Options{})
+	require.NoError(t, err)
+
+	assert.True(t, report.HasErrors())
+	assert.Equal(t, 1, report.Count(SeverityError))
+	assert.Equal(t, 1, report.Count(SeverityWarning))
+	assert.Equal(t, 0, report.Count(SeverityInfo))
+	assert.Equal(t, "ERROR invalid_date (calendar.txt:1 end_date): invalid date '20190301x'", report.Notices[0].String())
+}
+
+func TestValidateEncoding(t *testing.T) {
+	files := validFeed()
+	files["stop_times.txt"] = append(files["stop_times.txt"], "t\x80,12:10:00,12:10:00,s2,3")
+
+	fsys := fstest.MapFS{}
+	for name, lines := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(strings.Join(lines, "\n"))}
+	}
+
+	// Detected as Windows-1252
+	report, err := Validate(fsys, Options{Now: time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(report.Notices))
+	assert.Equal(t, "unknown trip_id 't€'", report.Notices[0].Message)
+
+	report, err = Validate(fsys, Options{
+		Now:      time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC),
+		Encoding: parse.EncodingLatin1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(report.Notices))
+	assert.Equal(t, "unknown trip_id 't\u0080'", report.Notices[0].Message)
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"tidbyt.dev/gtfs/model"
 )
 
 // Don't love this, but some internal functions are finicky and need
@@ -183,3 +185,28 @@ func TestWhiteboxDelayFromOffsetAndTime(t *testing.T) {
 		)
 	}
 }
+
+func TestWhiteboxMatchFareLegRules(t *testing.T) {
+	rules := []model.FareLegRule{
+		{LegGroupID: "exact", NetworkID: "bus", FromAreaID: "a", FareProductID: "p1"},
+		{LegGroupID: "any", NetworkID: "bus", FareProductID: "p2"},
+	}
+
+	// Without rule_priority, blank fields only match when no rule
+	// matches explicitly
+	matched := matchFareLegRules(rules, "bus", map[string]bool{"a": true}, map[string]bool{})
+	assert.Equal(t, []model.FareLegRule{rules[0]}, matched)
+
+	// With the rule_priority column, blank fields match anything,
+	// even if every priority is 0
+	for i := range rules {
+		rules[i].HasRulePriority = true
+	}
+	matched = matchFareLegRules(rules, "bus", map[string]bool{"a": true}, map[string]bool{})
+	assert.Equal(t, rules, matched)
+
+	// And only the highest priority rules are kept
+	rules[1].RulePriority = 1
+	matched = matchFareLegRules(rules, "bus", map[string]bool{"a": true}, map[string]bool{})
+	assert.Equal(t, []model.FareLegRule{rules[1]}, matched)
+}